@@ -21,7 +21,7 @@ package main
 import (
 	"context"
 
-	"github.com/google/knative-gcp/pkg/broker/config/volume"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	"github.com/google/knative-gcp/pkg/broker/handler"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils/clients"
@@ -29,16 +29,16 @@ import (
 )
 
 // InitializeSyncPool initializes the fanout sync pool. Uses the given projectID to initialize the
-// retry pool's pubsub client and uses targetsVolumeOpts to initialize the targets volume watcher.
+// retry pool's pubsub client and uses targetsOpts to initialize the targets config watcher.
 func InitializeSyncPool(
 	ctx context.Context,
 	projectID clients.ProjectID,
 	podName metrics.PodName,
 	containerName metrics.ContainerName,
-	targetsVolumeOpts []volume.Option,
+	targetsOpts []stream.Option,
 	opts ...handler.Option,
 ) (*handler.FanoutPool, error) {
 	// Implementation generated by wire. Providers for required FanoutPool dependencies should be
 	// added here.
-	panic(wire.Build(handler.ProviderSet, volume.NewTargetsFromFile, metrics.NewDeliveryReporter))
+	panic(wire.Build(handler.ProviderSet, stream.NewTargets, metrics.NewDeliveryReporter))
 }
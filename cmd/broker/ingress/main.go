@@ -17,6 +17,13 @@ limitations under the License.
 package main
 
 import (
+	"time"
+
+	"cloud.google.com/go/pubsub"
+
+	"github.com/google/knative-gcp/pkg/broker/config/propagation"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
+	"github.com/google/knative-gcp/pkg/broker/ingress"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils"
@@ -31,6 +38,20 @@ type envConfig struct {
 	PodName   string `envconfig:"POD_NAME" required:"true"`
 	Port      int    `envconfig:"PORT" default:"8080"`
 	ProjectID string `envconfig:"PROJECT_ID"`
+
+	// TargetsConfigService is the address of the service streaming the targets config. The
+	// targets config is only loaded from the mounted broker configmap without it.
+	TargetsConfigService string `envconfig:"TARGETS_CONFIG_SERVICE"`
+	// BrokerCell is the name of the BrokerCell the pod belongs to. The service only streams
+	// the targets config of the Brokers placed on it.
+	BrokerCell string `envconfig:"BROKER_CELL"`
+
+	// Pub/Sub publish settings. Zero values use the Pub/Sub client defaults.
+	PublishDelayThreshold         time.Duration `envconfig:"PUBLISH_DELAY_THRESHOLD"`
+	PublishCountThreshold         int           `envconfig:"PUBLISH_COUNT_THRESHOLD"`
+	PublishByteThreshold          int           `envconfig:"PUBLISH_BYTE_THRESHOLD"`
+	PublishMaxOutstandingMessages int64         `envconfig:"PUBLISH_MAX_OUTSTANDING_MESSAGES"`
+	PublishMaxOutstandingBytes    int64         `envconfig:"PUBLISH_MAX_OUTSTANDING_BYTES"`
 }
 
 const (
@@ -57,12 +78,30 @@ func main() {
 	}
 	logger.Desugar().Info("Starting ingress handler", zap.Any("envConfig", env), zap.Any("Project ID", projectID))
 
+	// The applied targets config generation is reported as metrics, and on the
+	// targets config stream so that the trigger and broker reconcilers can reflect
+	// the config propagation.
+	configReporter, err := metrics.NewConfigReporter(metrics.PodName(env.PodName), metrics.ContainerName(component))
+	if err != nil {
+		logger.Desugar().Fatal("Failed to create config reporter", zap.Error(err))
+	}
+	propagationReporter := propagation.NewReporter(ctx, configReporter)
+
 	ingress, err := InitializeHandler(
 		ctx,
 		clients.Port(env.Port),
 		clients.ProjectID(projectID),
 		metrics.PodName(env.PodName),
 		metrics.ContainerName(component),
+		res.KubeClient,
+		[]stream.Option{
+			stream.WithAddress(env.TargetsConfigService),
+			stream.WithNode(env.PodName),
+			stream.WithCell(env.BrokerCell),
+			stream.WithTokenFile(stream.TokenPath),
+			stream.WithObserver(propagationReporter.Observe),
+		},
+		buildSinkOptions(env)...,
 	)
 	if err != nil {
 		logger.Desugar().Fatal("Unable to create ingress handler: ", zap.Error(err))
@@ -73,3 +112,23 @@ func main() {
 		logger.Desugar().Fatal("failed to start ingress: ", zap.Error(err))
 	}
 }
+
+func buildSinkOptions(env envConfig) []ingress.MultiTopicDecoupleSinkOption {
+	ps := pubsub.DefaultPublishSettings
+	if env.PublishDelayThreshold > 0 {
+		ps.DelayThreshold = env.PublishDelayThreshold
+	}
+	if env.PublishCountThreshold > 0 {
+		ps.CountThreshold = env.PublishCountThreshold
+	}
+	if env.PublishByteThreshold > 0 {
+		ps.ByteThreshold = env.PublishByteThreshold
+	}
+	return []ingress.MultiTopicDecoupleSinkOption{
+		ingress.WithPublishSettings(ps),
+		ingress.WithFlowControl(ingress.FlowControlSettings{
+			MaxOutstandingMessages: env.PublishMaxOutstandingMessages,
+			MaxOutstandingBytes:    env.PublishMaxOutstandingBytes,
+		}),
+	}
+}
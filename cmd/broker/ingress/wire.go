@@ -21,11 +21,12 @@ package main
 import (
 	"context"
 
-	"github.com/google/knative-gcp/pkg/broker/config/volume"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	"github.com/google/knative-gcp/pkg/broker/ingress"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"github.com/google/wire"
+	"k8s.io/client-go/kubernetes"
 )
 
 func InitializeHandler(
@@ -34,10 +35,12 @@ func InitializeHandler(
 	projectID clients.ProjectID,
 	podName metrics.PodName,
 	containerName metrics.ContainerName,
+	kubeClient kubernetes.Interface,
+	targetsOpts []stream.Option,
+	opts ...ingress.MultiTopicDecoupleSinkOption,
 ) (*ingress.Handler, error) {
 	panic(wire.Build(
 		ingress.HandlerSet,
-		wire.Value([]volume.Option(nil)),
-		volume.NewTargetsFromFile,
+		stream.NewTargets,
 	))
 }
@@ -7,18 +7,18 @@ package main
 
 import (
 	"context"
-	"github.com/google/knative-gcp/pkg/broker/config/volume"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	"github.com/google/knative-gcp/pkg/broker/ingress"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils/clients"
+	"k8s.io/client-go/kubernetes"
 )
 
 // Injectors from wire.go:
 
-func InitializeHandler(ctx context.Context, port clients.Port, projectID clients.ProjectID, podName metrics.PodName, containerName metrics.ContainerName) (*ingress.Handler, error) {
+func InitializeHandler(ctx context.Context, port clients.Port, projectID clients.ProjectID, podName metrics.PodName, containerName metrics.ContainerName, kubeClient kubernetes.Interface, targetsOpts []stream.Option, opts ...ingress.MultiTopicDecoupleSinkOption) (*ingress.Handler, error) {
 	httpMessageReceiver := clients.NewHTTPMessageReceiver(port)
-	v := _wireValue
-	readonlyTargets, err := volume.NewTargetsFromFile(v...)
+	readonlyTargets, err := stream.NewTargets(ctx, targetsOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,15 +26,13 @@ func InitializeHandler(ctx context.Context, port clients.Port, projectID clients
 	if err != nil {
 		return nil, err
 	}
-	multiTopicDecoupleSink := ingress.NewMultiTopicDecoupleSink(ctx, readonlyTargets, client)
 	ingressReporter, err := metrics.NewIngressReporter(podName, containerName)
 	if err != nil {
 		return nil, err
 	}
-	handler := ingress.NewHandler(ctx, httpMessageReceiver, multiTopicDecoupleSink, ingressReporter)
+	multiTopicDecoupleSink := ingress.NewMultiTopicDecoupleSink(ctx, readonlyTargets, client, ingressReporter, opts...)
+	tokenVerifier := ingress.NewTokenVerifier(kubeClient)
+	authenticator := ingress.NewAuthenticator(readonlyTargets, tokenVerifier)
+	handler := ingress.NewHandler(ctx, httpMessageReceiver, multiTopicDecoupleSink, authenticator, ingressReporter)
 	return handler, nil
 }
-
-var (
-	_wireValue = []volume.Option(nil)
-)
@@ -23,9 +23,15 @@ import (
 
 	"cloud.google.com/go/pubsub"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/system"
 
-	"github.com/google/knative-gcp/pkg/broker/config/volume"
+	"github.com/google/knative-gcp/pkg/broker/circuitbreaker"
+	"github.com/google/knative-gcp/pkg/broker/config/propagation"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
+	"github.com/google/knative-gcp/pkg/broker/deliveryauth"
 	"github.com/google/knative-gcp/pkg/broker/handler"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/dedup"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/utils"
@@ -46,6 +52,13 @@ type envConfig struct {
 	TargetsConfigPath  string `envconfig:"TARGETS_CONFIG_PATH" default:"/var/run/cloud-run-events/broker/targets"`
 	HandlerConcurrency int    `envconfig:"HANDLER_CONCURRENCY"`
 
+	// TargetsConfigService is the address of the service streaming the targets config. The
+	// targets config is only loaded from TargetsConfigPath without it.
+	TargetsConfigService string `envconfig:"TARGETS_CONFIG_SERVICE"`
+	// BrokerCell is the name of the BrokerCell the pod belongs to. The service only streams
+	// the targets config of the Brokers placed on it.
+	BrokerCell string `envconfig:"BROKER_CELL"`
+
 	// Outstanding messages effectively limits how many connections we will create to each subscriber.
 	// If such connections are long, it will consume a lot of memory (aggregated) without limiting.
 	OutstandingMessagesPerSub int `envconfig:"OUTSTANDING_MESSAGES_PER_SUB" default:"100"`
@@ -60,8 +73,24 @@ type envConfig struct {
 	// Max to 10m.
 	TimeoutPerEvent time.Duration `envconfig:"TIMEOUT_PER_EVENT"`
 
+	// DrainTimeout is how long the handlers wait for the events being processed to finish
+	// when they are stopped, on shutdown or targets config change. It should fit in the
+	// termination grace period of the pod.
+	DrainTimeout time.Duration `envconfig:"DRAIN_TIMEOUT" default:"25s"`
+
+	// DedupCapacity is the max number of deliveries the handlers remember to skip the
+	// redelivery of events to the targets they were already delivered to. Events are not
+	// deduplicated if zero.
+	DedupCapacity int `envconfig:"DEDUP_CAPACITY"`
+	// DedupTTL is how long the handlers remember a delivery.
+	DedupTTL time.Duration `envconfig:"DEDUP_TTL" default:"10m"`
+
 	MinRetryBackoff time.Duration `envconfig:"MIN_RETRY_BACKOFF" default:"1s"`
 	MaxRetryBackoff time.Duration `envconfig:"MAX_RETRY_BACKOFF" default:"1m"`
+
+	// ServiceAccountName is the Kubernetes service account of the pod. It issues
+	// the Kubernetes tokens that authenticate deliveries.
+	ServiceAccountName string `envconfig:"SERVICE_ACCOUNT_NAME" default:"broker"`
 }
 
 func main() {
@@ -87,17 +116,36 @@ func main() {
 		logger.Fatalf("failed to get default ProjectID: %v", err)
 	}
 
+	// Open circuit breakers are reported on the targets config stream so that
+	// the trigger reconciler can reflect them in the trigger status.
+	breakers := circuitbreaker.NewBreakers()
+
+	// The applied targets config generation is reported as metrics, and on the
+	// targets config stream so that the trigger and broker reconcilers can reflect
+	// the config propagation.
+	configReporter, err := metrics.NewConfigReporter(metrics.PodName(env.PodName), metrics.ContainerName(component))
+	if err != nil {
+		logger.Fatal("Failed to create config reporter", zap.Error(err))
+	}
+	propagationReporter := propagation.NewReporter(ctx, configReporter)
+
 	syncSignal := poolSyncSignal(ctx, targetsUpdateCh)
 	syncPool, err := InitializeSyncPool(
 		ctx,
 		clients.ProjectID(projectID),
 		metrics.PodName(env.PodName),
 		metrics.ContainerName(component),
-		[]volume.Option{
-			volume.WithPath(env.TargetsConfigPath),
-			volume.WithNotifyChan(targetsUpdateCh),
+		[]stream.Option{
+			stream.WithAddress(env.TargetsConfigService),
+			stream.WithNode(env.PodName),
+			stream.WithCell(env.BrokerCell),
+			stream.WithTokenFile(stream.TokenPath),
+			stream.WithPath(env.TargetsConfigPath),
+			stream.WithNotifyChan(targetsUpdateCh),
+			stream.WithObserver(propagationReporter.Observe),
+			stream.WithOpenCircuitBreakers(breakers.OpenKeys, poolResyncPeriod),
 		},
-		buildHandlerOptions(env)...,
+		buildHandlerOptions(env, res.KubeClient, breakers)...,
 	)
 	if err != nil {
 		logger.Fatal("Failed to get retry sync pool", zap.Error(err))
@@ -108,7 +156,10 @@ func main() {
 
 	// Context will be done if a TERM signal is issued.
 	<-ctx.Done()
-	logger.Info("Exiting...")
+	// Stop pulling events and wait for the events being processed to be acked or nacked.
+	logger.Info("Draining the handlers...")
+	syncPool.Drain()
+	logger.Info("Done draining, exit.")
 }
 
 func poolSyncSignal(ctx context.Context, targetsUpdateCh chan struct{}) chan struct{} {
@@ -131,7 +182,7 @@ func poolSyncSignal(ctx context.Context, targetsUpdateCh chan struct{}) chan str
 	return ch
 }
 
-func buildHandlerOptions(env envConfig) []handler.Option {
+func buildHandlerOptions(env envConfig, kubeClient kubernetes.Interface, breakers *circuitbreaker.Breakers) []handler.Option {
 	rs := pubsub.DefaultReceiveSettings
 	// If Synchronous is true, then no more than MaxOutstandingMessages will be in memory at one time.
 	// MaxOutstandingBytes still refers to the total bytes processed, rather than in memory.
@@ -153,6 +204,12 @@ func buildHandlerOptions(env envConfig) []handler.Option {
 		MaxBackoff: env.MaxRetryBackoff,
 	}))
 	opts = append(opts, handler.WithPubsubReceiveSettings(rs))
+	opts = append(opts, handler.WithDeliveryTokens(deliveryauth.NewTokens(kubeClient, system.Namespace(), env.ServiceAccountName)))
+	opts = append(opts, handler.WithCircuitBreakers(breakers))
+	opts = append(opts, handler.WithDrainTimeout(env.DrainTimeout))
+	if env.DedupCapacity > 0 {
+		opts = append(opts, handler.WithDedupStore(dedup.NewLRUStore(env.DedupCapacity, env.DedupTTL)))
+	}
 	// The default CeClient is good?
 	return opts
 }
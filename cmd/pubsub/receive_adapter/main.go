@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"time"
@@ -62,10 +63,35 @@ type envConfig struct {
 	// Otherwise, only Sink is used (for either the sub.reply or sub.reply)
 	Transformer string `envconfig:"TRANSFORMER_URI"`
 
+	// Environment variable containing the number of attempts to deliver an
+	// event before dead lettering it. Only set if Pub/Sub doesn't dead letter
+	// the events itself.
+	MaxDeliveryAttempts int `envconfig:"MAX_DELIVERY_ATTEMPTS"`
+
+	// Environment variable containing the dead letter sink URI.
+	DeadLetterSink string `envconfig:"DEAD_LETTER_SINK_URI"`
+
+	// Environment variable containing the maximum number of events delivered
+	// in a batch. Only set if the events are delivered in batches.
+	BatchMaxEvents int `envconfig:"BATCH_MAX_EVENTS"`
+
+	// Environment variable containing the maximum time an event waits for its
+	// batch to fill up.
+	BatchMaxLatency time.Duration `envconfig:"BATCH_MAX_LATENCY"`
+
 	// Environment variable specifying the type of adapter to use.
 	// Used for CE conversion.
 	AdapterType string `envconfig:"ADAPTER_TYPE"`
 
+	// Environment variable containing the JSON converters.GenericConfig
+	// declaring the converter of AdapterType. Only set if AdapterType isn't
+	// a built-in converter.
+	ConverterConfig string `envconfig:"CONVERTER_CONFIG"`
+
+	// Environment variable specifying the format of the events sent to the
+	// sink: binary, structured or push.
+	SendMode string `envconfig:"SEND_MODE"`
+
 	// Topic is the environment variable containing the PubSub Topic being
 	// subscribed to's name. In the form that is unique within the project.
 	// E.g. 'laconia', not 'projects/my-gcp-project/topics/laconia'.
@@ -163,14 +189,33 @@ func main() {
 		logger.Error("Failed to convert base64 extensions to map: %v", zap.Error(err))
 	}
 
+	if env.ConverterConfig != "" {
+		var cfg converters.GenericConfig
+		if err := json.Unmarshal([]byte(env.ConverterConfig), &cfg); err != nil {
+			logger.Fatal("Failed to process converter config", zap.Error(err))
+		}
+		fn, err := converters.NewGenericConverter(cfg)
+		if err != nil {
+			logger.Fatal("Failed to create the converter", zap.Error(err))
+		}
+		if err := converters.Register(converters.ConverterType(env.AdapterType), fn); err != nil {
+			logger.Fatal("Failed to register the converter", zap.Error(err))
+		}
+	}
+
 	logger.Info("Initializing adapter", zap.String("projectID", projectID), zap.String("topicID", env.Topic), zap.String("subscriptionID", env.Subscription))
 
 	args := &AdapterArgs{
-		TopicID:        env.Topic,
-		ConverterType:  converters.ConverterType(env.AdapterType),
-		SinkURI:        env.Sink,
-		TransformerURI: env.Transformer,
-		Extensions:     extensions,
+		TopicID:             env.Topic,
+		ConverterType:       converters.ConverterType(env.AdapterType),
+		SendMode:            converters.ModeType(env.SendMode),
+		SinkURI:             env.Sink,
+		TransformerURI:      env.Transformer,
+		Extensions:          extensions,
+		MaxDeliveryAttempts: env.MaxDeliveryAttempts,
+		DeadLetterSinkURI:   env.DeadLetterSink,
+		BatchMaxEvents:      env.BatchMaxEvents,
+		BatchMaxLatency:     env.BatchMaxLatency,
 	}
 
 	adapter, err := InitializeAdapter(ctx,
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"regexp"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// AllowedPublishersAnnotationKey is the annotation key for the comma separated list of
+	// identities allowed to publish events to the Broker. If set, the ingress rejects
+	// requests without a valid bearer token with 401 and requests from other identities
+	// with 403. The token audience must be the Broker address.
+	AllowedPublishersAnnotationKey = "broker.events.cloud.google.com/allowed-publishers"
+)
+
+var (
+	// Google service accounts and users are identified by their email.
+	emailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+	// Kubernetes service accounts are identified by their user name.
+	serviceAccountRegexp = regexp.MustCompile(`^system:serviceaccount:[a-z0-9]([-a-z0-9]*[a-z0-9])?:[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`)
+)
+
+// GetAllowedPublishers returns the identities allowed to publish events to the Broker,
+// or nil if publishing is not restricted.
+func (b *Broker) GetAllowedPublishers() []string {
+	raw, ok := b.GetAnnotations()[AllowedPublishersAnnotationKey]
+	if !ok {
+		return nil
+	}
+	var publishers []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			publishers = append(publishers, p)
+		}
+	}
+	return publishers
+}
+
+func validateAllowedPublishers(b *Broker) *apis.FieldError {
+	raw, ok := b.GetAnnotations()[AllowedPublishersAnnotationKey]
+	if !ok {
+		return nil
+	}
+	publishers := b.GetAllowedPublishers()
+	if len(publishers) == 0 {
+		return apis.ErrInvalidValue(raw, AllowedPublishersAnnotationKey)
+	}
+	for _, p := range publishers {
+		if !emailRegexp.MatchString(p) && !serviceAccountRegexp.MatchString(p) {
+			return apis.ErrInvalidValue(p, AllowedPublishersAnnotationKey)
+		}
+	}
+	return nil
+}
@@ -37,6 +37,13 @@ const (
 	// BrokerConditionSubscription reports the status of the Broker's PubSub
 	// subscription. This condition is specific to the Google Cloud Broker.
 	BrokerConditionSubscription apis.ConditionType = "SubscriptionReady"
+	// BrokerConditionTargetsConfigApplied reports whether all data plane pods of the
+	// Broker's BrokerCell applied the latest targets config. It doesn't affect the
+	// readiness of the Broker.
+	BrokerConditionTargetsConfigApplied apis.ConditionType = "TargetsConfigApplied"
+	// BrokerConditionReplayed reports whether the Broker's PubSub subscription was seeked
+	// to the requested replay. It doesn't affect the readiness of the Broker.
+	BrokerConditionReplayed apis.ConditionType = "Replayed"
 )
 
 // GetCondition returns the condition currently associated with the given type, or nil.
@@ -105,3 +112,39 @@ func (bs *BrokerStatus) MarkSubscriptionUnknown(reason, format string, args ...i
 func (bs *BrokerStatus) MarkSubscriptionReady() {
 	brokerCondSet.Manage(bs).MarkTrue(BrokerConditionSubscription)
 }
+
+func (bs *BrokerStatus) MarkTargetsConfigApplied() {
+	brokerCondSet.Manage(bs).MarkTrue(BrokerConditionTargetsConfigApplied)
+}
+
+func (bs *BrokerStatus) MarkTargetsConfigPropagating(reason, format string, args ...interface{}) {
+	brokerCondSet.Manage(bs).MarkUnknown(BrokerConditionTargetsConfigApplied, reason, format, args...)
+}
+
+// ClearTargetsConfigApplied removes the targets config applied condition, e.g. when the
+// generation of the targets config is unknown.
+func (bs *BrokerStatus) ClearTargetsConfigApplied() {
+	brokerCondSet.Manage(bs).ClearCondition(BrokerConditionTargetsConfigApplied)
+}
+
+func (bs *BrokerStatus) MarkReplayed(r *Replay) {
+	brokerCondSet.Manage(bs).MarkTrueWithReason(BrokerConditionReplayed, "Replayed", "%s", replayedMessage(r))
+	setStatusAnnotation(&bs.Status, ReplayedStatusAnnotationKey, r.String())
+}
+
+func (bs *BrokerStatus) MarkReplayFailed(reason, format string, args ...interface{}) {
+	brokerCondSet.Manage(bs).MarkFalse(BrokerConditionReplayed, reason, format, args...)
+	setStatusAnnotation(&bs.Status, ReplayedStatusAnnotationKey, "")
+}
+
+// IsReplayed returns true if the given replay was already applied.
+func (bs *BrokerStatus) IsReplayed(r *Replay) bool {
+	return isReplayed(&bs.Status, r)
+}
+
+// ClearReplayed removes the replayed condition, e.g. when the Broker no longer requests
+// a replay.
+func (bs *BrokerStatus) ClearReplayed() {
+	brokerCondSet.Manage(bs).ClearCondition(BrokerConditionReplayed)
+	setStatusAnnotation(&bs.Status, ReplayedStatusAnnotationKey, "")
+}
@@ -385,3 +385,29 @@ func TestBrokerConditionStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestBrokerTargetsConfigAppliedCondition(t *testing.T) {
+	bs := &BrokerStatus{}
+	bs.SetAddress(apis.HTTP("example.com"))
+	bs.MarkBrokerCellReady()
+	bs.MarkTopicReady()
+	bs.MarkSubscriptionReady()
+
+	bs.MarkTargetsConfigPropagating("TargetsConfigPropagating", "applied on 1/2 pods")
+	if got := bs.GetCondition(BrokerConditionTargetsConfigApplied); got == nil || got.Status != corev1.ConditionUnknown {
+		t.Errorf("targets config applied condition got=%v, want status Unknown", got)
+	}
+	// A propagating targets config doesn't affect the readiness of the Broker.
+	if !bs.IsReady() {
+		t.Error("expected happy true with a propagating targets config, got false")
+	}
+
+	bs.MarkTargetsConfigApplied()
+	if got := bs.GetCondition(BrokerConditionTargetsConfigApplied); got == nil || got.Status != corev1.ConditionTrue {
+		t.Errorf("targets config applied condition got=%v, want status True", got)
+	}
+	bs.ClearTargetsConfigApplied()
+	if got := bs.GetCondition(BrokerConditionTargetsConfigApplied); got != nil {
+		t.Errorf("targets config applied condition got=%v, want nil", got)
+	}
+}
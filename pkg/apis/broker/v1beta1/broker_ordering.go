@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"regexp"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// OrderingKeyAttributeAnnotationKey is the annotation key to opt a Broker into ordered
+	// delivery. The value is the name of the CloudEvent attribute, e.g. the "partitionkey"
+	// extension, whose value is used as the Pub/Sub ordering key. Events with the same
+	// ordering key are delivered to each Trigger in the order they were accepted.
+	// Message ordering of the decouple subscription is only set when it is created.
+	OrderingKeyAttributeAnnotationKey = "broker.events.cloud.google.com/ordering-key-attribute"
+)
+
+// CloudEvent attribute names consist of lower-case letters and digits.
+var attributeNameRegexp = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// GetOrderingKeyAttribute returns the CloudEvent attribute used as the ordering key,
+// or an empty string if ordered delivery is not enabled for the Broker.
+func (b *Broker) GetOrderingKeyAttribute() string {
+	return b.GetAnnotations()[OrderingKeyAttributeAnnotationKey]
+}
+
+func validateOrderingKeyAttribute(b *Broker) *apis.FieldError {
+	attr, ok := b.GetAnnotations()[OrderingKeyAttributeAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if !attributeNameRegexp.MatchString(attr) {
+		return apis.ErrInvalidValue(attr, OrderingKeyAttributeAnnotationKey)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// BrokerCellAnnotationKey is the annotation key to place a Broker on the BrokerCell with
+	// the given name in the system namespace. The BrokerCell must be created by the operator;
+	// it is not created for the Broker. Brokers without placement annotations are placed on
+	// the default BrokerCell.
+	BrokerCellAnnotationKey = "broker.events.cloud.google.com/broker-cell"
+
+	// BrokerCellScopeAnnotationKey is the annotation key to choose the scope of the BrokerCell
+	// of a Broker. The only supported value is BrokerCellScopeNamespace.
+	BrokerCellScopeAnnotationKey = "broker.events.cloud.google.com/broker-cell-scope"
+
+	// BrokerCellScopeNamespace places the Broker on a BrokerCell dedicated to the Brokers in
+	// its namespace, isolating them from the Brokers in other namespaces.
+	BrokerCellScopeNamespace = "namespace"
+
+	// NamespaceBrokerCellSuffix is the suffix of the names of the BrokerCells dedicated to the
+	// Brokers in a namespace. Such names can't be chosen with BrokerCellAnnotationKey.
+	NamespaceBrokerCellSuffix = "-ns"
+)
+
+// GetBrokerCell returns the name of the BrokerCell the Broker is placed on by annotation,
+// or an empty string if it has none.
+func (b *Broker) GetBrokerCell() string {
+	return b.GetAnnotations()[BrokerCellAnnotationKey]
+}
+
+// HasNamespaceScopedBrokerCell returns true if the Broker is placed on the BrokerCell of
+// its namespace.
+func (b *Broker) HasNamespaceScopedBrokerCell() bool {
+	return b.GetAnnotations()[BrokerCellScopeAnnotationKey] == BrokerCellScopeNamespace
+}
+
+// IsReservedBrokerCellName returns true if the name is reserved for the BrokerCells dedicated
+// to the Brokers in a namespace.
+func IsReservedBrokerCellName(name string) bool {
+	return strings.HasSuffix(name, NamespaceBrokerCellSuffix)
+}
+
+func validateBrokerCell(b *Broker) *apis.FieldError {
+	annotations := b.GetAnnotations()
+	name, hasName := annotations[BrokerCellAnnotationKey]
+	scope, hasScope := annotations[BrokerCellScopeAnnotationKey]
+	if hasName && hasScope {
+		return apis.ErrMultipleOneOf(BrokerCellAnnotationKey, BrokerCellScopeAnnotationKey)
+	}
+	if hasName && (len(validation.IsDNS1123Label(name)) > 0 || IsReservedBrokerCellName(name)) {
+		return apis.ErrInvalidValue(name, BrokerCellAnnotationKey)
+	}
+	if hasScope && scope != BrokerCellScopeNamespace {
+		return apis.ErrInvalidValue(scope, BrokerCellScopeAnnotationKey)
+	}
+	return nil
+}
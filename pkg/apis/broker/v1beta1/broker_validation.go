@@ -24,7 +24,12 @@ import (
 
 // Validate verifies that the Broker is valid.
 func (b *Broker) Validate(ctx context.Context) *apis.FieldError {
-	// The Google Cloud Broker doesn't have any custom validations. The
-	// eventing webhook will run the usual validations.
-	return nil
+	// The eventing webhook will run the usual validations. The Google Cloud
+	// Broker only validates the annotations of its own features.
+	errs := validateOrderingKeyAttribute(b)
+	errs = errs.Also(validateRateLimit(b.GetAnnotations(), BrokerRateLimitAnnotationKey, BrokerRateLimitBurstAnnotationKey))
+	errs = errs.Also(validateAllowedPublishers(b))
+	errs = errs.Also(validateBrokerCell(b))
+	errs = errs.Also(validateRetention(b))
+	return errs.ViaField("metadata", "annotations")
 }
@@ -19,6 +19,9 @@ package v1beta1
 import (
 	"context"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestBroker_Validate(t *testing.T) {
@@ -27,3 +30,120 @@ func TestBroker_Validate(t *testing.T) {
 		t.Errorf("expected nil, got %v", err)
 	}
 }
+
+func TestBroker_ValidateOrderingKeyAttribute(t *testing.T) {
+	cases := []struct {
+		name    string
+		attr    string
+		wantErr bool
+	}{{
+		name: "valid attribute",
+		attr: "partitionkey",
+	}, {
+		name:    "empty attribute",
+		attr:    "",
+		wantErr: true,
+	}, {
+		name:    "invalid attribute",
+		attr:    "Partition-Key",
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := Broker{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{OrderingKeyAttributeAnnotationKey: tc.attr},
+			}}
+			err := b.Validate(context.TODO())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if !tc.wantErr && b.GetOrderingKeyAttribute() != tc.attr {
+				t.Errorf("GetOrderingKeyAttribute() got=%q, want=%q", b.GetOrderingKeyAttribute(), tc.attr)
+			}
+		})
+	}
+}
+
+func TestBroker_ValidateAllowedPublishers(t *testing.T) {
+	cases := []struct {
+		name       string
+		publishers string
+		want       []string
+		wantErr    bool
+	}{{
+		name:       "google service account",
+		publishers: "publisher@my-project.iam.gserviceaccount.com",
+		want:       []string{"publisher@my-project.iam.gserviceaccount.com"},
+	}, {
+		name:       "multiple identities",
+		publishers: "publisher@my-project.iam.gserviceaccount.com, system:serviceaccount:ns:publisher",
+		want:       []string{"publisher@my-project.iam.gserviceaccount.com", "system:serviceaccount:ns:publisher"},
+	}, {
+		name:       "empty list",
+		publishers: " , ",
+		wantErr:    true,
+	}, {
+		name:       "invalid identity",
+		publishers: "system:serviceaccount:ns",
+		wantErr:    true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := Broker{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AllowedPublishersAnnotationKey: tc.publishers},
+			}}
+			err := b.Validate(context.TODO())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, b.GetAllowedPublishers()); diff != "" {
+				t.Errorf("GetAllowedPublishers() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestBroker_ValidateBrokerCell(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{{
+		name:        "brokercell name",
+		annotations: map[string]string{BrokerCellAnnotationKey: "team-a"},
+	}, {
+		name:        "namespace scope",
+		annotations: map[string]string{BrokerCellScopeAnnotationKey: BrokerCellScopeNamespace},
+	}, {
+		name:        "invalid brokercell name",
+		annotations: map[string]string{BrokerCellAnnotationKey: "Team_A"},
+		wantErr:     true,
+	}, {
+		name:        "reserved brokercell name",
+		annotations: map[string]string{BrokerCellAnnotationKey: "team-a-ns"},
+		wantErr:     true,
+	}, {
+		name:        "invalid scope",
+		annotations: map[string]string{BrokerCellScopeAnnotationKey: "cluster"},
+		wantErr:     true,
+	}, {
+		name: "both name and scope",
+		annotations: map[string]string{
+			BrokerCellAnnotationKey:      "team-a",
+			BrokerCellScopeAnnotationKey: BrokerCellScopeNamespace,
+		},
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := Broker{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			err := b.Validate(context.TODO())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strconv"
+
+	"knative.dev/pkg/apis"
+)
+
+// The rate limits are enforced by each data plane pod separately, so the effective
+// limit is the configured limit times the number of ingress, fanout or retry replicas
+// of the BrokerCell.
+const (
+	// BrokerRateLimitAnnotationKey is the annotation key for the maximum number of events
+	// per second each Broker ingress pod accepts. Events over the limit are rejected with 429.
+	BrokerRateLimitAnnotationKey = "broker.events.cloud.google.com/rate-limit"
+	// BrokerRateLimitBurstAnnotationKey is the annotation key for the number of events the
+	// Broker ingress accepts in a burst. Defaults to the rate limit rounded up.
+	BrokerRateLimitBurstAnnotationKey = "broker.events.cloud.google.com/rate-limit-burst"
+
+	// TriggerRateLimitAnnotationKey is the annotation key for the maximum number of events
+	// per second each fanout and retry pod delivers to the subscriber of a Trigger. Events
+	// over the limit in the fanout are sent to the retry queue.
+	TriggerRateLimitAnnotationKey = "trigger.events.cloud.google.com/rate-limit"
+	// TriggerRateLimitBurstAnnotationKey is the annotation key for the number of events
+	// delivered to the subscriber of a Trigger in a burst. Defaults to the rate limit rounded up.
+	TriggerRateLimitBurstAnnotationKey = "trigger.events.cloud.google.com/rate-limit-burst"
+)
+
+// RateLimit is a token bucket rate limit parsed from annotations.
+// +k8s:deepcopy-gen=false
+type RateLimit struct {
+	// EventsPerSecond is the rate tokens are added to the bucket.
+	EventsPerSecond float64
+	// Burst is the size of the bucket. Zero means the default burst.
+	Burst int32
+}
+
+// GetRateLimit returns the ingress rate limit of the Broker, or nil if it's not limited.
+func (b *Broker) GetRateLimit() (*RateLimit, error) {
+	rl, fe := parseRateLimit(b.GetAnnotations(), BrokerRateLimitAnnotationKey, BrokerRateLimitBurstAnnotationKey)
+	if fe != nil {
+		return nil, fe
+	}
+	return rl, nil
+}
+
+// GetRateLimit returns the delivery rate limit of the Trigger, or nil if it's not limited.
+func (t *Trigger) GetRateLimit() (*RateLimit, error) {
+	rl, fe := parseRateLimit(t.GetAnnotations(), TriggerRateLimitAnnotationKey, TriggerRateLimitBurstAnnotationKey)
+	if fe != nil {
+		return nil, fe
+	}
+	return rl, nil
+}
+
+func parseRateLimit(annotations map[string]string, rateKey, burstKey string) (*RateLimit, *apis.FieldError) {
+	rawRate, hasRate := annotations[rateKey]
+	rawBurst, hasBurst := annotations[burstKey]
+	if !hasRate {
+		if hasBurst {
+			return nil, apis.ErrMissingField(rateKey)
+		}
+		return nil, nil
+	}
+	eps, err := strconv.ParseFloat(rawRate, 64)
+	if err != nil || eps <= 0 {
+		return nil, apis.ErrInvalidValue(rawRate, rateKey)
+	}
+	rl := &RateLimit{EventsPerSecond: eps}
+	if hasBurst {
+		burst, err := strconv.ParseInt(rawBurst, 10, 32)
+		if err != nil || burst < 1 {
+			return nil, apis.ErrInvalidValue(rawBurst, burstKey)
+		}
+		rl.Burst = int32(burst)
+	}
+	return rl, nil
+}
+
+func validateRateLimit(annotations map[string]string, rateKey, burstKey string) *apis.FieldError {
+	_, fe := parseRateLimit(annotations, rateKey, burstKey)
+	return fe
+}
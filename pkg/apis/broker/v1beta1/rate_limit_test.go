@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRateLimitAnnotations(t *testing.T) {
+	cases := []struct {
+		name    string
+		rate    string
+		burst   string
+		want    *RateLimit
+		wantErr bool
+	}{{
+		name: "no rate limit",
+	}, {
+		name: "rate only",
+		rate: "2.5",
+		want: &RateLimit{EventsPerSecond: 2.5},
+	}, {
+		name:  "rate and burst",
+		rate:  "100",
+		burst: "200",
+		want:  &RateLimit{EventsPerSecond: 100, Burst: 200},
+	}, {
+		name:    "invalid rate",
+		rate:    "fast",
+		wantErr: true,
+	}, {
+		name:    "non-positive rate",
+		rate:    "0",
+		wantErr: true,
+	}, {
+		name:    "invalid burst",
+		rate:    "10",
+		burst:   "0",
+		wantErr: true,
+	}, {
+		name:    "burst without rate",
+		burst:   "10",
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			brokerAnnotations := map[string]string{}
+			triggerAnnotations := map[string]string{}
+			if tc.rate != "" {
+				brokerAnnotations[BrokerRateLimitAnnotationKey] = tc.rate
+				triggerAnnotations[TriggerRateLimitAnnotationKey] = tc.rate
+			}
+			if tc.burst != "" {
+				brokerAnnotations[BrokerRateLimitBurstAnnotationKey] = tc.burst
+				triggerAnnotations[TriggerRateLimitBurstAnnotationKey] = tc.burst
+			}
+
+			b := Broker{ObjectMeta: metav1.ObjectMeta{Annotations: brokerAnnotations}}
+			if err := b.Validate(context.TODO()); (err != nil) != tc.wantErr {
+				t.Errorf("Broker.Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{Annotations: triggerAnnotations}}
+			if err := trig.Validate(context.TODO()); (err != nil) != tc.wantErr {
+				t.Errorf("Trigger.Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			got, err := b.GetRateLimit()
+			if err != nil {
+				t.Fatalf("Broker.GetRateLimit() got unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Broker.GetRateLimit() (-want, +got) = %v", diff)
+			}
+			got, err = trig.GetRateLimit()
+			if err != nil {
+				t.Fatalf("Trigger.GetRateLimit() got unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Trigger.GetRateLimit() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
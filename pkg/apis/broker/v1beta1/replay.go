@@ -0,0 +1,190 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const (
+	// BrokerRetentionDurationAnnotationKey is the annotation key for how long the Pub/Sub
+	// subscriptions of a Broker and its Triggers retain acknowledged events, e.g. "24h".
+	// Retained events can be replayed by seeking to a time within the retention window.
+	BrokerRetentionDurationAnnotationKey = "broker.events.cloud.google.com/retention-duration"
+
+	// BrokerReplayTimeAnnotationKey is the annotation key to replay the events published to
+	// a Broker since the given RFC 3339 time to all its Triggers. Only the events retained
+	// for the BrokerRetentionDurationAnnotationKey can be replayed, so it must be set too.
+	BrokerReplayTimeAnnotationKey = "broker.events.cloud.google.com/replay-time"
+	// BrokerReplaySnapshotAnnotationKey is the annotation key to replay the events of a Broker
+	// to all its Triggers from the Pub/Sub snapshot with the given name.
+	BrokerReplaySnapshotAnnotationKey = "broker.events.cloud.google.com/replay-snapshot"
+
+	// TriggerReplayTimeAnnotationKey is the annotation key to replay the events of a Trigger
+	// since the given RFC 3339 time. Only isolated Triggers can be replayed, from their own
+	// subscription, and only if their Broker retains acknowledged events.
+	TriggerReplayTimeAnnotationKey = "trigger.events.cloud.google.com/replay-time"
+	// TriggerReplaySnapshotAnnotationKey is the annotation key to replay the events of a
+	// Trigger from the Pub/Sub snapshot with the given name.
+	TriggerReplaySnapshotAnnotationKey = "trigger.events.cloud.google.com/replay-snapshot"
+
+	// ReplayedStatusAnnotationKey is the status annotation recording the replay last applied
+	// to a Broker or Trigger, so the reconcilers only seek once per request. It is kept in the
+	// status annotations since the eventing webhook drops unknown status fields.
+	ReplayedStatusAnnotationKey = "events.cloud.google.com/replayed"
+
+	// Pub/Sub bounds of the message retention duration of a subscription.
+	minRetentionDuration = 10 * time.Minute
+	maxRetentionDuration = 7 * 24 * time.Hour
+)
+
+// See https://cloud.google.com/pubsub/docs/admin#resource_names
+var snapshotNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9\-_.~+%]{2,254}$`)
+
+// Replay is a request to seek a Pub/Sub subscription parsed from annotations. Seeking to a
+// time redelivers the retained events published since then; seeking to a snapshot
+// redelivers the events that were unacknowledged when the snapshot was taken.
+// +k8s:deepcopy-gen=false
+type Replay struct {
+	// Time is the publish time to seek to, when Snapshot is empty.
+	Time time.Time
+	// Snapshot is the name of the Pub/Sub snapshot to seek to.
+	Snapshot string
+}
+
+func (r *Replay) String() string {
+	if r.Snapshot != "" {
+		return fmt.Sprintf("snapshot %q", r.Snapshot)
+	}
+	return "time " + r.Time.UTC().Format(time.RFC3339Nano)
+}
+
+// replayedMessage is the message of the replay condition once the replay was applied.
+func replayedMessage(r *Replay) string {
+	return "Subscription seeked to " + r.String()
+}
+
+// GetRetentionDuration returns how long the subscriptions of the Broker retain acknowledged
+// events, or zero if they don't.
+func (b *Broker) GetRetentionDuration() (time.Duration, error) {
+	d, fe := parseRetentionDuration(b.GetAnnotations())
+	if fe != nil {
+		return 0, fe
+	}
+	return d, nil
+}
+
+// GetReplay returns the replay requested on the Broker, or nil if there is none.
+func (b *Broker) GetReplay() (*Replay, error) {
+	r, fe := parseReplay(b.GetAnnotations(), BrokerReplayTimeAnnotationKey, BrokerReplaySnapshotAnnotationKey)
+	if fe != nil {
+		return nil, fe
+	}
+	return r, nil
+}
+
+// GetReplay returns the replay requested on the Trigger, or nil if there is none.
+func (t *Trigger) GetReplay() (*Replay, error) {
+	r, fe := parseReplay(t.GetAnnotations(), TriggerReplayTimeAnnotationKey, TriggerReplaySnapshotAnnotationKey)
+	if fe != nil {
+		return nil, fe
+	}
+	return r, nil
+}
+
+func parseRetentionDuration(annotations map[string]string) (time.Duration, *apis.FieldError) {
+	raw, ok := annotations[BrokerRetentionDurationAnnotationKey]
+	if !ok {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, apis.ErrInvalidValue(raw, BrokerRetentionDurationAnnotationKey)
+	}
+	if d < minRetentionDuration || d > maxRetentionDuration {
+		return 0, apis.ErrOutOfBoundsValue(raw, minRetentionDuration, maxRetentionDuration, BrokerRetentionDurationAnnotationKey)
+	}
+	return d, nil
+}
+
+func parseReplay(annotations map[string]string, timeKey, snapshotKey string) (*Replay, *apis.FieldError) {
+	rawTime, hasTime := annotations[timeKey]
+	snapshot, hasSnapshot := annotations[snapshotKey]
+	switch {
+	case hasTime && hasSnapshot:
+		return nil, apis.ErrMultipleOneOf(timeKey, snapshotKey)
+	case hasTime:
+		t, err := time.Parse(time.RFC3339, rawTime)
+		if err != nil {
+			return nil, apis.ErrInvalidValue(rawTime, timeKey)
+		}
+		return &Replay{Time: t}, nil
+	case hasSnapshot:
+		if !snapshotNameRegexp.MatchString(snapshot) {
+			return nil, apis.ErrInvalidValue(snapshot, snapshotKey)
+		}
+		return &Replay{Snapshot: snapshot}, nil
+	}
+	return nil, nil
+}
+
+func validateRetention(b *Broker) *apis.FieldError {
+	annotations := b.GetAnnotations()
+	_, errs := parseRetentionDuration(annotations)
+	r, fe := parseReplay(annotations, BrokerReplayTimeAnnotationKey, BrokerReplaySnapshotAnnotationKey)
+	if r != nil && r.Snapshot == "" {
+		if _, ok := annotations[BrokerRetentionDurationAnnotationKey]; !ok {
+			fe = apis.ErrGeneric(fmt.Sprintf("replaying to a time requires %s", BrokerRetentionDurationAnnotationKey), BrokerReplayTimeAnnotationKey)
+		}
+	}
+	return errs.Also(fe)
+}
+
+func validateTriggerReplay(t *Trigger) *apis.FieldError {
+	r, fe := parseReplay(t.GetAnnotations(), TriggerReplayTimeAnnotationKey, TriggerReplaySnapshotAnnotationKey)
+	if r != nil && !t.IsIsolated() {
+		key := TriggerReplayTimeAnnotationKey
+		if r.Snapshot != "" {
+			key = TriggerReplaySnapshotAnnotationKey
+		}
+		return apis.ErrGeneric(fmt.Sprintf("replay requires %s to be true", IsolatedAnnotationKey), key)
+	}
+	return fe
+}
+
+// setStatusAnnotation sets the status annotation with the given key, or removes it if the
+// value is empty.
+func setStatusAnnotation(s *duckv1.Status, key, value string) {
+	if value == "" {
+		delete(s.Annotations, key)
+		return
+	}
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string, 1)
+	}
+	s.Annotations[key] = value
+}
+
+// isReplayed returns true if the status annotations record r as the applied replay.
+func isReplayed(s *duckv1.Status, r *Replay) bool {
+	return s.Annotations[ReplayedStatusAnnotationKey] == r.String()
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBroker_ValidateRetention(t *testing.T) {
+	cases := []struct {
+		name          string
+		annotations   map[string]string
+		wantRetention time.Duration
+		wantReplay    *Replay
+		wantErr       bool
+	}{{
+		name: "no retention",
+	}, {
+		name:          "valid retention",
+		annotations:   map[string]string{BrokerRetentionDurationAnnotationKey: "24h"},
+		wantRetention: 24 * time.Hour,
+	}, {
+		name:        "invalid retention",
+		annotations: map[string]string{BrokerRetentionDurationAnnotationKey: "one day"},
+		wantErr:     true,
+	}, {
+		name:        "retention too short",
+		annotations: map[string]string{BrokerRetentionDurationAnnotationKey: "1m"},
+		wantErr:     true,
+	}, {
+		name:        "retention too long",
+		annotations: map[string]string{BrokerRetentionDurationAnnotationKey: "8760h"},
+		wantErr:     true,
+	}, {
+		name: "replay to time",
+		annotations: map[string]string{
+			BrokerRetentionDurationAnnotationKey: "24h",
+			BrokerReplayTimeAnnotationKey:        "2020-08-01T10:00:00Z",
+		},
+		wantRetention: 24 * time.Hour,
+		wantReplay:    &Replay{Time: time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)},
+	}, {
+		name:        "replay to time without retention",
+		annotations: map[string]string{BrokerReplayTimeAnnotationKey: "2020-08-01T10:00:00Z"},
+		wantErr:     true,
+	}, {
+		name:        "replay to snapshot",
+		annotations: map[string]string{BrokerReplaySnapshotAnnotationKey: "my-snapshot"},
+		wantReplay:  &Replay{Snapshot: "my-snapshot"},
+	}, {
+		name:        "invalid replay time",
+		annotations: map[string]string{BrokerReplayTimeAnnotationKey: "yesterday"},
+		wantErr:     true,
+	}, {
+		name:        "invalid replay snapshot",
+		annotations: map[string]string{BrokerReplaySnapshotAnnotationKey: "1snapshot"},
+		wantErr:     true,
+	}, {
+		name: "replay to both time and snapshot",
+		annotations: map[string]string{
+			BrokerReplayTimeAnnotationKey:     "2020-08-01T10:00:00Z",
+			BrokerReplaySnapshotAnnotationKey: "my-snapshot",
+		},
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := Broker{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			fe := b.Validate(context.TODO())
+			if (fe != nil) != tc.wantErr {
+				t.Fatalf("Validate() got error=%v, want error=%v", fe, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			retention, err := b.GetRetentionDuration()
+			if err != nil {
+				t.Fatalf("GetRetentionDuration() got error=%v", err)
+			}
+			if retention != tc.wantRetention {
+				t.Errorf("GetRetentionDuration() got=%v, want=%v", retention, tc.wantRetention)
+			}
+			replay, err := b.GetReplay()
+			if err != nil {
+				t.Fatalf("GetReplay() got error=%v", err)
+			}
+			if diff := cmp.Diff(tc.wantReplay, replay); diff != "" {
+				t.Errorf("GetReplay() (-want,+got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestTrigger_ValidateReplay(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		wantReplay  *Replay
+		wantErr     bool
+	}{{
+		name: "no replay",
+	}, {
+		name: "replay to time",
+		annotations: map[string]string{
+			IsolatedAnnotationKey:          "true",
+			TriggerReplayTimeAnnotationKey: "2020-08-01T10:00:00+02:00",
+		},
+		wantReplay: &Replay{Time: time.Date(2020, 8, 1, 8, 0, 0, 0, time.UTC)},
+	}, {
+		name:        "replay of a trigger that isn't isolated",
+		annotations: map[string]string{TriggerReplaySnapshotAnnotationKey: "my-snapshot"},
+		wantErr:     true,
+	}, {
+		name:        "invalid replay time",
+		annotations: map[string]string{TriggerReplayTimeAnnotationKey: "2020-08-01"},
+		wantErr:     true,
+	}, {
+		name: "replay to both time and snapshot",
+		annotations: map[string]string{
+			TriggerReplayTimeAnnotationKey:     "2020-08-01T10:00:00Z",
+			TriggerReplaySnapshotAnnotationKey: "my-snapshot",
+		},
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			fe := validateTriggerReplay(&trig)
+			if (fe != nil) != tc.wantErr {
+				t.Fatalf("validateTriggerReplay() got error=%v, want error=%v", fe, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			replay, err := trig.GetReplay()
+			if err != nil {
+				t.Fatalf("GetReplay() got error=%v", err)
+			}
+			if diff := cmp.Diff(tc.wantReplay, replay); diff != "" {
+				t.Errorf("GetReplay() (-want,+got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestReplayedCondition(t *testing.T) {
+	replay := &Replay{Time: time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)}
+	other := &Replay{Snapshot: "my-snapshot"}
+
+	bs := &BrokerStatus{}
+	bs.InitializeConditions()
+	if bs.IsReplayed(replay) {
+		t.Error("IsReplayed() got=true before the replay was marked, want=false")
+	}
+	bs.MarkReplayed(replay)
+	if !bs.IsReplayed(replay) {
+		t.Error("IsReplayed() got=false after the replay was marked, want=true")
+	}
+	if bs.IsReplayed(other) {
+		t.Error("IsReplayed() got=true for another replay, want=false")
+	}
+	if got, want := bs.GetCondition(BrokerConditionReplayed).Message, `Subscription seeked to time 2020-08-01T10:00:00Z`; got != want {
+		t.Errorf("Replayed message got=%v, want=%v", got, want)
+	}
+	if got, want := bs.Annotations[ReplayedStatusAnnotationKey], "time 2020-08-01T10:00:00Z"; got != want {
+		t.Errorf("Replayed status annotation got=%v, want=%v", got, want)
+	}
+	bs.ClearReplayed()
+	if bs.GetCondition(BrokerConditionReplayed) != nil {
+		t.Error("Replayed condition not cleared")
+	}
+
+	ts := &TriggerStatus{}
+	ts.InitializeConditions()
+	ts.MarkReplayed(other)
+	if !ts.IsReplayed(other) {
+		t.Error("IsReplayed() got=false after the replay was marked, want=true")
+	}
+	ts.MarkReplayFailed("SubscriptionSeekFailed", "failed")
+	if ts.IsReplayed(other) {
+		t.Error("IsReplayed() got=true after the replay failed, want=false")
+	}
+}
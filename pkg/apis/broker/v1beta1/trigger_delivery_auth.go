@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// DeliveryAuthAnnotationKey is the annotation key for how event deliveries to the subscriber
+	// of a Trigger are authenticated. Deliveries carry a bearer token of the broker data plane
+	// service account whose audience is the subscriber URI.
+	DeliveryAuthAnnotationKey = "trigger.events.cloud.google.com/delivery-auth"
+
+	// DeliveryAuthGoogleIDToken attaches a Google-signed ID token, e.g. for Cloud Run or IAP
+	// protected subscribers.
+	DeliveryAuthGoogleIDToken = "google-id-token"
+	// DeliveryAuthKubernetesToken attaches a Kubernetes service account token.
+	DeliveryAuthKubernetesToken = "kubernetes-token"
+)
+
+// GetDeliveryAuth returns how deliveries to the subscriber are authenticated, or an
+// empty string if they are not.
+func (t *Trigger) GetDeliveryAuth() string {
+	return t.GetAnnotations()[DeliveryAuthAnnotationKey]
+}
+
+func validateDeliveryAuth(t *Trigger) *apis.FieldError {
+	auth, ok := t.GetAnnotations()[DeliveryAuthAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch auth {
+	case DeliveryAuthGoogleIDToken, DeliveryAuthKubernetesToken:
+		return nil
+	}
+	return apis.ErrInvalidValue(auth, DeliveryAuthAnnotationKey)
+}
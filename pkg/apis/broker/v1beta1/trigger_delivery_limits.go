@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strconv"
+	"time"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// MaxInFlightAnnotationKey is the annotation key for the maximum number of concurrent
+	// deliveries to the subscriber of a Trigger per broker data plane pod. Deliveries over
+	// the limit are sent to the retry queue instead of waiting for a free slot.
+	MaxInFlightAnnotationKey = "trigger.events.cloud.google.com/max-in-flight"
+
+	// CircuitBreakerThresholdAnnotationKey is the annotation key for the number of consecutive
+	// deliveries to the subscriber of a Trigger that fail with 5xx or time out before the
+	// circuit opens. While the circuit is open, deliveries are sent straight to the retry queue.
+	CircuitBreakerThresholdAnnotationKey = "trigger.events.cloud.google.com/circuit-breaker-threshold"
+	// CircuitBreakerCoolDownAnnotationKey is the annotation key for how long the circuit stays
+	// open before a trial delivery is allowed, e.g. "30s".
+	CircuitBreakerCoolDownAnnotationKey = "trigger.events.cloud.google.com/circuit-breaker-cool-down"
+
+	// DefaultCircuitBreakerCoolDown is the cool down used when the annotation is not set.
+	DefaultCircuitBreakerCoolDown = 30 * time.Second
+)
+
+// CircuitBreaker is the circuit breaker of a Trigger parsed from annotations.
+// +k8s:deepcopy-gen=false
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed deliveries that opens the circuit.
+	FailureThreshold int32
+	// CoolDown is how long the circuit stays open before a trial delivery.
+	CoolDown time.Duration
+}
+
+// GetMaxInFlight returns the maximum number of concurrent deliveries to the subscriber,
+// or zero if it's not limited.
+func (t *Trigger) GetMaxInFlight() (int32, error) {
+	max, fe := parseMaxInFlight(t.GetAnnotations())
+	if fe != nil {
+		return 0, fe
+	}
+	return max, nil
+}
+
+// GetCircuitBreaker returns the circuit breaker of the subscriber, or nil if it has none.
+func (t *Trigger) GetCircuitBreaker() (*CircuitBreaker, error) {
+	cb, fe := parseCircuitBreaker(t.GetAnnotations())
+	if fe != nil {
+		return nil, fe
+	}
+	return cb, nil
+}
+
+func parseMaxInFlight(annotations map[string]string) (int32, *apis.FieldError) {
+	raw, ok := annotations[MaxInFlightAnnotationKey]
+	if !ok {
+		return 0, nil
+	}
+	max, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || max < 1 {
+		return 0, apis.ErrInvalidValue(raw, MaxInFlightAnnotationKey)
+	}
+	return int32(max), nil
+}
+
+func parseCircuitBreaker(annotations map[string]string) (*CircuitBreaker, *apis.FieldError) {
+	rawThreshold, hasThreshold := annotations[CircuitBreakerThresholdAnnotationKey]
+	rawCoolDown, hasCoolDown := annotations[CircuitBreakerCoolDownAnnotationKey]
+	if !hasThreshold {
+		if hasCoolDown {
+			return nil, apis.ErrMissingField(CircuitBreakerThresholdAnnotationKey)
+		}
+		return nil, nil
+	}
+	threshold, err := strconv.ParseInt(rawThreshold, 10, 32)
+	if err != nil || threshold < 1 {
+		return nil, apis.ErrInvalidValue(rawThreshold, CircuitBreakerThresholdAnnotationKey)
+	}
+	cb := &CircuitBreaker{FailureThreshold: int32(threshold), CoolDown: DefaultCircuitBreakerCoolDown}
+	if hasCoolDown {
+		coolDown, err := time.ParseDuration(rawCoolDown)
+		if err != nil || coolDown < time.Second {
+			return nil, apis.ErrInvalidValue(rawCoolDown, CircuitBreakerCoolDownAnnotationKey)
+		}
+		cb.CoolDown = coolDown
+	}
+	return cb, nil
+}
+
+func validateDeliveryLimits(t *Trigger) *apis.FieldError {
+	_, errs := parseMaxInFlight(t.GetAnnotations())
+	_, fe := parseCircuitBreaker(t.GetAnnotations())
+	return errs.Also(fe)
+}
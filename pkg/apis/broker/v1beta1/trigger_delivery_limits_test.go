@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeliveryLimitsAnnotations(t *testing.T) {
+	cases := []struct {
+		name            string
+		annotations     map[string]string
+		wantMaxInFlight int32
+		wantBreaker     *CircuitBreaker
+		wantErr         bool
+	}{{
+		name: "no limits",
+	}, {
+		name:            "max in flight",
+		annotations:     map[string]string{MaxInFlightAnnotationKey: "10"},
+		wantMaxInFlight: 10,
+	}, {
+		name:        "circuit breaker with default cool down",
+		annotations: map[string]string{CircuitBreakerThresholdAnnotationKey: "5"},
+		wantBreaker: &CircuitBreaker{FailureThreshold: 5, CoolDown: DefaultCircuitBreakerCoolDown},
+	}, {
+		name: "circuit breaker with cool down",
+		annotations: map[string]string{
+			CircuitBreakerThresholdAnnotationKey: "3",
+			CircuitBreakerCoolDownAnnotationKey:  "2m",
+		},
+		wantBreaker: &CircuitBreaker{FailureThreshold: 3, CoolDown: 2 * time.Minute},
+	}, {
+		name:        "invalid max in flight",
+		annotations: map[string]string{MaxInFlightAnnotationKey: "0"},
+		wantErr:     true,
+	}, {
+		name:        "invalid threshold",
+		annotations: map[string]string{CircuitBreakerThresholdAnnotationKey: "many"},
+		wantErr:     true,
+	}, {
+		name: "cool down too short",
+		annotations: map[string]string{
+			CircuitBreakerThresholdAnnotationKey: "3",
+			CircuitBreakerCoolDownAnnotationKey:  "10ms",
+		},
+		wantErr: true,
+	}, {
+		name:        "cool down without threshold",
+		annotations: map[string]string{CircuitBreakerCoolDownAnnotationKey: "30s"},
+		wantErr:     true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if err := trig.Validate(context.TODO()); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			max, err := trig.GetMaxInFlight()
+			if err != nil {
+				t.Fatalf("GetMaxInFlight() got error=%v", err)
+			}
+			if max != tc.wantMaxInFlight {
+				t.Errorf("GetMaxInFlight() got=%d, want=%d", max, tc.wantMaxInFlight)
+			}
+			cb, err := trig.GetCircuitBreaker()
+			if err != nil {
+				t.Fatalf("GetCircuitBreaker() got error=%v", err)
+			}
+			if diff := cmp.Diff(tc.wantBreaker, cb); diff != "" {
+				t.Errorf("GetCircuitBreaker() (-want,+got): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+
+	"github.com/google/knative-gcp/pkg/broker/cesql"
+)
+
+const (
+	// FiltersAnnotationKey is the annotation key for the advanced filters of a Trigger.
+	// The value is a JSON list of SubscriptionsAPIFilter. An event must pass all the
+	// filters, and the filters take precedence over spec.filter.attributes.
+	FiltersAnnotationKey = "trigger.events.cloud.google.com/filters"
+)
+
+// SubscriptionsAPIFilter is a filter following the dialects of the Knative
+// trigger filters proposal. Exactly one dialect must be set.
+// +k8s:deepcopy-gen=false
+type SubscriptionsAPIFilter struct {
+	// All evaluates to true if all the nested filters evaluate to true.
+	All []SubscriptionsAPIFilter `json:"all,omitempty"`
+	// Any evaluates to true if any of the nested filters evaluates to true.
+	Any []SubscriptionsAPIFilter `json:"any,omitempty"`
+	// Not evaluates to true if the nested filter evaluates to false.
+	Not *SubscriptionsAPIFilter `json:"not,omitempty"`
+	// Exact evaluates to true if the attribute values exactly match.
+	Exact map[string]string `json:"exact,omitempty"`
+	// Prefix evaluates to true if the attribute values start with the given prefixes.
+	Prefix map[string]string `json:"prefix,omitempty"`
+	// Suffix evaluates to true if the attribute values end with the given suffixes.
+	Suffix map[string]string `json:"suffix,omitempty"`
+	// SQL is a CloudEvents SQL expression that must evaluate to true.
+	SQL string `json:"sql,omitempty"`
+}
+
+// GetFilters returns the advanced filters of the Trigger from its annotations.
+func (t *Trigger) GetFilters() ([]SubscriptionsAPIFilter, error) {
+	raw, ok := t.GetAnnotations()[FiltersAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var filters []SubscriptionsAPIFilter
+	if err := json.Unmarshal([]byte(raw), &filters); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", FiltersAnnotationKey, err)
+	}
+	return filters, nil
+}
+
+func validateFiltersAnnotation(t *Trigger) *apis.FieldError {
+	filters, err := t.GetFilters()
+	if err != nil {
+		return apis.ErrInvalidValue(t.GetAnnotations()[FiltersAnnotationKey], FiltersAnnotationKey)
+	}
+	var errs *apis.FieldError
+	for i, f := range filters {
+		errs = errs.Also(f.Validate().ViaIndex(i).ViaKey(FiltersAnnotationKey))
+	}
+	return errs
+}
+
+// Validate validates a filter and its nested filters.
+func (f *SubscriptionsAPIFilter) Validate() *apis.FieldError {
+	var errs *apis.FieldError
+	dialects := 0
+	if len(f.All) > 0 {
+		dialects++
+		for i, nested := range f.All {
+			errs = errs.Also(nested.Validate().ViaFieldIndex("all", i))
+		}
+	}
+	if len(f.Any) > 0 {
+		dialects++
+		for i, nested := range f.Any {
+			errs = errs.Also(nested.Validate().ViaFieldIndex("any", i))
+		}
+	}
+	if f.Not != nil {
+		dialects++
+		errs = errs.Also(f.Not.Validate().ViaField("not"))
+	}
+	if len(f.Exact) > 0 {
+		dialects++
+	}
+	if len(f.Prefix) > 0 {
+		dialects++
+	}
+	if len(f.Suffix) > 0 {
+		dialects++
+	}
+	if f.SQL != "" {
+		dialects++
+		if _, err := cesql.Parse(f.SQL); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s: %v", f.SQL, err), "sql"))
+		}
+	}
+	switch {
+	case dialects == 0:
+		errs = errs.Also(apis.ErrMissingOneOf("all", "any", "not", "exact", "prefix", "suffix", "sql"))
+	case dialects > 1:
+		errs = errs.Also(apis.ErrMultipleOneOf("all", "any", "not", "exact", "prefix", "suffix", "sql"))
+	}
+	return errs
+}
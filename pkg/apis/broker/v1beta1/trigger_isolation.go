@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strconv"
+
+	"knative.dev/pkg/apis"
+)
+
+// IsolatedAnnotationKey is the annotation key to give a Trigger its own Pub/Sub subscription
+// on the decouple topic of its Broker when set to "true". Events are then delivered to the
+// subscriber independently of the other Triggers, so a slow or failing subscriber doesn't
+// hold up the delivery to the others. Once the annotation is removed, the subscription of
+// the Trigger is deleted along with the events it didn't deliver yet.
+const IsolatedAnnotationKey = "trigger.events.cloud.google.com/isolated"
+
+// IsolatedSubscriptionStatusAnnotationKey is the status annotation recording the subscription
+// of an isolated Trigger, so it's only deleted if the Trigger was isolated.
+const IsolatedSubscriptionStatusAnnotationKey = "trigger.events.cloud.google.com/isolated-subscription"
+
+// IsIsolated returns true if the Trigger has its own subscription on the decouple topic.
+func (t *Trigger) IsIsolated() bool {
+	isolated, _ := strconv.ParseBool(t.GetAnnotations()[IsolatedAnnotationKey])
+	return isolated
+}
+
+// IsolatedSubscription returns the ID of the subscription of the Trigger on the decouple
+// topic of its Broker, or an empty string if it has none.
+func (ts *TriggerStatus) IsolatedSubscription() string {
+	return ts.Annotations[IsolatedSubscriptionStatusAnnotationKey]
+}
+
+func (ts *TriggerStatus) MarkIsolatedSubscription(subID string) {
+	setStatusAnnotation(&ts.Status, IsolatedSubscriptionStatusAnnotationKey, subID)
+}
+
+func (ts *TriggerStatus) ClearIsolatedSubscription() {
+	setStatusAnnotation(&ts.Status, IsolatedSubscriptionStatusAnnotationKey, "")
+}
+
+func validateIsolated(t *Trigger) *apis.FieldError {
+	raw, ok := t.GetAnnotations()[IsolatedAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.ParseBool(raw); err != nil {
+		return apis.ErrInvalidValue(raw, IsolatedAnnotationKey)
+	}
+	return nil
+}
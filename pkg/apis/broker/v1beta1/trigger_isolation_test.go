@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTrigger_IsIsolated(t *testing.T) {
+	cases := []struct {
+		name         string
+		annotations  map[string]string
+		wantIsolated bool
+		wantErr      bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:         "isolated",
+		annotations:  map[string]string{IsolatedAnnotationKey: "true"},
+		wantIsolated: true,
+	}, {
+		name:        "not isolated",
+		annotations: map[string]string{IsolatedAnnotationKey: "false"},
+	}, {
+		name:        "invalid value",
+		annotations: map[string]string{IsolatedAnnotationKey: "yes please"},
+		wantErr:     true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if fe := validateIsolated(&trig); (fe != nil) != tc.wantErr {
+				t.Errorf("validateIsolated() got error=%v, want error=%v", fe, tc.wantErr)
+			}
+			if got := trig.IsIsolated(); got != tc.wantIsolated {
+				t.Errorf("IsIsolated() got=%v, want=%v", got, tc.wantIsolated)
+			}
+		})
+	}
+}
+
+func TestTriggerStatus_IsolatedSubscription(t *testing.T) {
+	ts := &TriggerStatus{}
+	if got := ts.IsolatedSubscription(); got != "" {
+		t.Errorf("IsolatedSubscription() got=%q before it was marked, want empty", got)
+	}
+	ts.MarkIsolatedSubscription("my-sub")
+	if got, want := ts.IsolatedSubscription(), "my-sub"; got != want {
+		t.Errorf("IsolatedSubscription() got=%q, want=%q", got, want)
+	}
+	ts.ClearIsolatedSubscription()
+	if got := ts.IsolatedSubscription(); got != "" {
+		t.Errorf("IsolatedSubscription() got=%q after it was cleared, want empty", got)
+	}
+}
@@ -34,6 +34,22 @@ var triggerCondSet = apis.NewLivingConditionSet(
 const (
 	TriggerConditionTopic        apis.ConditionType = "TopicReady"
 	TriggerConditionSubscription apis.ConditionType = "SubscriptionReady"
+
+	// TriggerConditionCircuitBreaker reports whether the circuit breaker of the subscriber
+	// is closed on all broker data plane pods. It doesn't affect the readiness of the Trigger.
+	TriggerConditionCircuitBreaker apis.ConditionType = "CircuitBreakerClosed"
+
+	// TriggerConditionTargetsConfigApplied reports whether all broker data plane pods applied
+	// the latest targets config. It doesn't affect the readiness of the Trigger.
+	TriggerConditionTargetsConfigApplied apis.ConditionType = "TargetsConfigApplied"
+
+	// TriggerConditionReplayed reports whether the subscription of the Trigger was seeked
+	// to the requested replay. It doesn't affect the readiness of the Trigger.
+	TriggerConditionReplayed apis.ConditionType = "Replayed"
+
+	// TriggerConditionReplyResolved reports whether the reply destination of the Trigger was
+	// resolved. It doesn't affect the readiness of the Trigger.
+	TriggerConditionReplyResolved apis.ConditionType = "ReplyResolved"
 )
 
 // GetCondition returns the condition currently associated with the given type, or nil.
@@ -112,6 +128,74 @@ func (bs *TriggerStatus) MarkSubscriptionReady() {
 	triggerCondSet.Manage(bs).MarkTrue(TriggerConditionSubscription)
 }
 
+func (ts *TriggerStatus) MarkCircuitBreakerClosed() {
+	triggerCondSet.Manage(ts).MarkTrue(TriggerConditionCircuitBreaker)
+}
+
+func (ts *TriggerStatus) MarkCircuitBreakerOpen(reason, format string, args ...interface{}) {
+	triggerCondSet.Manage(ts).MarkFalse(TriggerConditionCircuitBreaker, reason, format, args...)
+}
+
+// ClearCircuitBreaker removes the circuit breaker condition, e.g. when the Trigger no longer
+// has a circuit breaker.
+func (ts *TriggerStatus) ClearCircuitBreaker() {
+	triggerCondSet.Manage(ts).ClearCondition(TriggerConditionCircuitBreaker)
+}
+
+func (ts *TriggerStatus) MarkTargetsConfigApplied() {
+	triggerCondSet.Manage(ts).MarkTrue(TriggerConditionTargetsConfigApplied)
+}
+
+func (ts *TriggerStatus) MarkTargetsConfigPropagating(reason, format string, args ...interface{}) {
+	triggerCondSet.Manage(ts).MarkUnknown(TriggerConditionTargetsConfigApplied, reason, format, args...)
+}
+
+// ClearTargetsConfigApplied removes the targets config applied condition, e.g. when the
+// generation of the targets config is unknown.
+func (ts *TriggerStatus) ClearTargetsConfigApplied() {
+	triggerCondSet.Manage(ts).ClearCondition(TriggerConditionTargetsConfigApplied)
+}
+
+func (ts *TriggerStatus) MarkReplayed(r *Replay) {
+	triggerCondSet.Manage(ts).MarkTrueWithReason(TriggerConditionReplayed, "Replayed", "%s", replayedMessage(r))
+	setStatusAnnotation(&ts.Status, ReplayedStatusAnnotationKey, r.String())
+}
+
+func (ts *TriggerStatus) MarkReplayFailed(reason, format string, args ...interface{}) {
+	triggerCondSet.Manage(ts).MarkFalse(TriggerConditionReplayed, reason, format, args...)
+	setStatusAnnotation(&ts.Status, ReplayedStatusAnnotationKey, "")
+}
+
+// IsReplayed returns true if the given replay was already applied.
+func (ts *TriggerStatus) IsReplayed(r *Replay) bool {
+	return isReplayed(&ts.Status, r)
+}
+
+// ClearReplayed removes the replayed condition, e.g. when the Trigger no longer requests
+// a replay.
+func (ts *TriggerStatus) ClearReplayed() {
+	triggerCondSet.Manage(ts).ClearCondition(TriggerConditionReplayed)
+	setStatusAnnotation(&ts.Status, ReplayedStatusAnnotationKey, "")
+}
+
+func (ts *TriggerStatus) MarkReplyResolved(address string) {
+	triggerCondSet.Manage(ts).MarkTrue(TriggerConditionReplyResolved)
+	setStatusAnnotation(&ts.Status, ReplyAddressStatusAnnotationKey, address)
+}
+
+// MarkReplyResolveFailed marks the reply destination unresolved. The address last resolved
+// is kept, so the replies are still sent there.
+func (ts *TriggerStatus) MarkReplyResolveFailed(reason, format string, args ...interface{}) {
+	triggerCondSet.Manage(ts).MarkFalse(TriggerConditionReplyResolved, reason, format, args...)
+}
+
+// ClearReplyResolved removes the reply resolved condition, e.g. when the replies of the
+// Trigger are sent to its Broker.
+func (ts *TriggerStatus) ClearReplyResolved() {
+	triggerCondSet.Manage(ts).ClearCondition(TriggerConditionReplyResolved)
+	setStatusAnnotation(&ts.Status, ReplyAddressStatusAnnotationKey, "")
+}
+
 func (ts *TriggerStatus) MarkSubscriberResolvedSucceeded() {
 	triggerCondSet.Manage(ts).MarkTrue(eventingv1beta1.TriggerConditionSubscriberResolved)
 }
@@ -428,3 +428,95 @@ func TestTriggerConditionStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestTriggerCircuitBreakerCondition(t *testing.T) {
+	ts := &TriggerStatus{}
+	ts.PropagateBrokerStatus(TestHelper.ReadyBrokerStatus())
+	ts.MarkSubscriptionReady()
+	ts.MarkTopicReady()
+	ts.MarkSubscriberResolvedSucceeded()
+	ts.MarkDependencySucceeded()
+
+	ts.MarkCircuitBreakerOpen("CircuitBreakerOpen", "induced open")
+	if got := ts.GetCondition(TriggerConditionCircuitBreaker); got == nil || got.Status != corev1.ConditionFalse {
+		t.Errorf("circuit breaker condition got=%v, want status False", got)
+	}
+	// An open circuit breaker doesn't affect the readiness of the Trigger.
+	if !ts.IsReady() {
+		t.Error("expected happy true with an open circuit breaker, got false")
+	}
+
+	ts.MarkCircuitBreakerClosed()
+	if got := ts.GetCondition(TriggerConditionCircuitBreaker); got == nil || got.Status != corev1.ConditionTrue {
+		t.Errorf("circuit breaker condition got=%v, want status True", got)
+	}
+	ts.ClearCircuitBreaker()
+	if got := ts.GetCondition(TriggerConditionCircuitBreaker); got != nil {
+		t.Errorf("circuit breaker condition got=%v, want nil", got)
+	}
+}
+
+func TestTriggerTargetsConfigAppliedCondition(t *testing.T) {
+	ts := &TriggerStatus{}
+	ts.PropagateBrokerStatus(TestHelper.ReadyBrokerStatus())
+	ts.MarkSubscriptionReady()
+	ts.MarkTopicReady()
+	ts.MarkSubscriberResolvedSucceeded()
+	ts.MarkDependencySucceeded()
+
+	ts.MarkTargetsConfigPropagating("TargetsConfigPropagating", "applied on 1/2 pods")
+	if got := ts.GetCondition(TriggerConditionTargetsConfigApplied); got == nil || got.Status != corev1.ConditionUnknown {
+		t.Errorf("targets config applied condition got=%v, want status Unknown", got)
+	}
+	// A propagating targets config doesn't affect the readiness of the Trigger.
+	if !ts.IsReady() {
+		t.Error("expected happy true with a propagating targets config, got false")
+	}
+
+	ts.MarkTargetsConfigApplied()
+	if got := ts.GetCondition(TriggerConditionTargetsConfigApplied); got == nil || got.Status != corev1.ConditionTrue {
+		t.Errorf("targets config applied condition got=%v, want status True", got)
+	}
+	ts.ClearTargetsConfigApplied()
+	if got := ts.GetCondition(TriggerConditionTargetsConfigApplied); got != nil {
+		t.Errorf("targets config applied condition got=%v, want nil", got)
+	}
+}
+
+func TestTriggerReplyResolvedCondition(t *testing.T) {
+	ts := &TriggerStatus{}
+	ts.PropagateBrokerStatus(TestHelper.ReadyBrokerStatus())
+	ts.MarkSubscriptionReady()
+	ts.MarkTopicReady()
+	ts.MarkSubscriberResolvedSucceeded()
+	ts.MarkDependencySucceeded()
+
+	ts.MarkReplyResolved("http://reply.example.com")
+	if got := ts.GetCondition(TriggerConditionReplyResolved); got == nil || got.Status != corev1.ConditionTrue {
+		t.Errorf("reply resolved condition got=%v, want status True", got)
+	}
+	if got, want := ts.ReplyAddress(), "http://reply.example.com"; got != want {
+		t.Errorf("reply address got=%q, want=%q", got, want)
+	}
+
+	ts.MarkReplyResolveFailed("ReplyUnresolved", "induced failure")
+	if got := ts.GetCondition(TriggerConditionReplyResolved); got == nil || got.Status != corev1.ConditionFalse {
+		t.Errorf("reply resolved condition got=%v, want status False", got)
+	}
+	// The address last resolved is kept.
+	if got, want := ts.ReplyAddress(), "http://reply.example.com"; got != want {
+		t.Errorf("reply address got=%q, want=%q", got, want)
+	}
+	// An unresolved reply destination doesn't affect the readiness of the Trigger.
+	if !ts.IsReady() {
+		t.Error("expected happy true with an unresolved reply destination, got false")
+	}
+
+	ts.ClearReplyResolved()
+	if got := ts.GetCondition(TriggerConditionReplyResolved); got != nil {
+		t.Errorf("reply resolved condition got=%v, want nil", got)
+	}
+	if got := ts.ReplyAddress(); got != "" {
+		t.Errorf("reply address got=%q, want empty", got)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const (
+	// ReplyDestinationAnnotationKey is the annotation key for where the replies of the subscriber
+	// of a Trigger are sent. The value is a JSON Destination, e.g. a reference to another Broker
+	// or a sink URI. The reference must be in the namespace of the Trigger. Defaults to the Broker
+	// of the Trigger.
+	ReplyDestinationAnnotationKey = "trigger.events.cloud.google.com/reply-destination"
+
+	// ReplyRequiredAttributesAnnotationKey is the annotation key for the comma separated
+	// CloudEvent attributes, including extensions, that replies of the subscriber of a Trigger
+	// must carry. Replies without them are dropped.
+	ReplyRequiredAttributesAnnotationKey = "trigger.events.cloud.google.com/reply-required-attributes"
+
+	// ReplyAddressStatusAnnotationKey is the status annotation recording the address the reply
+	// destination of a Trigger was last resolved to, so the replies are still sent there while
+	// the destination cannot be resolved.
+	ReplyAddressStatusAnnotationKey = "trigger.events.cloud.google.com/reply-address"
+)
+
+// GetReplyDestination returns where replies of the subscriber are sent, or nil if they are
+// sent to the Broker of the Trigger.
+func (t *Trigger) GetReplyDestination() (*duckv1.Destination, error) {
+	raw, ok := t.GetAnnotations()[ReplyDestinationAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var dest duckv1.Destination
+	if err := json.Unmarshal([]byte(raw), &dest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", ReplyDestinationAnnotationKey, err)
+	}
+	return &dest, nil
+}
+
+// ReplyAddress returns the address the reply destination of the Trigger was last resolved
+// to, or an empty string if it has none.
+func (ts *TriggerStatus) ReplyAddress() string {
+	return ts.Annotations[ReplyAddressStatusAnnotationKey]
+}
+
+// GetReplyRequiredAttributes returns the attributes replies of the subscriber must carry.
+func (t *Trigger) GetReplyRequiredAttributes() []string {
+	raw := t.GetAnnotations()[ReplyRequiredAttributesAnnotationKey]
+	if raw == "" {
+		return nil
+	}
+	var attrs []string
+	for _, a := range strings.Split(raw, ",") {
+		attrs = append(attrs, strings.TrimSpace(a))
+	}
+	return attrs
+}
+
+func validateReply(ctx context.Context, t *Trigger) *apis.FieldError {
+	var errs *apis.FieldError
+	dest, err := t.GetReplyDestination()
+	if err != nil {
+		errs = errs.Also(apis.ErrInvalidValue(t.GetAnnotations()[ReplyDestinationAnnotationKey], ReplyDestinationAnnotationKey))
+	} else if dest != nil {
+		errs = errs.Also(dest.Validate(ctx).ViaKey(ReplyDestinationAnnotationKey))
+		if dest.Ref != nil && dest.Ref.Namespace != "" && dest.Ref.Namespace != t.Namespace {
+			errs = errs.Also(apis.ErrInvalidValue(dest.Ref.Namespace, "ref.namespace").ViaKey(ReplyDestinationAnnotationKey))
+		}
+	}
+	for _, a := range t.GetReplyRequiredAttributes() {
+		if !attributeNameRegexp.MatchString(a) {
+			errs = errs.Also(apis.ErrInvalidValue(a, ReplyRequiredAttributesAnnotationKey))
+		}
+	}
+	return errs
+}
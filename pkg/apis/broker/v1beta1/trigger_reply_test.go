@@ -0,0 +1,106 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestReplyAnnotations(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		wantDest    *duckv1.Destination
+		wantAttrs   []string
+		wantErr     bool
+	}{{
+		name: "no reply annotations",
+	}, {
+		name: "broker reply destination",
+		annotations: map[string]string{
+			ReplyDestinationAnnotationKey: `{"ref":{"apiVersion":"eventing.knative.dev/v1beta1","kind":"Broker","name":"replies"}}`,
+		},
+		wantDest: &duckv1.Destination{Ref: &duckv1.KReference{
+			APIVersion: "eventing.knative.dev/v1beta1",
+			Kind:       "Broker",
+			Name:       "replies",
+		}},
+	}, {
+		name: "uri reply destination",
+		annotations: map[string]string{
+			ReplyDestinationAnnotationKey: `{"uri":"http://sink.example.com"}`,
+		},
+		wantDest: &duckv1.Destination{URI: apis.HTTP("sink.example.com")},
+	}, {
+		name: "required attributes",
+		annotations: map[string]string{
+			ReplyRequiredAttributesAnnotationKey: "subject, traceparent",
+		},
+		wantAttrs: []string{"subject", "traceparent"},
+	}, {
+		name: "malformed reply destination",
+		annotations: map[string]string{
+			ReplyDestinationAnnotationKey: `{"uri":`,
+		},
+		wantErr: true,
+	}, {
+		name: "empty reply destination",
+		annotations: map[string]string{
+			ReplyDestinationAnnotationKey: `{}`,
+		},
+		wantErr: true,
+	}, {
+		name: "reply destination in another namespace",
+		annotations: map[string]string{
+			ReplyDestinationAnnotationKey: `{"ref":{"apiVersion":"eventing.knative.dev/v1beta1","kind":"Broker","name":"replies","namespace":"other"}}`,
+		},
+		wantErr: true,
+	}, {
+		name: "invalid required attribute",
+		annotations: map[string]string{
+			ReplyRequiredAttributesAnnotationKey: "subject,Bad-Name",
+		},
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Annotations: tc.annotations}}
+			if err := trig.Validate(context.TODO()); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			dest, err := trig.GetReplyDestination()
+			if err != nil {
+				t.Fatalf("GetReplyDestination() got error=%v", err)
+			}
+			if diff := cmp.Diff(tc.wantDest, dest); diff != "" {
+				t.Errorf("GetReplyDestination() (-want,+got): %v", diff)
+			}
+			if diff := cmp.Diff(tc.wantAttrs, trig.GetReplyRequiredAttributes()); diff != "" {
+				t.Errorf("GetReplyRequiredAttributes() (-want,+got): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+
+	"github.com/google/knative-gcp/pkg/broker/transform"
+)
+
+const (
+	// TransformationsAnnotationKey is the annotation key for the transformations applied
+	// to the events delivered to the subscriber of a Trigger. The value is a JSON list
+	// of EventTransformation applied in order, after the filters. Events failing a
+	// transformation, e.g. because their data isn't JSON, are not delivered; they are
+	// sent untransformed to the dead letter sink of the Trigger, if it has one.
+	TransformationsAnnotationKey = "trigger.events.cloud.google.com/transformations"
+)
+
+// EventTransformation is a change applied to events. Exactly one operation must be set.
+// +k8s:deepcopy-gen=false
+type EventTransformation struct {
+	// Set sets the attributes to the given values.
+	Set map[string]string `json:"set,omitempty"`
+	// Remove removes the attributes. The id, source and type attributes cannot be removed.
+	Remove []string `json:"remove,omitempty"`
+	// Rename renames the attributes to the given names.
+	Rename map[string]string `json:"rename,omitempty"`
+	// Extract sets the attributes to the fields of the JSON data selected by the given
+	// JSONPath expressions, e.g. "{.order.id}".
+	Extract map[string]string `json:"extract,omitempty"`
+	// Data replaces the data with the output of a Go template executed on the attributes,
+	// with the data under "data", e.g. `{"id": {{ json .data.order.id }}}`.
+	Data string `json:"data,omitempty"`
+}
+
+// GetTransformations returns the transformations of the Trigger from its annotations.
+func (t *Trigger) GetTransformations() ([]EventTransformation, error) {
+	raw, ok := t.GetAnnotations()[TransformationsAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var transformations []EventTransformation
+	if err := json.Unmarshal([]byte(raw), &transformations); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", TransformationsAnnotationKey, err)
+	}
+	return transformations, nil
+}
+
+func validateTransformationsAnnotation(t *Trigger) *apis.FieldError {
+	transformations, err := t.GetTransformations()
+	if err != nil {
+		return apis.ErrInvalidValue(t.GetAnnotations()[TransformationsAnnotationKey], TransformationsAnnotationKey)
+	}
+	var errs *apis.FieldError
+	for i, tr := range transformations {
+		errs = errs.Also(tr.Validate().ViaIndex(i).ViaKey(TransformationsAnnotationKey))
+	}
+	return errs
+}
+
+// Validate validates a transformation.
+func (tr *EventTransformation) Validate() *apis.FieldError {
+	var ops []string
+	var err error
+	if len(tr.Set) > 0 {
+		ops = append(ops, "set")
+		_, err = transform.Set(tr.Set)
+	}
+	if len(tr.Remove) > 0 {
+		ops = append(ops, "remove")
+		_, err = transform.Remove(tr.Remove)
+	}
+	if len(tr.Rename) > 0 {
+		ops = append(ops, "rename")
+		_, err = transform.Rename(tr.Rename)
+	}
+	if len(tr.Extract) > 0 {
+		ops = append(ops, "extract")
+		_, err = transform.Extract(tr.Extract)
+	}
+	if tr.Data != "" {
+		ops = append(ops, "data")
+		_, err = transform.Data(tr.Data)
+	}
+	switch {
+	case len(ops) == 0:
+		return apis.ErrMissingOneOf("set", "remove", "rename", "extract", "data")
+	case len(ops) > 1:
+		return apis.ErrMultipleOneOf(ops...)
+	case err != nil:
+		return &apis.FieldError{Message: err.Error(), Paths: ops}
+	}
+	return nil
+}
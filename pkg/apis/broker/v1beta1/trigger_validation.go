@@ -24,7 +24,15 @@ import (
 
 // Validate the Trigger.
 func (t *Trigger) Validate(ctx context.Context) *apis.FieldError {
-	// The Google Cloud Broker doesn't have any custom validations. The
-	// eventing webhook will run the usual validations.
-	return nil
+	// The eventing webhook will run the usual validations. The Google Cloud
+	// Broker only validates the annotations of its own features.
+	errs := validateFiltersAnnotation(t)
+	errs = errs.Also(validateRateLimit(t.GetAnnotations(), TriggerRateLimitAnnotationKey, TriggerRateLimitBurstAnnotationKey))
+	errs = errs.Also(validateDeliveryAuth(t))
+	errs = errs.Also(validateDeliveryLimits(t))
+	errs = errs.Also(validateReply(ctx, t))
+	errs = errs.Also(validateTriggerReplay(t))
+	errs = errs.Also(validateIsolated(t))
+	errs = errs.Also(validateTransformationsAnnotation(t))
+	return errs.ViaField("metadata", "annotations")
 }
@@ -19,6 +19,8 @@ package v1beta1
 import (
 	"context"
 	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestTrigger_Validate(t *testing.T) {
@@ -27,3 +29,123 @@ func TestTrigger_Validate(t *testing.T) {
 		t.Errorf("expected nil, got %v", err)
 	}
 }
+
+func TestTrigger_ValidateFilters(t *testing.T) {
+	cases := []struct {
+		name    string
+		filters string
+		wantErr bool
+	}{{
+		name:    "valid filters",
+		filters: `[{"prefix":{"type":"com.example."}},{"any":[{"exact":{"source":"a"}},{"not":{"sql":"priority > 3"}}]}]`,
+	}, {
+		name:    "invalid json",
+		filters: `{"prefix":`,
+		wantErr: true,
+	}, {
+		name:    "no dialect",
+		filters: `[{}]`,
+		wantErr: true,
+	}, {
+		name:    "multiple dialects",
+		filters: `[{"prefix":{"type":"a"},"suffix":{"type":"b"}}]`,
+		wantErr: true,
+	}, {
+		name:    "invalid nested sql",
+		filters: `[{"all":[{"sql":"type = "}]}]`,
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{FiltersAnnotationKey: tc.filters},
+			}}
+			err := trig.Validate(context.TODO())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrigger_ValidateTransformations(t *testing.T) {
+	cases := []struct {
+		name            string
+		transformations string
+		wantErr         bool
+	}{{
+		name:            "valid transformations",
+		transformations: `[{"rename":{"subject":"topic"}},{"set":{"type":"com.example.order"}},{"extract":{"orderid":"{.order.id}"}},{"remove":["time"]},{"data":"{{ json .data.order }}"}]`,
+	}, {
+		name:            "invalid json",
+		transformations: `[{"set":`,
+		wantErr:         true,
+	}, {
+		name:            "no operation",
+		transformations: `[{}]`,
+		wantErr:         true,
+	}, {
+		name:            "multiple operations",
+		transformations: `[{"set":{"type":"a"},"remove":["subject"]}]`,
+		wantErr:         true,
+	}, {
+		name:            "remove required attribute",
+		transformations: `[{"remove":["source"]}]`,
+		wantErr:         true,
+	}, {
+		name:            "invalid attribute name",
+		transformations: `[{"set":{"Order-ID":"a"}}]`,
+		wantErr:         true,
+	}, {
+		name:            "invalid jsonpath",
+		transformations: `[{"extract":{"orderid":"{.order["}}]`,
+		wantErr:         true,
+	}, {
+		name:            "invalid data template",
+		transformations: `[{"data":"{{ .data"}]`,
+		wantErr:         true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{TransformationsAnnotationKey: tc.transformations},
+			}}
+			err := trig.Validate(context.TODO())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrigger_ValidateDeliveryAuth(t *testing.T) {
+	cases := []struct {
+		name    string
+		auth    string
+		wantErr bool
+	}{{
+		name: "google id token",
+		auth: DeliveryAuthGoogleIDToken,
+	}, {
+		name: "kubernetes token",
+		auth: DeliveryAuthKubernetesToken,
+	}, {
+		name:    "unknown auth",
+		auth:    "basic",
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trig := Trigger{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{DeliveryAuthAnnotationKey: tc.auth},
+			}}
+			err := trig.Validate(context.TODO())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if !tc.wantErr && trig.GetDeliveryAuth() != tc.auth {
+				t.Errorf("GetDeliveryAuth() got=%q, want=%q", trig.GetDeliveryAuth(), tc.auth)
+			}
+		})
+	}
+}
@@ -43,6 +43,7 @@ func ToV1PubSubSpec(from duckv1beta1.PubSubSpec) duckv1.PubSubSpec {
 	to.IdentitySpec = ToV1IdentitySpec(from.IdentitySpec)
 	to.Secret = from.Secret
 	to.Project = from.Project
+	to.Delivery = ToV1DeliverySpec(from.Delivery)
 	return to
 }
 
@@ -61,6 +62,33 @@ func FromV1PubSubSpec(from duckv1.PubSubSpec) duckv1beta1.PubSubSpec {
 	to.IdentitySpec = FromV1IdentitySpec(from.IdentitySpec)
 	to.Secret = from.Secret
 	to.Project = from.Project
+	to.Delivery = FromV1DeliverySpec(from.Delivery)
+	return to
+}
+
+func ToV1DeliverySpec(from *duckv1beta1.DeliverySpec) *duckv1.DeliverySpec {
+	if from == nil {
+		return nil
+	}
+	to := &duckv1.DeliverySpec{}
+	to.MaxDeliveryAttempts = from.MaxDeliveryAttempts
+	to.DeadLetterTopic = from.DeadLetterTopic
+	to.DeadLetterSink = from.DeadLetterSink
+	to.MinimumBackoff = from.MinimumBackoff
+	to.MaximumBackoff = from.MaximumBackoff
+	return to
+}
+
+func FromV1DeliverySpec(from *duckv1.DeliverySpec) *duckv1beta1.DeliverySpec {
+	if from == nil {
+		return nil
+	}
+	to := &duckv1beta1.DeliverySpec{}
+	to.MaxDeliveryAttempts = from.MaxDeliveryAttempts
+	to.DeadLetterTopic = from.DeadLetterTopic
+	to.DeadLetterSink = from.DeadLetterSink
+	to.MinimumBackoff = from.MinimumBackoff
+	to.MaximumBackoff = from.MaximumBackoff
 	return to
 }
 
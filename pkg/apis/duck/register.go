@@ -17,6 +17,8 @@ limitations under the License.
 // Package duck contains Cloud Run Events API versions for duck components
 package duck
 
+import "time"
+
 const (
 	GroupName = "duck.cloud.google.com"
 
@@ -76,4 +78,21 @@ const (
 
 	// defaultSecretName is the default secret name for the controller
 	DefaultSecretName = "google-cloud-key"
+
+	// MinDeliveryAttempts is the minimum number of delivery attempts Pub/Sub allows before dead lettering a message.
+	MinDeliveryAttempts = 5
+	// MaxDeliveryAttempts is the maximum number of delivery attempts Pub/Sub allows before dead lettering a message.
+	MaxDeliveryAttempts = 100
+	// DefaultMaxDeliveryAttempts is the number of delivery attempts before dead lettering an event when a dead
+	// letter is configured without a maximum number of delivery attempts.
+	DefaultMaxDeliveryAttempts = 5
+
+	// MinBackoff is the minimum allowed delay between the delivery attempts of an event.
+	MinBackoff = 0 * time.Second
+	// MaxBackoff is the maximum allowed delay between the delivery attempts of an event.
+	MaxBackoff = 600 * time.Second
+	// DefaultMinimumBackoff is the minimum backoff Pub/Sub uses when only the maximum backoff is set.
+	DefaultMinimumBackoff = 10 * time.Second
+	// DefaultMaximumBackoff is the maximum backoff Pub/Sub uses when only the minimum backoff is set.
+	DefaultMaximumBackoff = 600 * time.Second
 )
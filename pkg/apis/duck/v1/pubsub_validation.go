@@ -0,0 +1,68 @@
+/*
+Copyright 2020 Google LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/knative-gcp/pkg/apis/duck"
+	"knative.dev/pkg/apis"
+)
+
+// Validate validates the delivery spec of a PubSub duck type.
+func (d *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if d.MaxDeliveryAttempts != nil {
+		if a := *d.MaxDeliveryAttempts; a < duck.MinDeliveryAttempts || a > duck.MaxDeliveryAttempts {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(a, duck.MinDeliveryAttempts, duck.MaxDeliveryAttempts, "maxDeliveryAttempts"))
+		}
+	}
+	if d.DeadLetterTopic != "" && d.DeadLetterSink != nil {
+		errs = errs.Also(apis.ErrMultipleOneOf("deadLetterTopic", "deadLetterSink"))
+	}
+	if d.DeadLetterSink != nil {
+		if err := d.DeadLetterSink.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("deadLetterSink"))
+		}
+	}
+	minBackoff, err := validateBackoff(d.MinimumBackoff, "minimumBackoff")
+	errs = errs.Also(err)
+	maxBackoff, err := validateBackoff(d.MaximumBackoff, "maximumBackoff")
+	errs = errs.Also(err)
+	if d.MinimumBackoff != nil && d.MaximumBackoff != nil && err == nil && minBackoff > maxBackoff {
+		errs = errs.Also(&apis.FieldError{
+			Message: "minimumBackoff is greater than maximumBackoff",
+			Paths:   []string{"minimumBackoff", "maximumBackoff"},
+		})
+	}
+	return errs
+}
+
+func validateBackoff(backoff *string, field string) (time.Duration, *apis.FieldError) {
+	if backoff == nil {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(*backoff)
+	if err != nil {
+		return 0, apis.ErrInvalidValue(*backoff, field)
+	}
+	if d < duck.MinBackoff || d > duck.MaxBackoff {
+		return 0, apis.ErrOutOfBoundsValue(*backoff, duck.MinBackoff.String(), duck.MaxBackoff.String(), field)
+	}
+	return d, nil
+}
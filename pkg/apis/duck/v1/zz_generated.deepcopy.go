@@ -27,6 +27,42 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
+	*out = *in
+	if in.MaxDeliveryAttempts != nil {
+		in, out := &in.MaxDeliveryAttempts, &out.MaxDeliveryAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DeadLetterSink != nil {
+		in, out := &in.DeadLetterSink, &out.DeadLetterSink
+		*out = new(duckv1.Destination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinimumBackoff != nil {
+		in, out := &in.MinimumBackoff, &out.MinimumBackoff
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaximumBackoff != nil {
+		in, out := &in.MaximumBackoff, &out.MaximumBackoff
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeliverySpec.
+func (in *DeliverySpec) DeepCopy() *DeliverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdentitySpec) DeepCopyInto(out *IdentitySpec) {
 	*out = *in
@@ -131,6 +167,11 @@ func (in *PubSubSpec) DeepCopyInto(out *PubSubSpec) {
 		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Delivery != nil {
+		in, out := &in.Delivery, &out.Delivery
+		*out = new(DeliverySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
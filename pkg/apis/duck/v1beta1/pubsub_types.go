@@ -63,6 +63,47 @@ type PubSubSpec struct {
 	// If omitted, defaults to same as the cluster.
 	// +optional
 	Project string `json:"project,omitempty"`
+
+	// Delivery configures how the events that fail to be delivered to the sink are
+	// retried and dead lettered. If omitted, they are retried until they expire.
+	// +optional
+	Delivery *DeliverySpec `json:"delivery,omitempty"`
+}
+
+// DeliverySpec configures the retries and the dead letter of the events that a
+// source fails to deliver to its sink.
+type DeliverySpec struct {
+	// MaxDeliveryAttempts is the number of times an event is attempted to be
+	// delivered before it is dead lettered, or dropped if no dead letter is
+	// configured. Must be between 5 and 100. Defaults to 5 if a dead letter is
+	// configured, otherwise events are retried until they expire. With a
+	// DeadLetterSink, the attempts are counted by each replica of the receive
+	// adapter, so an event redelivered to several replicas may be attempted more.
+	// +optional
+	MaxDeliveryAttempts *int32 `json:"maxDeliveryAttempts,omitempty"`
+
+	// DeadLetterTopic is the ID of the Cloud Pub/Sub topic, in the same project as
+	// the source, that Pub/Sub forwards the undeliverable events to. The Pub/Sub
+	// service account must be allowed to publish to it and to acknowledge the
+	// messages of the source's subscription.
+	// Mutually exclusive with DeadLetterSink.
+	// +optional
+	DeadLetterTopic string `json:"deadLetterTopic,omitempty"`
+
+	// DeadLetterSink is the destination the undeliverable events are sent to.
+	// Mutually exclusive with DeadLetterTopic.
+	// +optional
+	DeadLetterSink *duckv1.Destination `json:"deadLetterSink,omitempty"`
+
+	// MinimumBackoff is the minimum delay between the delivery attempts of an
+	// event, e.g. "10s". Must be between 0 and 600 seconds.
+	// +optional
+	MinimumBackoff *string `json:"minimumBackoff,omitempty"`
+
+	// MaximumBackoff is the maximum delay between the delivery attempts of an
+	// event, e.g. "600s". Must be between 0 and 600 seconds.
+	// +optional
+	MaximumBackoff *string `json:"maximumBackoff,omitempty"`
 }
 
 // PubSubStatus shows how we expect folks to embed Addressable in
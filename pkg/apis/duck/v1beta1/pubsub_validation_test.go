@@ -0,0 +1,106 @@
+/*
+Copyright 2020 Google LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestDeliverySpec_Validate(t *testing.T) {
+	sink := &duckv1.Destination{
+		URI: apis.HTTP("dead-letter"),
+	}
+	testCases := map[string]struct {
+		spec    *DeliverySpec
+		wantErr bool
+	}{
+		"empty": {
+			spec: &DeliverySpec{},
+		},
+		"valid dead letter sink": {
+			spec: &DeliverySpec{
+				MaxDeliveryAttempts: ptr.Int32(10),
+				DeadLetterSink:      sink,
+				MinimumBackoff:      ptr.String("1s"),
+				MaximumBackoff:      ptr.String("1m"),
+			},
+		},
+		"valid dead letter topic": {
+			spec: &DeliverySpec{
+				DeadLetterTopic: "dead-letter",
+			},
+		},
+		"too few delivery attempts": {
+			spec: &DeliverySpec{
+				MaxDeliveryAttempts: ptr.Int32(4),
+			},
+			wantErr: true,
+		},
+		"too many delivery attempts": {
+			spec: &DeliverySpec{
+				MaxDeliveryAttempts: ptr.Int32(101),
+			},
+			wantErr: true,
+		},
+		"both dead letter topic and sink": {
+			spec: &DeliverySpec{
+				DeadLetterTopic: "dead-letter",
+				DeadLetterSink:  sink,
+			},
+			wantErr: true,
+		},
+		"invalid dead letter sink": {
+			spec: &DeliverySpec{
+				DeadLetterSink: &duckv1.Destination{},
+			},
+			wantErr: true,
+		},
+		"invalid backoff": {
+			spec: &DeliverySpec{
+				MinimumBackoff: ptr.String("1 minute"),
+			},
+			wantErr: true,
+		},
+		"backoff too long": {
+			spec: &DeliverySpec{
+				MaximumBackoff: ptr.String("11m"),
+			},
+			wantErr: true,
+		},
+		"minimum backoff greater than maximum backoff": {
+			spec: &DeliverySpec{
+				MinimumBackoff: ptr.String("1m"),
+				MaximumBackoff: ptr.String("1s"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := tc.spec.Validate(context.Background())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("Validate() got=%v, want error=%v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -51,6 +51,13 @@ func (current *CloudAuditLogsSourceSpec) Validate(ctx context.Context) *apis.Fie
 		errs = errs.Also(apis.ErrMissingField("methodName"))
 	}
 
+	// Delivery [optional]
+	if current.Delivery != nil {
+		if err := current.Delivery.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("delivery"))
+		}
+	}
+
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
 		errs = errs.Also(err)
 	}
@@ -66,7 +73,7 @@ func (current *CloudAuditLogsSource) CheckImmutableFields(ctx context.Context, o
 	// Modification of Topic, Secret, ServiceAccountName, Project, ServiceName, MethodName and ResourceName are not allowed. Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(CloudAuditLogsSourceSpec{},
-			"Sink", "CloudEventOverrides")); diff != "" {
+			"Sink", "CloudEventOverrides", "Delivery")); diff != "" {
 		return &apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
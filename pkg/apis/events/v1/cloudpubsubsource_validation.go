@@ -68,6 +68,13 @@ func (current *CloudPubSubSourceSpec) Validate(ctx context.Context) *apis.FieldE
 		}
 	}
 
+	// Delivery [optional]
+	if current.Delivery != nil {
+		if err := current.Delivery.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("delivery"))
+		}
+	}
+
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
 		errs = errs.Also(err)
 	}
@@ -83,7 +90,7 @@ func (current *CloudPubSubSource) CheckImmutableFields(ctx context.Context, orig
 	// Modification of Topic, Secret, ServiceAccountName and Project are not allowed. Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(CloudPubSubSourceSpec{},
-			"Sink", "AckDeadline", "RetainAckedMessages", "RetentionDuration", "CloudEventOverrides")); diff != "" {
+			"Sink", "AckDeadline", "RetainAckedMessages", "RetentionDuration", "CloudEventOverrides", "Delivery")); diff != "" {
 		return &apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
@@ -57,6 +57,13 @@ func (current *CloudSchedulerSourceSpec) Validate(ctx context.Context) *apis.Fie
 		errs = errs.Also(apis.ErrMissingField("data"))
 	}
 
+	// Delivery [optional]
+	if current.Delivery != nil {
+		if err := current.Delivery.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("delivery"))
+		}
+	}
+
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
 		errs = errs.Also(err)
 	}
@@ -71,7 +78,7 @@ func (current *CloudSchedulerSource) CheckImmutableFields(ctx context.Context, o
 	// Modification of Location, Schedule, Data, Secret, ServiceAccountName, Project are not allowed. Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(CloudSchedulerSourceSpec{},
-			"Sink", "CloudEventOverrides")); diff != "" {
+			"Sink", "CloudEventOverrides", "Delivery")); diff != "" {
 		return &apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
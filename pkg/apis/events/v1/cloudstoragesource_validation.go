@@ -48,6 +48,13 @@ func (current *CloudStorageSourceSpec) Validate(ctx context.Context) *apis.Field
 		errs = errs.Also(apis.ErrMissingField("bucket"))
 	}
 
+	// Delivery [optional]
+	if current.Delivery != nil {
+		if err := current.Delivery.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("delivery"))
+		}
+	}
+
 	if err := duck.ValidateCredential(current.Secret, current.ServiceAccountName); err != nil {
 		errs = errs.Also(err)
 	}
@@ -62,7 +69,7 @@ func (current *CloudStorageSource) CheckImmutableFields(ctx context.Context, ori
 	// Modification of EventType, Secret, ServiceAccountName, Project, Bucket, ObjectNamePrefix and PayloadFormat are not allowed. Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(CloudStorageSourceSpec{},
-			"Sink", "CloudEventOverrides")); diff != "" {
+			"Sink", "CloudEventOverrides", "Delivery")); diff != "" {
 		return &apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
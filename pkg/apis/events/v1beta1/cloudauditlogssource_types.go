@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	kngcpduck "github.com/google/knative-gcp/pkg/duck/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -81,6 +82,16 @@ type CloudAuditLogsSourceSpec struct {
 	// operation. The name is a scheme-less URI, not including the
 	// API service name.
 	ResourceName string `json:"resourceName,omitempty"`
+
+	// Mode defines the encoding and structure of the payload of when the
+	// CloudAuditLogsSource invokes the sink. Defaults to CloudEventsBinary.
+	// +optional
+	Mode inteventsv1beta1.ModeType `json:"mode,omitempty"`
+}
+
+// PubSubMode returns the mode currently set for the CloudAuditLogsSource.
+func (s *CloudAuditLogsSource) PubSubMode() inteventsv1beta1.ModeType {
+	return s.Spec.Mode
 }
 
 type CloudAuditLogsSourceStatus struct {
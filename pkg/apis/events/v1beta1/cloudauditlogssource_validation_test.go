@@ -25,6 +25,7 @@ import (
 
 	gcpauthtesthelper "github.com/google/knative-gcp/pkg/apis/configs/gcpauth/testhelper"
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 )
 
 var (
@@ -155,6 +156,22 @@ func TestCloudAuditLogsSourceValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"valid mode": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Mode = inteventsv1beta1.ModeCloudEventsStructured
+				return *obj
+			}(),
+			error: false,
+		},
+		"invalid mode": {
+			spec: func() CloudAuditLogsSourceSpec {
+				obj := auditLogsSourceSpec.DeepCopy()
+				obj.Mode = "invalid"
+				return *obj
+			}(),
+			error: true,
+		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
@@ -287,6 +304,17 @@ func TestCloudAuditLogsSourceCheckImmutableFields(t *testing.T) {
 			},
 			allowed: false,
 		},
+		"Mode changed": {
+			orig: &auditLogsSourceSpec,
+			updated: CloudAuditLogsSourceSpec{
+				MethodName:   auditLogsSourceSpec.MethodName,
+				PubSubSpec:   auditLogsSourceSpec.PubSubSpec,
+				ResourceName: auditLogsSourceSpec.ResourceName,
+				ServiceName:  auditLogsSourceSpec.ServiceName,
+				Mode:         inteventsv1beta1.ModePushCompatible,
+			},
+			allowed: true,
+		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
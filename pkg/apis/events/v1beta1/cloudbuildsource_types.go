@@ -15,6 +15,7 @@ package v1beta1
 
 import (
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	kngcpduck "github.com/google/knative-gcp/pkg/duck/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis/duck"
@@ -52,6 +53,16 @@ type CloudBuildSourceSpec struct {
 	// This brings in the PubSub based Source Specs. Includes:
 	// Sink, CloudEventOverrides, Secret and Project
 	duckv1beta1.PubSubSpec `json:",inline"`
+
+	// Mode defines the encoding and structure of the payload of when the
+	// CloudBuildSource invokes the sink. Defaults to CloudEventsBinary.
+	// +optional
+	Mode inteventsv1beta1.ModeType `json:"mode,omitempty"`
+}
+
+// PubSubMode returns the mode currently set for the CloudBuildSource.
+func (s *CloudBuildSource) PubSubMode() inteventsv1beta1.ModeType {
+	return s.Spec.Mode
 }
 
 const (
@@ -24,6 +24,7 @@ import (
 
 	"github.com/google/knative-gcp/pkg/apis/duck"
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	metadatatesting "github.com/google/knative-gcp/pkg/gclient/metadata/testing"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -192,6 +193,22 @@ func TestCloudBuildSourceCheckValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"valid mode": {
+			spec: func() CloudBuildSourceSpec {
+				obj := buildSourceSpec.DeepCopy()
+				obj.Mode = inteventsv1beta1.ModeCloudEventsStructured
+				return *obj
+			}(),
+			error: false,
+		},
+		"invalid mode": {
+			spec: func() CloudBuildSourceSpec {
+				obj := buildSourceSpec.DeepCopy()
+				obj.Mode = "invalid"
+				return *obj
+			}(),
+			error: true,
+		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
@@ -393,6 +410,15 @@ func TestCloudBuildSourceCheckImmutableFields(t *testing.T) {
 			},
 			allowed: true,
 		},
+		"Mode changed": {
+			orig: &buildSourceSpec,
+			updated: func() CloudBuildSourceSpec {
+				obj := buildSourceSpec.DeepCopy()
+				obj.Mode = inteventsv1beta1.ModePushCompatible
+				return *obj
+			}(),
+			allowed: true,
+		},
 		"no change": {
 			orig:    &buildSourceSpec,
 			updated: buildSourceSpec,
@@ -21,6 +21,7 @@ import (
 
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
 	"github.com/google/knative-gcp/pkg/apis/intevents"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	kngcpduck "github.com/google/knative-gcp/pkg/duck/v1beta1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -89,6 +90,16 @@ type CloudPubSubSourceSpec struct {
 	// shorter than 10 minutes. Defaults to 7 days ('7d').
 	// +optional
 	RetentionDuration *string `json:"retentionDuration,omitempty"`
+
+	// Mode defines the encoding and structure of the payload of when the
+	// CloudPubSubSource invokes the sink. Defaults to CloudEventsBinary.
+	// +optional
+	Mode inteventsv1beta1.ModeType `json:"mode,omitempty"`
+}
+
+// PubSubMode returns the mode currently set for the CloudPubSubSource.
+func (s *CloudPubSubSource) PubSubMode() inteventsv1beta1.ModeType {
+	return s.Spec.Mode
 }
 
 // GetAckDeadline parses AckDeadline and returns the default if an error occurs.
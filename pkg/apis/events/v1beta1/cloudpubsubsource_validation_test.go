@@ -27,6 +27,7 @@ import (
 
 	gcpauthtesthelper "github.com/google/knative-gcp/pkg/apis/configs/gcpauth/testhelper"
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 )
 
 var (
@@ -231,6 +232,22 @@ func TestCloudPubSubSourceCheckValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"valid mode": {
+			spec: func() CloudPubSubSourceSpec {
+				obj := pubSubSourceSpec.DeepCopy()
+				obj.Mode = inteventsv1beta1.ModeCloudEventsStructured
+				return *obj
+			}(),
+			error: false,
+		},
+		"invalid mode": {
+			spec: func() CloudPubSubSourceSpec {
+				obj := pubSubSourceSpec.DeepCopy()
+				obj.Mode = "invalid"
+				return *obj
+			}(),
+			error: true,
+		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
@@ -448,6 +465,15 @@ func TestCloudPubSubSourceCheckImmutableFields(t *testing.T) {
 			},
 			allowed: true,
 		},
+		"Mode changed": {
+			orig: &pubSubSourceSpec,
+			updated: func() CloudPubSubSourceSpec {
+				obj := pubSubSourceSpec.DeepCopy()
+				obj.Mode = inteventsv1beta1.ModePushCompatible
+				return *obj
+			}(),
+			allowed: true,
+		},
 		"no change": {
 			orig:    &pubSubSourceSpec,
 			updated: pubSubSourceSpec,
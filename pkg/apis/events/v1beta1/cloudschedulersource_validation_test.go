@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -356,6 +357,22 @@ func TestCloudSchedulerSourceSpecValidationFields(t *testing.T) {
 			}
 			return fe
 		}(),
+	}, {
+		name: "valid mode",
+		spec: func() *CloudSchedulerSourceSpec {
+			s := minimalCloudSchedulerSourceSpec.DeepCopy()
+			s.Mode = inteventsv1beta1.ModeCloudEventsStructured
+			return s
+		}(),
+		want: nil,
+	}, {
+		name: "invalid mode",
+		spec: func() *CloudSchedulerSourceSpec {
+			s := minimalCloudSchedulerSourceSpec.DeepCopy()
+			s.Mode = "invalid"
+			return s
+		}(),
+		want: apis.ErrInvalidValue("invalid", "mode"),
 	}}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -456,6 +473,15 @@ func TestCloudSchedulerSourceSpecCheckImmutableFields(t *testing.T) {
 			},
 			allowed: false,
 		},
+		"Mode changed": {
+			orig: &schedulerWithSecret,
+			updated: func() CloudSchedulerSourceSpec {
+				obj := schedulerWithSecret.DeepCopy()
+				obj.Mode = inteventsv1beta1.ModePushCompatible
+				return *obj
+			}(),
+			allowed: true,
+		},
 	}
 
 	for n, tc := range testCases {
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	kngcpduck "github.com/google/knative-gcp/pkg/duck/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -76,6 +77,16 @@ type CloudStorageSourceSpec struct {
 	// See https://cloud.google.com/storage/docs/pubsub-notifications#payload.
 	// +optional
 	PayloadFormat string `json:"payloadFormat,omitempty"`
+
+	// Mode defines the encoding and structure of the payload of when the
+	// CloudStorageSource invokes the sink. Defaults to CloudEventsBinary.
+	// +optional
+	Mode inteventsv1beta1.ModeType `json:"mode,omitempty"`
+}
+
+// PubSubMode returns the mode currently set for the CloudStorageSource.
+func (s *CloudStorageSource) PubSubMode() inteventsv1beta1.ModeType {
+	return s.Spec.Mode
 }
 
 const (
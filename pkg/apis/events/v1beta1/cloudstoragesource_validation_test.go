@@ -22,6 +22,7 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go"
 	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	inteventsv1beta1 "github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -350,6 +351,22 @@ func TestSpecValidationFields(t *testing.T) {
 			}
 			return fe
 		}(),
+	}, {
+		name: "valid mode",
+		spec: func() *CloudStorageSourceSpec {
+			s := minimalCloudStorageSourceSpec.DeepCopy()
+			s.Mode = inteventsv1beta1.ModeCloudEventsStructured
+			return s
+		}(),
+		want: nil,
+	}, {
+		name: "invalid mode",
+		spec: func() *CloudStorageSourceSpec {
+			s := minimalCloudStorageSourceSpec.DeepCopy()
+			s.Mode = "invalid"
+			return s
+		}(),
+		want: apis.ErrInvalidValue("invalid", "mode"),
 	}}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -485,6 +502,18 @@ func TestCheckImmutableFields(t *testing.T) {
 			},
 			allowed: false,
 		},
+		"Mode changed": {
+			orig: &storageSourceSpec,
+			updated: CloudStorageSourceSpec{
+				Bucket:           storageSourceSpec.Bucket,
+				EventTypes:       storageSourceSpec.EventTypes,
+				ObjectNamePrefix: storageSourceSpec.ObjectNamePrefix,
+				PayloadFormat:    storageSourceSpec.PayloadFormat,
+				PubSubSpec:       storageSourceSpec.PubSubSpec,
+				Mode:             inteventsv1beta1.ModePushCompatible,
+			},
+			allowed: true,
+		},
 	}
 
 	for n, tc := range testCases {
@@ -43,6 +43,18 @@ const (
 	MinAckDeadline = 0 * time.Second
 	// MinAckDeadline is the maximum ack deadline (10 minutes) to validate the pullSubscription.
 	MaxAckDeadline = 10 * time.Minute
+	// DefaultBatchMaxEvents is the default maximum number of events (100) in a batch of the pullSubscription.
+	DefaultBatchMaxEvents = 100
+	// MinBatchMaxEvents is the minimum maximum number of events (1) in a batch to validate the pullSubscription.
+	MinBatchMaxEvents = 1
+	// MaxBatchMaxEvents is the maximum maximum number of events (1000) in a batch to validate the pullSubscription.
+	MaxBatchMaxEvents = 1000
+	// DefaultBatchMaxLatency is the default maximum batch latency (1 second) of the pullSubscription.
+	DefaultBatchMaxLatency = time.Second
+	// MinBatchMaxLatency is the minimum maximum batch latency (1 millisecond) to validate the pullSubscription.
+	MinBatchMaxLatency = time.Millisecond
+	// MaxBatchMaxLatency is the maximum maximum batch latency (10 seconds) to validate the pullSubscription.
+	MaxBatchMaxLatency = 10 * time.Second
 )
 
 var (
@@ -44,4 +44,14 @@ func (ss *PullSubscriptionSpec) SetDefaults(ctx context.Context) {
 	}
 
 	ss.PubSubSpec.SetPubSubDefaults(ctx)
+
+	if ss.Batching != nil {
+		if ss.Batching.MaxEvents == nil {
+			ss.Batching.MaxEvents = ptr.Int32(intevents.DefaultBatchMaxEvents)
+		}
+		if ss.Batching.MaxLatency == nil {
+			maxLatency := intevents.DefaultBatchMaxLatency
+			ss.Batching.MaxLatency = ptr.String(maxLatency.String())
+		}
+	}
 }
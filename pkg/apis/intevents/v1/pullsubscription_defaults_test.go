@@ -99,6 +99,28 @@ func TestPullSubscriptionDefaults(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "batching",
+		start: &PullSubscription{
+			Spec: PullSubscriptionSpec{
+				Batching: &BatchingSpec{
+					MaxEvents: ptr.Int32(10),
+				},
+			},
+		},
+		want: &PullSubscription{
+			Spec: PullSubscriptionSpec{
+				RetentionDuration: ptr.String(defaultRetentionDuration.String()),
+				AckDeadline:       ptr.String(defaultAckDeadline.String()),
+				PubSubSpec: duckv1.PubSubSpec{
+					Secret: &gcpauthtesthelper.Secret,
+				},
+				Batching: &BatchingSpec{
+					MaxEvents:  ptr.Int32(10),
+					MaxLatency: ptr.String("1s"),
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -94,6 +94,26 @@ type PullSubscriptionSpec struct {
 	// PullSubscription uses.
 	// +optional
 	AdapterType string `json:"adapterType,omitempty"`
+
+	// Batching configures the PullSubscription to deliver events to the sink
+	// in batches, using the CloudEvents JSON batch format. If not set, events
+	// are delivered one at a time.
+	// +optional
+	Batching *BatchingSpec `json:"batching,omitempty"`
+}
+
+// BatchingSpec defines how the events of a PullSubscription are batched.
+type BatchingSpec struct {
+	// MaxEvents is the maximum number of events in a batch. Cannot be less
+	// than 1 or more than 1000. Defaults to 100.
+	// +optional
+	MaxEvents *int32 `json:"maxEvents,omitempty"`
+
+	// MaxLatency is the maximum time an event waits for its batch to fill
+	// up before the batch is delivered. Cannot be shorter than 1 millisecond
+	// or longer than 10 seconds. Defaults to 1 second ('1s').
+	// +optional
+	MaxLatency *string `json:"maxLatency,omitempty"`
 }
 
 // GetAckDeadline parses AckDeadline and returns the default if an error occurs.
@@ -116,6 +136,24 @@ func (ps PullSubscriptionSpec) GetRetentionDuration() time.Duration {
 	return intevents.DefaultRetentionDuration
 }
 
+// GetMaxEvents returns MaxEvents or the default if it's not set.
+func (bs BatchingSpec) GetMaxEvents() int {
+	if bs.MaxEvents != nil {
+		return int(*bs.MaxEvents)
+	}
+	return intevents.DefaultBatchMaxEvents
+}
+
+// GetMaxLatency parses MaxLatency and returns the default if an error occurs.
+func (bs BatchingSpec) GetMaxLatency() time.Duration {
+	if bs.MaxLatency != nil {
+		if duration, err := time.ParseDuration(*bs.MaxLatency); err == nil {
+			return duration
+		}
+	}
+	return intevents.DefaultBatchMaxLatency
+}
+
 const (
 	// PullSubscriptionConditionReady has status True when the PullSubscription is
 	// ready to send events.
@@ -154,6 +192,11 @@ type PullSubscriptionStatus struct {
 	// +optional
 	TransformerURI *apis.URL `json:"transformerUri,omitempty"`
 
+	// DeadLetterSinkURI is the current active dead letter sink URI that has
+	// been configured for the PullSubscription.
+	// +optional
+	DeadLetterSinkURI *apis.URL `json:"deadLetterSinkUri,omitempty"`
+
 	// SubscriptionID is the created subscription ID used by the PullSubscription.
 	// +optional
 	SubscriptionID string `json:"subscriptionId,omitempty"`
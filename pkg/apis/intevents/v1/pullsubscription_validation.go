@@ -75,6 +75,24 @@ func (current *PullSubscriptionSpec) Validate(ctx context.Context) *apis.FieldEr
 		}
 	}
 
+	// Delivery [optional]
+	if current.Delivery != nil {
+		if err := current.Delivery.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("delivery"))
+		}
+	}
+
+	// Batching [optional]
+	if current.Batching != nil {
+		if err := current.Batching.Validate(ctx); err != nil {
+			errs = errs.Also(err.ViaField("batching"))
+		}
+		// Replies from the transformer are only supported for single events.
+		if current.Transformer != nil && !equality.Semantic.DeepEqual(current.Transformer, &duckv1.Destination{}) {
+			errs = errs.Also(apis.ErrMultipleOneOf("batching", "transformer"))
+		}
+	}
+
 	if current.Secret != nil {
 		if !equality.Semantic.DeepEqual(current.Secret, &corev1.SecretKeySelector{}) {
 			err := validateSecret(current.Secret)
@@ -87,6 +105,24 @@ func (current *PullSubscriptionSpec) Validate(ctx context.Context) *apis.FieldEr
 	return errs
 }
 
+func (current *BatchingSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if current.MaxEvents != nil {
+		if me := *current.MaxEvents; me < intevents.MinBatchMaxEvents || me > intevents.MaxBatchMaxEvents {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(me, intevents.MinBatchMaxEvents, intevents.MaxBatchMaxEvents, "maxEvents"))
+		}
+	}
+	if current.MaxLatency != nil {
+		ml, err := time.ParseDuration(*current.MaxLatency)
+		if err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(*current.MaxLatency, "maxLatency"))
+		} else if ml < intevents.MinBatchMaxLatency || ml > intevents.MaxBatchMaxLatency {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(*current.MaxLatency, intevents.MinBatchMaxLatency.String(), intevents.MaxBatchMaxLatency.String(), "maxLatency"))
+		}
+	}
+	return errs
+}
+
 // TODO move this to a common place.
 func validateSecret(secret *corev1.SecretKeySelector) *apis.FieldError {
 	var errs *apis.FieldError
@@ -107,7 +143,7 @@ func (current *PullSubscription) CheckImmutableFields(ctx context.Context, origi
 	// Modification of Topic, Secret, ServiceAccountName and Project are not allowed. Everything else is mutable.
 	if diff := cmp.Diff(original.Spec, current.Spec,
 		cmpopts.IgnoreFields(PullSubscriptionSpec{},
-			"Sink", "Transformer", "AckDeadline", "RetainAckedMessages", "RetentionDuration", "CloudEventOverrides")); diff != "" {
+			"Sink", "Transformer", "AckDeadline", "RetainAckedMessages", "RetentionDuration", "CloudEventOverrides", "Delivery", "Batching")); diff != "" {
 		return &apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
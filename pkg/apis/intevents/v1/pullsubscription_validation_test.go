@@ -210,6 +210,60 @@ func TestPubSubCheckValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"ok batching": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxEvents:  ptr.Int32(500),
+					MaxLatency: ptr.String("100ms"),
+				}
+				return *obj
+			}(),
+			error: false,
+		},
+		"bad batching, max events range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxEvents: ptr.Int32(1001),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad batching, max latency": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxLatency: ptr.String("1 second"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad batching, max latency range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxLatency: ptr.String("1m"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad batching, with transformer": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = obj.Sink.DeepCopy()
+				obj.Batching = &BatchingSpec{}
+				return *obj
+			}(),
+			error: true,
+		},
 		"bad secret, missing key": {
 			spec: func() PullSubscriptionSpec {
 				obj := pullSubscriptionSpec.DeepCopy()
@@ -567,6 +621,17 @@ func TestPubSubCheckImmutableFields(t *testing.T) {
 			},
 			allowed: true,
 		},
+		"Batching changed": {
+			orig: &pullSubscriptionSpec,
+			updated: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Batching = &BatchingSpec{
+					MaxEvents: ptr.Int32(10),
+				}
+				return *obj
+			}(),
+			allowed: true,
+		},
 		"no change": {
 			orig:    &pullSubscriptionSpec,
 			updated: pullSubscriptionSpec,
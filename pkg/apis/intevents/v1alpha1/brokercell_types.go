@@ -17,6 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -70,6 +73,147 @@ var (
 
 // BrokerCellSpec defines the desired state of a Brokercell.
 type BrokerCellSpec struct {
+	// BrokerNamespace restricts the BrokerCell to the Brokers in the given namespace.
+	// Brokers in other namespaces can't be placed on it. If empty, Brokers in all
+	// namespaces can be placed on it.
+	// +optional
+	BrokerNamespace string `json:"brokerNamespace,omitempty"`
+
+	// Ingress holds the settings of the ingress component.
+	// +optional
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+
+	// Fanout holds the settings of the fanout component.
+	// +optional
+	Fanout *ComponentParameters `json:"fanout,omitempty"`
+
+	// Retry holds the settings of the retry component.
+	// +optional
+	Retry *ComponentParameters `json:"retry,omitempty"`
+}
+
+// ComponentParameters defines the settings of the Deployment and the autoscaler of a
+// BrokerCell component. Unset fields use the defaults of the component.
+type ComponentParameters struct {
+	// Resources overrides the default compute resources of the component container.
+	// Only the resources that are set are overridden. A default limit below an overridden
+	// request, or a default request above an overridden limit, is set to the overridden value.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// MinReplicas is the lower limit of the number of replicas the autoscaler can
+	// scale the component down to.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of the number of replicas the autoscaler can
+	// scale the component up to.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// AvgCPUUtilization is the target average CPU utilization of the component, as a
+	// percentage of the requested CPU.
+	// +optional
+	AvgCPUUtilization *int32 `json:"avgCPUUtilization,omitempty"`
+
+	// AvgMemoryUsage is the target average memory usage of the component.
+	// +optional
+	AvgMemoryUsage *resource.Quantity `json:"avgMemoryUsage,omitempty"`
+
+	// Metrics are additional metrics the autoscaler scales the component on, e.g. the
+	// backlog of a Pub/Sub subscription exported as an external metric.
+	// +optional
+	Metrics []hpav2beta2.MetricSpec `json:"metrics,omitempty"`
+
+	// PodAnnotations are added to the pods of the component.
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// NodeSelector constrains the pods of the component to the nodes with the given
+	// labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations let the pods of the component be scheduled on nodes with matching
+	// taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// BacklogAutoscaling scales the component on the backlog of the Pub/Sub subscriptions
+	// it pulls from with a KEDA ScaledObject, instead of with a HorizontalPodAutoscaler on
+	// its CPU and memory usage. The replica bounds still apply, the other autoscaling
+	// settings are ignored. Only the fanout and retry components support it.
+	// +optional
+	BacklogAutoscaling *BacklogAutoscaling `json:"backlogAutoscaling,omitempty"`
+}
+
+// BacklogAutoscaling defines how a BrokerCell component scales on the backlog of the
+// decouple subscriptions of its Brokers (fanout) or the retry subscriptions of their
+// Triggers (retry). The backlog of all the subscriptions is queried from Cloud Monitoring
+// at once with the KEDA gcp-stackdriver scaler, which requires KEDA 2.7 or later. Unset
+// fields use the KEDA defaults of PullSubscriptions.
+type BacklogAutoscaling struct {
+	// SubscriptionSize is the target number of undelivered messages per replica across
+	// the subscriptions.
+	// +optional
+	SubscriptionSize *int32 `json:"subscriptionSize,omitempty"`
+
+	// OldestUnackedMessageAge is the target age of the oldest unacked message across the
+	// subscriptions. The component is scaled on it in addition to the subscription size.
+	// +optional
+	OldestUnackedMessageAge *metav1.Duration `json:"oldestUnackedMessageAge,omitempty"`
+
+	// PollingInterval is the interval KEDA polls the subscription metrics at.
+	// +optional
+	PollingInterval *metav1.Duration `json:"pollingInterval,omitempty"`
+
+	// CooldownPeriod is how long KEDA waits after the backlog is drained before scaling
+	// the component down to its min replicas.
+	// +optional
+	CooldownPeriod *metav1.Duration `json:"cooldownPeriod,omitempty"`
+
+	// TriggerAuthentication is the name of the KEDA TriggerAuthentication in the namespace
+	// of the BrokerCell that authenticates the queries of the subscription metrics. If it's
+	// unset, a TriggerAuthentication using the GCP pod identity (Workload Identity) of the
+	// KEDA operator is created for the BrokerCell.
+	// +optional
+	TriggerAuthentication string `json:"triggerAuthentication,omitempty"`
+}
+
+// IngressSpec defines the settings of the BrokerCell ingress component.
+type IngressSpec struct {
+	ComponentParameters `json:",inline"`
+
+	// PublishSettings controls how the ingress batches events published to
+	// the decouple topics and how many publishes may be outstanding.
+	// +optional
+	PublishSettings *PublishSettings `json:"publishSettings,omitempty"`
+}
+
+// PublishSettings defines the Pub/Sub publish settings of the ingress.
+// Unset fields use the Pub/Sub client defaults.
+type PublishSettings struct {
+	// DelayThreshold is the max time to wait before publishing a non-empty batch.
+	// +optional
+	DelayThreshold *metav1.Duration `json:"delayThreshold,omitempty"`
+
+	// CountThreshold publishes a batch when it has this many events.
+	// +optional
+	CountThreshold *int32 `json:"countThreshold,omitempty"`
+
+	// ByteThreshold publishes a batch when its size in bytes reaches this value.
+	// +optional
+	ByteThreshold *int32 `json:"byteThreshold,omitempty"`
+
+	// MaxOutstandingMessages is the max number of events being published at
+	// the same time. Events exceeding the limit are rejected with 429.
+	// +optional
+	MaxOutstandingMessages *int32 `json:"maxOutstandingMessages,omitempty"`
+
+	// MaxOutstandingBytes is the max number of bytes being published at the
+	// same time. Events exceeding the limit are rejected with 429.
+	// +optional
+	MaxOutstandingBytes *int64 `json:"maxOutstandingBytes,omitempty"`
 }
 
 // BrokerCellStatus represents the current state of a BrokerCell.
@@ -84,6 +228,35 @@ type BrokerCellStatus struct {
 	// `namespace`.
 	// Example: "http://broker-ingress.cloud-run-events.svc.cluster.local/{namespace}/{name}"
 	IngressTemplate string `json:"ingressTemplate,omitempty"`
+
+	// TargetsConfigGeneration is the generation of the broker targets config that the
+	// data plane pods of the BrokerCell should apply. The pods report the generation
+	// they applied on the targets config stream, see pkg/broker/config/stream.
+	TargetsConfigGeneration int64 `json:"targetsConfigGeneration,omitempty"`
+
+	// TargetsConfigAppliedPods is the number of data plane pods of the BrokerCell that
+	// applied TargetsConfigGeneration or a later one.
+	TargetsConfigAppliedPods int32 `json:"targetsConfigAppliedPods,omitempty"`
+
+	// TargetsConfigPods is the number of data plane pods of the BrokerCell, not counting
+	// the pods being deleted. It's zero when the targets config isn't streamed, since the
+	// pods don't report the generation they applied.
+	TargetsConfigPods int32 `json:"targetsConfigPods,omitempty"`
+
+	// OpenCircuitBreakers lists the targets whose circuit breaker is open on some data
+	// plane pods of the BrokerCell, as the pods report them on the targets config stream.
+	// The trigger reconciler reflects them in the Trigger status.
+	// +optional
+	OpenCircuitBreakers []OpenCircuitBreaker `json:"openCircuitBreakers,omitempty"`
+}
+
+// OpenCircuitBreaker is a target whose circuit breaker is open on some data plane pods.
+type OpenCircuitBreaker struct {
+	// Target is the key of the target, i.e. namespace/broker/trigger.
+	Target string `json:"target"`
+
+	// Pods are the sorted names of the data plane pods the circuit breaker is open on.
+	Pods []string `json:"pods"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -18,12 +18,221 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
+const (
+	// maxPublishCountThreshold and maxPublishByteThreshold are the Pub/Sub
+	// limits of a single publish request.
+	maxPublishCountThreshold = 1000
+	maxPublishByteThreshold  = 10 * 1000 * 1000
+
+	// minKedaSubscriptionSize, minKedaPollingInterval and minKedaCooldownPeriod are the
+	// same lower bounds as the KEDA autoscaling annotations of PullSubscriptions.
+	minKedaSubscriptionSize = 5
+	minKedaPollingInterval  = 5 * time.Second
+	minKedaCooldownPeriod   = 15 * time.Second
+)
+
 // Validate verifies that the BrokerCell is valid.
 func (bc *BrokerCell) Validate(ctx context.Context) *apis.FieldError {
-	// The BrokerCell doesn't have any validations.
+	return bc.Spec.Validate(ctx).ViaField("spec")
+}
+
+func (bcs *BrokerCellSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if bcs.BrokerNamespace != "" && len(validation.IsDNS1123Label(bcs.BrokerNamespace)) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(bcs.BrokerNamespace, "brokerNamespace"))
+	}
+	if bcs.Ingress != nil {
+		errs = errs.Also(bcs.Ingress.Validate(ctx).ViaField("ingress"))
+	}
+	if bcs.Fanout != nil {
+		errs = errs.Also(bcs.Fanout.Validate(ctx).ViaField("fanout"))
+	}
+	if bcs.Retry != nil {
+		errs = errs.Also(bcs.Retry.Validate(ctx).ViaField("retry"))
+	}
+	return errs
+}
+
+func (is *IngressSpec) Validate(ctx context.Context) *apis.FieldError {
+	errs := is.ComponentParameters.Validate(ctx)
+	if is.BacklogAutoscaling != nil {
+		// The ingress doesn't pull from any subscription.
+		errs = errs.Also(apis.ErrDisallowedFields("backlogAutoscaling"))
+	}
+	if is.PublishSettings != nil {
+		errs = errs.Also(is.PublishSettings.Validate(ctx).ViaField("publishSettings"))
+	}
+	return errs
+}
+
+func (cp *ComponentParameters) Validate(ctx context.Context) *apis.FieldError {
+	errs := validateResources(cp.Resources).ViaField("resources")
+	if cp.MinReplicas != nil && *cp.MinReplicas < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(strconv.Itoa(int(*cp.MinReplicas)), "minReplicas"))
+	}
+	if cp.MaxReplicas != nil && *cp.MaxReplicas < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(strconv.Itoa(int(*cp.MaxReplicas)), "maxReplicas"))
+	}
+	if cp.MinReplicas != nil && cp.MaxReplicas != nil && *cp.MinReplicas > *cp.MaxReplicas {
+		errs = errs.Also(apis.ErrGeneric("minReplicas must not be greater than maxReplicas", "minReplicas", "maxReplicas"))
+	}
+	if cp.AvgCPUUtilization != nil && *cp.AvgCPUUtilization < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(strconv.Itoa(int(*cp.AvgCPUUtilization)), "avgCPUUtilization"))
+	}
+	if cp.AvgMemoryUsage != nil && cp.AvgMemoryUsage.Sign() <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(cp.AvgMemoryUsage.String(), "avgMemoryUsage"))
+	}
+	for i, m := range cp.Metrics {
+		errs = errs.Also(validateMetric(m).ViaFieldIndex("metrics", i))
+	}
+	for k := range cp.PodAnnotations {
+		if len(validation.IsQualifiedName(strings.ToLower(k))) > 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "podAnnotations"))
+		}
+	}
+	for k, v := range cp.NodeSelector {
+		if len(validation.IsQualifiedName(k)) > 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "nodeSelector"))
+		} else if len(validation.IsValidLabelValue(v)) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaFieldKey("nodeSelector", k))
+		}
+	}
+	for i, t := range cp.Tolerations {
+		errs = errs.Also(validateToleration(t).ViaFieldIndex("tolerations", i))
+	}
+	if cp.BacklogAutoscaling != nil {
+		errs = errs.Also(cp.BacklogAutoscaling.Validate(ctx).ViaField("backlogAutoscaling"))
+	}
+	return errs
+}
+
+func (ba *BacklogAutoscaling) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if ba.SubscriptionSize != nil && *ba.SubscriptionSize < minKedaSubscriptionSize {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*ba.SubscriptionSize, minKedaSubscriptionSize, math.MaxInt32, "subscriptionSize"))
+	}
+	if ba.OldestUnackedMessageAge != nil && ba.OldestUnackedMessageAge.Duration < time.Second {
+		errs = errs.Also(apis.ErrInvalidValue(ba.OldestUnackedMessageAge.Duration.String(), "oldestUnackedMessageAge"))
+	}
+	if ba.PollingInterval != nil && ba.PollingInterval.Duration < minKedaPollingInterval {
+		errs = errs.Also(apis.ErrInvalidValue(ba.PollingInterval.Duration.String(), "pollingInterval"))
+	}
+	if ba.CooldownPeriod != nil && ba.CooldownPeriod.Duration < minKedaCooldownPeriod {
+		errs = errs.Also(apis.ErrInvalidValue(ba.CooldownPeriod.Duration.String(), "cooldownPeriod"))
+	}
+	if ba.TriggerAuthentication != "" && len(validation.IsDNS1123Subdomain(ba.TriggerAuthentication)) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(ba.TriggerAuthentication, "triggerAuthentication"))
+	}
+	return errs
+}
+
+// validateResources verifies that the resource quantities aren't negative and that the
+// requests don't exceed the limits.
+func validateResources(rr corev1.ResourceRequirements) *apis.FieldError {
+	var errs *apis.FieldError
+	for name, q := range rr.Limits {
+		if q.Sign() < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(q.String(), apis.CurrentField).ViaFieldKey("limits", string(name)))
+		}
+	}
+	for name, q := range rr.Requests {
+		if q.Sign() < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(q.String(), apis.CurrentField).ViaFieldKey("requests", string(name)))
+			continue
+		}
+		if limit, ok := rr.Limits[name]; ok && q.Cmp(limit) > 0 {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("request %s must not be greater than limit %s", q.String(), limit.String()), apis.CurrentField).ViaFieldKey("requests", string(name)))
+		}
+	}
+	return errs
+}
+
+// validateMetric verifies that the source of the metric's type is set.
+func validateMetric(m hpav2beta2.MetricSpec) *apis.FieldError {
+	switch m.Type {
+	case hpav2beta2.ResourceMetricSourceType:
+		if m.Resource == nil {
+			return apis.ErrMissingField("resource")
+		}
+	case hpav2beta2.PodsMetricSourceType:
+		if m.Pods == nil {
+			return apis.ErrMissingField("pods")
+		}
+	case hpav2beta2.ObjectMetricSourceType:
+		if m.Object == nil {
+			return apis.ErrMissingField("object")
+		}
+	case hpav2beta2.ExternalMetricSourceType:
+		if m.External == nil {
+			return apis.ErrMissingField("external")
+		}
+	case "":
+		return apis.ErrMissingField("type")
+	default:
+		return apis.ErrInvalidValue(m.Type, "type")
+	}
 	return nil
 }
+
+func validateToleration(t corev1.Toleration) *apis.FieldError {
+	var errs *apis.FieldError
+	if t.Key != "" && len(validation.IsQualifiedName(t.Key)) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(t.Key, "key"))
+	}
+	switch t.Operator {
+	case corev1.TolerationOpEqual, "":
+		if t.Key == "" {
+			errs = errs.Also(apis.ErrGeneric("operator must be Exists when key is empty", "key", "operator"))
+		}
+		if len(validation.IsValidLabelValue(t.Value)) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(t.Value, "value"))
+		}
+	case corev1.TolerationOpExists:
+		if t.Value != "" {
+			errs = errs.Also(apis.ErrGeneric("value must be empty when operator is Exists", "value"))
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(t.Operator, "operator"))
+	}
+	switch t.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(t.Effect, "effect"))
+	}
+	if t.TolerationSeconds != nil && t.Effect != corev1.TaintEffectNoExecute {
+		errs = errs.Also(apis.ErrGeneric("tolerationSeconds requires effect NoExecute", "tolerationSeconds"))
+	}
+	return errs
+}
+
+func (ps *PublishSettings) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if ps.DelayThreshold != nil && ps.DelayThreshold.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(ps.DelayThreshold.Duration.String(), "delayThreshold"))
+	}
+	if ps.CountThreshold != nil && (*ps.CountThreshold < 1 || *ps.CountThreshold > maxPublishCountThreshold) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*ps.CountThreshold, 1, maxPublishCountThreshold, "countThreshold"))
+	}
+	if ps.ByteThreshold != nil && (*ps.ByteThreshold < 1 || *ps.ByteThreshold > maxPublishByteThreshold) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*ps.ByteThreshold, 1, maxPublishByteThreshold, "byteThreshold"))
+	}
+	if ps.MaxOutstandingMessages != nil && *ps.MaxOutstandingMessages < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(strconv.Itoa(int(*ps.MaxOutstandingMessages)), "maxOutstandingMessages"))
+	}
+	if ps.MaxOutstandingBytes != nil && *ps.MaxOutstandingBytes < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(strconv.FormatInt(*ps.MaxOutstandingBytes, 10), "maxOutstandingBytes"))
+	}
+	return errs
+}
@@ -19,11 +19,227 @@ package v1alpha1
 import (
 	"context"
 	"testing"
+	"time"
+
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/ptr"
 )
 
 func TestBrokerCell_Validate(t *testing.T) {
-	bc := BrokerCell{}
-	if err := bc.Validate(context.TODO()); err != nil {
-		t.Errorf("expected nil, got %v", err)
+	cases := []struct {
+		name    string
+		spec    BrokerCellSpec
+		wantErr string
+	}{{
+		name: "empty spec",
+	}, {
+		name: "valid publish settings",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				PublishSettings: &PublishSettings{
+					DelayThreshold:         &metav1.Duration{Duration: 10 * time.Millisecond},
+					CountThreshold:         ptr.Int32(100),
+					ByteThreshold:          ptr.Int32(1000000),
+					MaxOutstandingMessages: ptr.Int32(1000),
+					MaxOutstandingBytes:    ptr.Int64(100000000),
+				},
+			},
+		},
+	}, {
+		name: "broker namespace",
+		spec: BrokerCellSpec{BrokerNamespace: "team-a"},
+	}, {
+		name:    "invalid broker namespace",
+		spec:    BrokerCellSpec{BrokerNamespace: "Team_A"},
+		wantErr: "invalid value: Team_A: spec.brokerNamespace",
+	}, {
+		name: "invalid delay threshold",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				PublishSettings: &PublishSettings{
+					DelayThreshold: &metav1.Duration{Duration: -time.Second},
+				},
+			},
+		},
+		wantErr: "invalid value: -1s: spec.ingress.publishSettings.delayThreshold",
+	}, {
+		name: "count threshold out of bounds",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				PublishSettings: &PublishSettings{
+					CountThreshold: ptr.Int32(1001),
+				},
+			},
+		},
+		wantErr: "expected 1 <= 1001 <= 1000: spec.ingress.publishSettings.countThreshold",
+	}, {
+		name: "invalid outstanding limits",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				PublishSettings: &PublishSettings{
+					MaxOutstandingMessages: ptr.Int32(0),
+					MaxOutstandingBytes:    ptr.Int64(-1),
+				},
+			},
+		},
+		wantErr: "invalid value: -1: spec.ingress.publishSettings.maxOutstandingBytes\ninvalid value: 0: spec.ingress.publishSettings.maxOutstandingMessages",
+	}, {
+		name: "valid component parameters",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				ComponentParameters: ComponentParameters{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+					MinReplicas:       ptr.Int32(2),
+					MaxReplicas:       ptr.Int32(20),
+					AvgCPUUtilization: ptr.Int32(80),
+					PodAnnotations:    map[string]string{"cluster-autoscaler.kubernetes.io/safe-to-evict": "true"},
+				},
+			},
+			Fanout: &ComponentParameters{
+				AvgMemoryUsage: resourceQuantity("1Gi"),
+				Metrics: []hpav2beta2.MetricSpec{{
+					Type: hpav2beta2.ExternalMetricSourceType,
+					External: &hpav2beta2.ExternalMetricSource{
+						Metric: hpav2beta2.MetricIdentifier{Name: "pubsub.googleapis.com|subscription|num_undelivered_messages"},
+						Target: hpav2beta2.MetricTarget{Type: hpav2beta2.AverageValueMetricType, AverageValue: resourceQuantity("1000")},
+					},
+				}},
+			},
+			Retry: &ComponentParameters{
+				NodeSelector: map[string]string{"cloud.google.com/gke-nodepool": "broker"},
+				Tolerations: []corev1.Toleration{{
+					Key:      "dedicated",
+					Operator: corev1.TolerationOpEqual,
+					Value:    "broker",
+					Effect:   corev1.TaintEffectNoSchedule,
+				}},
+			},
+		},
+	}, {
+		name: "request exceeds limit",
+		spec: BrokerCellSpec{
+			Fanout: &ComponentParameters{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			},
+		},
+		wantErr: "request 2Gi must not be greater than limit 1Gi: spec.fanout.resources.requests[memory]",
+	}, {
+		name: "invalid replicas",
+		spec: BrokerCellSpec{
+			Retry: &ComponentParameters{
+				MinReplicas: ptr.Int32(5),
+				MaxReplicas: ptr.Int32(2),
+			},
+		},
+		wantErr: "minReplicas must not be greater than maxReplicas: spec.retry.maxReplicas, spec.retry.minReplicas",
+	}, {
+		name: "invalid autoscaling targets",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				ComponentParameters: ComponentParameters{
+					MinReplicas:       ptr.Int32(0),
+					AvgCPUUtilization: ptr.Int32(0),
+					AvgMemoryUsage:    resourceQuantity("0"),
+				},
+			},
+		},
+		wantErr: "invalid value: 0: spec.ingress.avgCPUUtilization, spec.ingress.avgMemoryUsage, spec.ingress.minReplicas",
+	}, {
+		name: "metric without source",
+		spec: BrokerCellSpec{
+			Fanout: &ComponentParameters{
+				Metrics: []hpav2beta2.MetricSpec{{Type: hpav2beta2.ExternalMetricSourceType}},
+			},
+		},
+		wantErr: "missing field(s): spec.fanout.metrics[0].external",
+	}, {
+		name: "invalid node selector",
+		spec: BrokerCellSpec{
+			Fanout: &ComponentParameters{
+				NodeSelector: map[string]string{"pool": "not valid"},
+			},
+		},
+		wantErr: "invalid value: not valid: spec.fanout.nodeSelector[pool]",
+	}, {
+		name: "invalid toleration",
+		spec: BrokerCellSpec{
+			Retry: &ComponentParameters{
+				Tolerations: []corev1.Toleration{{
+					Operator: corev1.TolerationOpExists,
+					Value:    "broker",
+					Effect:   "Never",
+				}},
+			},
+		},
+		wantErr: "invalid value: Never: spec.retry.tolerations[0].effect\nvalue must be empty when operator is Exists: spec.retry.tolerations[0].value",
+	}, {
+		name: "valid backlog autoscaling",
+		spec: BrokerCellSpec{
+			Fanout: &ComponentParameters{
+				MaxReplicas: ptr.Int32(20),
+				BacklogAutoscaling: &BacklogAutoscaling{
+					SubscriptionSize:        ptr.Int32(50),
+					OldestUnackedMessageAge: &metav1.Duration{Duration: 30 * time.Second},
+					PollingInterval:         &metav1.Duration{Duration: 10 * time.Second},
+					CooldownPeriod:          &metav1.Duration{Duration: time.Minute},
+					TriggerAuthentication:   "keda-gcp-credentials",
+				},
+			},
+			Retry: &ComponentParameters{
+				BacklogAutoscaling: &BacklogAutoscaling{},
+			},
+		},
+	}, {
+		name: "invalid backlog autoscaling",
+		spec: BrokerCellSpec{
+			Retry: &ComponentParameters{
+				BacklogAutoscaling: &BacklogAutoscaling{
+					SubscriptionSize:      ptr.Int32(1),
+					PollingInterval:       &metav1.Duration{Duration: time.Second},
+					TriggerAuthentication: "Invalid_Name",
+				},
+			},
+		},
+		wantErr: "expected 5 <= 1 <= 2147483647: spec.retry.backlogAutoscaling.subscriptionSize\ninvalid value: 1s: spec.retry.backlogAutoscaling.pollingInterval\ninvalid value: Invalid_Name: spec.retry.backlogAutoscaling.triggerAuthentication",
+	}, {
+		name: "ingress backlog autoscaling",
+		spec: BrokerCellSpec{
+			Ingress: &IngressSpec{
+				ComponentParameters: ComponentParameters{
+					BacklogAutoscaling: &BacklogAutoscaling{},
+				},
+			},
+		},
+		wantErr: "must not set the field(s): spec.ingress.backlogAutoscaling",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := BrokerCell{Spec: tc.spec}
+			err := bc.Validate(context.TODO())
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected nil, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("Validate got=%v, want=%v", err, tc.wantErr)
+			}
+		})
 	}
 }
+
+func resourceQuantity(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
@@ -21,18 +21,56 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apis "knative.dev/pkg/apis"
 	v1 "knative.dev/pkg/apis/duck/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BacklogAutoscaling) DeepCopyInto(out *BacklogAutoscaling) {
+	*out = *in
+	if in.SubscriptionSize != nil {
+		in, out := &in.SubscriptionSize, &out.SubscriptionSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OldestUnackedMessageAge != nil {
+		in, out := &in.OldestUnackedMessageAge, &out.OldestUnackedMessageAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BacklogAutoscaling.
+func (in *BacklogAutoscaling) DeepCopy() *BacklogAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(BacklogAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerCell) DeepCopyInto(out *BrokerCell) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -91,6 +129,21 @@ func (in *BrokerCellList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerCellSpec) DeepCopyInto(out *BrokerCellSpec) {
 	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fanout != nil {
+		in, out := &in.Fanout, &out.Fanout
+		*out = new(ComponentParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(ComponentParameters)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -108,6 +161,13 @@ func (in *BrokerCellSpec) DeepCopy() *BrokerCellSpec {
 func (in *BrokerCellStatus) DeepCopyInto(out *BrokerCellStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.OpenCircuitBreakers != nil {
+		in, out := &in.OpenCircuitBreakers, &out.OpenCircuitBreakers
+		*out = make([]OpenCircuitBreaker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -121,6 +181,160 @@ func (in *BrokerCellStatus) DeepCopy() *BrokerCellStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentParameters) DeepCopyInto(out *ComponentParameters) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AvgCPUUtilization != nil {
+		in, out := &in.AvgCPUUtilization, &out.AvgCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AvgMemoryUsage != nil {
+		in, out := &in.AvgMemoryUsage, &out.AvgMemoryUsage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2beta2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BacklogAutoscaling != nil {
+		in, out := &in.BacklogAutoscaling, &out.BacklogAutoscaling
+		*out = new(BacklogAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentParameters.
+func (in *ComponentParameters) DeepCopy() *ComponentParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
+	*out = *in
+	in.ComponentParameters.DeepCopyInto(&out.ComponentParameters)
+	if in.PublishSettings != nil {
+		in, out := &in.PublishSettings, &out.PublishSettings
+		*out = new(PublishSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressSpec.
+func (in *IngressSpec) DeepCopy() *IngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenCircuitBreaker) DeepCopyInto(out *OpenCircuitBreaker) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenCircuitBreaker.
+func (in *OpenCircuitBreaker) DeepCopy() *OpenCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishSettings) DeepCopyInto(out *PublishSettings) {
+	*out = *in
+	if in.DelayThreshold != nil {
+		in, out := &in.DelayThreshold, &out.DelayThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CountThreshold != nil {
+		in, out := &in.CountThreshold, &out.CountThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ByteThreshold != nil {
+		in, out := &in.ByteThreshold, &out.ByteThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOutstandingMessages != nil {
+		in, out := &in.MaxOutstandingMessages, &out.MaxOutstandingMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOutstandingBytes != nil {
+		in, out := &in.MaxOutstandingBytes, &out.MaxOutstandingBytes
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishSettings.
+func (in *PublishSettings) DeepCopy() *PublishSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullSubscription) DeepCopyInto(out *PullSubscription) {
 	*out = *in
@@ -45,6 +45,16 @@ func (ss *PullSubscriptionSpec) SetDefaults(ctx context.Context) {
 
 	ss.PubSubSpec.SetPubSubDefaults(ctx)
 
+	if ss.Batching != nil {
+		if ss.Batching.MaxEvents == nil {
+			ss.Batching.MaxEvents = ptr.Int32(intevents.DefaultBatchMaxEvents)
+		}
+		if ss.Batching.MaxLatency == nil {
+			maxLatency := intevents.DefaultBatchMaxLatency
+			ss.Batching.MaxLatency = ptr.String(maxLatency.String())
+		}
+	}
+
 	switch ss.Mode {
 	case ModeCloudEventsBinary, ModeCloudEventsStructured, ModePushCompatible:
 		// Valid Mode.
@@ -137,6 +137,29 @@ func TestPullSubscriptionDefaults(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "batching",
+		start: &PullSubscription{
+			Spec: PullSubscriptionSpec{
+				Batching: &BatchingSpec{
+					MaxEvents: ptr.Int32(10),
+				},
+			},
+		},
+		want: &PullSubscription{
+			Spec: PullSubscriptionSpec{
+				Mode:              ModeCloudEventsBinary,
+				RetentionDuration: ptr.String(defaultRetentionDuration.String()),
+				AckDeadline:       ptr.String(defaultAckDeadline.String()),
+				PubSubSpec: duckv1beta1.PubSubSpec{
+					Secret: &gcpauthtesthelper.Secret,
+				},
+				Batching: &BatchingSpec{
+					MaxEvents:  ptr.Int32(10),
+					MaxLatency: ptr.String("1s"),
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -96,6 +96,46 @@ func TestGetRetentionDuration_default(t *testing.T) {
 	}
 }
 
+func TestGetMaxEvents(t *testing.T) {
+	want := 10
+	s := &BatchingSpec{MaxEvents: ptr.Int32(10)}
+	got := s.GetMaxEvents()
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("failed to get expected (-want, +got) = %v", diff)
+	}
+}
+
+func TestGetMaxLatency(t *testing.T) {
+	want := 100 * time.Millisecond
+	s := &BatchingSpec{MaxLatency: ptr.String("100ms")}
+	got := s.GetMaxLatency()
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("failed to get expected (-want, +got) = %v", diff)
+	}
+}
+
+func TestGetMaxEvents_default(t *testing.T) {
+	want := intevents.DefaultBatchMaxEvents
+	s := &BatchingSpec{}
+	got := s.GetMaxEvents()
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("failed to get expected (-want, +got) = %v", diff)
+	}
+}
+
+func TestGetMaxLatency_default(t *testing.T) {
+	want := intevents.DefaultBatchMaxLatency
+	s := &BatchingSpec{}
+	got := s.GetMaxLatency()
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("failed to get expected (-want, +got) = %v", diff)
+	}
+}
+
 func TestPullSubscriptionIdentitySpec(t *testing.T) {
 	s := &PullSubscription{
 		Spec: PullSubscriptionSpec{
@@ -213,6 +213,82 @@ func TestPubSubCheckValidationFields(t *testing.T) {
 			}(),
 			error: true,
 		},
+		"ok delivery": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Delivery = &v1beta1.DeliverySpec{
+					MaxDeliveryAttempts: ptr.Int32(10),
+					DeadLetterSink:      obj.Sink.DeepCopy(),
+					MinimumBackoff:      ptr.String("10s"),
+				}
+				return *obj
+			}(),
+			error: false,
+		},
+		"bad delivery, attempts range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Delivery = &v1beta1.DeliverySpec{
+					MaxDeliveryAttempts: ptr.Int32(1),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"ok batching": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxEvents:  ptr.Int32(500),
+					MaxLatency: ptr.String("100ms"),
+				}
+				return *obj
+			}(),
+			error: false,
+		},
+		"bad batching, max events range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxEvents: ptr.Int32(1001),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad batching, max latency": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxLatency: ptr.String("1 second"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad batching, max latency range": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = nil
+				obj.Batching = &BatchingSpec{
+					MaxLatency: ptr.String("1m"),
+				}
+				return *obj
+			}(),
+			error: true,
+		},
+		"bad batching, with transformer": {
+			spec: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Transformer = obj.Sink.DeepCopy()
+				obj.Batching = &BatchingSpec{}
+				return *obj
+			}(),
+			error: true,
+		},
 		"bad secret, missing key": {
 			spec: func() PullSubscriptionSpec {
 				obj := pullSubscriptionSpec.DeepCopy()
@@ -343,6 +419,17 @@ func TestPubSubCheckImmutableFields(t *testing.T) {
 			},
 			allowed: false,
 		},
+		"Delivery changed": {
+			orig: &pullSubscriptionSpec,
+			updated: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Delivery = &v1beta1.DeliverySpec{
+					DeadLetterTopic: "dead-letter",
+				}
+				return *obj
+			}(),
+			allowed: true,
+		},
 		"Sink.APIVersion changed": {
 			orig: &pullSubscriptionSpec,
 			updated: PullSubscriptionSpec{
@@ -603,6 +690,17 @@ func TestPubSubCheckImmutableFields(t *testing.T) {
 			},
 			allowed: true,
 		},
+		"Batching changed": {
+			orig: &pullSubscriptionSpec,
+			updated: func() PullSubscriptionSpec {
+				obj := pullSubscriptionSpec.DeepCopy()
+				obj.Batching = &BatchingSpec{
+					MaxEvents: ptr.Int32(10),
+				}
+				return *obj
+			}(),
+			allowed: true,
+		},
 		"no change": {
 			orig:    &pullSubscriptionSpec,
 			updated: pullSubscriptionSpec,
@@ -27,6 +27,32 @@ import (
 	v1 "knative.dev/pkg/apis/duck/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchingSpec) DeepCopyInto(out *BatchingSpec) {
+	*out = *in
+	if in.MaxEvents != nil {
+		in, out := &in.MaxEvents, &out.MaxEvents
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxLatency != nil {
+		in, out := &in.MaxLatency, &out.MaxLatency
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchingSpec.
+func (in *BatchingSpec) DeepCopy() *BatchingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullSubscription) DeepCopyInto(out *PullSubscription) {
 	*out = *in
@@ -107,6 +133,11 @@ func (in *PullSubscriptionSpec) DeepCopyInto(out *PullSubscriptionSpec) {
 		*out = new(v1.Destination)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Batching != nil {
+		in, out := &in.Batching, &out.Batching
+		*out = new(BatchingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -129,6 +160,11 @@ func (in *PullSubscriptionStatus) DeepCopyInto(out *PullSubscriptionStatus) {
 		*out = new(apis.URL)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeadLetterSinkURI != nil {
+		in, out := &in.DeadLetterSinkURI, &out.DeadLetterSinkURI
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -0,0 +1,357 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// node is a node of the expression tree. Evaluated values are
+// either string, int32 or bool.
+type node interface {
+	eval(attrs map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type attributeNode struct {
+	name string
+}
+
+func (n *attributeNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	v, ok := attrs[n.name]
+	if !ok {
+		return nil, fmt.Errorf("missing attribute %q", n.name)
+	}
+	return v, nil
+}
+
+type existsNode struct {
+	attribute string
+}
+
+func (n *existsNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	_, ok := attrs[n.attribute]
+	return ok, nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	b, err := evalBool(n.operand, attrs)
+	if err != nil {
+		return nil, err
+	}
+	return !b, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	left, err := evalBool(n.left, attrs)
+	if err != nil {
+		return nil, err
+	}
+	// Short circuit AND and OR.
+	if n.op == "AND" && !left {
+		return false, nil
+	}
+	if n.op == "OR" && left {
+		return true, nil
+	}
+	right, err := evalBool(n.right, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "XOR" {
+		return left != right, nil
+	}
+	return right, nil
+}
+
+type comparisonNode struct {
+	op          string
+	left, right node
+}
+
+func (n *comparisonNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "=":
+		return equal(left, right)
+	case "!=", "<>":
+		eq, err := equal(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return !eq, nil
+	}
+	l, err := toInt(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toInt(right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+type likeNode struct {
+	operand node
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+func (n *likeNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	return n.pattern.MatchString(toString(v)) != n.negate, nil
+}
+
+type inNode struct {
+	operand node
+	set     []node
+	negate  bool
+}
+
+func (n *inNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range n.set {
+		ev, err := e.eval(attrs)
+		if err != nil {
+			return nil, err
+		}
+		eq, err := equal(v, ev)
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			return !n.negate, nil
+		}
+	}
+	return n.negate, nil
+}
+
+type arithmeticNode struct {
+	op          string
+	left, right node
+}
+
+func (n *arithmeticNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	l, err := evalInt(n.left, attrs)
+	if err != nil {
+		return nil, err
+	}
+	r, err := evalInt(n.right, attrs)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+	if r == 0 {
+		return nil, errors.New("division by zero")
+	}
+	if n.op == "/" {
+		return l / r, nil
+	}
+	return l % r, nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n *negateNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	v, err := evalInt(n.operand, attrs)
+	if err != nil {
+		return nil, err
+	}
+	return -v, nil
+}
+
+type functionNode struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []node
+}
+
+func (n *functionNode) eval(attrs map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, a := range n.args {
+		v, err := a.eval(attrs)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	v, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("function %s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+type function struct {
+	// arity is the number of arguments, or -1 if variadic.
+	arity int
+	call  func(args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"LOWER": {arity: 1, call: func(args []interface{}) (interface{}, error) {
+		return strings.ToLower(toString(args[0])), nil
+	}},
+	"UPPER": {arity: 1, call: func(args []interface{}) (interface{}, error) {
+		return strings.ToUpper(toString(args[0])), nil
+	}},
+	"TRIM": {arity: 1, call: func(args []interface{}) (interface{}, error) {
+		return strings.TrimSpace(toString(args[0])), nil
+	}},
+	"LENGTH": {arity: 1, call: func(args []interface{}) (interface{}, error) {
+		return int32(len([]rune(toString(args[0])))), nil
+	}},
+	"CONCAT": {arity: -1, call: func(args []interface{}) (interface{}, error) {
+		var sb strings.Builder
+		for _, a := range args {
+			sb.WriteString(toString(a))
+		}
+		return sb.String(), nil
+	}},
+	"ABS": {arity: 1, call: func(args []interface{}) (interface{}, error) {
+		i, err := toInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return -i, nil
+		}
+		return i, nil
+	}},
+}
+
+func evalBool(n node, attrs map[string]interface{}) (bool, error) {
+	v, err := n.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	return toBool(v)
+}
+
+func evalInt(n node, attrs map[string]interface{}) (int32, error) {
+	v, err := n.eval(attrs)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(v)
+}
+
+// equal compares two values after casting the right value to the type of the left value.
+func equal(left, right interface{}) (bool, error) {
+	switch l := left.(type) {
+	case bool:
+		r, err := toBool(right)
+		return l == r, err
+	case int32:
+		r, err := toInt(right)
+		return l == r, err
+	default:
+		return toString(left) == toString(right), nil
+	}
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		switch strings.ToLower(t) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("cannot cast %v to boolean", v)
+}
+
+func toInt(v interface{}) (int32, error) {
+	switch t := v.(type) {
+	case int32:
+		return t, nil
+	case string:
+		i, err := strconv.ParseInt(t, 10, 32)
+		if err == nil {
+			return int32(i), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot cast %v to integer", v)
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case int32:
+		return strconv.FormatInt(int64(t), 10)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
+)
+
+// EventAttributes returns the context attributes and extensions of the event
+// to evaluate expressions against. Optional attributes are only present if set.
+func EventAttributes(e *event.Event) map[string]interface{} {
+	attrs := map[string]interface{}{
+		"specversion": e.SpecVersion(),
+		"id":          e.ID(),
+		"source":      e.Source(),
+		"type":        e.Type(),
+	}
+	if v := e.Subject(); v != "" {
+		attrs["subject"] = v
+	}
+	if v := e.DataContentType(); v != "" {
+		attrs["datacontenttype"] = v
+	}
+	if v := e.DataSchema(); v != "" {
+		attrs["dataschema"] = v
+	}
+	if v := e.Time(); !v.IsZero() {
+		attrs["time"] = v.Format(time.RFC3339Nano)
+	}
+	for k, v := range e.Extensions() {
+		switch t := v.(type) {
+		case bool, int32:
+			attrs[k] = t
+		default:
+			s, err := cetypes.Format(v)
+			if err != nil {
+				continue
+			}
+			attrs[k] = s
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,25 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cesql implements a subset of the CloudEvents SQL expression
+// language (https://github.com/cloudevents/spec/blob/master/cesql/spec.md)
+// to filter events by their context attributes.
+//
+// Supported are boolean (NOT, AND, OR, XOR), comparison (=, !=, <>, <, <=,
+// >, >=), LIKE, IN, EXISTS and integer arithmetic (+, -, *, /, %) operators,
+// string, integer and boolean literals, and the LOWER, UPPER, TRIM, LENGTH,
+// CONCAT and ABS functions.
+package cesql
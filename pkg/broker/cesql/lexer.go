@@ -0,0 +1,146 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenInt
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	// text is the raw text of identifiers, integers and operators,
+	// and the unescaped value of string literals.
+	text string
+	pos  int
+}
+
+// keyword returns the upper case text if the token is an identifier,
+// so that keywords can be matched case-insensitively.
+func (t token) keyword() string {
+	if t.kind != tokenIdent {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// tokenize splits the expression into tokens. The last token is always tokenEOF.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '\'' || c == '"':
+			s, n, err := scanString(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i += n
+		case isDigit(c):
+			j := i
+			for j < len(expr) && isDigit(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenInt, text: expr[i:j], pos: i})
+			i = j
+		case isLetter(c):
+			j := i
+			for j < len(expr) && (isLetter(expr[j]) || isDigit(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expr[i:j], pos: i})
+			i = j
+		default:
+			op := scanOperator(expr[i:])
+			if op == "" {
+				return nil, fmt.Errorf("position %d: unexpected character %q", i, c)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// scanString scans a single or double quoted string literal at the start of s.
+// It returns the unescaped value and the number of bytes consumed.
+func scanString(s string) (string, int, error) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated string literal")
+			}
+			i++
+			// Only quotes and backslashes are unescaped. Other escape
+			// sequences, e.g. of LIKE wildcards, are kept as is.
+			if s[i] != '\\' && s[i] != '\'' && s[i] != '"' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(s[i])
+		case quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string literal")
+}
+
+func scanOperator(s string) string {
+	for _, op := range []string{"!=", "<>", "<=", ">=", "=", "<", ">", "+", "-", "*", "/", "%"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
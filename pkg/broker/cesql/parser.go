@@ -0,0 +1,348 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Expression is a parsed CESQL expression.
+type Expression struct {
+	src  string
+	root node
+}
+
+// Parse parses a CESQL expression.
+func Parse(expr string) (*Expression, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("position %d: unexpected token %q", t.pos, t.text)
+	}
+	return &Expression{src: expr, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Evaluate evaluates the expression against the given attributes and casts
+// the result to a boolean. Evaluation errors, such as referencing a missing
+// attribute, are returned together with false.
+func (e *Expression) Evaluate(attrs map[string]interface{}) (bool, error) {
+	v, err := e.root.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	return toBool(v)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// acceptKeyword consumes the next token if it is the given keyword.
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.peek().keyword() == kw {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if t := p.next(); t.kind != kind {
+		return fmt.Errorf("position %d: expected %q, got %q", t.pos, text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseXor()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseXor()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseXor() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("XOR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "XOR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.acceptKeyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind == tokenOperator {
+		switch t.text {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &comparisonNode{op: t.text, left: left, right: right}, nil
+		}
+		return left, nil
+	}
+
+	negate := false
+	if t.keyword() == "NOT" {
+		// Only NOT LIKE and NOT IN are valid after an operand.
+		if kw := p.tokens[p.pos+1].keyword(); kw == "LIKE" || kw == "IN" {
+			p.next()
+			negate = true
+		}
+	}
+	switch {
+	case p.acceptKeyword("LIKE"):
+		pt := p.next()
+		if pt.kind != tokenString {
+			return nil, fmt.Errorf("position %d: LIKE expects a string pattern, got %q", pt.pos, pt.text)
+		}
+		return &likeNode{operand: left, pattern: likePatternToRegexp(pt.text), negate: negate}, nil
+	case p.acceptKeyword("IN"):
+		if err := p.expect(tokenLeftParen, "("); err != nil {
+			return nil, err
+		}
+		var set []node
+		for {
+			n, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, n)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return &inNode{operand: left, set: set, negate: negate}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokenOperator && (t.text == "+" || t.text == "-"); t = p.peek() {
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{op: t.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokenOperator && (t.text == "*" || t.text == "/" || t.text == "%"); t = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{op: t.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t := p.peek(); t.kind == tokenOperator && t.text == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+	case tokenInt:
+		i, err := strconv.ParseInt(t.text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: invalid integer %q", t.pos, t.text)
+		}
+		return &literalNode{value: int32(i)}, nil
+	case tokenLeftParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case tokenIdent:
+		switch t.keyword() {
+		case "TRUE":
+			return &literalNode{value: true}, nil
+		case "FALSE":
+			return &literalNode{value: false}, nil
+		case "EXISTS":
+			at := p.next()
+			if at.kind != tokenIdent {
+				return nil, fmt.Errorf("position %d: EXISTS expects an attribute name, got %q", at.pos, at.text)
+			}
+			return &existsNode{attribute: strings.ToLower(at.text)}, nil
+		}
+		if p.peek().kind == tokenLeftParen {
+			return p.parseFunction(t)
+		}
+		return &attributeNode{name: strings.ToLower(t.text)}, nil
+	}
+	return nil, fmt.Errorf("position %d: unexpected token %q", t.pos, t.text)
+}
+
+func (p *parser) parseFunction(name token) (node, error) {
+	fn, ok := functions[name.keyword()]
+	if !ok {
+		return nil, fmt.Errorf("position %d: unknown function %q", name.pos, name.text)
+	}
+	p.next()
+	var args []node
+	if p.peek().kind != tokenRightParen {
+		for {
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, n)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(tokenRightParen, ")"); err != nil {
+		return nil, err
+	}
+	if fn.arity >= 0 && len(args) != fn.arity {
+		return nil, fmt.Errorf("position %d: function %s expects %d arguments, got %d", name.pos, name.keyword(), fn.arity, len(args))
+	}
+	return &functionNode{name: name.keyword(), fn: fn.call, args: args}, nil
+}
+
+// likePatternToRegexp converts a LIKE pattern, where % matches any sequence
+// of characters and _ matches a single character, to a regular expression.
+func likePatternToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
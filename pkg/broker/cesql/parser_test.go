@@ -0,0 +1,143 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestEvaluate(t *testing.T) {
+	attrs := map[string]interface{}{
+		"id":       "abc",
+		"source":   "/storage/bucket",
+		"type":     "com.google.storage.finalize",
+		"subject":  "objects/image.png",
+		"size":     int32(42),
+		"priority": "3",
+		"flag":     true,
+	}
+	cases := []struct {
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{expr: "TRUE", want: true},
+		{expr: "false", want: false},
+		{expr: "type = 'com.google.storage.finalize'", want: true},
+		{expr: "type != \"com.google.storage.finalize\"", want: false},
+		{expr: "type <> 'other'", want: true},
+		{expr: "type LIKE 'com.google.%'", want: true},
+		{expr: "subject LIKE '%.png'", want: true},
+		{expr: "subject NOT LIKE '%.png'", want: false},
+		{expr: "id LIKE 'a_c'", want: true},
+		{expr: "id LIKE 'a\\_c'", want: false},
+		{expr: "source IN ('/a', '/storage/bucket')", want: true},
+		{expr: "source NOT IN ('/a', '/storage/bucket')", want: false},
+		{expr: "EXISTS subject AND NOT EXISTS missing", want: true},
+		{expr: "size > 40 AND size <= 42", want: true},
+		{expr: "size + 8 = 50", want: true},
+		{expr: "size * 2 - 4 / 2 = 82", want: true},
+		{expr: "size % 5 = 2", want: true},
+		{expr: "-size < 0", want: true},
+		{expr: "priority > 2", want: true},
+		{expr: "priority = 3", want: true},
+		{expr: "flag", want: true},
+		{expr: "flag = 'true'", want: true},
+		{expr: "flag XOR TRUE", want: false},
+		{expr: "type = 'x' OR (size = 42 AND flag)", want: true},
+		{expr: "LOWER(UPPER(id)) = 'abc'", want: true},
+		{expr: "LENGTH(id) = 3", want: true},
+		{expr: "CONCAT(id, '-', size) = 'abc-42'", want: true},
+		{expr: "ABS(-size) = 42", want: true},
+		{expr: "TRIM('  x ') = 'x'", want: true},
+		{expr: "missing = 'x'", wantErr: true},
+		{expr: "type = 'x' OR missing = 'x'", wantErr: true},
+		{expr: "size / 0 = 1", wantErr: true},
+		{expr: "id > 1", wantErr: true},
+		{expr: "id", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) got unexpected error: %v", tc.expr, err)
+			}
+			got, err := e.Evaluate(attrs)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Evaluate() got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Evaluate() got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cases := []string{
+		"",
+		"type =",
+		"type = 'unterminated",
+		"(type = 'a'",
+		"type = 'a')",
+		"type LIKE 1",
+		"type IN 'a'",
+		"EXISTS 'a'",
+		"UNKNOWN(type)",
+		"LOWER(type, id)",
+		"type # 'a'",
+		"size = 99999999999",
+	}
+	for _, expr := range cases {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); err == nil {
+				t.Errorf("Parse(%q) got no error, want error", expr)
+			}
+		})
+	}
+}
+
+func TestEventAttributes(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("type")
+	e.SetExtension("count", 3)
+	e.SetExtension("enabled", true)
+	e.SetExtension("name", "value")
+
+	attrs := EventAttributes(&e)
+	want := map[string]interface{}{
+		"specversion": "1.0",
+		"id":          "id",
+		"source":      "source",
+		"type":        "type",
+		"count":       int32(3),
+		"enabled":     true,
+		"name":        "value",
+	}
+	if len(attrs) != len(want) {
+		t.Errorf("EventAttributes() got=%v, want=%v", attrs, want)
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Errorf("EventAttributes()[%q] got=%v, want=%v", k, attrs[k], v)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package circuitbreaker provides circuit breakers for targets configured in
+// the broker targets config.
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+type state int
+
+const (
+	closed state = iota
+	open
+	// halfOpen allows a single trial request after the cool down.
+	halfOpen
+)
+
+type breaker struct {
+	state    state
+	failures int32
+	// since is when the circuit was opened or the last trial request started.
+	since time.Time
+}
+
+// Breakers holds a circuit breaker per key, e.g. a target key. A circuit opens after
+// the configured number of consecutive failures and rejects requests for the cool down.
+// Then a trial request is allowed: its success closes the circuit and its failure opens
+// it for another cool down.
+type Breakers struct {
+	mu       sync.Mutex
+	breakers map[string]*breaker
+	now      func() time.Time
+}
+
+// NewBreakers creates an empty Breakers.
+func NewBreakers() *Breakers {
+	return &Breakers{
+		breakers: make(map[string]*breaker),
+		now:      time.Now,
+	}
+}
+
+// Allow returns true if a request of the key is allowed under the circuit breaker config.
+// A nil b or config doesn't reject anything.
+func (b *Breakers) Allow(key string, cb *config.CircuitBreaker) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br := b.get(key, cb)
+	if br == nil || br.state == closed {
+		return true
+	}
+	// The cool down also bounds a trial request whose result was never recorded.
+	if b.now().Sub(br.since) < coolDown(cb) {
+		return false
+	}
+	br.state = halfOpen
+	br.since = b.now()
+	return true
+}
+
+// Record records the result of a request of the key. It returns whether the circuit
+// is open after the result and whether that changed.
+func (b *Breakers) Record(key string, cb *config.CircuitBreaker, failed bool) (isOpen bool, changed bool) {
+	if b == nil {
+		return false, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br := b.get(key, cb)
+	if br == nil {
+		return false, false
+	}
+	wasOpen := br.state != closed
+	switch {
+	case !failed:
+		br.state = closed
+		br.failures = 0
+	case br.state == halfOpen:
+		br.state = open
+		br.since = b.now()
+	case br.state == closed:
+		br.failures++
+		if br.failures >= cb.FailureThreshold {
+			br.state = open
+			br.since = b.now()
+		}
+	}
+	isOpen = br.state != closed
+	return isOpen, isOpen != wasOpen
+}
+
+// OpenKeys returns the sorted keys whose circuit is not closed.
+func (b *Breakers) OpenKeys() []string {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var keys []string
+	for key, br := range b.breakers {
+		if br.state != closed {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Prune removes the breakers of keys that no longer exist, e.g. deleted targets.
+func (b *Breakers) Prune(exists func(key string) bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.breakers {
+		if !exists(key) {
+			delete(b.breakers, key)
+		}
+	}
+}
+
+// get returns the breaker of the key, or nil if the config has no circuit breaker.
+// It must be called with the lock held.
+func (b *Breakers) get(key string, cb *config.CircuitBreaker) *breaker {
+	if cb == nil || cb.FailureThreshold <= 0 {
+		delete(b.breakers, key)
+		return nil
+	}
+	br, ok := b.breakers[key]
+	if !ok {
+		br = &breaker{}
+		b.breakers[key] = br
+	}
+	return br
+}
+
+func coolDown(cb *config.CircuitBreaker) time.Duration {
+	return time.Duration(cb.CoolDownSeconds) * time.Second
+}
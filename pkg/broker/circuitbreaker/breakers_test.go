@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestBreakers(t *testing.T) {
+	now := time.Now()
+	b := NewBreakers()
+	b.now = func() time.Time { return now }
+	cb := &config.CircuitBreaker{FailureThreshold: 2, CoolDownSeconds: 10}
+
+	record := func(failed, wantOpen, wantChanged bool) {
+		t.Helper()
+		open, changed := b.Record("key", cb, failed)
+		if open != wantOpen || changed != wantChanged {
+			t.Fatalf("Record(failed=%v) got open=%v changed=%v, want open=%v changed=%v", failed, open, changed, wantOpen, wantChanged)
+		}
+	}
+	allow := func(want bool) {
+		t.Helper()
+		if got := b.Allow("key", cb); got != want {
+			t.Fatalf("Allow got=%v, want=%v", got, want)
+		}
+	}
+
+	// A success resets the consecutive failures.
+	record(true, false, false)
+	record(false, false, false)
+	record(true, false, false)
+	record(false, false, false)
+	allow(true)
+
+	// Consecutive failures open the circuit.
+	record(true, false, false)
+	record(true, true, true)
+	allow(false)
+	if diff := cmp.Diff([]string{"key"}, b.OpenKeys()); diff != "" {
+		t.Errorf("OpenKeys (-want,+got): %v", diff)
+	}
+
+	// A single trial is allowed after the cool down.
+	now = now.Add(10 * time.Second)
+	allow(true)
+	allow(false)
+	// A failed trial opens the circuit for another cool down.
+	record(true, true, false)
+	allow(false)
+
+	// A trial whose result is never recorded doesn't block further trials.
+	now = now.Add(10 * time.Second)
+	allow(true)
+	now = now.Add(10 * time.Second)
+	allow(true)
+	// A successful trial closes the circuit.
+	record(false, false, true)
+	allow(true)
+	if keys := b.OpenKeys(); len(keys) != 0 {
+		t.Errorf("OpenKeys got=%v, want empty", keys)
+	}
+}
+
+func TestBreakersDisabled(t *testing.T) {
+	var nilBreakers *Breakers
+	for _, b := range []*Breakers{nilBreakers, NewBreakers()} {
+		for _, cb := range []*config.CircuitBreaker{nil, {}} {
+			for i := 0; i < 5; i++ {
+				if open, _ := b.Record("key", cb, true); open {
+					t.Fatal("Record got open=true, want=false")
+				}
+				if !b.Allow("key", cb) {
+					t.Fatal("Allow got=false, want=true")
+				}
+			}
+		}
+	}
+}
+
+func TestBreakersPrune(t *testing.T) {
+	b := NewBreakers()
+	cb := &config.CircuitBreaker{FailureThreshold: 1, CoolDownSeconds: 10}
+	b.Record("deleted", cb, true)
+	b.Record("existing", cb, true)
+
+	b.Prune(func(key string) bool { return key == "existing" })
+	if diff := cmp.Diff([]string{"existing"}, b.OpenKeys()); diff != "" {
+		t.Errorf("OpenKeys (-want,+got): %v", diff)
+	}
+}
@@ -21,6 +21,8 @@ import (
 
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
+
+	"github.com/google/knative-gcp/pkg/broker/transform"
 )
 
 // CachedTargets provides a in-memory cached copy of targets.
@@ -28,17 +30,41 @@ type CachedTargets struct {
 	Value atomic.Value
 }
 
+// cachedValue is a TargetsConfig with the filters and transformations of
+// its targets compiled, so that they are compiled only once per config update.
+type cachedValue struct {
+	config          *TargetsConfig
+	filters         map[string]EventFilter
+	transformations map[string]transform.Operation
+}
+
 var _ ReadonlyTargets = (*CachedTargets)(nil)
 
 // Store atomically stores a TargetsConfig.
 func (ct *CachedTargets) Store(t *TargetsConfig) {
-	ct.Value.Store(t)
+	filters := make(map[string]EventFilter)
+	transformations := make(map[string]transform.Operation)
+	for _, b := range t.GetBrokers() {
+		for _, target := range b.Targets {
+			if len(target.Filters) > 0 {
+				filters[target.Key()] = CompileFilters(target.Filters)
+			}
+			if len(target.Transformations) > 0 {
+				transformations[target.Key()] = CompileTransformations(target.Transformations)
+			}
+		}
+	}
+	ct.Value.Store(&cachedValue{config: t, filters: filters, transformations: transformations})
 }
 
 // Load atomically loads a stored TargetsConfig.
 // If there was no TargetsConfig stored, nil will be returned.
 func (ct *CachedTargets) Load() *TargetsConfig {
-	return ct.Value.Load().(*TargetsConfig)
+	val, ok := ct.Value.Load().(*cachedValue)
+	if !ok {
+		return nil
+	}
+	return val.config
 }
 
 // RangeAllTargets ranges over all targets.
@@ -75,6 +101,28 @@ func (ct *CachedTargets) GetTargetByKey(key string) (*Target, bool) {
 	return ct.GetTarget(namespace, brokerName, targetName)
 }
 
+// GetTargetFilter returns the compiled filter of a target by its trigger key.
+// It returns false if the target doesn't exist or has no filters.
+func (ct *CachedTargets) GetTargetFilter(key string) (EventFilter, bool) {
+	val, ok := ct.Value.Load().(*cachedValue)
+	if !ok {
+		return nil, false
+	}
+	f, ok := val.filters[key]
+	return f, ok
+}
+
+// GetTargetTransformation returns the compiled transformations of a target by its trigger key.
+// It returns false if the target doesn't exist or has no transformations.
+func (ct *CachedTargets) GetTargetTransformation(key string) (transform.Operation, bool) {
+	val, ok := ct.Value.Load().(*cachedValue)
+	if !ok {
+		return nil, false
+	}
+	op, ok := val.transformations[key]
+	return op, ok
+}
+
 // GetBroker returns a broker and its targets if it exists.
 // Do not modify the returned Broker copy.
 func (ct *CachedTargets) GetBroker(namespace, name string) (*Broker, bool) {
@@ -19,6 +19,8 @@ package config
 import (
 	"fmt"
 	"strings"
+
+	"github.com/google/knative-gcp/pkg/broker/transform"
 )
 
 // ReadonlyTargets provides "read" functions for brokers and targets.
@@ -32,6 +34,12 @@ type ReadonlyTargets interface {
 	// GetTargetByKey returns a target by its trigger key. The format of trigger key is namespace/brokerName/targetName.
 	// Do not modify the returned Target copy.
 	GetTargetByKey(key string) (*Target, bool)
+	// GetTargetFilter returns the compiled filter of a target by its trigger key.
+	// It returns false if the target doesn't exist or has no filters.
+	GetTargetFilter(key string) (EventFilter, bool)
+	// GetTargetTransformation returns the compiled transformations of a target by its trigger key.
+	// It returns false if the target doesn't exist or has no transformations.
+	GetTargetTransformation(key string) (transform.Operation, bool)
 	// GetBroker returns a broker and its targets if it exists.
 	// Do not modify the returned Broker copy.
 	GetBroker(namespace, name string) (*Broker, bool)
@@ -63,6 +71,12 @@ type BrokerMutation interface {
 	SetDecoupleQueue(q *Queue) BrokerMutation
 	// SetState sets the broker state.
 	SetState(s State) BrokerMutation
+	// SetOrderingKeyAttribute sets the broker ordering key attribute.
+	SetOrderingKeyAttribute(attr string) BrokerMutation
+	// SetRateLimit sets the broker ingress rate limit.
+	SetRateLimit(rl *RateLimit) BrokerMutation
+	// SetAllowedPublishers sets the identities allowed to publish to the broker.
+	SetAllowedPublishers(publishers []string) BrokerMutation
 	// UpsertTargets upserts Targets to the broker.
 	// The targets' namespace and broker will be forced to be
 	// the same as the broker's namespace and name.
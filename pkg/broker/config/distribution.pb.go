@@ -0,0 +1,502 @@
+//
+//Copyright 2020 Google LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.8.0
+// source: pkg/broker/config/distribution.proto
+
+package config
+
+import (
+	context "context"
+	reflect "reflect"
+	sync "sync"
+
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// A request of the targets config stream.
+type WatchTargetsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the data plane pod.
+	Node string `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	// The version of the last update the pod applied, or 0 if it has none.
+	Version int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	// The error applying the last update. Non-empty if the update was rejected.
+	ErrorDetail string `protobuf:"bytes,3,opt,name=error_detail,json=errorDetail,proto3" json:"error_detail,omitempty"`
+	// The name of the brokercell of the data plane pod. Only the first request
+	// of a stream needs it.
+	Cell string `protobuf:"bytes,4,opt,name=cell,proto3" json:"cell,omitempty"`
+	// The keys of the targets whose circuit breaker is open on the pod. Every
+	// request carries them, and the pod sends a request with its current version
+	// whenever they change.
+	OpenCircuitBreakers []string `protobuf:"bytes,5,rep,name=open_circuit_breakers,json=openCircuitBreakers,proto3" json:"open_circuit_breakers,omitempty"`
+}
+
+func (x *WatchTargetsRequest) Reset() {
+	*x = WatchTargetsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_broker_config_distribution_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchTargetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTargetsRequest) ProtoMessage() {}
+
+func (x *WatchTargetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_broker_config_distribution_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTargetsRequest.ProtoReflect.Descriptor instead.
+func (*WatchTargetsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_broker_config_distribution_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WatchTargetsRequest) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *WatchTargetsRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WatchTargetsRequest) GetErrorDetail() string {
+	if x != nil {
+		return x.ErrorDetail
+	}
+	return ""
+}
+
+func (x *WatchTargetsRequest) GetCell() string {
+	if x != nil {
+		return x.Cell
+	}
+	return ""
+}
+
+func (x *WatchTargetsRequest) GetOpenCircuitBreakers() []string {
+	if x != nil {
+		return x.OpenCircuitBreakers
+	}
+	return nil
+}
+
+// An update of the targets config.
+type TargetsUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The version of the targets config after applying the update.
+	Version int64 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Whether the update replaces the whole targets config.
+	Snapshot bool `protobuf:"varint,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	// The brokers to add or update, keyed by broker key. Only the targets to
+	// add or update are included. Broker fields other than the targets replace
+	// the existing ones.
+	Brokers map[string]*Broker `protobuf:"bytes,3,rep,name=brokers,proto3" json:"brokers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The keys of the brokers to delete.
+	DeletedBrokers []string `protobuf:"bytes,4,rep,name=deleted_brokers,json=deletedBrokers,proto3" json:"deleted_brokers,omitempty"`
+	// The keys of the targets to delete.
+	DeletedTargets []string `protobuf:"bytes,5,rep,name=deleted_targets,json=deletedTargets,proto3" json:"deleted_targets,omitempty"`
+	// The generation of the targets config after applying the update.
+	Generation int64 `protobuf:"varint,6,opt,name=generation,proto3" json:"generation,omitempty"`
+	// The time the controller stamped the generation.
+	UpdateTime *timestamp.Timestamp `protobuf:"bytes,7,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+}
+
+func (x *TargetsUpdate) Reset() {
+	*x = TargetsUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_broker_config_distribution_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TargetsUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetsUpdate) ProtoMessage() {}
+
+func (x *TargetsUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_broker_config_distribution_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetsUpdate.ProtoReflect.Descriptor instead.
+func (*TargetsUpdate) Descriptor() ([]byte, []int) {
+	return file_pkg_broker_config_distribution_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TargetsUpdate) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *TargetsUpdate) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+func (x *TargetsUpdate) GetBrokers() map[string]*Broker {
+	if x != nil {
+		return x.Brokers
+	}
+	return nil
+}
+
+func (x *TargetsUpdate) GetDeletedBrokers() []string {
+	if x != nil {
+		return x.DeletedBrokers
+	}
+	return nil
+}
+
+func (x *TargetsUpdate) GetDeletedTargets() []string {
+	if x != nil {
+		return x.DeletedTargets
+	}
+	return nil
+}
+
+func (x *TargetsUpdate) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *TargetsUpdate) GetUpdateTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+var File_pkg_broker_config_distribution_proto protoreflect.FileDescriptor
+
+var file_pkg_broker_config_distribution_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x70, 0x6b, 0x67, 0x2f, 0x62, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2f, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x1f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x1f, 0x70, 0x6b, 0x67, 0x2f, 0x62, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xae, 0x01, 0x0a, 0x13, 0x57, 0x61, 0x74, 0x63, 0x68, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f,
+	0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x6c,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x6c, 0x6c, 0x12, 0x32, 0x0a,
+	0x15, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x5f, 0x62, 0x72,
+	0x65, 0x61, 0x6b, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x6f, 0x70,
+	0x65, 0x6e, 0x43, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72,
+	0x73, 0x22, 0xfe, 0x02, 0x0a, 0x0d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a,
+	0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x3c, 0x0a, 0x07, 0x62, 0x72, 0x6f,
+	0x6b, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x2e, 0x42, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07,
+	0x62, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x64, 0x5f, 0x62, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x42, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x73,
+	0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x64, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x67,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x1a, 0x4a, 0x0a, 0x0c, 0x42, 0x72, 0x6f, 0x6b, 0x65, 0x72,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x24, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x42, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x32, 0x5d, 0x0a, 0x13, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x44, 0x69, 0x73,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x46, 0x0a, 0x0c, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12, 0x1b, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x28, 0x01, 0x30,
+	0x01, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x6b, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x2d, 0x67,
+	0x63, 0x70, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x62, 0x72, 0x6f, 0x6b, 0x65, 0x72, 0x2f, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_broker_config_distribution_proto_rawDescOnce sync.Once
+	file_pkg_broker_config_distribution_proto_rawDescData = file_pkg_broker_config_distribution_proto_rawDesc
+)
+
+func file_pkg_broker_config_distribution_proto_rawDescGZIP() []byte {
+	file_pkg_broker_config_distribution_proto_rawDescOnce.Do(func() {
+		file_pkg_broker_config_distribution_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_broker_config_distribution_proto_rawDescData)
+	})
+	return file_pkg_broker_config_distribution_proto_rawDescData
+}
+
+var file_pkg_broker_config_distribution_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pkg_broker_config_distribution_proto_goTypes = []interface{}{
+	(*WatchTargetsRequest)(nil), // 0: config.WatchTargetsRequest
+	(*TargetsUpdate)(nil),       // 1: config.TargetsUpdate
+	nil,                         // 2: config.TargetsUpdate.BrokersEntry
+	(*timestamp.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*Broker)(nil),              // 4: config.Broker
+}
+var file_pkg_broker_config_distribution_proto_depIdxs = []int32{
+	2, // 0: config.TargetsUpdate.brokers:type_name -> config.TargetsUpdate.BrokersEntry
+	3, // 1: config.TargetsUpdate.update_time:type_name -> google.protobuf.Timestamp
+	4, // 2: config.TargetsUpdate.BrokersEntry.value:type_name -> config.Broker
+	0, // 3: config.TargetsDistribution.WatchTargets:input_type -> config.WatchTargetsRequest
+	1, // 4: config.TargetsDistribution.WatchTargets:output_type -> config.TargetsUpdate
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_pkg_broker_config_distribution_proto_init() }
+func file_pkg_broker_config_distribution_proto_init() {
+	if File_pkg_broker_config_distribution_proto != nil {
+		return
+	}
+	file_pkg_broker_config_targets_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_broker_config_distribution_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchTargetsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_broker_config_distribution_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TargetsUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_broker_config_distribution_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_broker_config_distribution_proto_goTypes,
+		DependencyIndexes: file_pkg_broker_config_distribution_proto_depIdxs,
+		MessageInfos:      file_pkg_broker_config_distribution_proto_msgTypes,
+	}.Build()
+	File_pkg_broker_config_distribution_proto = out.File
+	file_pkg_broker_config_distribution_proto_rawDesc = nil
+	file_pkg_broker_config_distribution_proto_goTypes = nil
+	file_pkg_broker_config_distribution_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// TargetsDistributionClient is the client API for TargetsDistribution service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TargetsDistributionClient interface {
+	// WatchTargets streams the targets config. The first update on a stream is
+	// a snapshot of the whole config. Every update must be acknowledged by a
+	// request before the next one is sent, which then carries the changes since
+	// the acknowledged version. A rejected update is followed by a snapshot.
+	WatchTargets(ctx context.Context, opts ...grpc.CallOption) (TargetsDistribution_WatchTargetsClient, error)
+}
+
+type targetsDistributionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTargetsDistributionClient(cc grpc.ClientConnInterface) TargetsDistributionClient {
+	return &targetsDistributionClient{cc}
+}
+
+func (c *targetsDistributionClient) WatchTargets(ctx context.Context, opts ...grpc.CallOption) (TargetsDistribution_WatchTargetsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TargetsDistribution_serviceDesc.Streams[0], "/config.TargetsDistribution/WatchTargets", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &targetsDistributionWatchTargetsClient{stream}
+	return x, nil
+}
+
+type TargetsDistribution_WatchTargetsClient interface {
+	Send(*WatchTargetsRequest) error
+	Recv() (*TargetsUpdate, error)
+	grpc.ClientStream
+}
+
+type targetsDistributionWatchTargetsClient struct {
+	grpc.ClientStream
+}
+
+func (x *targetsDistributionWatchTargetsClient) Send(m *WatchTargetsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *targetsDistributionWatchTargetsClient) Recv() (*TargetsUpdate, error) {
+	m := new(TargetsUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TargetsDistributionServer is the server API for TargetsDistribution service.
+type TargetsDistributionServer interface {
+	// WatchTargets streams the targets config. The first update on a stream is
+	// a snapshot of the whole config. Every update must be acknowledged by a
+	// request before the next one is sent, which then carries the changes since
+	// the acknowledged version. A rejected update is followed by a snapshot.
+	WatchTargets(TargetsDistribution_WatchTargetsServer) error
+}
+
+// UnimplementedTargetsDistributionServer can be embedded to have forward compatible implementations.
+type UnimplementedTargetsDistributionServer struct {
+}
+
+func (*UnimplementedTargetsDistributionServer) WatchTargets(TargetsDistribution_WatchTargetsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTargets not implemented")
+}
+
+func RegisterTargetsDistributionServer(s *grpc.Server, srv TargetsDistributionServer) {
+	s.RegisterService(&_TargetsDistribution_serviceDesc, srv)
+}
+
+func _TargetsDistribution_WatchTargets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TargetsDistributionServer).WatchTargets(&targetsDistributionWatchTargetsServer{stream})
+}
+
+type TargetsDistribution_WatchTargetsServer interface {
+	Send(*TargetsUpdate) error
+	Recv() (*WatchTargetsRequest, error)
+	grpc.ServerStream
+}
+
+type targetsDistributionWatchTargetsServer struct {
+	grpc.ServerStream
+}
+
+func (x *targetsDistributionWatchTargetsServer) Send(m *TargetsUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *targetsDistributionWatchTargetsServer) Recv() (*WatchTargetsRequest, error) {
+	m := new(WatchTargetsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _TargetsDistribution_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "config.TargetsDistribution",
+	HandlerType: (*TargetsDistributionServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTargets",
+			Handler:       _TargetsDistribution_WatchTargets_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/broker/config/distribution.proto",
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/knative-gcp/pkg/broker/cesql"
+)
+
+// EventFilter is a compiled target filter.
+type EventFilter interface {
+	// Match returns true if the event with the given attributes passes the filter.
+	// Otherwise it returns false and a reason explaining which sub-expression
+	// rejected the event.
+	Match(attrs map[string]interface{}) (bool, string)
+}
+
+// CompileFilters compiles target filters into a single EventFilter that
+// requires all the filters to pass. Invalid filters never match.
+func CompileFilters(filters []*Filter) EventFilter {
+	return compileAll(filters)
+}
+
+func compileAll(filters []*Filter) allFilter {
+	all := make(allFilter, 0, len(filters))
+	for _, f := range filters {
+		all = append(all, compileFilter(f))
+	}
+	return all
+}
+
+func compileFilter(f *Filter) EventFilter {
+	switch d := f.GetDialect().(type) {
+	case *Filter_Exact:
+		return &attributesFilter{dialect: "exact", attrs: d.Exact.GetAttributes(), match: func(v, f string) bool { return v == f }}
+	case *Filter_Prefix:
+		return &attributesFilter{dialect: "prefix", attrs: d.Prefix.GetAttributes(), match: strings.HasPrefix}
+	case *Filter_Suffix:
+		return &attributesFilter{dialect: "suffix", attrs: d.Suffix.GetAttributes(), match: strings.HasSuffix}
+	case *Filter_All:
+		return compileAll(d.All.GetFilters())
+	case *Filter_Any:
+		anyOf := make(anyFilter, 0, len(d.Any.GetFilters()))
+		for _, f := range d.Any.GetFilters() {
+			anyOf = append(anyOf, compileFilter(f))
+		}
+		return anyOf
+	case *Filter_Not:
+		return &notFilter{filter: compileFilter(d.Not)}
+	case *Filter_Cesql:
+		expr, err := cesql.Parse(d.Cesql)
+		if err != nil {
+			return invalidFilter(fmt.Sprintf("invalid cesql expression %q: %v", d.Cesql, err))
+		}
+		return &cesqlFilter{expr: expr}
+	}
+	return invalidFilter("filter has no dialect")
+}
+
+type attributesFilter struct {
+	dialect string
+	attrs   map[string]string
+	match   func(value, filter string) bool
+}
+
+func (f *attributesFilter) Match(attrs map[string]interface{}) (bool, string) {
+	for k, want := range f.attrs {
+		v, ok := attrs[k]
+		if !ok {
+			return false, fmt.Sprintf("%s: event missing attribute %q", f.dialect, k)
+		}
+		if !f.match(fmt.Sprint(v), want) {
+			return false, fmt.Sprintf("%s: event attribute %q does not match %q", f.dialect, k, want)
+		}
+	}
+	return true, ""
+}
+
+type allFilter []EventFilter
+
+func (f allFilter) Match(attrs map[string]interface{}) (bool, string) {
+	for _, sub := range f {
+		if ok, reason := sub.Match(attrs); !ok {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+type anyFilter []EventFilter
+
+func (f anyFilter) Match(attrs map[string]interface{}) (bool, string) {
+	if len(f) == 0 {
+		return true, ""
+	}
+	reasons := make([]string, 0, len(f))
+	for _, sub := range f {
+		ok, reason := sub.Match(attrs)
+		if ok {
+			return true, ""
+		}
+		reasons = append(reasons, reason)
+	}
+	return false, fmt.Sprintf("any: [%s]", strings.Join(reasons, "; "))
+}
+
+type notFilter struct {
+	filter EventFilter
+}
+
+func (f *notFilter) Match(attrs map[string]interface{}) (bool, string) {
+	if ok, _ := f.filter.Match(attrs); ok {
+		return false, "not: nested filter matched"
+	}
+	return true, ""
+}
+
+type cesqlFilter struct {
+	expr *cesql.Expression
+}
+
+func (f *cesqlFilter) Match(attrs map[string]interface{}) (bool, string) {
+	ok, err := f.expr.Evaluate(attrs)
+	if err != nil {
+		return false, fmt.Sprintf("cesql %q: %v", f.expr, err)
+	}
+	if !ok {
+		return false, fmt.Sprintf("cesql %q: evaluated to false", f.expr)
+	}
+	return true, ""
+}
+
+type invalidFilter string
+
+func (f invalidFilter) Match(map[string]interface{}) (bool, string) {
+	return false, string(f)
+}
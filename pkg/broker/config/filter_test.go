@@ -0,0 +1,165 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func exact(attrs map[string]string) *Filter {
+	return &Filter{Dialect: &Filter_Exact{Exact: &AttributesFilter{Attributes: attrs}}}
+}
+
+func prefix(attrs map[string]string) *Filter {
+	return &Filter{Dialect: &Filter_Prefix{Prefix: &AttributesFilter{Attributes: attrs}}}
+}
+
+func suffix(attrs map[string]string) *Filter {
+	return &Filter{Dialect: &Filter_Suffix{Suffix: &AttributesFilter{Attributes: attrs}}}
+}
+
+func sqlFilter(expr string) *Filter {
+	return &Filter{Dialect: &Filter_Cesql{Cesql: expr}}
+}
+
+func TestCompileFilters(t *testing.T) {
+	attrs := map[string]interface{}{
+		"type":   "com.example.order.created",
+		"source": "/orders/eu",
+		"count":  int32(3),
+	}
+	cases := []struct {
+		name       string
+		filters    []*Filter
+		want       bool
+		wantReason string
+	}{{
+		name: "no filters",
+		want: true,
+	}, {
+		name:    "exact match",
+		filters: []*Filter{exact(map[string]string{"type": "com.example.order.created", "count": "3"})},
+		want:    true,
+	}, {
+		name:       "exact mismatch",
+		filters:    []*Filter{exact(map[string]string{"type": "com.example.order"})},
+		wantReason: `exact: event attribute "type" does not match "com.example.order"`,
+	}, {
+		name:       "missing attribute",
+		filters:    []*Filter{exact(map[string]string{"subject": "x"})},
+		wantReason: `exact: event missing attribute "subject"`,
+	}, {
+		name:    "prefix and suffix",
+		filters: []*Filter{prefix(map[string]string{"type": "com.example."}), suffix(map[string]string{"source": "/eu"})},
+		want:    true,
+	}, {
+		name:       "suffix mismatch",
+		filters:    []*Filter{prefix(map[string]string{"type": "com.example."}), suffix(map[string]string{"source": "/us"})},
+		wantReason: `suffix: event attribute "source" does not match "/us"`,
+	}, {
+		name: "any",
+		filters: []*Filter{{Dialect: &Filter_Any{Any: &FilterList{Filters: []*Filter{
+			suffix(map[string]string{"source": "/us"}),
+			suffix(map[string]string{"source": "/eu"}),
+		}}}}},
+		want: true,
+	}, {
+		name: "any mismatch",
+		filters: []*Filter{{Dialect: &Filter_Any{Any: &FilterList{Filters: []*Filter{
+			suffix(map[string]string{"source": "/us"}),
+			suffix(map[string]string{"source": "/asia"}),
+		}}}}},
+		wantReason: `any: [suffix: event attribute "source" does not match "/us"; suffix: event attribute "source" does not match "/asia"]`,
+	}, {
+		name: "all",
+		filters: []*Filter{{Dialect: &Filter_All{All: &FilterList{Filters: []*Filter{
+			prefix(map[string]string{"type": "com.example."}),
+			sqlFilter("count > 2"),
+		}}}}},
+		want: true,
+	}, {
+		name:       "not",
+		filters:    []*Filter{{Dialect: &Filter_Not{Not: prefix(map[string]string{"type": "com.example."})}}},
+		wantReason: "not: nested filter matched",
+	}, {
+		name:       "cesql false",
+		filters:    []*Filter{sqlFilter("count > 5")},
+		wantReason: `cesql "count > 5": evaluated to false`,
+	}, {
+		name:       "cesql error",
+		filters:    []*Filter{sqlFilter("missing = 1")},
+		wantReason: `cesql "missing = 1": missing attribute "missing"`,
+	}, {
+		name:       "invalid cesql",
+		filters:    []*Filter{sqlFilter("count >")},
+		wantReason: `invalid cesql expression "count >"`,
+	}, {
+		name:       "no dialect",
+		filters:    []*Filter{{}},
+		wantReason: "filter has no dialect",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, reason := CompileFilters(tc.filters).Match(attrs)
+			if got != tc.want {
+				t.Errorf("Match() got=%v, want=%v", got, tc.want)
+			}
+			if !strings.HasPrefix(reason, tc.wantReason) {
+				t.Errorf("Match() reason got=%q, want prefix %q", reason, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestCachedTargetsGetTargetFilter(t *testing.T) {
+	targets := &CachedTargets{}
+	if _, ok := targets.GetTargetFilter("ns/broker/target"); ok {
+		t.Error("GetTargetFilter() on empty targets got=true, want=false")
+	}
+	targets.Store(&TargetsConfig{
+		Brokers: map[string]*Broker{
+			"ns/broker": {
+				Name:      "broker",
+				Namespace: "ns",
+				Targets: map[string]*Target{
+					"target": {
+						Name:      "target",
+						Namespace: "ns",
+						Broker:    "broker",
+						Filters:   []*Filter{exact(map[string]string{"type": "a"})},
+					},
+					"nofilter": {
+						Name:      "nofilter",
+						Namespace: "ns",
+						Broker:    "broker",
+					},
+				},
+			},
+		},
+	})
+	f, ok := targets.GetTargetFilter("ns/broker/target")
+	if !ok {
+		t.Fatal("GetTargetFilter() got=false, want=true")
+	}
+	if got, _ := f.Match(map[string]interface{}{"type": "a"}); !got {
+		t.Error("Match() got=false, want=true")
+	}
+	if _, ok := targets.GetTargetFilter("ns/broker/nofilter"); ok {
+		t.Error("GetTargetFilter() for target without filters got=true, want=false")
+	}
+}
@@ -52,6 +52,24 @@ func (m *brokerMutation) SetState(s config.State) config.BrokerMutation {
 	return m
 }
 
+func (m *brokerMutation) SetOrderingKeyAttribute(attr string) config.BrokerMutation {
+	m.delete = false
+	m.b.OrderingKeyAttribute = attr
+	return m
+}
+
+func (m *brokerMutation) SetRateLimit(rl *config.RateLimit) config.BrokerMutation {
+	m.delete = false
+	m.b.RateLimit = rl
+	return m
+}
+
+func (m *brokerMutation) SetAllowedPublishers(publishers []string) config.BrokerMutation {
+	m.delete = false
+	m.b.AllowedPublishers = publishers
+	return m
+}
+
 func (m *brokerMutation) UpsertTargets(targets ...*config.Target) config.BrokerMutation {
 	m.delete = false
 	if m.b.Targets == nil {
@@ -93,6 +93,30 @@ func TestMutateBroker(t *testing.T) {
 		assertBroker(t, wantBroker, "ns", "broker", targets)
 	})
 
+	t.Run("update broker ordering key attribute", func(t *testing.T) {
+		wantBroker.OrderingKeyAttribute = "partitionkey"
+		targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+			m.SetOrderingKeyAttribute("partitionkey")
+		})
+		assertBroker(t, wantBroker, "ns", "broker", targets)
+	})
+
+	t.Run("update broker rate limit", func(t *testing.T) {
+		wantBroker.RateLimit = &config.RateLimit{EventsPerSecond: 10, Burst: 20}
+		targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+			m.SetRateLimit(&config.RateLimit{EventsPerSecond: 10, Burst: 20})
+		})
+		assertBroker(t, wantBroker, "ns", "broker", targets)
+	})
+
+	t.Run("update broker allowed publishers", func(t *testing.T) {
+		wantBroker.AllowedPublishers = []string{"system:serviceaccount:ns:publisher"}
+		targets.MutateBroker("ns", "broker", func(m config.BrokerMutation) {
+			m.SetAllowedPublishers([]string{"system:serviceaccount:ns:publisher"})
+		})
+		assertBroker(t, wantBroker, "ns", "broker", targets)
+	})
+
 	t1 := &config.Target{
 		Id:      "uid-1",
 		Address: "consumer1.example.com",
@@ -161,6 +185,11 @@ func TestMutateBroker(t *testing.T) {
 	})
 
 	t.Run("delete and then change broker", func(t *testing.T) {
+		// Delete resets the broker so the ordering key attribute, rate limit and
+		// allowed publishers are gone.
+		wantBroker.OrderingKeyAttribute = ""
+		wantBroker.RateLimit = nil
+		wantBroker.AllowedPublishers = nil
 		wantBroker.Targets = map[string]*config.Target{
 			"t1": t1,
 			"t2": t2,
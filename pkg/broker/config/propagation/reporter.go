@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package propagation reports the propagation of the broker targets config to the data
+// plane pods as metrics. The pods report the generation they applied to the controller
+// on the targets config stream, see pkg/broker/config/stream.
+package propagation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"knative.dev/eventing/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/metrics"
+)
+
+// Reporter reports the generation of the targets config applied by the pod and its
+// propagation delay as metrics.
+type Reporter struct {
+	logger  *zap.Logger
+	metrics *metrics.ConfigReporter
+
+	mu sync.Mutex
+	// generation is the generation of the last targets config applied.
+	generation int64
+}
+
+// NewReporter creates a Reporter.
+func NewReporter(ctx context.Context, reporter *metrics.ConfigReporter) *Reporter {
+	return &Reporter{
+		logger:  logging.FromContext(ctx),
+		metrics: reporter,
+	}
+}
+
+// Observe records the targets config applied by the pod. It reports the propagation delay
+// of every new generation. Pass it to the targets loader with stream.WithObserver.
+func (r *Reporter) Observe(cfg *config.TargetsConfig) {
+	gen := cfg.GetGeneration()
+	r.mu.Lock()
+	if gen == r.generation {
+		r.mu.Unlock()
+		return
+	}
+	r.generation = gen
+	r.mu.Unlock()
+
+	// Configs stamped by a controller without generations have no delay to report.
+	if gen != 0 && cfg.GetUpdateTime() != nil {
+		delay := time.Since(cfg.GetUpdateTime().AsTime())
+		if err := r.metrics.ReportConfigApplied(context.Background(), gen, delay); err != nil {
+			r.logger.Warn("failed to report the targets config propagation", zap.Error(err))
+		}
+	}
+}
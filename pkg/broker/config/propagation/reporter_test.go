@@ -0,0 +1,51 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	logtest "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/metrics"
+	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+)
+
+func TestReporter(t *testing.T) {
+	reportertest.ResetConfigMetrics()
+	configReporter, err := metrics.NewConfigReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReporter(logtest.TestContextWithLogger(t), configReporter)
+
+	r.Observe(&config.TargetsConfig{Generation: 1, UpdateTime: timestamppb.New(time.Now().Add(-time.Second))})
+	wantTags := map[string]string{
+		metricskey.ContainerName: "container",
+		metricskey.PodName:       "pod",
+	}
+	metricstest.CheckLastValueData(t, "targets_config_generation", wantTags, 1)
+
+	r.Observe(&config.TargetsConfig{Generation: 2, UpdateTime: timestamppb.New(time.Now())})
+	metricstest.CheckLastValueData(t, "targets_config_generation", wantTags, 2)
+}
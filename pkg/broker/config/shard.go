@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// ShardOf returns the shard of the targets config the broker is assigned to.
+func ShardOf(brokerKey string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(brokerKey))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// SplitShards splits the brokers of the targets config into shards by their hashed keys,
+// so that changing a broker only changes its shard. The shards share the brokers of cfg
+// and are not stamped with a generation.
+func SplitShards(cfg *TargetsConfig, shards int) []*TargetsConfig {
+	if shards < 1 {
+		shards = 1
+	}
+	split := make([]*TargetsConfig, shards)
+	for i := range split {
+		split[i] = &TargetsConfig{}
+	}
+	for key, b := range cfg.GetBrokers() {
+		s := split[ShardOf(key, shards)]
+		if s.Brokers == nil {
+			s.Brokers = make(map[string]*Broker)
+		}
+		s.Brokers[key] = b
+	}
+	return split
+}
+
+// MergeShards merges the shards of a targets config. The merged config has the generation
+// of the latest shard. It returns an error if a broker is in more than one shard.
+func MergeShards(shards ...*TargetsConfig) (*TargetsConfig, error) {
+	merged := &TargetsConfig{Brokers: make(map[string]*Broker)}
+	for _, s := range shards {
+		for key, b := range s.GetBrokers() {
+			if _, ok := merged.Brokers[key]; ok {
+				return nil, fmt.Errorf("broker %q is in more than one config shard", key)
+			}
+			merged.Brokers[key] = b
+		}
+		if s.GetGeneration() > merged.Generation {
+			merged.Generation = s.GetGeneration()
+			merged.UpdateTime = s.GetUpdateTime()
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSplitAndMergeShards(t *testing.T) {
+	cfg := &TargetsConfig{Brokers: make(map[string]*Broker)}
+	for i := 0; i < 10; i++ {
+		b := &Broker{Namespace: fmt.Sprintf("ns%d", i), Name: "broker"}
+		cfg.Brokers[b.Key()] = b
+	}
+
+	shards := SplitShards(cfg, 3)
+	if len(shards) != 3 {
+		t.Fatalf("number of shards got=%d, want=3", len(shards))
+	}
+	for i, s := range shards {
+		for key := range s.Brokers {
+			if got := ShardOf(key, 3); got != i {
+				t.Errorf("broker %s in shard got=%d, want=%d", key, i, got)
+			}
+		}
+	}
+	shards[1].Generation, shards[1].UpdateTime = 2, timestamppb.New(time.Unix(2, 0))
+	shards[2].Generation, shards[2].UpdateTime = 1, timestamppb.New(time.Unix(1, 0))
+
+	got, err := MergeShards(shards...)
+	if err != nil {
+		t.Fatalf("MergeShards got unexpected error: %v", err)
+	}
+	// The merged config has the generation of the latest shard.
+	cfg.Generation, cfg.UpdateTime = 2, timestamppb.New(time.Unix(2, 0))
+	if diff := cmp.Diff(cfg, got, protocmp.Transform()); diff != "" {
+		t.Errorf("MergeShards (-want,+got): %v", diff)
+	}
+
+	dup := &TargetsConfig{Brokers: map[string]*Broker{"ns0/broker": cfg.Brokers["ns0/broker"]}}
+	if _, err := MergeShards(dup, dup); err == nil {
+		t.Error("MergeShards of duplicate brokers got error=nil, want error")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// TokenAudience is the audience of the Kubernetes service account tokens that data
+	// plane pods authenticate to the config service with. Tokens for this audience are
+	// not accepted by the Kubernetes API server or the broker ingress.
+	TokenAudience = "broker-targets.events.cloud.google.com"
+
+	// TokenPath is the path of the projected service account token of data plane pods
+	// for TokenAudience. The kubelet rotates the token in place.
+	TokenPath = "/var/run/secrets/broker-targets/token"
+
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+)
+
+// Authorizer authorizes the data plane pods streaming the targets config.
+type Authorizer interface {
+	// Authorize returns a gRPC status error if the bearer token is not allowed to
+	// stream the targets config.
+	Authorize(ctx context.Context, token string) error
+}
+
+// NewTokenReviewAuthorizer creates an Authorizer that verifies the tokens for TokenAudience
+// with the Kubernetes TokenReview API and allows the given users, e.g.
+// system:serviceaccount:<namespace>:<name>.
+func NewTokenReviewAuthorizer(client kubernetes.Interface, users ...string) Authorizer {
+	return &tokenReviewAuthorizer{client: client, users: users}
+}
+
+type tokenReviewAuthorizer struct {
+	client kubernetes.Interface
+	users  []string
+}
+
+func (a *tokenReviewAuthorizer) Authorize(ctx context.Context, token string) error {
+	review, err := a.client.AuthenticationV1().TokenReviews().CreateContext(ctx, &authv1.TokenReview{
+		Spec: authv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: []string{TokenAudience},
+		},
+	})
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to review token: %v", err)
+	}
+	if !review.Status.Authenticated {
+		return status.Errorf(codes.Unauthenticated, "token not authenticated: %s", review.Status.Error)
+	}
+	// API servers that don't support audiences leave the status audiences empty.
+	if !containsString(review.Status.Audiences, TokenAudience) {
+		return status.Errorf(codes.Unauthenticated, "token audience is not %q", TokenAudience)
+	}
+	if !containsString(a.users, review.Status.User.Username) {
+		return status.Errorf(codes.PermissionDenied, "%q is not allowed to stream the targets config", review.Status.User.Username)
+	}
+	return nil
+}
+
+// authorizeStream is a grpc.StreamServerInterceptor that rejects the streams not allowed
+// by the authorizer of the server.
+func (s *Server) authorizeStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if s.authorizer == nil {
+		return handler(srv, ss)
+	}
+	token := bearerToken(ss.Context())
+	if token == "" {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if err := s.authorizer.Authorize(ss.Context(), token); err != nil {
+		s.logger.Warn("Rejected targets config stream", zap.Error(err))
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// bearerToken returns the bearer token in the metadata of the incoming context, or an
+// empty string if there is none.
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, auth := range md.Get(authorizationHeader) {
+		if strings.HasPrefix(auth, bearerPrefix) {
+			return strings.TrimPrefix(auth, bearerPrefix)
+		}
+	}
+	return ""
+}
+
+// tokenFileCredentials sends the token in the file as a bearer token. The file is read
+// on every stream so that rotated tokens are picked up.
+type tokenFileCredentials string
+
+var _ credentials.PerRPCCredentials = tokenFileCredentials("")
+
+func (c tokenFileCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(string(c))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	return map[string]string{authorizationHeader: bearerPrefix + strings.TrimSpace(string(b))}, nil
+}
+
+// RequireTransportSecurity returns false. The config service is only reachable within the
+// cluster, and the token is only valid for TokenAudience.
+func (c tokenFileCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
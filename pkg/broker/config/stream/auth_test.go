@@ -0,0 +1,179 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+const dataPlaneUser = "system:serviceaccount:cloud-run-events:broker"
+
+// fakeAuthorizer maps the allowed tokens to nil and the rejected ones to their errors.
+type fakeAuthorizer map[string]error
+
+func (a fakeAuthorizer) Authorize(ctx context.Context, token string) error {
+	err, ok := a[token]
+	if !ok {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return err
+}
+
+// tokenCredentials sends a fixed bearer token.
+type tokenCredentials string
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authorizationHeader: bearerPrefix + string(c)}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func TestServerAuthorizesStreams(t *testing.T) {
+	s := NewServer(zap.NewNop(), fakeAuthorizer{
+		"allowed": nil,
+		"denied":  status.Error(codes.PermissionDenied, "denied"),
+	})
+	s.Publish("cell", testConfig("address", "t1"))
+	address := startServer(t, s)
+
+	cases := []struct {
+		name     string
+		token    string
+		wantCode codes.Code
+	}{{
+		name:     "missing token",
+		wantCode: codes.Unauthenticated,
+	}, {
+		name:     "invalid token",
+		token:    "invalid",
+		wantCode: codes.Unauthenticated,
+	}, {
+		name:     "denied token",
+		token:    "denied",
+		wantCode: codes.PermissionDenied,
+	}, {
+		name:     "allowed token",
+		token:    "allowed",
+		wantCode: codes.OK,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			opts := []grpc.DialOption{grpc.WithInsecure()}
+			if tc.token != "" {
+				opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(tc.token)))
+			}
+			conn, err := grpc.DialContext(ctx, address, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			stream, err := config.NewTargetsDistributionClient(conn).WatchTargets(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := stream.Send(&config.WatchTargetsRequest{Node: "pod", Cell: "cell"}); err != nil {
+				t.Fatal(err)
+			}
+			_, err = stream.Recv()
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("Recv got code=%v, want=%v (error: %v)", got, tc.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestTokenReviewAuthorizer(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    authv1.TokenReviewStatus
+		reviewErr error
+		wantCode  codes.Code
+	}{{
+		name: "allowed",
+		status: authv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          authv1.UserInfo{Username: dataPlaneUser},
+			Audiences:     []string{TokenAudience},
+		},
+		wantCode: codes.OK,
+	}, {
+		name: "other user",
+		status: authv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          authv1.UserInfo{Username: "system:serviceaccount:ns:default"},
+			Audiences:     []string{TokenAudience},
+		},
+		wantCode: codes.PermissionDenied,
+	}, {
+		name:     "not authenticated",
+		status:   authv1.TokenReviewStatus{Error: "invalid token"},
+		wantCode: codes.Unauthenticated,
+	}, {
+		name: "audiences not supported",
+		status: authv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          authv1.UserInfo{Username: dataPlaneUser},
+		},
+		wantCode: codes.Unauthenticated,
+	}, {
+		name:      "token review failed",
+		reviewErr: errors.New("api server unavailable"),
+		wantCode:  codes.Unavailable,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				review := action.(clienttesting.CreateAction).GetObject().(*authv1.TokenReview).DeepCopy()
+				if review.Spec.Token != "token" {
+					t.Errorf("token got=%q, want=%q", review.Spec.Token, "token")
+				}
+				if len(review.Spec.Audiences) != 1 || review.Spec.Audiences[0] != TokenAudience {
+					t.Errorf("audiences got=%v, want=[%v]", review.Spec.Audiences, TokenAudience)
+				}
+				if tc.reviewErr != nil {
+					return true, &authv1.TokenReview{}, tc.reviewErr
+				}
+				review.Status = tc.status
+				return true, review, nil
+			})
+			err := NewTokenReviewAuthorizer(client, dataPlaneUser).Authorize(context.Background(), "token")
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("Authorize got code=%v, want=%v (error: %v)", got, tc.wantCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// Option is the option to load targets.
+type Option func(*Targets)
+
+// WithAddress is the option to stream targets from the config service at the given
+// address. Without it, targets are only loaded from the file.
+func WithAddress(address string) Option {
+	return func(t *Targets) {
+		t.address = address
+	}
+}
+
+// WithNode is the option to identify the pod to the config service.
+func WithNode(node string) Option {
+	return func(t *Targets) {
+		t.node = node
+	}
+}
+
+// WithCell is the option to stream the targets config of the given brokercell.
+func WithCell(cell string) Option {
+	return func(t *Targets) {
+		t.cell = cell
+	}
+}
+
+// WithPath is the option to load targets from the file at the given path
+// when they are not streamed.
+func WithPath(path string) Option {
+	return func(t *Targets) {
+		t.path = path
+	}
+}
+
+// WithNotifyChan is the option to notify the given channel
+// when the config cache was updated.
+func WithNotifyChan(ch chan<- struct{}) Option {
+	return func(t *Targets) {
+		t.notifyChan = ch
+	}
+}
+
+// WithTokenFile is the option to authenticate to the config service with the
+// token in the file at the given path, e.g. TokenPath.
+func WithTokenFile(path string) Option {
+	return func(t *Targets) {
+		t.dialOpts = append(t.dialOpts, grpc.WithPerRPCCredentials(tokenFileCredentials(path)))
+	}
+}
+
+// WithDialOptions is the option to dial the config service with the given options.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(t *Targets) {
+		t.dialOpts = append(t.dialOpts, opts...)
+	}
+}
+
+// WithObserver is the option to call the given function with every targets config
+// applied, whether streamed or loaded from the file. The function must not modify
+// the config.
+func WithObserver(observe func(*config.TargetsConfig)) Option {
+	return func(t *Targets) {
+		t.observe = observe
+	}
+}
+
+// WithOpenCircuitBreakers is the option to report the keys of the targets whose circuit
+// breaker is open, as returned by openKeys, to the config service. They are checked
+// every period and reported when they change. They aren't reported when the targets
+// are loaded from the file.
+func WithOpenCircuitBreakers(openKeys func() []string, period time.Duration) Option {
+	return func(t *Targets) {
+		t.openKeys = openKeys
+		t.reportPeriod = period
+	}
+}
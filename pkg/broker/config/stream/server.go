@@ -0,0 +1,326 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stream distributes the broker targets config from the controller to the
+// broker data plane pods over a gRPC stream.
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// minKeepaliveTime is the minimum interval of client keepalive pings the server accepts.
+const minKeepaliveTime = 10 * time.Second
+
+// Server implements config.TargetsDistributionServer. It streams the latest targets
+// config published to it for the brokercell of each node. Nothing is streamed until a
+// config is published, so data plane pods connected to a server that doesn't reconcile
+// the config, e.g. a controller replica that isn't the leader, keep their current config.
+type Server struct {
+	logger *zap.Logger
+	// authorizer authorizes the streams. If nil, all streams are allowed.
+	authorizer Authorizer
+	// notify is called with the brokercell whose nodes changed what they report.
+	notify func(cell string)
+
+	mu sync.Mutex
+	// cells holds the targets config of each brokercell.
+	cells map[string]*cellTargets
+}
+
+// cellTargets is the targets config of a brokercell.
+type cellTargets struct {
+	version int64
+	current *config.TargetsConfig
+	// changed is closed when a new version is published.
+	changed chan struct{}
+	// nodes holds what each connected node reported.
+	nodes map[string]*NodeReport
+}
+
+// NodeReport is what a connected node reported on its stream.
+type NodeReport struct {
+	// Version is the last version acknowledged by the node.
+	Version int64
+	// Generation is the generation of the targets config of the last version
+	// acknowledged by the node.
+	Generation int64
+	// OpenCircuitBreakers are the keys of the targets whose circuit breaker is
+	// open on the node.
+	OpenCircuitBreakers []string
+}
+
+var _ config.TargetsDistributionServer = (*Server)(nil)
+
+// NewServer creates a Server without a targets config. The streams are authorized by
+// the authorizer; a nil authorizer allows all streams.
+func NewServer(logger *zap.Logger, authorizer Authorizer) *Server {
+	return &Server{
+		logger:     logger,
+		authorizer: authorizer,
+		cells:      make(map[string]*cellTargets),
+	}
+}
+
+// OnReport sets the function called with the brokercell whose connected nodes changed
+// what they report, i.e. a node connected, disconnected, acknowledged a new generation
+// or reported different open circuit breakers. It must be set before serving.
+func (s *Server) OnReport(notify func(cell string)) {
+	s.notify = notify
+}
+
+// cell returns the targets config of the brokercell. The caller must hold s.mu.
+func (s *Server) cell(name string) *cellTargets {
+	c, ok := s.cells[name]
+	if !ok {
+		c = &cellTargets{
+			changed: make(chan struct{}),
+			nodes:   make(map[string]*NodeReport),
+		}
+		s.cells[name] = c
+	}
+	return c
+}
+
+// Publish publishes the targets config of the brokercell as a new version if it differs
+// from the current one.
+func (s *Server) Publish(cell string, cfg *config.TargetsConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.cell(cell)
+	if c.current != nil && proto.Equal(c.current, cfg) {
+		return
+	}
+	c.version++
+	c.current = proto.Clone(cfg).(*config.TargetsConfig)
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Current returns the current version and targets config of the brokercell, or a nil
+// config if none was published. Do not modify the returned config.
+func (s *Server) Current(cell string) (int64, *config.TargetsConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.cell(cell)
+	return c.version, c.current
+}
+
+// AckedVersions returns the last version acknowledged by each connected node of the
+// brokercell.
+func (s *Server) AckedVersions(cell string) map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.cell(cell)
+	acked := make(map[string]int64, len(c.nodes))
+	for node, r := range c.nodes {
+		if r.Version != 0 {
+			acked[node] = r.Version
+		}
+	}
+	return acked
+}
+
+// Nodes returns what each connected node of the brokercell reported.
+func (s *Server) Nodes(cell string) map[string]NodeReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.cell(cell)
+	nodes := make(map[string]NodeReport, len(c.nodes))
+	for node, r := range c.nodes {
+		nodes[node] = *r
+	}
+	return nodes
+}
+
+// ListenAndServe serves the targets config on the address until ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context, address string) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves the targets config on the listener until ctx is done.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	srv := grpc.NewServer(
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             minKeepaliveTime,
+			PermitWithoutStream: true,
+		}),
+		grpc.StreamInterceptor(s.authorizeStream),
+	)
+	config.RegisterTargetsDistributionServer(srv, s)
+	go func() {
+		<-ctx.Done()
+		srv.Stop()
+	}()
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) latest(cell string) (int64, *config.TargetsConfig, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.cell(cell)
+	return c.version, c.current, c.changed
+}
+
+// report updates what the node reported, and notifies if it changed.
+func (s *Server) report(cell, node string, update func(r *NodeReport)) {
+	s.mu.Lock()
+	c := s.cell(cell)
+	r, ok := c.nodes[node]
+	if !ok {
+		r = &NodeReport{}
+		c.nodes[node] = r
+	}
+	old := *r
+	update(r)
+	changed := !ok || !reflect.DeepEqual(old, *r)
+	s.mu.Unlock()
+	if changed && s.notify != nil {
+		s.notify(cell)
+	}
+}
+
+func (s *Server) ack(cell, node string, version, generation int64) {
+	s.report(cell, node, func(r *NodeReport) {
+		r.Version, r.Generation = version, generation
+	})
+}
+
+func (s *Server) reportOpenCircuitBreakers(cell, node string, keys []string) {
+	s.report(cell, node, func(r *NodeReport) {
+		r.OpenCircuitBreakers = keys
+	})
+}
+
+func (s *Server) forget(cell, node string) {
+	s.mu.Lock()
+	_, ok := s.cell(cell).nodes[node]
+	delete(s.cell(cell).nodes, node)
+	s.mu.Unlock()
+	if ok && s.notify != nil {
+		s.notify(cell)
+	}
+}
+
+// WatchTargets implements config.TargetsDistributionServer.
+func (s *Server) WatchTargets(stream config.TargetsDistribution_WatchTargetsServer) error {
+	ctx := stream.Context()
+	reqs := make(chan *config.WatchTargetsRequest)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The stream starts with the first request, which identifies the node and its brokercell.
+	var node, cell string
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-recvErr:
+		return ignoreEOF(err)
+	case req := <-reqs:
+		node, cell = req.Node, req.Cell
+		s.reportOpenCircuitBreakers(cell, node, req.OpenCircuitBreakers)
+	}
+	logger := s.logger.With(zap.String("node", node), zap.String("brokerCell", cell))
+	defer s.forget(cell, node)
+
+	var (
+		// acked is the config the node has, or nil if it needs a snapshot.
+		acked *config.TargetsConfig
+		// handled is the version of the last update the node responded to.
+		handled int64
+		// sent is the config of the update waiting for the node response.
+		sent        *config.TargetsConfig
+		sentVersion int64
+	)
+	for {
+		version, current, changed := s.latest(cell)
+		if sent == nil && current != nil && version != handled {
+			u := config.SnapshotUpdate(current)
+			if acked != nil {
+				u = config.DiffTargets(acked, current)
+			}
+			u.Version = version
+			if err := stream.Send(u); err != nil {
+				return err
+			}
+			sent, sentVersion = current, version
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-recvErr:
+			return ignoreEOF(err)
+		case <-changed:
+		case req := <-reqs:
+			s.reportOpenCircuitBreakers(cell, node, req.OpenCircuitBreakers)
+			if sent == nil || (req.Version != sentVersion && req.ErrorDetail == "") {
+				// Not a response to the update.
+				continue
+			}
+			handled = sentVersion
+			if req.ErrorDetail != "" {
+				// The next update will be a snapshot.
+				logger.Warn("Node rejected the targets config update", zap.Int64("version", sentVersion), zap.String("error", req.ErrorDetail))
+				acked = nil
+			} else {
+				acked = sent
+				s.ack(cell, node, sentVersion, sent.GetGeneration())
+			}
+			sent = nil
+		}
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func testConfig(address string, targets ...string) *config.TargetsConfig {
+	b := &config.Broker{Namespace: "ns", Name: "broker", Address: address, Targets: make(map[string]*config.Target)}
+	for _, name := range targets {
+		b.Targets[name] = &config.Target{Namespace: "ns", Broker: "broker", Name: name}
+	}
+	return &config.TargetsConfig{Brokers: map[string]*config.Broker{b.Key(): b}}
+}
+
+// startServer serves s on a local port until the test ends and returns its address.
+func startServer(t *testing.T, s *Server) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.Serve(ctx, lis); err != nil {
+			t.Errorf("Serve got unexpected error: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return lis.Addr().String()
+}
+
+func TestServerWatchTargets(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil)
+	address := startServer(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	stream, err := config.NewTargetsDistributionClient(conn).WatchTargets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recv := func(wantVersion int64, wantSnapshot bool, wantTargets []string) {
+		t.Helper()
+		u, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Version != wantVersion || u.Snapshot != wantSnapshot {
+			t.Errorf("update got version=%d snapshot=%v, want version=%d snapshot=%v", u.Version, u.Snapshot, wantVersion, wantSnapshot)
+		}
+		var gotTargets []string
+		for name := range u.Brokers["ns/broker"].GetTargets() {
+			gotTargets = append(gotTargets, name)
+		}
+		if diff := cmp.Diff(wantTargets, sortedStrings(gotTargets)); diff != "" {
+			t.Errorf("update targets (-want,+got): %v", diff)
+		}
+	}
+	send := func(version int64, errorDetail string) {
+		t.Helper()
+		if err := stream.Send(&config.WatchTargetsRequest{Node: "pod", Cell: "cell", Version: version, ErrorDetail: errorDetail}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send(0, "")
+	// The config of other brokercells isn't streamed.
+	s.Publish("other", testConfig("other", "t2"))
+	s.Publish("cell", testConfig("address", "t1"))
+	recv(1, true, []string{"t1"})
+	send(1, "")
+
+	// Updates carry the changes since the acknowledged version.
+	s.Publish("cell", testConfig("address", "t1", "t2"))
+	recv(2, false, []string{"t2"})
+	// A rejected update is followed by a snapshot of the next version.
+	send(1, "rejected")
+	s.Publish("cell", testConfig("address", "t1", "t2", "t3"))
+	recv(3, true, []string{"t1", "t2", "t3"})
+	send(3, "")
+
+	// The same config isn't published again.
+	s.Publish("cell", testConfig("address", "t1", "t2", "t3"))
+	s.Publish("cell", testConfig("address", "t1"))
+	u, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Version != 4 {
+		t.Errorf("update got version=%d, want=4", u.Version)
+	}
+	if diff := cmp.Diff([]string{"ns/broker/t2", "ns/broker/t3"}, sortedStrings(u.DeletedTargets)); diff != "" {
+		t.Errorf("update deleted targets (-want,+got): %v", diff)
+	}
+	send(4, "")
+
+	waitFor(t, func() bool { return s.AckedVersions("cell")["pod"] == 4 })
+	stream.CloseSend()
+	waitFor(t, func() bool { return len(s.AckedVersions("cell")) == 0 })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for the condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func sortedStrings(s []string) []string {
+	sort.Strings(s)
+	return s
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"knative.dev/eventing/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/volume"
+)
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// Targets implements config.ReadonlyTargets with data streamed from
+// the config service. The targets are empty until the first snapshot
+// is streamed, and the last streamed targets are kept while the stream
+// is disconnected. The controller doesn't write the targets config
+// file when it streams the targets config, so it isn't loaded.
+type Targets struct {
+	config.CachedTargets
+	address    string
+	node       string
+	cell       string
+	path       string
+	notifyChan chan<- struct{}
+	observe    func(*config.TargetsConfig)
+	dialOpts   []grpc.DialOption
+	// openKeys returns the keys of the targets whose circuit breaker is open.
+	openKeys     func() []string
+	reportPeriod time.Duration
+
+	// mu serializes the updates of the cached targets from the stream.
+	mu sync.Mutex
+	// streaming is true if the stream applied a snapshot since it was last connected.
+	streaming bool
+	// version is the version of the last update applied from the stream.
+	version int64
+}
+
+var _ config.ReadonlyTargets = (*Targets)(nil)
+
+// NewTargets streams the targets config from the config service until ctx is
+// done. Without an address, it loads the targets config from the file instead.
+func NewTargets(ctx context.Context, opts ...Option) (config.ReadonlyTargets, error) {
+	t := &Targets{
+		CachedTargets: config.CachedTargets{},
+		dialOpts: []grpc.DialOption{
+			grpc.WithInsecure(),
+			grpc.WithKeepaliveParams(keepalive.ClientParameters{
+				Time:                3 * minKeepaliveTime,
+				Timeout:             minKeepaliveTime,
+				PermitWithoutStream: true,
+			}),
+		},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.address == "" {
+		var fileOpts []volume.Option
+		if t.path != "" {
+			fileOpts = append(fileOpts, volume.WithPath(t.path))
+		}
+		if t.notifyChan != nil {
+			fileOpts = append(fileOpts, volume.WithNotifyChan(t.notifyChan))
+		}
+		if t.observe != nil {
+			fileOpts = append(fileOpts, volume.WithObserver(t.observe))
+		}
+		return volume.NewTargetsFromFile(fileOpts...)
+	}
+
+	t.Store(&config.TargetsConfig{})
+	conn, err := grpc.DialContext(ctx, t.address, t.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the config service: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go t.watchStream(ctx, config.NewTargetsDistributionClient(conn))
+	return t, nil
+}
+
+// watchStream streams the targets config and reconnects with backoff until ctx is done.
+func (t *Targets) watchStream(ctx context.Context, client config.TargetsDistributionClient) {
+	logger := logging.FromContext(ctx)
+	delay := minRetryDelay
+	for {
+		received, err := t.watch(ctx, client)
+		t.mu.Lock()
+		t.streaming = false
+		t.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("Targets config stream disconnected, keeping the last streamed targets config", zap.Error(err))
+		if received {
+			delay = minRetryDelay
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// watch applies and acknowledges the updates of a stream until it fails. It
+// returns whether any update was received.
+func (t *Targets) watch(ctx context.Context, client config.TargetsDistributionClient) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.WatchTargets(ctx)
+	if err != nil {
+		return false, err
+	}
+	s := &reportingStream{stream: stream, openKeys: t.openKeys}
+	t.mu.Lock()
+	version := t.version
+	t.mu.Unlock()
+	if err := s.send(&config.WatchTargetsRequest{Node: t.node, Cell: t.cell, Version: version}); err != nil {
+		return false, err
+	}
+	if t.openKeys != nil {
+		go t.reportOpenCircuitBreakers(ctx, s)
+	}
+	received := false
+	for {
+		u, err := stream.Recv()
+		if err != nil {
+			return received, err
+		}
+		received = true
+		req := &config.WatchTargetsRequest{Node: t.node}
+		if version, err := t.apply(u); err != nil {
+			logging.FromContext(ctx).Error("Rejected the targets config update", zap.Int64("version", u.Version), zap.Error(err))
+			req.Version = version
+			req.ErrorDetail = err.Error()
+		} else {
+			req.Version = u.Version
+			t.notify()
+		}
+		if err := s.send(req); err != nil {
+			return received, err
+		}
+	}
+}
+
+// reportOpenCircuitBreakers sends a request with the current version whenever the open
+// circuit breakers change, until ctx is done.
+func (t *Targets) reportOpenCircuitBreakers(ctx context.Context, s *reportingStream) {
+	ticker := time.NewTicker(t.reportPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !s.openKeysChanged() {
+			continue
+		}
+		t.mu.Lock()
+		version := t.version
+		t.mu.Unlock()
+		if err := s.send(&config.WatchTargetsRequest{Node: t.node, Version: version}); err != nil {
+			// The stream failure is handled by the receiving loop.
+			return
+		}
+	}
+}
+
+// reportingStream serializes the requests sent on a stream, and adds the open circuit
+// breakers to each of them.
+type reportingStream struct {
+	stream   config.TargetsDistribution_WatchTargetsClient
+	openKeys func() []string
+
+	mu sync.Mutex
+	// reported is the open circuit breakers of the last request sent.
+	reported []string
+}
+
+func (s *reportingStream) send(req *config.WatchTargetsRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.openKeys != nil {
+		req.OpenCircuitBreakers = s.openKeys()
+	}
+	if err := s.stream.Send(req); err != nil {
+		return err
+	}
+	s.reported = req.OpenCircuitBreakers
+	return nil
+}
+
+func (s *reportingStream) openKeysChanged() bool {
+	keys := s.openKeys()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(keys) != len(s.reported) {
+		return true
+	}
+	for i := range keys {
+		if keys[i] != s.reported[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// apply applies the update to the cached targets. It returns the version of the
+// cached targets and an error if the update was rejected.
+func (t *Targets) apply(u *config.TargetsUpdate) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !u.Snapshot && !t.streaming {
+		return t.version, fmt.Errorf("received an update of version %d without a snapshot", u.Version)
+	}
+	cfg, err := config.ApplyUpdate(t.Load(), u)
+	if err != nil {
+		return t.version, err
+	}
+	t.store(cfg)
+	t.streaming = true
+	t.version = u.Version
+	return t.version, nil
+}
+
+func (t *Targets) store(cfg *config.TargetsConfig) {
+	t.Store(cfg)
+	if t.observe != nil {
+		t.observe(cfg)
+	}
+}
+
+func (t *Targets) notify() {
+	if t.notifyChan != nil {
+		t.notifyChan <- struct{}{}
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestTargetsStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configtest-*")
+	if err != nil {
+		t.Fatalf("unexpected error from creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	// The file isn't loaded when the targets config is streamed.
+	path := filepath.Join(dir, "targets")
+	writeConfig(t, path, testConfig("file-v1"))
+	tokenPath := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenPath, []byte("pod-token\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reserve an address for the config service, which isn't serving yet.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := lis.Addr().String()
+	lis.Close()
+
+	ctx, cancel := context.WithCancel(logtest.TestContextWithLogger(t))
+	defer cancel()
+	ch := make(chan struct{}, 10)
+	targets, err := NewTargets(ctx, WithAddress(address), WithNode("pod"), WithCell("cell"), WithPath(path), WithNotifyChan(ch), WithTokenFile(tokenPath))
+	if err != nil {
+		t.Fatalf("unexpected error from NewTargets: %v", err)
+	}
+	if b, ok := targets.GetBroker("ns", "broker"); ok {
+		t.Errorf("broker got=%v before the stream connected, want none", b)
+	}
+	wantAddress := func(want string) {
+		t.Helper()
+		deadline := time.After(10 * time.Second)
+		for {
+			if b, ok := targets.GetBroker("ns", "broker"); ok && b.Address == want {
+				return
+			}
+			select {
+			case <-ch:
+			case <-deadline:
+				b, _ := targets.GetBroker("ns", "broker")
+				t.Fatalf("broker address got=%v, want=%v", b.GetAddress(), want)
+			}
+		}
+	}
+
+	s := NewServer(zap.NewNop(), fakeAuthorizer{"pod-token": nil})
+	s.Publish("cell", testConfig("stream-v1"))
+	lis, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveCtx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Serve(serveCtx, lis)
+	}()
+	wantAddress("stream-v1")
+	waitFor(t, func() bool { return s.AckedVersions("cell")["pod"] == 1 })
+
+	s.Publish("cell", testConfig("stream-v2"))
+	wantAddress("stream-v2")
+	waitFor(t, func() bool { return s.AckedVersions("cell")["pod"] == 2 })
+
+	// The last streamed config is kept once the stream is disconnected.
+	stop()
+	<-done
+	waitFor(t, func() bool {
+		st := targets.(*Targets)
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		return !st.streaming
+	})
+	writeConfig(t, path, testConfig("file-v2"))
+	wantAddress("stream-v2")
+}
+
+func TestTargetsStreamReportsOpenCircuitBreakers(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil)
+	notified := make(chan string, 100)
+	s.OnReport(func(cell string) { notified <- cell })
+	cfg := testConfig("address", "t1")
+	cfg.Generation = 3
+	s.Publish("cell", cfg)
+	address := startServer(t, s)
+
+	var mu sync.Mutex
+	var open []string
+	setOpen := func(keys ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		open = keys
+	}
+	openKeys := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return open
+	}
+	setOpen("ns/broker/t1")
+
+	ctx, cancel := context.WithCancel(logtest.TestContextWithLogger(t))
+	defer cancel()
+	if _, err := NewTargets(ctx, WithAddress(address), WithNode("pod"), WithCell("cell"), WithOpenCircuitBreakers(openKeys, 10*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error from NewTargets: %v", err)
+	}
+	wantReport := func(want NodeReport) {
+		t.Helper()
+		waitFor(t, func() bool { return cmp.Equal(want, s.Nodes("cell")["pod"]) })
+	}
+	// The open circuit breakers are reported with the first request.
+	wantReport(NodeReport{Version: 1, Generation: 3, OpenCircuitBreakers: []string{"ns/broker/t1"}})
+	if cell := <-notified; cell != "cell" {
+		t.Errorf("notified cell got=%q, want=%q", cell, "cell")
+	}
+
+	// Changes are reported without a targets config update.
+	setOpen()
+	wantReport(NodeReport{Version: 1, Generation: 3})
+	setOpen("ns/broker/t1", "ns/broker/t2")
+	wantReport(NodeReport{Version: 1, Generation: 3, OpenCircuitBreakers: []string{"ns/broker/t1", "ns/broker/t2"}})
+
+	// The report is forgotten when the node disconnects.
+	cancel()
+	waitFor(t, func() bool { return len(s.Nodes("cell")) == 0 })
+}
+
+func writeConfig(t *testing.T, path string, cfg *config.TargetsConfig) {
+	t.Helper()
+	b, err := proto.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Atomically replace the file like Kubernetes updates ConfigMap volumes.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
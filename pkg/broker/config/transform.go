@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	"github.com/google/knative-gcp/pkg/broker/transform"
+)
+
+// CompileTransformations compiles target transformations into a single operation
+// that applies them in order. Invalid transformations fail to apply.
+func CompileTransformations(ts []*Transformation) transform.Operation {
+	ops := make(transform.Operations, 0, len(ts))
+	for i, t := range ts {
+		op, err := compileTransformation(t)
+		if err != nil {
+			op = invalidOperation{err: fmt.Errorf("invalid transformation %d: %w", i, err)}
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+func compileTransformation(t *Transformation) (transform.Operation, error) {
+	switch op := t.GetOperation().(type) {
+	case *Transformation_Set:
+		return transform.Set(op.Set.GetValues())
+	case *Transformation_Remove:
+		return transform.Remove(op.Remove.GetNames())
+	case *Transformation_Rename:
+		return transform.Rename(op.Rename.GetValues())
+	case *Transformation_Extract:
+		return transform.Extract(op.Extract.GetValues())
+	case *Transformation_DataTemplate:
+		return transform.Data(op.DataTemplate)
+	}
+	return nil, errors.New("transformation has no operation")
+}
+
+type invalidOperation struct {
+	err error
+}
+
+func (op invalidOperation) Apply(*event.Event) error {
+	return op.err
+}
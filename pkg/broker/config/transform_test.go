@@ -0,0 +1,118 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestCompileTransformations(t *testing.T) {
+	cases := []struct {
+		name     string
+		ts       []*Transformation
+		wantType string
+		wantErr  bool
+	}{{
+		name: "no transformations",
+	}, {
+		name: "transformations in order",
+		ts: []*Transformation{
+			{Operation: &Transformation_Set{Set: &AttributeValues{Values: map[string]string{"subject": "new"}}}},
+			{Operation: &Transformation_Rename{Rename: &AttributeValues{Values: map[string]string{"subject": "type"}}}},
+		},
+		wantType: "new",
+	}, {
+		name: "invalid transformation",
+		ts: []*Transformation{
+			{Operation: &Transformation_Remove{Remove: &AttributeNames{Names: []string{"id"}}}},
+		},
+		wantErr: true,
+	}, {
+		name:    "transformation without operation",
+		ts:      []*Transformation{{}},
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := event.New()
+			e.SetID("id")
+			e.SetSource("source")
+			e.SetType("type")
+			err := CompileTransformations(tc.ts).Apply(&e)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Apply() error got=%v, want error=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			want := "type"
+			if tc.wantType != "" {
+				want = tc.wantType
+			}
+			if e.Type() != want {
+				t.Errorf("type got=%v, want=%v", e.Type(), want)
+			}
+		})
+	}
+}
+
+func TestCachedTargetsGetTargetTransformation(t *testing.T) {
+	targets := &CachedTargets{}
+	if _, ok := targets.GetTargetTransformation("ns/broker/target"); ok {
+		t.Error("GetTargetTransformation() on empty targets got=true, want=false")
+	}
+	targets.Store(&TargetsConfig{
+		Brokers: map[string]*Broker{
+			"ns/broker": {
+				Name:      "broker",
+				Namespace: "ns",
+				Targets: map[string]*Target{
+					"target": {
+						Name:      "target",
+						Namespace: "ns",
+						Broker:    "broker",
+						Transformations: []*Transformation{
+							{Operation: &Transformation_Set{Set: &AttributeValues{Values: map[string]string{"type": "new"}}}},
+						},
+					},
+					"notransformation": {
+						Name:      "notransformation",
+						Namespace: "ns",
+						Broker:    "broker",
+					},
+				},
+			},
+		},
+	})
+	op, ok := targets.GetTargetTransformation("ns/broker/target")
+	if !ok {
+		t.Fatal("GetTargetTransformation() got=false, want=true")
+	}
+	e := event.New()
+	e.SetType("type")
+	if err := op.Apply(&e); err != nil {
+		t.Fatalf("Apply() got unexpected error: %v", err)
+	}
+	if e.Type() != "new" {
+		t.Errorf("type got=%v, want=%v", e.Type(), "new")
+	}
+	if _, ok := targets.GetTargetTransformation("ns/broker/notransformation"); ok {
+		t.Error("GetTargetTransformation() for target without transformations got=true, want=false")
+	}
+}
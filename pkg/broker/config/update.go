@@ -0,0 +1,145 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// SnapshotUpdate returns the update that replaces any targets config with the given one.
+func SnapshotUpdate(cfg *TargetsConfig) *TargetsUpdate {
+	return &TargetsUpdate{
+		Snapshot:   true,
+		Brokers:    cfg.GetBrokers(),
+		Generation: cfg.GetGeneration(),
+		UpdateTime: cfg.GetUpdateTime(),
+	}
+}
+
+// DiffTargets returns the update that changes the old targets config into the new one.
+// Neither config is modified, but the update shares the brokers and targets of the new one.
+func DiffTargets(old, new *TargetsConfig) *TargetsUpdate {
+	u := &TargetsUpdate{Generation: new.GetGeneration(), UpdateTime: new.GetUpdateTime()}
+	for key, ob := range old.GetBrokers() {
+		nb, ok := new.GetBrokers()[key]
+		if !ok {
+			u.DeletedBrokers = append(u.DeletedBrokers, key)
+			continue
+		}
+		for name, t := range ob.Targets {
+			if _, ok := nb.Targets[name]; !ok {
+				u.DeletedTargets = append(u.DeletedTargets, t.Key())
+			}
+		}
+	}
+	for key, nb := range new.GetBrokers() {
+		ob, ok := old.GetBrokers()[key]
+		if !ok {
+			addBroker(u, key, nb)
+			continue
+		}
+		var changed map[string]*Target
+		for name, t := range nb.Targets {
+			if !proto.Equal(t, ob.Targets[name]) {
+				if changed == nil {
+					changed = make(map[string]*Target)
+				}
+				changed[name] = t
+			}
+		}
+		if changed == nil && brokerFieldsEqual(ob, nb) {
+			continue
+		}
+		b := withoutTargets(nb)
+		b.Targets = changed
+		addBroker(u, key, b)
+	}
+	return u
+}
+
+// ApplyUpdate returns the targets config after applying the update to cfg. cfg is not
+// modified. It returns an error if the update is malformed.
+func ApplyUpdate(cfg *TargetsConfig, u *TargetsUpdate) (*TargetsConfig, error) {
+	result := &TargetsConfig{
+		Brokers:    make(map[string]*Broker),
+		Generation: u.Generation,
+		UpdateTime: u.UpdateTime,
+	}
+	if !u.Snapshot {
+		for key, b := range cfg.GetBrokers() {
+			result.Brokers[key] = b
+		}
+	}
+	for _, key := range u.DeletedBrokers {
+		delete(result.Brokers, key)
+	}
+	for _, key := range u.DeletedTargets {
+		if strings.Count(key, "/") != 2 {
+			return nil, fmt.Errorf("invalid target key %q", key)
+		}
+		namespace, broker, name := SplitTriggerKey(key)
+		b, ok := result.Brokers[BrokerKey(namespace, broker)]
+		if !ok {
+			continue
+		}
+		b = proto.Clone(b).(*Broker)
+		delete(b.Targets, name)
+		result.Brokers[b.Key()] = b
+	}
+	for key, ub := range u.Brokers {
+		if ub.Key() != key {
+			return nil, fmt.Errorf("broker %q has key %q", ub.Key(), key)
+		}
+		b := withoutTargets(ub)
+		b.Targets = make(map[string]*Target)
+		if existing, ok := result.Brokers[key]; ok {
+			for name, t := range existing.Targets {
+				b.Targets[name] = t
+			}
+		}
+		for name, t := range ub.Targets {
+			if t.Name != name || t.Namespace != ub.Namespace || t.Broker != ub.Name {
+				return nil, fmt.Errorf("target %q in broker %q has name %q", t.Key(), key, name)
+			}
+			b.Targets[name] = t
+		}
+		result.Brokers[key] = b
+	}
+	return result, nil
+}
+
+func addBroker(u *TargetsUpdate, key string, b *Broker) {
+	if u.Brokers == nil {
+		u.Brokers = make(map[string]*Broker)
+	}
+	u.Brokers[key] = b
+}
+
+// withoutTargets returns a copy of the broker fields other than the targets. The broker
+// may be read concurrently, so it is cloned rather than modified.
+func withoutTargets(b *Broker) *Broker {
+	copy := proto.Clone(b).(*Broker)
+	copy.Targets = nil
+	return copy
+}
+
+func brokerFieldsEqual(a, b *Broker) bool {
+	return proto.Equal(withoutTargets(a), withoutTargets(b))
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDiffAndApplyUpdate(t *testing.T) {
+	target := func(broker, name, address string) *Target {
+		return &Target{Namespace: "ns", Broker: broker, Name: name, Address: address}
+	}
+	broker := func(name, address string, targets ...*Target) *Broker {
+		b := &Broker{Namespace: "ns", Name: name, Address: address, Targets: make(map[string]*Target)}
+		for _, t := range targets {
+			b.Targets[t.Name] = t
+		}
+		return b
+	}
+	config := func(brokers ...*Broker) *TargetsConfig {
+		c := &TargetsConfig{Brokers: make(map[string]*Broker)}
+		for _, b := range brokers {
+			c.Brokers[b.Key()] = b
+		}
+		return c
+	}
+
+	cases := []struct {
+		name        string
+		old         *TargetsConfig
+		new         *TargetsConfig
+		wantBrokers map[string][]string
+		wantDeleted []string
+	}{{
+		name:        "unchanged",
+		old:         config(broker("b1", "a", target("b1", "t1", "a"))),
+		new:         config(broker("b1", "a", target("b1", "t1", "a"))),
+		wantBrokers: map[string][]string{},
+	}, {
+		name: "broker added and deleted",
+		old:  config(broker("b1", "a", target("b1", "t1", "a"))),
+		new:  config(broker("b2", "a", target("b2", "t1", "a"))),
+		wantBrokers: map[string][]string{
+			"ns/b2": {"t1"},
+		},
+		wantDeleted: []string{"ns/b1"},
+	}, {
+		name: "broker fields changed",
+		old:  config(broker("b1", "a", target("b1", "t1", "a"))),
+		new:  config(broker("b1", "b", target("b1", "t1", "a"))),
+		wantBrokers: map[string][]string{
+			"ns/b1": nil,
+		},
+	}, {
+		name: "targets added, changed and deleted",
+		old:  config(broker("b1", "a", target("b1", "t1", "a"), target("b1", "t2", "a"), target("b1", "t3", "a"))),
+		new:  config(broker("b1", "a", target("b1", "t1", "a"), target("b1", "t2", "b"), target("b1", "t4", "a"))),
+		wantBrokers: map[string][]string{
+			"ns/b1": {"t2", "t4"},
+		},
+		wantDeleted: []string{"ns/b1/t3"},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldCopy := proto.Clone(tc.old)
+			u := DiffTargets(tc.old, tc.new)
+
+			gotBrokers := make(map[string][]string)
+			for key, b := range u.Brokers {
+				var names []string
+				for name := range b.Targets {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				gotBrokers[key] = names
+			}
+			if diff := cmp.Diff(tc.wantBrokers, gotBrokers); diff != "" {
+				t.Errorf("DiffTargets brokers (-want,+got): %v", diff)
+			}
+			gotDeleted := append(u.DeletedBrokers, u.DeletedTargets...)
+			if diff := cmp.Diff(tc.wantDeleted, gotDeleted); diff != "" {
+				t.Errorf("DiffTargets deleted (-want,+got): %v", diff)
+			}
+
+			got, err := ApplyUpdate(tc.old, u)
+			if err != nil {
+				t.Fatalf("ApplyUpdate got unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.new, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ApplyUpdate (-want,+got): %v", diff)
+			}
+			if diff := cmp.Diff(oldCopy, tc.old, protocmp.Transform()); diff != "" {
+				t.Errorf("ApplyUpdate modified the config (-want,+got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestApplySnapshotUpdate(t *testing.T) {
+	old := &TargetsConfig{Brokers: map[string]*Broker{
+		"ns/b1": {Namespace: "ns", Name: "b1"},
+	}}
+	want := &TargetsConfig{
+		Brokers: map[string]*Broker{
+			"ns/b2": {Namespace: "ns", Name: "b2", Targets: map[string]*Target{
+				"t1": {Namespace: "ns", Broker: "b2", Name: "t1"},
+			}},
+		},
+		Generation: 3,
+		UpdateTime: timestamppb.New(time.Unix(1600000000, 0)),
+	}
+	got, err := ApplyUpdate(old, SnapshotUpdate(want))
+	if err != nil {
+		t.Fatalf("ApplyUpdate got unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ApplyUpdate (-want,+got): %v", diff)
+	}
+}
+
+func TestApplyMalformedUpdate(t *testing.T) {
+	cases := []struct {
+		name   string
+		update *TargetsUpdate
+	}{{
+		name:   "invalid target key",
+		update: &TargetsUpdate{DeletedTargets: []string{"ns/t1"}},
+	}, {
+		name: "mismatched broker key",
+		update: &TargetsUpdate{Brokers: map[string]*Broker{
+			"ns/b1": {Namespace: "ns", Name: "b2"},
+		}},
+	}, {
+		name: "mismatched target name",
+		update: &TargetsUpdate{Brokers: map[string]*Broker{
+			"ns/b1": {Namespace: "ns", Name: "b1", Targets: map[string]*Target{
+				"t1": {Namespace: "ns", Broker: "b1", Name: "t2"},
+			}},
+		}},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ApplyUpdate(&TargetsConfig{}, tc.update); err == nil {
+				t.Error("ApplyUpdate got error=nil, want error")
+			}
+		})
+	}
+}
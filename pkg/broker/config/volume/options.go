@@ -16,6 +16,8 @@ limitations under the License.
 
 package volume
 
+import "github.com/google/knative-gcp/pkg/broker/config"
+
 // Option is the option to load targets.
 type Option func(*Targets)
 
@@ -33,3 +35,11 @@ func WithNotifyChan(ch chan<- struct{}) Option {
 		t.notifyChan = ch
 	}
 }
+
+// WithObserver is the option to call the given function with every
+// targets config loaded. The function must not modify the config.
+func WithObserver(observe func(*config.TargetsConfig)) Option {
+	return func(t *Targets) {
+		t.observe = observe
+	}
+}
@@ -20,7 +20,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/google/knative-gcp/pkg/broker/config"
@@ -35,10 +38,13 @@ const (
 // loaded from a file.
 // It also watches the file for any changes and will automatically
 // refresh the in memory cache.
+// If the path is a directory, each file in it holds a shard of the
+// targets config and the shards are merged.
 type Targets struct {
 	config.CachedTargets
 	path       string
 	notifyChan chan<- struct{}
+	observe    func(*config.TargetsConfig)
 }
 
 var _ config.ReadonlyTargets = (*Targets)(nil)
@@ -115,6 +121,14 @@ func (t *Targets) watchWith(watcher *fsnotify.Watcher) error {
 }
 
 func (t *Targets) sync() error {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if info.IsDir() {
+		return t.syncShards()
+	}
+
 	b, err := t.readFile()
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
@@ -125,10 +139,51 @@ func (t *Targets) sync() error {
 		return fmt.Errorf("failed to unmarshal config file: %w", err)
 	}
 
-	t.Store(&val)
+	t.store(&val)
+	return nil
+}
+
+// syncShards merges the shards of the targets config in the directory. Hidden
+// files, such as the ones Kubernetes uses to swap ConfigMap volumes atomically,
+// are skipped.
+func (t *Targets) syncShards() error {
+	files, err := ioutil.ReadDir(t.path)
+	if err != nil {
+		return fmt.Errorf("failed to read config directory: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	var shards []*config.TargetsConfig
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(t.path, f.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read config shard %q: %w", f.Name(), err)
+		}
+		var shard config.TargetsConfig
+		if err := proto.Unmarshal(b, &shard); err != nil {
+			return fmt.Errorf("failed to unmarshal config shard %q: %w", f.Name(), err)
+		}
+		shards = append(shards, &shard)
+	}
+	val, err := config.MergeShards(shards...)
+	if err != nil {
+		return err
+	}
+
+	t.store(val)
 	return nil
 }
 
+func (t *Targets) store(val *config.TargetsConfig) {
+	t.Store(val)
+	if t.observe != nil {
+		t.observe(val)
+	}
+}
+
 func (t *Targets) readFile() ([]byte, error) {
 	return ioutil.ReadFile(t.path)
 }
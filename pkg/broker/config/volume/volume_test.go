@@ -17,13 +17,16 @@ limitations under the License.
 package volume
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/google/knative-gcp/pkg/broker/config"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestSyncConfigFromFile(t *testing.T) {
@@ -173,6 +176,109 @@ func TestSyncConfigFromFile(t *testing.T) {
 	}
 }
 
+func TestSyncConfigFromShards(t *testing.T) {
+	broker := func(ns, name, address string) *config.Broker {
+		return &config.Broker{
+			Id:        "b-uid-" + name,
+			Address:   address,
+			Name:      name,
+			Namespace: ns,
+			State:     config.State_READY,
+			Targets: map[string]*config.Target{
+				"name1": {Id: "uid-" + name, Name: "name1", Namespace: ns, Broker: name, State: config.State_READY},
+			},
+		}
+	}
+	shards := []*config.TargetsConfig{
+		{
+			Brokers:    map[string]*config.Broker{"ns1/broker1": broker("ns1", "broker1", "broker1.ns1.example.com")},
+			Generation: 2,
+			UpdateTime: timestamppb.New(time.Unix(1600000002, 0)),
+		},
+		{
+			Brokers: map[string]*config.Broker{
+				"ns2/broker2": broker("ns2", "broker2", "broker2.ns2.example.com"),
+				"ns3/broker3": broker("ns3", "broker3", "broker3.ns3.example.com"),
+			},
+			Generation: 1,
+			UpdateTime: timestamppb.New(time.Unix(1600000001, 0)),
+		},
+	}
+	merged := func() *config.TargetsConfig {
+		m := &config.TargetsConfig{Brokers: make(map[string]*config.Broker)}
+		for _, s := range shards {
+			for k, b := range s.Brokers {
+				m.Brokers[k] = b
+			}
+			// The merged config has the generation of the latest shard.
+			if s.Generation > m.Generation {
+				m.Generation, m.UpdateTime = s.Generation, s.UpdateTime
+			}
+		}
+		return m
+	}
+
+	dir, err := ioutil.TempDir("", "configtest-*")
+	if err != nil {
+		t.Fatalf("unexpected error from creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeShardsVolume(t, dir, "..v1", shards)
+	path := filepath.Join(dir, "targets")
+	if err := os.Symlink(filepath.Join("..data", "targets"), path); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan struct{}, 1)
+	targets, err := NewTargetsFromFile(WithPath(path), WithNotifyChan(ch))
+	if err != nil {
+		t.Fatalf("unexpected error from NewTargetsFromFile: %v", err)
+	}
+	want := merged()
+	if got := targets.(*Targets).Load(); !proto.Equal(want, got) {
+		t.Errorf("initial targets got=%+v, want=%+v", got, want)
+	}
+
+	shards[1].Brokers["ns2/broker2"].Address = "updated.ns2.example.com"
+	delete(shards[1].Brokers, "ns3/broker3")
+	shards[1].Generation = 3
+	shards[1].UpdateTime = timestamppb.New(time.Unix(1600000003, 0))
+	writeShardsVolume(t, dir, "..v2", shards)
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timeout waiting for the notification")
+	}
+	want = merged()
+	if got := targets.(*Targets).Load(); !proto.Equal(want, got) {
+		t.Errorf("updated targets got=%+v, want=%+v", got, want)
+	}
+}
+
+// writeShardsVolume writes the shards into a new versioned directory and atomically
+// swaps the ..data symlink to it, like K8s updates projected volumes.
+func writeShardsVolume(t *testing.T, dir, version string, shards []*config.TargetsConfig) {
+	t.Helper()
+	shardsDir := filepath.Join(dir, version, "targets")
+	if err := os.MkdirAll(shardsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range shards {
+		b, _ := proto.Marshal(s)
+		if err := ioutil.WriteFile(filepath.Join(shardsDir, fmt.Sprint(i)), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func atomicWriteFile(t *testing.T, file string, bytes []byte) {
 	t.Helper()
 	// In order to more closely replicate how K8s writes ConfigMaps to the file system, we will
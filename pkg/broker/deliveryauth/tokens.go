@@ -0,0 +1,171 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deliveryauth provides the tokens that authenticate event deliveries
+// from the broker data plane to subscribers.
+package deliveryauth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// kubeTokenExpirationSeconds is the requested lifetime of Kubernetes tokens.
+// Tokens are requested again shortly before they expire.
+const kubeTokenExpirationSeconds = 3600
+
+type sourceKey struct {
+	auth     config.DeliveryAuth
+	audience string
+}
+
+// Tokens provides tokens of the data plane service account per audience. Tokens are
+// cached until shortly before they expire.
+type Tokens struct {
+	mu      sync.Mutex
+	sources map[sourceKey]oauth2.TokenSource
+
+	newGoogleSource func(audience string) (oauth2.TokenSource, error)
+	newKubeSource   func(audience string) oauth2.TokenSource
+}
+
+// NewTokens creates Tokens for the Kubernetes service account with the given namespace and name.
+// Google ID tokens are issued for the Google service account of the default credentials.
+func NewTokens(kubeClient kubernetes.Interface, namespace, serviceAccount string) *Tokens {
+	return &Tokens{
+		sources: make(map[sourceKey]oauth2.TokenSource),
+		newGoogleSource: func(audience string) (oauth2.TokenSource, error) {
+			// The token source outlives any request so it doesn't take a request context.
+			return idtoken.NewTokenSource(context.Background(), audience)
+		},
+		newKubeSource: func(audience string) oauth2.TokenSource {
+			return &kubeTokenSource{
+				client:         kubeClient,
+				namespace:      namespace,
+				serviceAccount: serviceAccount,
+				audience:       audience,
+			}
+		},
+	}
+}
+
+// Token returns a token of the given type for the audience.
+// It returns an empty token if auth is NO_AUTH.
+func (t *Tokens) Token(ctx context.Context, auth config.DeliveryAuth, audience string) (string, error) {
+	if auth == config.DeliveryAuth_NO_AUTH {
+		return "", nil
+	}
+	src, err := t.source(auth, audience)
+	if err != nil {
+		return "", err
+	}
+	// The token sources outlive the deliveries and the Kubernetes client takes no
+	// context, so a fetch is abandoned rather than cancelled once ctx is done. Its
+	// token is still cached for the next deliveries.
+	type result struct {
+		tok *oauth2.Token
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		tok, err := src.Token()
+		ch <- result{tok: tok, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("failed to get %v token: %w", auth, ctx.Err())
+	case r := <-ch:
+		if r.err != nil {
+			return "", fmt.Errorf("failed to get %v token: %w", auth, r.err)
+		}
+		return r.tok.AccessToken, nil
+	}
+}
+
+// Prune removes the token sources for which exists returns false, e.g. those of
+// deleted targets.
+func (t *Tokens) Prune(exists func(auth config.DeliveryAuth, audience string) bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.sources {
+		if !exists(key.auth, key.audience) {
+			delete(t.sources, key)
+		}
+	}
+}
+
+func (t *Tokens) source(auth config.DeliveryAuth, audience string) (oauth2.TokenSource, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := sourceKey{auth: auth, audience: audience}
+	if src, ok := t.sources[key]; ok {
+		return src, nil
+	}
+
+	var src oauth2.TokenSource
+	switch auth {
+	case config.DeliveryAuth_GOOGLE_ID_TOKEN:
+		var err error
+		if src, err = t.newGoogleSource(audience); err != nil {
+			return nil, fmt.Errorf("failed to create Google ID token source: %w", err)
+		}
+	case config.DeliveryAuth_KUBERNETES_TOKEN:
+		src = t.newKubeSource(audience)
+	default:
+		return nil, fmt.Errorf("unknown delivery auth %v", auth)
+	}
+	// ReuseTokenSource caches the token until shortly before it expires.
+	src = oauth2.ReuseTokenSource(nil, src)
+	t.sources[key] = src
+	return src, nil
+}
+
+// kubeTokenSource requests Kubernetes service account tokens with the TokenRequest API.
+type kubeTokenSource struct {
+	client         kubernetes.Interface
+	namespace      string
+	serviceAccount string
+	audience       string
+}
+
+func (s *kubeTokenSource) Token() (*oauth2.Token, error) {
+	expiration := int64(kubeTokenExpirationSeconds)
+	tr, err := s.client.CoreV1().ServiceAccounts(s.namespace).CreateToken(s.serviceAccount, &authv1.TokenRequest{
+		Spec: authv1.TokenRequestSpec{
+			Audiences:         []string{s.audience},
+			ExpirationSeconds: &expiration,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: tr.Status.Token,
+		TokenType:   "Bearer",
+		Expiry:      tr.Status.ExpirationTimestamp.Time,
+	}, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deliveryauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestNoAuthToken(t *testing.T) {
+	tokens := NewTokens(fake.NewSimpleClientset(), "ns", "broker")
+	tok, err := tokens.Token(context.Background(), config.DeliveryAuth_NO_AUTH, "https://subscriber.example.com")
+	if err != nil {
+		t.Fatalf("Token got unexpected error: %v", err)
+	}
+	if tok != "" {
+		t.Errorf("Token got=%q, want empty", tok)
+	}
+}
+
+func TestKubernetesToken(t *testing.T) {
+	const audience = "https://subscriber.example.com"
+	requests := 0
+	expiry := time.Now().Add(time.Hour)
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		create := action.(clienttesting.CreateAction)
+		if create.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		if create.GetNamespace() != "ns" {
+			t.Errorf("namespace got=%q, want=%q", create.GetNamespace(), "ns")
+		}
+		tr := create.GetObject().(*authv1.TokenRequest).DeepCopy()
+		if len(tr.Spec.Audiences) != 1 || tr.Spec.Audiences[0] != audience {
+			t.Errorf("audiences got=%v, want=[%s]", tr.Spec.Audiences, audience)
+		}
+		requests++
+		tr.Status = authv1.TokenRequestStatus{
+			Token:               "kube-token",
+			ExpirationTimestamp: metav1.NewTime(expiry),
+		}
+		return true, tr, nil
+	})
+
+	tokens := NewTokens(client, "ns", "broker")
+	for i := 0; i < 2; i++ {
+		tok, err := tokens.Token(context.Background(), config.DeliveryAuth_KUBERNETES_TOKEN, audience)
+		if err != nil {
+			t.Fatalf("Token got unexpected error: %v", err)
+		}
+		if tok != "kube-token" {
+			t.Errorf("Token got=%q, want=%q", tok, "kube-token")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("token requests got=%d, want=1", requests)
+	}
+}
+
+// countingSource returns a token that expires immediately and counts the refreshes.
+type countingSource struct {
+	audience string
+	count    int
+}
+
+func (s *countingSource) Token() (*oauth2.Token, error) {
+	s.count++
+	return &oauth2.Token{AccessToken: s.audience, Expiry: time.Now().Add(-time.Second)}, nil
+}
+
+func TestGoogleIDToken(t *testing.T) {
+	sources := map[string]*countingSource{}
+	tokens := NewTokens(fake.NewSimpleClientset(), "ns", "broker")
+	tokens.newGoogleSource = func(audience string) (oauth2.TokenSource, error) {
+		src := &countingSource{audience: audience}
+		sources[audience] = src
+		return src, nil
+	}
+
+	for _, audience := range []string{"https://a.example.com", "https://b.example.com", "https://a.example.com"} {
+		tok, err := tokens.Token(context.Background(), config.DeliveryAuth_GOOGLE_ID_TOKEN, audience)
+		if err != nil {
+			t.Fatalf("Token got unexpected error: %v", err)
+		}
+		if tok != audience {
+			t.Errorf("Token got=%q, want=%q", tok, audience)
+		}
+	}
+	if len(sources) != 2 {
+		t.Errorf("token sources got=%d, want=2", len(sources))
+	}
+	// Expired tokens are refreshed.
+	if got := sources["https://a.example.com"].count; got != 2 {
+		t.Errorf("token refreshes got=%d, want=2", got)
+	}
+}
+
+// blockingSource blocks until it's released.
+type blockingSource struct {
+	release chan struct{}
+}
+
+func (s *blockingSource) Token() (*oauth2.Token, error) {
+	<-s.release
+	return &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestTokenCancelled(t *testing.T) {
+	src := &blockingSource{release: make(chan struct{})}
+	defer close(src.release)
+	tokens := NewTokens(fake.NewSimpleClientset(), "ns", "broker")
+	tokens.newGoogleSource = func(string) (oauth2.TokenSource, error) {
+		return src, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := tokens.Token(ctx, config.DeliveryAuth_GOOGLE_ID_TOKEN, "https://a.example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Token got error=%v, want=%v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	created := 0
+	tokens := NewTokens(fake.NewSimpleClientset(), "ns", "broker")
+	tokens.newGoogleSource = func(audience string) (oauth2.TokenSource, error) {
+		created++
+		return &countingSource{audience: audience}, nil
+	}
+
+	for _, audience := range []string{"https://a.example.com", "https://b.example.com"} {
+		if _, err := tokens.Token(context.Background(), config.DeliveryAuth_GOOGLE_ID_TOKEN, audience); err != nil {
+			t.Fatalf("Token got unexpected error: %v", err)
+		}
+	}
+	tokens.Prune(func(auth config.DeliveryAuth, audience string) bool {
+		return audience == "https://a.example.com"
+	})
+	if got := len(tokens.sources); got != 1 {
+		t.Errorf("token sources after prune got=%d, want=1", got)
+	}
+	if _, ok := tokens.sources[sourceKey{auth: config.DeliveryAuth_GOOGLE_ID_TOKEN, audience: "https://a.example.com"}]; !ok {
+		t.Error("token source in use was pruned")
+	}
+
+	// A nil Tokens can be pruned.
+	var none *Tokens
+	none.Prune(func(config.DeliveryAuth, string) bool { return false })
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventutil
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestOrderingKey(t *testing.T) {
+	e := cloudevents.NewEvent()
+	e.SetExtension("partitionkey", "key")
+	e.SetExtension("number", 42)
+
+	cases := []struct {
+		name string
+		attr string
+		want string
+	}{{
+		name: "unordered",
+		want: "",
+	}, {
+		name: "string attribute",
+		attr: "partitionkey",
+		want: "key",
+	}, {
+		name: "integer attribute",
+		attr: "number",
+		want: "42",
+	}, {
+		name: "missing attribute",
+		attr: "missing",
+		want: "",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := OrderingKey(&e, tc.attr); got != tc.want {
+				t.Errorf("OrderingKey() got=%q, want=%q", got, tc.want)
+			}
+		})
+	}
+}
@@ -19,6 +19,8 @@ package context
 import "errors"
 
 var (
-	ErrTargetKeyNotPresent = errors.New("target key not present in the context")
-	ErrBrokerKeyNotPresent = errors.New("broker key not present in the context")
+	ErrTargetKeyNotPresent       = errors.New("target key not present in the context")
+	ErrBrokerKeyNotPresent       = errors.New("broker key not present in the context")
+	ErrDeliveryAttemptNotPresent = errors.New("delivery attempt not present in the context")
+	ErrOriginalEventNotPresent   = errors.New("original event not present in the context")
 )
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+type originalEventKey struct{}
+
+type transformationErrorKey struct{}
+
+// WithOriginalEvent sets the event before it was transformed for the target
+// in the context. Events enqueued for retry must be the original ones, as
+// they are processed again from the start.
+func WithOriginalEvent(ctx context.Context, e *event.Event) context.Context {
+	return context.WithValue(ctx, originalEventKey{}, e)
+}
+
+// GetOriginalEvent gets the event before it was transformed from the context.
+func GetOriginalEvent(ctx context.Context) (*event.Event, error) {
+	untyped := ctx.Value(originalEventKey{})
+	if untyped == nil {
+		return nil, ErrOriginalEventNotPresent
+	}
+	return untyped.(*event.Event), nil
+}
+
+// WithTransformationError sets the error of the transformation that failed for
+// the target in the context. The event is then passed on untransformed.
+func WithTransformationError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, transformationErrorKey{}, err)
+}
+
+// GetTransformationError gets the error of the failed transformation from the
+// context, or nil if the event was transformed.
+func GetTransformationError(ctx context.Context) error {
+	err, _ := ctx.Value(transformationErrorKey{}).(error)
+	return err
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestOriginalEvent(t *testing.T) {
+	_, err := GetOriginalEvent(context.Background())
+	if err != ErrOriginalEventNotPresent {
+		t.Errorf("error from GetOriginalEvent got=%v, want=%v", err, ErrOriginalEventNotPresent)
+	}
+
+	e := event.New()
+	ctx := WithOriginalEvent(context.Background(), &e)
+	got, err := GetOriginalEvent(ctx)
+	if err != nil {
+		t.Errorf("unexpected error from GetOriginalEvent: %v", err)
+	}
+	if got != &e {
+		t.Errorf("GetOriginalEvent got=%p, want=%p", got, &e)
+	}
+}
+
+func TestTransformationError(t *testing.T) {
+	if err := GetTransformationError(context.Background()); err != nil {
+		t.Errorf("GetTransformationError got=%v, want nil", err)
+	}
+
+	want := errors.New("transformation failed")
+	ctx := WithTransformationError(context.Background(), want)
+	if got := GetTransformationError(ctx); got != want {
+		t.Errorf("GetTransformationError got=%v, want=%v", got, want)
+	}
+}
@@ -31,20 +31,26 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/dedup"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/deliver"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/fanout"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/filter"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/transformation"
+	"github.com/google/knative-gcp/pkg/broker/ratelimit"
 	"github.com/google/knative-gcp/pkg/metrics"
 )
 
 // FanoutPool is the sync pool for fanout handlers.
 // For each broker in the config, it will attempt to create a handler.
 // It will also stop/delete the handler if the corresponding broker is deleted
-// in the config.
+// in the config. Isolated targets get a handler of their own pulling from
+// their dedicated subscription.
 type FanoutPool struct {
 	options *Options
 	targets config.ReadonlyTargets
 	pool    sync.Map
+	// isolatedPool holds the handlers of the isolated targets by target key.
+	isolatedPool sync.Map
 
 	// Pubsub client used to pull events from decoupling topics.
 	pubsubClient *pubsub.Client
@@ -55,6 +61,12 @@ type FanoutPool struct {
 	// And we can set target address dynamically.
 	deliverClient *http.Client
 	statsReporter *metrics.DeliveryReporter
+	// rateLimiters are shared by the handlers to enforce the target rate limits.
+	rateLimiters *ratelimit.Limiters
+	// inFlight is shared by the handlers to enforce the target in-flight limits.
+	inFlight *ratelimit.InFlight
+	// orderedRetry is shared by the handlers to retry the events of ordered brokers in order.
+	orderedRetry *deliver.OrderedRetry
 }
 
 type fanoutHandlerCache struct {
@@ -80,6 +92,24 @@ func (hc *fanoutHandlerCache) shouldRenew(b *config.Broker) bool {
 	return false
 }
 
+type isolatedHandlerCache struct {
+	Handler
+	t *config.Target
+}
+
+// If somehow the existing handler's setting has deviated from the current target config,
+// we need to renew the handler.
+func (hc *isolatedHandlerCache) shouldRenew(t *config.Target) bool {
+	if !hc.IsAlive() {
+		return true
+	}
+	if t.IsolatedQueue.Topic != hc.t.IsolatedQueue.Topic ||
+		t.IsolatedQueue.Subscription != hc.t.IsolatedQueue.Subscription {
+		return true
+	}
+	return false
+}
+
 // NewFanoutPool creates a new fanout handler pool.
 func NewFanoutPool(
 	targets config.ReadonlyTargets,
@@ -113,6 +143,9 @@ func NewFanoutPool(
 		deliverClient:      deliverClient,
 		deliverRetryClient: retryClient,
 		statsReporter:      statsReporter,
+		rateLimiters:       ratelimit.NewLimiters(),
+		inFlight:           ratelimit.NewInFlight(),
+		orderedRetry:       deliver.NewOrderedRetry(pubsubClient, 0),
 	}
 	return p, nil
 }
@@ -126,20 +159,31 @@ func (p *FanoutPool) SyncOnce(ctx context.Context) error {
 
 	p.pool.Range(func(key, value interface{}) bool {
 		if _, ok := p.targets.GetBrokerByKey(key.(string)); !ok {
-			value.(*fanoutHandlerCache).Stop()
+			go value.(*fanoutHandlerCache).Drain(p.options.DrainTimeout)
 			p.pool.Delete(key)
 		}
 		return true
 	})
 
+	// Forget the circuit breakers, ordered retries, rate limiters and delivery tokens of deleted targets.
+	targetExists := func(key string) bool {
+		_, ok := p.targets.GetTargetByKey(key)
+		return ok
+	}
+	p.options.CircuitBreakers.Prune(targetExists)
+	p.orderedRetry.Prune(targetExists)
+	p.rateLimiters.Prune(targetExists)
+	p.options.DeliveryTokens.Prune(deliveryTokenInUse(p.targets))
+
 	p.targets.RangeBrokers(func(b *config.Broker) bool {
 		if value, ok := p.pool.Load(b.Key()); ok {
 			// Skip if we don't need to renew the handler.
 			if !value.(*fanoutHandlerCache).shouldRenew(b) {
 				return true
 			}
-			// Stop and clean up the old handler before we start a new one.
-			value.(*fanoutHandlerCache).Stop()
+			// Drain the old handler in the background while the new one takes over,
+			// so that its in-flight events aren't redelivered as duplicates.
+			go value.(*fanoutHandlerCache).Drain(p.options.DrainTimeout)
 			p.pool.Delete(b.Key())
 		}
 
@@ -157,6 +201,8 @@ func (p *FanoutPool) SyncOnce(ctx context.Context) error {
 			processors.ChainProcessors(
 				&fanout.Processor{MaxConcurrency: p.options.MaxConcurrencyPerEvent, Targets: p.targets},
 				&filter.Processor{Targets: p.targets},
+				&dedup.Processor{Store: p.options.DedupStore},
+				&transformation.Processor{Targets: p.targets},
 				&deliver.Processor{
 					DeliverClient:      p.deliverClient,
 					Targets:            p.targets,
@@ -164,6 +210,11 @@ func (p *FanoutPool) SyncOnce(ctx context.Context) error {
 					DeliverRetryClient: p.deliverRetryClient,
 					DeliverTimeout:     p.options.DeliveryTimeout,
 					StatsReporter:      p.statsReporter,
+					RateLimiters:       p.rateLimiters,
+					Tokens:             p.options.DeliveryTokens,
+					InFlight:           p.inFlight,
+					CircuitBreakers:    p.options.CircuitBreakers,
+					OrderedRetry:       p.orderedRetry,
 				},
 			),
 			p.options.TimeoutPerEvent,
@@ -187,5 +238,98 @@ func (p *FanoutPool) SyncOnce(ctx context.Context) error {
 		return true
 	})
 
+	p.syncIsolatedHandlers(ctx)
 	return nil
 }
+
+// Drain drains all handlers of the pool, e.g. on shutdown. See Handler.Drain.
+func (p *FanoutPool) Drain() {
+	drainAll(p.options.DrainTimeout, &p.pool, &p.isolatedPool)
+	p.orderedRetry.Stop()
+}
+
+// syncIsolatedHandlers syncs the handlers of the isolated targets. They deliver the events
+// of their own subscription, so a slow or failing target never holds up the acks of the
+// broker fanout.
+func (p *FanoutPool) syncIsolatedHandlers(ctx context.Context) {
+	p.isolatedPool.Range(func(key, value interface{}) bool {
+		if t, ok := p.targets.GetTargetByKey(key.(string)); !ok || t.IsolatedQueue == nil {
+			go value.(*isolatedHandlerCache).Drain(p.options.DrainTimeout)
+			p.isolatedPool.Delete(key)
+		}
+		return true
+	})
+
+	p.targets.RangeAllTargets(func(t *config.Target) bool {
+		if t.IsolatedQueue == nil {
+			return true
+		}
+		if value, ok := p.isolatedPool.Load(t.Key()); ok {
+			// Skip if we don't need to renew the handler.
+			if !value.(*isolatedHandlerCache).shouldRenew(t) {
+				return true
+			}
+			// Drain the old handler in the background while the new one takes over,
+			// so that its in-flight events aren't redelivered as duplicates.
+			go value.(*isolatedHandlerCache).Drain(p.options.DrainTimeout)
+			p.isolatedPool.Delete(t.Key())
+		}
+
+		// Don't start the handler if the target is not ready.
+		// The isolated sub might not be ready at this point.
+		if t.State != config.State_READY {
+			return true
+		}
+
+		sub := p.pubsubClient.Subscription(t.IsolatedQueue.Subscription)
+		sub.ReceiveSettings = p.options.PubsubReceiveSettings
+
+		h := NewHandler(
+			sub,
+			processors.ChainProcessors(
+				&filter.Processor{Targets: p.targets},
+				&dedup.Processor{Store: p.options.DedupStore},
+				&transformation.Processor{Targets: p.targets},
+				&deliver.Processor{
+					DeliverClient:      p.deliverClient,
+					Targets:            p.targets,
+					RetryOnFailure:     true,
+					DeliverRetryClient: p.deliverRetryClient,
+					DeliverTimeout:     p.options.DeliveryTimeout,
+					StatsReporter:      p.statsReporter,
+					RateLimiters:       p.rateLimiters,
+					Tokens:             p.options.DeliveryTokens,
+					InFlight:           p.inFlight,
+					CircuitBreakers:    p.options.CircuitBreakers,
+					OrderedRetry:       p.orderedRetry,
+				},
+			),
+			p.options.TimeoutPerEvent,
+			p.options.RetryPolicy,
+		)
+		hc := &isolatedHandlerCache{
+			Handler: *h,
+			t:       t,
+		}
+
+		ctx, err := metrics.AddTargetTags(ctx, t)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to add target tags to context", zap.Error(err))
+		}
+
+		// Deliver processor needs the broker in the context for reply.
+		ctx = handlerctx.WithBrokerKey(ctx, config.BrokerKey(t.Namespace, t.Broker))
+		ctx = handlerctx.WithTargetKey(ctx, t.Key())
+		// Start the handler with target in context.
+		hc.Start(ctx, func(err error) {
+			if err != nil {
+				logging.FromContext(ctx).Error("handler for isolated trigger has stopped with error", zap.String("trigger", t.Key()), zap.Error(err))
+			} else {
+				logging.FromContext(ctx).Info("handler for isolated trigger has stopped", zap.String("trigger", t.Key()))
+			}
+		})
+
+		p.isolatedPool.Store(t.Key(), hc)
+		return true
+	})
+}
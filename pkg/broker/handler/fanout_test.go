@@ -349,6 +349,8 @@ func TestFanoutSyncPoolE2E(t *testing.T) {
 		wantReply := reply.Clone()
 		// -1 because the delivery processor should decrement remaining hops.
 		eventutil.UpdateRemainingHops(ctx, &wantReply, hops-1)
+		// The delivery processor should record the target the reply came from.
+		wantReply.SetExtension("knativereplyfrom", t3.Key())
 
 		group, ctx := errgroup.WithContext(ctx)
 		group.Go(func() error {
@@ -391,6 +393,47 @@ func TestFanoutSyncPoolE2E(t *testing.T) {
 		expectMetrics.Expect200(t, t3.Name)
 		expectMetrics.Verify(t)
 	})
+
+	t.Run("isolated target received events from its own subscription", func(t *testing.T) {
+		t5 := helper.GenerateTarget(ctx, t, b1.Key(), nil)
+		t5 = helper.IsolateTarget(ctx, t, t5.Key())
+		expectMetrics.AddTrigger(t, t5.Name, wantTags(t5))
+		signal <- struct{}{}
+
+		// Set timeout context so that verification can be done before
+		// exiting test func.
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		group, ctx := errgroup.WithContext(ctx)
+		group.Go(func() error {
+			helper.VerifyNextTargetEvent(ctx, t, t1.Key(), &e)
+			return nil
+		})
+		group.Go(func() error {
+			helper.VerifyNextTargetEvent(ctx, t, t2.Key(), &e)
+			return nil
+		})
+		// The isolated target receives the event once, from its own handler.
+		group.Go(func() error {
+			helper.VerifyNextTargetEvent(ctx, t, t5.Key(), &e)
+			return nil
+		})
+
+		helper.SendEventToDecoupleQueue(ctx, t, b1.Key(), &e)
+
+		if err := group.Wait(); err != nil {
+			t.Error(err)
+		}
+
+		if _, ok := syncPool.isolatedPool.Load(t5.Key()); !ok {
+			t.Errorf("isolated target %q has no handler", t5.Key())
+		}
+		expectMetrics.Expect200(t, t1.Name)
+		expectMetrics.Expect200(t, t2.Name)
+		expectMetrics.Expect200(t, t5.Name)
+		expectMetrics.Verify(t)
+	})
 }
 
 func assertFanoutHandlers(t *testing.T, p *FanoutPool, targets config.Targets) {
@@ -25,6 +25,7 @@ import (
 	"cloud.google.com/go/pubsub"
 	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
+	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"go.uber.org/zap"
@@ -47,13 +48,31 @@ type Handler struct {
 
 	// retryLimiter limits how fast to retry failed events.
 	retryLimiter workqueue.RateLimiter
-	// delayNack defaults to time.Sleep; could be overridden in test.
-	delayNack func(time.Duration)
+	// delayNack waits for the backoff before nacking a failed event, or until
+	// ctx is done. Defaults to waitBackoff; could be overridden in test.
+	delayNack func(ctx context.Context, backoff time.Duration)
 	// cancel is function to stop pulling messages.
 	cancel context.CancelFunc
-	alive  atomic.Value
+	// processing is done once the events being processed are abandoned.
+	processing       context.Context
+	cancelProcessing context.CancelFunc
+	// stopped is closed once all received messages are acked or nacked.
+	stopped chan struct{}
+	alive   atomic.Value
 }
 
+// drainContext carries the values of the context messages are received with,
+// but is only done once the handler abandons the events being processed. It
+// lets in-flight events finish after the handler stops pulling messages.
+type drainContext struct {
+	context.Context
+	processing context.Context
+}
+
+func (c drainContext) Deadline() (time.Time, bool) { return c.processing.Deadline() }
+func (c drainContext) Done() <-chan struct{}       { return c.processing.Done() }
+func (c drainContext) Err() error                  { return c.processing.Err() }
+
 // NewHandler creates a new Handler.
 func NewHandler(
 	sub *pubsub.Subscription,
@@ -61,31 +80,52 @@ func NewHandler(
 	timeout time.Duration,
 	retryPolicy RetryPolicy,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		Subscription: sub,
 		Processor:    processor,
 		Timeout:      timeout,
 		retryLimiter: workqueue.NewItemExponentialFailureRateLimiter(retryPolicy.MinBackoff, retryPolicy.MaxBackoff),
-		delayNack:    time.Sleep,
 	}
+	h.delayNack = h.waitBackoff
+	return h
 }
 
 // Start starts the handler.
 // done func will be called if the pubsub inbound is closed.
 func (h *Handler) Start(ctx context.Context, done func(error)) {
 	ctx, h.cancel = context.WithCancel(ctx)
+	h.processing, h.cancelProcessing = context.WithCancel(context.Background())
+	h.stopped = make(chan struct{})
 	h.alive.Store(true)
 
 	go func() {
+		defer close(h.stopped)
 		// For any reason if inbound is closed, mark alive as false.
 		defer h.alive.Store(false)
 		done(h.Subscription.Receive(ctx, h.receive))
 	}()
 }
 
-// Stop stops the handlers.
+// Stop stops the handlers. The events being processed are abandoned and
+// their messages nacked.
 func (h *Handler) Stop() {
 	h.cancel()
+	h.cancelProcessing()
+}
+
+// Drain stops pulling messages and waits up to the given timeout for the
+// events being processed to finish, so that they are acked or nacked rather
+// than redelivered as duplicates. The events still being processed after the
+// timeout are abandoned. Drain returns once all messages are acked or nacked.
+func (h *Handler) Drain(timeout time.Duration) {
+	h.cancel()
+	select {
+	case <-h.stopped:
+	case <-time.After(timeout):
+		h.cancelProcessing()
+		<-h.stopped
+	}
+	h.cancelProcessing()
 }
 
 // IsAlive indicates whether the handler is alive.
@@ -93,7 +133,22 @@ func (h *Handler) IsAlive() bool {
 	return h.alive.Load().(bool)
 }
 
+// waitBackoff waits for the backoff, or until ctx is done or the events being
+// processed are abandoned.
+func (h *Handler) waitBackoff(ctx context.Context, backoff time.Duration) {
+	t := time.NewTimer(backoff)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	case <-h.processing.Done():
+	}
+}
+
 func (h *Handler) receive(ctx context.Context, msg *pubsub.Message) {
+	// Pubsub cancels ctx as soon as the handler stops pulling messages.
+	receiveCtx := ctx
+	ctx = drainContext{Context: ctx, processing: h.processing}
 	ctx = metrics.StartEventProcessing(ctx)
 	event, err := binding.ToEvent(ctx, cepubsub.NewMessage(msg))
 	if isNonRetryable(err) {
@@ -109,6 +164,13 @@ func (h *Handler) receive(ctx context.Context, msg *pubsub.Message) {
 		return
 	}
 
+	// Pubsub only populates DeliveryAttempt when the subscription has a dead letter
+	// policy, as the trigger retry subscriptions do. The attempt is tracked by Pubsub
+	// so it holds across all data plane pods and restarts.
+	if msg.DeliveryAttempt != nil {
+		ctx = handlerctx.WithDeliveryAttempt(ctx, *msg.DeliveryAttempt)
+	}
+
 	if h.Timeout != 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
@@ -116,8 +178,15 @@ func (h *Handler) receive(ctx context.Context, msg *pubsub.Message) {
 	}
 	if err := h.Processor.Process(ctx, event); err != nil {
 		backoffPeriod := h.retryLimiter.When(msg.ID)
+		// Don't hold up the drain of the handler with the backoff: once the handler
+		// stops pulling messages, the message is nacked right away.
+		if receiveCtx.Err() != nil || h.processing.Err() != nil {
+			logging.FromContext(ctx).Warn("failed to process event while draining; nack", zap.String("eventID", event.ID()), zap.Error(err))
+			msg.Nack()
+			return
+		}
 		logging.FromContext(ctx).Error("failed to process event; backoff nack", zap.String("eventID", event.ID()), zap.Duration("backoffPeriod", backoffPeriod), zap.Error(err))
-		h.delayNack(backoffPeriod)
+		h.delayNack(receiveCtx, backoffPeriod)
 		msg.Nack()
 		return
 	}
@@ -206,7 +206,7 @@ func TestRetryBackoff(t *testing.T) {
 	}
 	h := NewHandler(sub, processor, time.Second, RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: 16 * time.Millisecond})
 	// Mock sleep func to collect nack backoffs.
-	h.delayNack = func(d time.Duration) {
+	h.delayNack = func(_ context.Context, d time.Duration) {
 		delays = append(delays, d)
 	}
 	h.Start(ctx, func(err error) {})
@@ -256,3 +256,191 @@ func nextEventWithTimeout(eventCh <-chan *event.Event) *event.Event {
 		return got
 	}
 }
+
+// blockingProc blocks processing events until released or the context is done.
+type blockingProc struct {
+	processors.BaseProcessor
+	started chan struct{}
+	release chan struct{}
+	errs    chan error
+}
+
+func (p *blockingProc) Process(ctx context.Context, _ *event.Event) error {
+	p.started <- struct{}{}
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+	}
+	p.errs <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestHandlerDrain(t *testing.T) {
+	cases := []struct {
+		name    string
+		release bool
+		wantErr error
+	}{{
+		name:    "in-flight event finished",
+		release: true,
+	}, {
+		name:    "in-flight event abandoned after timeout",
+		wantErr: context.Canceled,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c, cleanup := testPubsubClient(ctx, t, "test-project")
+			defer cleanup()
+
+			topic, err := c.CreateTopic(ctx, "test-topic")
+			if err != nil {
+				t.Fatalf("failed to create topic: %v", err)
+			}
+			sub, err := c.CreateSubscription(ctx, "test-sub", pubsub.SubscriptionConfig{
+				Topic: topic,
+			})
+			if err != nil {
+				t.Fatalf("failed to create subscription: %v", err)
+			}
+
+			p, err := cepubsub.New(context.Background(),
+				cepubsub.WithClient(c),
+				cepubsub.WithProjectID("test-project"),
+				cepubsub.WithTopicID("test-topic"),
+			)
+			if err != nil {
+				t.Fatalf("failed to create cloudevents pubsub protocol: %v", err)
+			}
+
+			processor := &blockingProc{
+				started: make(chan struct{}, 1),
+				release: make(chan struct{}),
+				errs:    make(chan error, 1),
+			}
+			h := NewHandler(sub, processor, 0, RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+			h.delayNack = func(context.Context, time.Duration) {}
+			h.Start(ctx, func(err error) {})
+
+			testEvent := event.New()
+			testEvent.SetID("id")
+			testEvent.SetSource("source")
+			testEvent.SetSubject("subject")
+			testEvent.SetType("type")
+			if err := p.Send(ctx, binding.ToMessage(&testEvent)); err != nil {
+				t.Fatalf("failed to seed event to pubsub: %v", err)
+			}
+
+			select {
+			case <-processor.started:
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for the event to be processed")
+			}
+
+			drained := make(chan struct{})
+			go func() {
+				h.Drain(500 * time.Millisecond)
+				close(drained)
+			}()
+			if tc.release {
+				// The in-flight event is not abandoned while the handler drains.
+				select {
+				case <-drained:
+					t.Fatal("drain returned before the in-flight event finished")
+				case <-time.After(100 * time.Millisecond):
+				}
+				close(processor.release)
+			}
+
+			select {
+			case <-drained:
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for the handler to drain")
+			}
+			if err := <-processor.errs; err != tc.wantErr {
+				t.Errorf("processing context error got=%v, want=%v", err, tc.wantErr)
+			}
+			if h.IsAlive() {
+				t.Error("drained handler is still alive")
+			}
+		})
+	}
+}
+
+// failingProc fails processing all events.
+type failingProc struct {
+	processors.BaseProcessor
+	failed chan struct{}
+}
+
+func (p *failingProc) Process(context.Context, *event.Event) error {
+	select {
+	case p.failed <- struct{}{}:
+	default:
+	}
+	return errors.New("always error")
+}
+
+func TestHandlerDrainDuringRetryBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, cleanup := testPubsubClient(ctx, t, "test-project")
+	defer cleanup()
+
+	topic, err := c.CreateTopic(ctx, "test-topic")
+	if err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	sub, err := c.CreateSubscription(ctx, "test-sub", pubsub.SubscriptionConfig{
+		Topic: topic,
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	p, err := cepubsub.New(context.Background(),
+		cepubsub.WithClient(c),
+		cepubsub.WithProjectID("test-project"),
+		cepubsub.WithTopicID("test-topic"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create cloudevents pubsub protocol: %v", err)
+	}
+
+	processor := &failingProc{failed: make(chan struct{}, 1)}
+	// The failed event is nacked after a backoff much longer than the drain timeout.
+	h := NewHandler(sub, processor, 0, RetryPolicy{MinBackoff: time.Minute, MaxBackoff: time.Minute})
+	h.Start(ctx, func(err error) {})
+
+	testEvent := event.New()
+	testEvent.SetID("id")
+	testEvent.SetSource("source")
+	testEvent.SetSubject("subject")
+	testEvent.SetType("type")
+	if err := p.Send(ctx, binding.ToMessage(&testEvent)); err != nil {
+		t.Fatalf("failed to seed event to pubsub: %v", err)
+	}
+
+	select {
+	case <-processor.failed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the event to be processed")
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		h.Drain(5 * time.Second)
+		close(drained)
+	}()
+	// The pending backoff is interrupted once the handler drains, so the drain
+	// doesn't wait for its timeout.
+	select {
+	case <-drained:
+	case <-time.After(2 * time.Second):
+		t.Fatal("drain waited for the retry backoff")
+	}
+	if h.IsAlive() {
+		t.Error("drained handler is still alive")
+	}
+}
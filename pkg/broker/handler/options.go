@@ -21,12 +21,18 @@ import (
 	"time"
 
 	"cloud.google.com/go/pubsub"
+
+	"github.com/google/knative-gcp/pkg/broker/circuitbreaker"
+	"github.com/google/knative-gcp/pkg/broker/deliveryauth"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/dedup"
 )
 
 var (
 	defaultHandlerConcurrency     = runtime.NumCPU()
 	defaultMaxConcurrencyPerEvent = 1
 	defaultTimeout                = 10 * time.Minute
+	// The drain fits in the default termination grace period of 30s.
+	defaultDrainTimeout = 25 * time.Second
 
 	// This is the pubsub default MaxExtension.
 	// It would not make sense for handler timeout per event be greater
@@ -58,6 +64,16 @@ type Options struct {
 	PubsubReceiveSettings pubsub.ReceiveSettings
 	// RetryPolicy defines the retry policy for pubsub messages.
 	RetryPolicy RetryPolicy
+	// DeliveryTokens provides the tokens to authenticate deliveries to targets.
+	DeliveryTokens *deliveryauth.Tokens
+	// CircuitBreakers stop deliveries to failing targets.
+	CircuitBreakers *circuitbreaker.Breakers
+	// DrainTimeout is how long stopped handlers wait for the events being
+	// processed to finish before abandoning them.
+	DrainTimeout time.Duration
+	// DedupStore records the events delivered to targets to skip their
+	// redelivery. Events are not deduplicated if nil.
+	DedupStore dedup.Store
 }
 
 // NewOptions creates a Options.
@@ -67,6 +83,7 @@ func NewOptions(opts ...Option) (*Options, error) {
 		MaxConcurrencyPerEvent: defaultMaxConcurrencyPerEvent,
 		TimeoutPerEvent:        defaultTimeout,
 		PubsubReceiveSettings:  pubsub.DefaultReceiveSettings,
+		DrainTimeout:           defaultDrainTimeout,
 	}
 	for _, o := range opts {
 		o(opt)
@@ -122,3 +139,31 @@ func WithRetryPolicy(r RetryPolicy) Option {
 		o.RetryPolicy = r
 	}
 }
+
+// WithDeliveryTokens sets the DeliveryTokens.
+func WithDeliveryTokens(t *deliveryauth.Tokens) Option {
+	return func(o *Options) {
+		o.DeliveryTokens = t
+	}
+}
+
+// WithCircuitBreakers sets the CircuitBreakers.
+func WithCircuitBreakers(b *circuitbreaker.Breakers) Option {
+	return func(o *Options) {
+		o.CircuitBreakers = b
+	}
+}
+
+// WithDrainTimeout sets the DrainTimeout.
+func WithDrainTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = t
+	}
+}
+
+// WithDedupStore sets the DedupStore.
+func WithDedupStore(s dedup.Store) Option {
+	return func(o *Options) {
+		o.DedupStore = s
+	}
+}
@@ -22,6 +22,11 @@ import (
 
 	"cloud.google.com/go/pubsub"
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/google/knative-gcp/pkg/broker/circuitbreaker"
+	"github.com/google/knative-gcp/pkg/broker/deliveryauth"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/dedup"
 )
 
 func TestWithHandlerConcurrency(t *testing.T) {
@@ -96,3 +101,47 @@ func TestWithDeliveryTimeout(t *testing.T) {
 		t.Errorf("options timeout per event got=%v, want=%v", opt.DeliveryTimeout, want)
 	}
 }
+
+func TestWithDeliveryTokens(t *testing.T) {
+	want := deliveryauth.NewTokens(fake.NewSimpleClientset(), "ns", "broker")
+	opt, err := NewOptions(WithDeliveryTokens(want))
+	if err != nil {
+		t.Errorf("NewOptions got unexpected error: %v", err)
+	}
+	if opt.DeliveryTokens != want {
+		t.Errorf("options delivery tokens got=%p, want=%p", opt.DeliveryTokens, want)
+	}
+}
+
+func TestWithCircuitBreakers(t *testing.T) {
+	want := circuitbreaker.NewBreakers()
+	opt, err := NewOptions(WithCircuitBreakers(want))
+	if err != nil {
+		t.Errorf("NewOptions got unexpected error: %v", err)
+	}
+	if opt.CircuitBreakers != want {
+		t.Errorf("options circuit breakers got=%p, want=%p", opt.CircuitBreakers, want)
+	}
+}
+
+func TestWithDrainTimeout(t *testing.T) {
+	want := 10 * time.Second
+	opt, err := NewOptions(WithDrainTimeout(want))
+	if err != nil {
+		t.Errorf("NewOptions got unexpected error: %v", err)
+	}
+	if opt.DrainTimeout != want {
+		t.Errorf("options drain timeout got=%v, want=%v", opt.DrainTimeout, want)
+	}
+}
+
+func TestWithDedupStore(t *testing.T) {
+	want := dedup.NewLRUStore(10, time.Minute)
+	opt, err := NewOptions(WithDedupStore(want))
+	if err != nil {
+		t.Errorf("NewOptions got unexpected error: %v", err)
+	}
+	if opt.DedupStore != want {
+		t.Errorf("options dedup store got=%p, want=%p", opt.DedupStore, want)
+	}
+}
@@ -25,6 +25,8 @@ import (
 
 	"go.uber.org/zap"
 	"knative.dev/eventing/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
 )
 
 const (
@@ -36,6 +38,48 @@ type SyncPool interface {
 	SyncOnce(ctx context.Context) error
 }
 
+// drainer is implemented by the handler caches of the pools.
+type drainer interface {
+	Drain(timeout time.Duration)
+}
+
+// drainAll drains the handlers of the given pools concurrently and waits for
+// all of them to finish draining.
+// deliveryTokenInUse returns a function telling whether the targets still deliver to
+// an audience with a type of token, to prune the others.
+func deliveryTokenInUse(targets config.ReadonlyTargets) func(config.DeliveryAuth, string) bool {
+	type use struct {
+		auth     config.DeliveryAuth
+		audience string
+	}
+	inUse := make(map[use]bool)
+	targets.RangeAllTargets(func(t *config.Target) bool {
+		inUse[use{auth: t.DeliveryAuth, audience: t.Address}] = true
+		if t.DeadLetterAddress != "" {
+			inUse[use{auth: t.DeliveryAuth, audience: t.DeadLetterAddress}] = true
+		}
+		return true
+	})
+	return func(auth config.DeliveryAuth, audience string) bool {
+		return inUse[use{auth: auth, audience: audience}]
+	}
+}
+
+func drainAll(timeout time.Duration, pools ...*sync.Map) {
+	var wg sync.WaitGroup
+	for _, pool := range pools {
+		pool.Range(func(key, value interface{}) bool {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value.(drainer).Drain(timeout)
+			}()
+			return true
+		})
+	}
+	wg.Wait()
+}
+
 type healthChecker struct {
 	mux              sync.RWMutex
 	lastReportTime   time.Time
@@ -25,6 +25,9 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
 )
 
 func TestSyncPool(t *testing.T) {
@@ -138,3 +141,32 @@ func GetFreePort() (int, error) {
 	defer l.Close()
 	return l.Addr().(*net.TCPAddr).Port, nil
 }
+
+func TestDeliveryTokenInUse(t *testing.T) {
+	targets := memory.NewEmptyTargets()
+	targets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(&config.Target{
+			Name:              "target",
+			Address:           "https://target.example.com",
+			DeadLetterAddress: "https://dls.example.com",
+			DeliveryAuth:      config.DeliveryAuth_GOOGLE_ID_TOKEN,
+		})
+	})
+	inUse := deliveryTokenInUse(targets)
+
+	cases := []struct {
+		auth     config.DeliveryAuth
+		audience string
+		want     bool
+	}{
+		{config.DeliveryAuth_GOOGLE_ID_TOKEN, "https://target.example.com", true},
+		{config.DeliveryAuth_GOOGLE_ID_TOKEN, "https://dls.example.com", true},
+		{config.DeliveryAuth_KUBERNETES_TOKEN, "https://target.example.com", false},
+		{config.DeliveryAuth_GOOGLE_ID_TOKEN, "https://deleted.example.com", false},
+	}
+	for _, tc := range cases {
+		if got := inUse(tc.auth, tc.audience); got != tc.want {
+			t.Errorf("deliveryTokenInUse(%v, %q) got=%v, want=%v", tc.auth, tc.audience, got, tc.want)
+		}
+	}
+}
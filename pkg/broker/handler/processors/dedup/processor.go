@@ -0,0 +1,84 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dedup provides a processor to skip the redelivery of events to the
+// targets they were already delivered to.
+package dedup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"knative.dev/eventing/pkg/logging"
+
+	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+)
+
+// Processor is the processor to deduplicate the deliveries of events to targets.
+// Pubsub redelivers all the events of a nacked message, e.g. when the delivery
+// to only one target of the broker failed. Events are identified by their
+// source and ID within a target.
+type Processor struct {
+	processors.BaseProcessor
+
+	// Store records the delivered events. Events are never deduplicated if nil.
+	Store Store
+}
+
+var _ processors.Interface = (*Processor)(nil)
+
+// Process passes the event to the next processor unless it was already
+// delivered to the target, and records it once the next processor succeeded.
+// Errors of the store don't fail the processing: the event is delivered
+// at least once regardless.
+func (p *Processor) Process(ctx context.Context, event *event.Event) error {
+	if p.Store == nil {
+		return p.Next().Process(ctx, event)
+	}
+	tk, err := handlerctx.GetTargetKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := Key(tk, event)
+	seen, err := p.Store.Seen(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to look up the event in the dedup store", zap.String("target", tk), zap.String("eventID", event.ID()), zap.Error(err))
+	}
+	if seen {
+		logging.FromContext(ctx).Debug("event already delivered to target; skip", zap.String("target", tk), zap.String("eventID", event.ID()))
+		trace.FromContext(ctx).Annotate(nil, "event skipped: already delivered")
+		return nil
+	}
+
+	if err := p.Next().Process(ctx, event); err != nil {
+		return err
+	}
+	if err := p.Store.Mark(ctx, key); err != nil {
+		logging.FromContext(ctx).Warn("failed to record the event in the dedup store", zap.String("target", tk), zap.String("eventID", event.ID()), zap.Error(err))
+	}
+	return nil
+}
+
+// Key returns the key identifying the delivery of the event to the target.
+func Key(targetKey string, event *event.Event) string {
+	// Quote the parts as the source and ID may contain any character.
+	return fmt.Sprintf("%q/%q/%q", targetKey, event.Source(), event.ID())
+}
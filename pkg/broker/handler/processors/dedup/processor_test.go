@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+)
+
+func TestInvalidContext(t *testing.T) {
+	p := &Processor{Store: NewLRUStore(10, time.Minute)}
+	e := event.New()
+	err := p.Process(context.Background(), &e)
+	if err != handlerctx.ErrTargetKeyNotPresent {
+		t.Errorf("Process error got=%v, want=%v", err, handlerctx.ErrTargetKeyNotPresent)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Seen(context.Context, string) (bool, error) {
+	return false, errors.New("seen error")
+}
+func (failingStore) Mark(context.Context, string) error { return errors.New("mark error") }
+
+func TestDedupProcessor(t *testing.T) {
+	newEvent := func(source, id string) *event.Event {
+		e := event.New()
+		e.SetSource(source)
+		e.SetID(id)
+		e.SetType("type")
+		return &e
+	}
+
+	cases := []struct {
+		name      string
+		store     Store
+		nextErr   bool
+		delivered []struct{ target, source, id string }
+		target    string
+		e         *event.Event
+		wantNext  bool
+		wantErr   bool
+	}{{
+		name:     "no store",
+		target:   "ns/target",
+		e:        newEvent("source", "id"),
+		wantNext: true,
+	}, {
+		name:     "new event",
+		store:    NewLRUStore(10, time.Minute),
+		target:   "ns/target",
+		e:        newEvent("source", "id"),
+		wantNext: true,
+	}, {
+		name:      "event already delivered to target",
+		store:     NewLRUStore(10, time.Minute),
+		delivered: []struct{ target, source, id string }{{"ns/target", "source", "id"}},
+		target:    "ns/target",
+		e:         newEvent("source", "id"),
+	}, {
+		name:      "event delivered to another target",
+		store:     NewLRUStore(10, time.Minute),
+		delivered: []struct{ target, source, id string }{{"ns/other", "source", "id"}},
+		target:    "ns/target",
+		e:         newEvent("source", "id"),
+		wantNext:  true,
+	}, {
+		name:      "event with the same id from another source",
+		store:     NewLRUStore(10, time.Minute),
+		delivered: []struct{ target, source, id string }{{"ns/target", "other", "id"}},
+		target:    "ns/target",
+		e:         newEvent("source", "id"),
+		wantNext:  true,
+	}, {
+		name:     "store errors don't fail delivery",
+		store:    failingStore{},
+		target:   "ns/target",
+		e:        newEvent("source", "id"),
+		wantNext: true,
+	}, {
+		name:     "failed delivery",
+		store:    NewLRUStore(10, time.Minute),
+		nextErr:  true,
+		target:   "ns/target",
+		e:        newEvent("source", "id"),
+		wantNext: true,
+		wantErr:  true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, d := range tc.delivered {
+				tc.store.Mark(ctx, Key(d.target, newEvent(d.source, d.id)))
+			}
+
+			ch := make(chan *event.Event, 1)
+			next := &processors.FakeProcessor{PrevEventsCh: ch, AlwaysErr: tc.nextErr}
+			p := &Processor{Store: tc.store}
+			p.WithNext(next)
+
+			err := p.Process(handlerctx.WithTargetKey(ctx, tc.target), tc.e)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Process error got=%v, want error=%v", err, tc.wantErr)
+			}
+			if gotNext := len(ch) > 0; gotNext != tc.wantNext {
+				t.Errorf("event passed to next processor got=%v, want=%v", gotNext, tc.wantNext)
+			}
+
+			if s, ok := tc.store.(*LRUStore); ok {
+				seen, _ := s.Seen(ctx, Key(tc.target, tc.e))
+				if wantSeen := !tc.wantErr; seen != wantSeen {
+					t.Errorf("event recorded as delivered got=%v, want=%v", seen, wantSeen)
+				}
+			}
+		})
+	}
+}
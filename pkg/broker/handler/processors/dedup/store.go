@@ -0,0 +1,109 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store records the keys of the events successfully delivered to a target.
+// The in-memory LRUStore only deduplicates the deliveries of a single pod; an
+// implementation backed by an external store deduplicates across pods.
+type Store interface {
+	// Seen returns true if the key was marked and has not expired since.
+	Seen(ctx context.Context, key string) (bool, error)
+	// Mark records the key as delivered.
+	Mark(ctx context.Context, key string) error
+}
+
+type entry struct {
+	key     string
+	expires time.Time
+}
+
+// LRUStore is an in-memory Store bounded in size. Keys expire after the TTL and
+// the least recently marked keys are evicted once the capacity is reached.
+type LRUStore struct {
+	capacity int
+	ttl      time.Duration
+
+	mu sync.Mutex
+	// ll holds the entries from the most to the least recently marked.
+	ll      *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+var _ Store = (*LRUStore)(nil)
+
+// NewLRUStore creates an LRUStore holding up to capacity keys for the given TTL.
+func NewLRUStore(capacity int, ttl time.Duration) *LRUStore {
+	return &LRUStore{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Seen implements Store.
+func (s *LRUStore) Seen(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return false, nil
+	}
+	if s.now().After(el.Value.(*entry).expires) {
+		s.remove(el)
+		return false, nil
+	}
+	return true, nil
+}
+
+// Mark implements Store.
+func (s *LRUStore) Mark(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires := s.now().Add(s.ttl)
+	if el, ok := s.entries[key]; ok {
+		el.Value.(*entry).expires = expires
+		s.ll.MoveToFront(el)
+		return nil
+	}
+	s.entries[key] = s.ll.PushFront(&entry{key: key, expires: expires})
+	for s.ll.Len() > s.capacity {
+		s.remove(s.ll.Back())
+	}
+	return nil
+}
+
+// Len returns the number of keys held, including the expired ones not evicted yet.
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+func (s *LRUStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.entries, el.Value.(*entry).key)
+}
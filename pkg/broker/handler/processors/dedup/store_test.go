@@ -0,0 +1,63 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewLRUStore(2, time.Minute)
+	s.now = func() time.Time { return now }
+
+	assertSeen := func(key string, want bool) {
+		t.Helper()
+		got, err := s.Seen(ctx, key)
+		if err != nil {
+			t.Fatalf("Seen(%q) got unexpected error: %v", key, err)
+		}
+		if got != want {
+			t.Errorf("Seen(%q) got=%v, want=%v", key, got, want)
+		}
+	}
+
+	assertSeen("a", false)
+	s.Mark(ctx, "a")
+	s.Mark(ctx, "b")
+	assertSeen("a", true)
+	assertSeen("b", true)
+
+	// Marking a key again makes it the most recent one, so "b" gets evicted.
+	s.Mark(ctx, "a")
+	s.Mark(ctx, "c")
+	if got := s.Len(); got != 2 {
+		t.Errorf("Len() got=%d, want=%d", got, 2)
+	}
+	assertSeen("a", true)
+	assertSeen("b", false)
+	assertSeen("c", true)
+
+	now = now.Add(2 * time.Minute)
+	assertSeen("a", false)
+	if got := s.Len(); got != 1 {
+		t.Errorf("Len() got=%d, want=%d", got, 1)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deliver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// DefaultOrderedRetryRelease is the default period after which an ordering key of a
+// target is released if none of its events were sent to the retry topic.
+const DefaultOrderedRetryRelease = 10 * time.Minute
+
+type orderedRetryKey struct {
+	target      string
+	orderingKey string
+}
+
+// OrderedRetry publishes the failed deliveries of ordered brokers to the retry topics of
+// their targets with the ordering key of the event. Each target then retries its events
+// in order, without holding up the deliveries to the other targets of the broker.
+//
+// Once an event of a target is sent to the retry topic, the later events of the target
+// with the same ordering key are sent to the retry topic as well, so that they don't
+// overtake it. The ordering key is released once none of its events were sent to the
+// retry topic for the release period. The ordering keys are tracked per pod, which relies
+// on Pub/Sub delivering the events of an ordering key to the same subscriber.
+type OrderedRetry struct {
+	client  *pubsub.Client
+	release time.Duration
+
+	mut     sync.Mutex
+	topics  map[string]*pubsub.Topic
+	pending map[orderedRetryKey]time.Time
+}
+
+// NewOrderedRetry creates a new OrderedRetry publishing with the pubsub client. If release
+// is zero, DefaultOrderedRetryRelease is used.
+func NewOrderedRetry(client *pubsub.Client, release time.Duration) *OrderedRetry {
+	if release == 0 {
+		release = DefaultOrderedRetryRelease
+	}
+	return &OrderedRetry{
+		client:  client,
+		release: release,
+		topics:  make(map[string]*pubsub.Topic),
+		pending: make(map[orderedRetryKey]time.Time),
+	}
+}
+
+// Pending returns true if an earlier event of the target with the ordering key was
+// sent to the retry topic and the ordering key wasn't released since.
+func (r *OrderedRetry) Pending(target *config.Target, orderingKey string) bool {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	k := orderedRetryKey{target: target.Key(), orderingKey: orderingKey}
+	last, ok := r.pending[k]
+	if !ok {
+		return false
+	}
+	if time.Since(last) >= r.release {
+		delete(r.pending, k)
+		return false
+	}
+	return true
+}
+
+// Publish publishes the event to the retry topic of the target with the ordering key.
+func (r *OrderedRetry) Publish(ctx context.Context, target *config.Target, orderingKey string, e *event.Event) error {
+	msg := &pubsub.Message{OrderingKey: orderingKey}
+	if err := cepubsub.WritePubSubMessage(ctx, binding.ToMessage(e), msg); err != nil {
+		return err
+	}
+	topic := r.topic(target)
+	if _, err := topic.Publish(ctx, msg).Get(ctx); err != nil {
+		// A failed publish pauses publishing for the ordering key. Resume it so that
+		// the event can be retried.
+		topic.ResumePublish(orderingKey)
+		return err
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.pending[orderedRetryKey{target: target.Key(), orderingKey: orderingKey}] = time.Now()
+	return nil
+}
+
+// Prune stops the retry topics and forgets the ordering keys of the targets for which
+// keep returns false, and releases the expired ordering keys.
+func (r *OrderedRetry) Prune(keep func(targetKey string) bool) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	for key, topic := range r.topics {
+		if !keep(key) {
+			topic.Stop()
+			delete(r.topics, key)
+		}
+	}
+	for k, last := range r.pending {
+		if !keep(k.target) || time.Since(last) >= r.release {
+			delete(r.pending, k)
+		}
+	}
+}
+
+// Stop stops all retry topics.
+func (r *OrderedRetry) Stop() {
+	r.Prune(func(string) bool { return false })
+}
+
+func (r *OrderedRetry) topic(target *config.Target) *pubsub.Topic {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if topic, ok := r.topics[target.Key()]; ok {
+		if topic.ID() == target.RetryQueue.Topic {
+			return topic
+		}
+		topic.Stop()
+	}
+	topic := r.client.Topic(target.RetryQueue.Topic)
+	topic.EnableMessageOrdering = true
+	r.topics[target.Key()] = topic
+	return topic
+}
@@ -32,14 +32,54 @@ import (
 	"go.uber.org/zap"
 	"knative.dev/eventing/pkg/logging"
 
+	"github.com/google/knative-gcp/pkg/broker/cesql"
+	"github.com/google/knative-gcp/pkg/broker/circuitbreaker"
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/deliveryauth"
 	"github.com/google/knative-gcp/pkg/broker/eventutil"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/ratelimit"
 	"github.com/google/knative-gcp/pkg/metrics"
 )
 
-const defaultEventHopsLimit int32 = 255
+const (
+	defaultEventHopsLimit int32 = 255
+
+	// Extensions attached to events sent to the dead letter sink to
+	// record why the delivery failed.
+	extensionErrorDest = "knativeerrordest"
+	extensionErrorCode = "knativeerrorcode"
+	extensionErrorData = "knativeerrordata"
+
+	// extensionReplyFrom is attached to replies to record the key of the
+	// target that replied, in the form of namespace/broker/trigger.
+	extensionReplyFrom = "knativereplyfrom"
+
+	// maxErrorDataLength caps the size of the knativeerrordata extension.
+	maxErrorDataLength = 1024
+)
+
+var (
+	// errRateLimited is returned when the delivery would exceed the target's rate limit
+	// before the delivery times out.
+	errRateLimited = errors.New("target rate limit exceeded")
+
+	// errTooManyInFlight is returned when the target has the max number of deliveries in flight.
+	errTooManyInFlight = errors.New("too many in-flight deliveries to target")
+
+	// errCircuitOpen is returned when the circuit breaker of the target is open.
+	errCircuitOpen = errors.New("target circuit breaker is open")
+)
+
+// deliveryError is returned when the target responds with a non-2xx status code.
+type deliveryError struct {
+	statusCode int
+}
+
+func (e *deliveryError) Error() string {
+	return fmt.Sprintf("event delivery failed: HTTP status code %d", e.statusCode)
+}
 
 // Processor delivers events based on the broker/target in the context.
 type Processor struct {
@@ -65,6 +105,26 @@ type Processor struct {
 
 	// StatsReporter is used to report delivery metrics.
 	StatsReporter *metrics.DeliveryReporter
+
+	// RateLimiters limit the delivery rate per target.
+	// If nil, target rate limits are not enforced.
+	RateLimiters *ratelimit.Limiters
+
+	// Tokens provides the tokens to authenticate deliveries to targets
+	// that require them. If nil, such deliveries fail.
+	Tokens *deliveryauth.Tokens
+
+	// InFlight limits the concurrent deliveries per target.
+	// If nil, target in-flight limits are not enforced.
+	InFlight *ratelimit.InFlight
+
+	// CircuitBreakers stop deliveries to failing targets.
+	// If nil, target circuit breakers are not enforced.
+	CircuitBreakers *circuitbreaker.Breakers
+
+	// OrderedRetry sends the failed deliveries of ordered brokers to the retry
+	// topics of their targets in order. If nil, they are nacked instead.
+	OrderedRetry *OrderedRetry
 }
 
 var _ processors.Interface = (*Processor)(nil)
@@ -100,6 +160,25 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 		return nil
 	}
 
+	// Events failing their transformation would fail the same way if retried, so
+	// they go to the dead letter sink right away. Without one, they fail like a
+	// delivery: the fanout enqueues them for retry so the other targets still get
+	// them, and the retry queue nacks them until Pub/Sub dead letters them.
+	if err := handlerctx.GetTransformationError(ctx); err != nil {
+		if target.DeadLetterAddress == "" {
+			if !p.RetryOnFailure {
+				return err
+			}
+			logging.FromContext(ctx).Warn("event transformation failed", zap.String("target", tk), zap.Error(err))
+			return p.sendToRetryTopic(ctx, target, event)
+		}
+		trace.FromContext(ctx).Annotate(
+			[]trace.Attribute{trace.StringAttribute("error_message", err.Error())},
+			"sending to dead letter sink",
+		)
+		return p.sendToDeadLetterSink(ctx, target, event, err)
+	}
+
 	// Hops is a broker local counter so remove any hops value before forwarding.
 	// Do not modify the original event as we need to send the original
 	// event to retry queue on failure.
@@ -111,6 +190,22 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 
 	p.StatsReporter.FinishEventProcessing(ctx)
 
+	// The retried event is transformed again for the target.
+	retryEvent := event
+	if original, err := handlerctx.GetOriginalEvent(ctx); err == nil {
+		retryEvent = original
+	}
+	var orderingKey string
+	if p.RetryOnFailure && p.OrderedRetry != nil {
+		orderingKey = eventutil.OrderingKey(retryEvent, broker.OrderingKeyAttribute)
+	}
+	if orderingKey != "" && p.OrderedRetry.Pending(target, orderingKey) {
+		// An earlier event with the same ordering key is being retried, so this
+		// event must wait for it in the retry topic.
+		trace.FromContext(ctx).Annotate(nil, "enqueueing for ordered retry")
+		return p.sendToOrderedRetryTopic(ctx, target, orderingKey, retryEvent)
+	}
+
 	dctx := ctx
 	if p.DeliverTimeout > 0 {
 		var cancel context.CancelFunc
@@ -120,16 +215,35 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 
 	// Forward the event copy that has hops removed.
 	if err := p.deliver(dctx, target, broker, (*binding.EventMessage)(&copy), hops); err != nil {
+		// Events that were never sent to the target don't use up the delivery attempts.
+		if !notSent(err) && p.attemptsExhausted(ctx, target) {
+			logging.FromContext(ctx).Warn("target delivery attempts exhausted", zap.String("target", tk), zap.Error(err))
+			trace.FromContext(ctx).Annotate(
+				[]trace.Attribute{trace.StringAttribute("error_message", err.Error())},
+				"sending to dead letter sink",
+			)
+			return p.sendToDeadLetterSink(ctx, target, event, err)
+		}
 		if !p.RetryOnFailure {
 			return err
 		}
+		if broker.OrderingKeyAttribute != "" && p.OrderedRetry == nil {
+			// Sending the event to the retry topic would let later events with the
+			// same ordering key overtake it. Return the error so the message is nacked
+			// and redelivered in order from the decouple subscription.
+			logging.FromContext(ctx).Warn("target delivery failed; nacking ordered event", zap.String("target", tk), zap.Error(err))
+			return err
+		}
 
 		logging.FromContext(ctx).Warn("target delivery failed", zap.String("target", tk), zap.Error(err))
 		trace.FromContext(ctx).Annotate(
 			[]trace.Attribute{trace.StringAttribute("error_message", err.Error())},
 			"enqueueing for retry",
 		)
-		return p.sendToRetryTopic(ctx, target, event)
+		if orderingKey != "" {
+			return p.sendToOrderedRetryTopic(ctx, target, orderingKey, retryEvent)
+		}
+		return p.sendToRetryTopic(ctx, target, retryEvent)
 	}
 	// For post-delivery processing.
 	return p.Next().Process(ctx, event)
@@ -137,8 +251,35 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 
 // deliver delivers msg to target and sends the target's reply to the broker ingress.
 func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *config.Broker, msg binding.Message, hops int32) error {
+	release, ok := p.InFlight.Acquire(target.Key(), target.MaxInFlight)
+	if !ok {
+		return errTooManyInFlight
+	}
+	defer release()
+	if !p.CircuitBreakers.Allow(target.Key(), target.CircuitBreaker) {
+		return errCircuitOpen
+	}
+
+	if lim := p.RateLimiters.Get(target.Key(), target.RateLimit); lim != nil {
+		if p.RetryOnFailure {
+			// Don't hold up the fanout to the other targets. The event is sent
+			// to the retry queue instead, which delivers it at the target's rate.
+			if !lim.Allow() {
+				return errRateLimited
+			}
+		} else if err := lim.Wait(ctx); err != nil {
+			return fmt.Errorf("%w: %v", errRateLimited, err)
+		}
+	}
+
+	token, err := p.targetToken(ctx, target, target.Address)
+	if err != nil {
+		return err
+	}
+
 	startTime := time.Now()
-	resp, err := p.sendMsg(ctx, target.Address, msg)
+	resp, err := p.sendMsg(ctx, target.Address, token, msg)
+	p.recordDelivery(ctx, target, resp, err)
 	if err != nil {
 		return err
 	}
@@ -150,7 +291,7 @@ func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *
 
 	p.StatsReporter.ReportEventDispatchTime(ctx, time.Since(startTime), resp.StatusCode)
 	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("event delivery failed: HTTP status code %d", resp.StatusCode)
+		return &deliveryError{statusCode: resp.StatusCode}
 	}
 
 	respMsg := cehttp.NewMessageFromHttpResponse(resp)
@@ -165,6 +306,7 @@ func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *
 		n, _ := respMsg.BodyReader.Read(body)
 		respMsg.BodyReader.Close()
 		if n != 0 {
+			p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultInvalid, 0)
 			return errors.New("Received a malformed event in reply")
 		}
 		// No reply.
@@ -177,8 +319,15 @@ func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *
 		}, "event reply received")
 	}
 
+	return p.sendReply(ctx, target, broker, respMsg, hops)
+}
+
+// sendReply validates the reply of the target and sends it to the reply address of the target,
+// or to the broker if the target has none. Invalid replies, and replies of targets whose
+// replies are disabled, are dropped.
+func (p *Processor) sendReply(ctx context.Context, target *config.Target, broker *config.Broker, respMsg binding.Message, hops int32) error {
+	reply, err := binding.ToEvent(ctx, respMsg)
 	if hops <= 0 {
-		e, err := binding.ToEvent(ctx, respMsg)
 		if err != nil {
 			logging.FromContext(ctx).Error("failed to convert response message to event",
 				zap.Error(err),
@@ -189,42 +338,153 @@ func (p *Processor) deliver(ctx context.Context, target *config.Target, broker *
 		logging.FromContext(ctx).Warn("event has exhausted allowed hops: dropping reply",
 			zap.String("target", target.Name),
 			zap.Int32("hops", hops),
-			zap.Any("event context", e.Context),
+			zap.Any("event context", reply.Context),
 		)
 		if span := trace.FromContext(ctx); span.IsRecordingEvents() {
 			span.Annotate(
 				append(
-					ceclient.EventTraceAttributes(e),
+					ceclient.EventTraceAttributes(reply),
 					trace.Int64Attribute("remaining_hops", int64(hops)),
 				),
 				"Event reply dropped due to hop limit",
 			)
 		}
+		p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultDropped, 0)
+		return nil
+	}
+	if err == nil {
+		err = validateReply(reply, target.ReplyRequiredAttributes)
+	}
+	if err != nil {
+		// The event was delivered, so it must not be retried because of its reply.
+		// Retrying it would deliver it to the target again and likely get the same
+		// invalid reply back.
+		logging.FromContext(ctx).Warn("received an invalid event in reply: dropping reply",
+			zap.String("target", target.Key()),
+			zap.Error(err),
+		)
+		if span := trace.FromContext(ctx); span.IsRecordingEvents() {
+			span.Annotate([]trace.Attribute{
+				trace.StringAttribute("error_message", err.Error()),
+			}, "Invalid event reply dropped")
+		}
+		p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultInvalid, 0)
 		return nil
 	}
 
+	if target.RepliesDisabled {
+		// The reply destination of the target cannot be resolved, so drop the reply rather
+		// than sending it to the wrong destination.
+		logging.FromContext(ctx).Warn("replies of the target are disabled: dropping reply",
+			zap.String("target", target.Key()),
+		)
+		p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultDropped, 0)
+		return nil
+	}
+
+	// Record the lineage of the reply.
+	reply.SetExtension(extensionReplyFrom, target.Key())
+	address := broker.Address
+	if target.ReplyAddress != "" {
+		address = target.ReplyAddress
+	}
 	// Attach the previous hops for the reply.
-	replyResp, err := p.sendMsg(ctx, broker.Address, respMsg, eventutil.SetRemainingHopsTransformer(hops))
+	replyResp, err := p.sendMsg(ctx, address, "", (*binding.EventMessage)(reply), eventutil.SetRemainingHopsTransformer(hops))
 	if err != nil {
+		p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultFailed, 0)
+		return fmt.Errorf("failed to send reply: %w", err)
+	}
+	defer func() {
+		if err := replyResp.Body.Close(); err != nil {
+			logging.FromContext(ctx).Warn("failed to close reply response body", zap.Error(err))
+		}
+	}()
+	if replyResp.StatusCode/100 != 2 {
+		p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultFailed, replyResp.StatusCode)
+		return fmt.Errorf("failed to send reply: HTTP status code %d", replyResp.StatusCode)
+	}
+	p.StatsReporter.ReportReplyCount(ctx, metrics.ReplyResultSent, replyResp.StatusCode)
+	return nil
+}
+
+// validateReply returns an error if the reply is not a valid event or misses any of the
+// required attributes.
+func validateReply(reply *event.Event, required []string) error {
+	if err := reply.Validate(); err != nil {
 		return err
 	}
-	if err := replyResp.Body.Close(); err != nil {
-		logging.FromContext(ctx).Warn("failed to close reply response body", zap.Error(err))
+	if len(required) == 0 {
+		return nil
+	}
+	attrs := cesql.EventAttributes(reply)
+	for _, a := range required {
+		if _, ok := attrs[a]; !ok {
+			return fmt.Errorf("missing required attribute %q", a)
+		}
 	}
 	return nil
 }
 
-func (p *Processor) sendMsg(ctx context.Context, address string, msg binding.Message, transformers ...binding.Transformer) (*http.Response, error) {
+// recordDelivery records the result of the delivery to the target in its circuit breaker.
+// Responses with 5xx, timeouts and connection errors count as failures.
+func (p *Processor) recordDelivery(ctx context.Context, target *config.Target, resp *http.Response, err error) {
+	if errors.Is(err, context.Canceled) {
+		// The handler is stopping, which says nothing about the target.
+		return
+	}
+	failed := err != nil || resp.StatusCode/100 == 5
+	open, changed := p.CircuitBreakers.Record(target.Key(), target.CircuitBreaker, failed)
+	if !changed {
+		return
+	}
+	if open {
+		logging.FromContext(ctx).Warn("target circuit breaker opened", zap.String("target", target.Key()))
+	} else {
+		logging.FromContext(ctx).Info("target circuit breaker closed", zap.String("target", target.Key()))
+	}
+	p.StatsReporter.ReportCircuitBreakerState(ctx, open)
+}
+
+// notSent returns true if the delivery failed before the event was sent to the target.
+func notSent(err error) bool {
+	return errors.Is(err, errRateLimited) || errors.Is(err, errTooManyInFlight) || errors.Is(err, errCircuitOpen)
+}
+
+// targetToken returns the token to authenticate the deliveries of the target to the
+// address, or an empty string if the target doesn't require authentication.
+func (p *Processor) targetToken(ctx context.Context, target *config.Target, address string) (string, error) {
+	if target.DeliveryAuth == config.DeliveryAuth_NO_AUTH {
+		return "", nil
+	}
+	if p.Tokens == nil {
+		return "", fmt.Errorf("delivery auth %v is not supported", target.DeliveryAuth)
+	}
+	// The receiver verifies that the token was issued for its own address.
+	return p.Tokens.Token(ctx, target.DeliveryAuth, address)
+}
+
+// sendMsg sends msg to the address. If token is not empty, it's sent as a bearer token.
+func (p *Processor) sendMsg(ctx context.Context, address, token string, msg binding.Message, transformers ...binding.Transformer) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, nil)
 	if err != nil {
 		return nil, err
 	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	if err := cehttp.WriteRequest(ctx, msg, req, transformers...); err != nil {
 		return nil, err
 	}
 	return p.DeliverClient.Do(req)
 }
 
+func (p *Processor) sendToOrderedRetryTopic(ctx context.Context, target *config.Target, orderingKey string, event *event.Event) error {
+	if err := p.OrderedRetry.Publish(ctx, target, orderingKey, event); err != nil {
+		return fmt.Errorf("failed to send event to retry topic: %w", err)
+	}
+	return nil
+}
+
 func (p *Processor) sendToRetryTopic(ctx context.Context, target *config.Target, event *event.Event) error {
 	pctx := cecontext.WithTopic(ctx, target.RetryQueue.Topic)
 	if err := p.DeliverRetryClient.Send(pctx, *event); err != nil {
@@ -232,3 +492,55 @@ func (p *Processor) sendToRetryTopic(ctx context.Context, target *config.Target,
 	}
 	return nil
 }
+
+// attemptsExhausted returns true if the target has a dead letter sink and the
+// current delivery is the last attempt allowed by the target.
+func (p *Processor) attemptsExhausted(ctx context.Context, target *config.Target) bool {
+	if target.DeadLetterAddress == "" || target.MaxAttempts <= 0 {
+		return false
+	}
+	if p.RetryOnFailure {
+		// This is the initial delivery from the fanout.
+		return target.MaxAttempts <= 1
+	}
+	attempt, err := handlerctx.GetDeliveryAttempt(ctx)
+	if err != nil {
+		return false
+	}
+	// The initial delivery was already attempted by the fanout
+	// before the event was sent to the retry queue.
+	return int32(attempt)+1 >= target.MaxAttempts
+}
+
+func (p *Processor) sendToDeadLetterSink(ctx context.Context, target *config.Target, event *event.Event, deliveryErr error) error {
+	copy := event.Clone()
+	eventutil.DeleteRemainingHops(ctx, &copy)
+	copy.SetExtension(extensionErrorDest, target.Address)
+	var de *deliveryError
+	if errors.As(deliveryErr, &de) {
+		copy.SetExtension(extensionErrorCode, de.statusCode)
+	}
+	errData := deliveryErr.Error()
+	if len(errData) > maxErrorDataLength {
+		errData = errData[:maxErrorDataLength]
+	}
+	copy.SetExtension(extensionErrorData, errData)
+
+	token, err := p.targetToken(ctx, target, target.DeadLetterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to send event to dead letter sink: %w", err)
+	}
+	resp, err := p.sendMsg(ctx, target.DeadLetterAddress, token, (*binding.EventMessage)(&copy))
+	if err != nil {
+		return fmt.Errorf("failed to send event to dead letter sink: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logging.FromContext(ctx).Warn("failed to close dead letter sink response body", zap.Error(err))
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to send event to dead letter sink: HTTP status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -19,12 +19,14 @@ package deliver
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -42,13 +44,25 @@ import (
 	"go.uber.org/zap/zaptest"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"knative.dev/pkg/logging"
 	logtest "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
 
+	"github.com/google/knative-gcp/pkg/broker/circuitbreaker"
 	"github.com/google/knative-gcp/pkg/broker/config"
 	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/broker/deliveryauth"
 	"github.com/google/knative-gcp/pkg/broker/eventutil"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/fanout"
+	"github.com/google/knative-gcp/pkg/broker/ratelimit"
 	"github.com/google/knative-gcp/pkg/metrics"
 	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
 
@@ -94,6 +108,7 @@ func TestDeliverSuccess(t *testing.T) {
 		wantReply: func() *event.Event {
 			copy := sampleReply.Clone()
 			eventutil.UpdateRemainingHops(context.Background(), &copy, defaultEventHopsLimit)
+			copy.SetExtension(extensionReplyFrom, "ns/broker/target")
 			return &copy
 		}(),
 	}, {
@@ -200,6 +215,235 @@ func TestDeliverSuccess(t *testing.T) {
 	}
 }
 
+func TestDeliverReply(t *testing.T) {
+	sampleReply := newSampleEvent()
+	sampleReply.SetID("reply")
+
+	cases := []struct {
+		name               string
+		reply              *event.Event
+		requiredAttributes []string
+		repliesDisabled    bool
+		replyRespCode      int
+		wantErr            bool
+		wantReply          bool
+		wantTags           map[string]string
+	}{{
+		name:          "reply sent to reply address",
+		reply:         sampleReply,
+		replyRespCode: http.StatusAccepted,
+		wantReply:     true,
+		wantTags: map[string]string{
+			"reply_result":                    "sent",
+			metricskey.LabelResponseCode:      "202",
+			metricskey.LabelResponseCodeClass: "2xx",
+		},
+	}, {
+		name:               "reply missing required attribute",
+		reply:              sampleReply,
+		requiredAttributes: []string{"dataschema"},
+		wantTags: map[string]string{
+			"reply_result": "invalid",
+		},
+	}, {
+		name: "reply with required attribute",
+		reply: func() *event.Event {
+			e := sampleReply.Clone()
+			e.SetDataSchema("https://example.com/schema")
+			return &e
+		}(),
+		requiredAttributes: []string{"dataschema"},
+		replyRespCode:      http.StatusAccepted,
+		wantReply:          true,
+		wantTags: map[string]string{
+			"reply_result":                    "sent",
+			metricskey.LabelResponseCode:      "202",
+			metricskey.LabelResponseCodeClass: "2xx",
+		},
+	}, {
+		name:            "replies disabled",
+		reply:           sampleReply,
+		repliesDisabled: true,
+		wantTags: map[string]string{
+			"reply_result": "dropped",
+		},
+	}, {
+		name:          "reply rejected",
+		reply:         sampleReply,
+		replyRespCode: http.StatusInternalServerError,
+		wantErr:       true,
+		wantReply:     true,
+		wantTags: map[string]string{
+			"reply_result":                    "failed",
+			metricskey.LabelResponseCode:      "500",
+			metricskey.LabelResponseCodeClass: "5xx",
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetDeliveryMetrics()
+			ctx := logtest.TestContextWithLogger(t)
+			targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if err := cehttp.WriteResponseWriter(req.Context(), binding.ToMessage(tc.reply), http.StatusOK, w); err != nil {
+					t.Errorf("failed to write reply: %v", err)
+				}
+			}))
+			defer targetSvr.Close()
+			var gotReply *event.Event
+			replySvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				e, err := binding.ToEvent(req.Context(), cehttp.NewMessageFromHttpRequest(req))
+				if err != nil {
+					t.Errorf("reply destination received message cannot be converted to an event: %v", err)
+				}
+				gotReply = e
+				w.WriteHeader(tc.replyRespCode)
+			}))
+			defer replySvr.Close()
+			brokerSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				t.Error("broker received the reply, want it sent to the reply address")
+			}))
+			defer brokerSvr.Close()
+
+			broker := &config.Broker{Namespace: "ns", Name: "broker"}
+			target := &config.Target{
+				Namespace:               "ns",
+				Name:                    "target",
+				Broker:                  "broker",
+				Address:                 targetSvr.URL,
+				ReplyAddress:            replySvr.URL,
+				ReplyRequiredAttributes: tc.requiredAttributes,
+				RepliesDisabled:         tc.repliesDisabled,
+			}
+			testTargets := memory.NewEmptyTargets()
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.SetAddress(brokerSvr.URL)
+				bm.UpsertTargets(target)
+			})
+			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+			ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+			r, err := metrics.NewDeliveryReporter("pod", "container")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err = r.AddTags(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err = metrics.AddTargetTags(ctx, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{
+				DeliverClient: http.DefaultClient,
+				Targets:       testTargets,
+				StatsReporter: r,
+			}
+
+			if err := p.Process(ctx, newSampleEvent()); (err != nil) != tc.wantErr {
+				t.Errorf("Process got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if (gotReply != nil) != tc.wantReply {
+				t.Fatalf("reply destination got reply=%v, want reply=%v", gotReply, tc.wantReply)
+			}
+			if gotReply != nil {
+				if got := gotReply.Extensions()[extensionReplyFrom]; got != "ns/broker/target" {
+					t.Errorf("reply %s extension got=%v, want=%v", extensionReplyFrom, got, "ns/broker/target")
+				}
+			}
+			wantTags := map[string]string{
+				metricskey.LabelNamespaceName: "ns",
+				metricskey.LabelBrokerName:    "broker",
+				metricskey.LabelTriggerName:   "target",
+				metricskey.LabelFilterType:    "any",
+				metricskey.PodName:            "pod",
+				metricskey.ContainerName:      "container",
+			}
+			for k, v := range tc.wantTags {
+				wantTags[k] = v
+			}
+			metricstest.CheckCountData(t, "reply_count", wantTags, 1)
+		})
+	}
+}
+
+func TestDeliverInvalidReplyDropped(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	reply := newSampleEvent()
+	reply.SetID("reply")
+	var deliveries int32
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&deliveries, 1)
+		if err := cehttp.WriteResponseWriter(req.Context(), binding.ToMessage(reply), http.StatusOK, w); err != nil {
+			t.Errorf("failed to write reply: %v", err)
+		}
+	}))
+	defer targetSvr.Close()
+	replySvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("reply destination received the invalid reply, want it dropped")
+	}))
+	defer replySvr.Close()
+
+	srv, c, close := testPubsubClient(ctx, t, "test-project")
+	defer close()
+	if _, err := c.CreateTopic(ctx, "test-retry-topic"); err != nil {
+		t.Fatalf("failed to create test pubsub topc: %v", err)
+	}
+	ps, err := cepubsub.New(ctx, cepubsub.WithClient(c), cepubsub.WithProjectID("test-project"))
+	if err != nil {
+		t.Fatalf("failed to create pubsub protocol: %v", err)
+	}
+	deliverRetryClient, err := ceclient.New(ps)
+	if err != nil {
+		t.Fatalf("failed to create cloudevents client: %v", err)
+	}
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace:    "ns",
+		Name:         "target",
+		Broker:       "broker",
+		Address:      targetSvr.URL,
+		ReplyAddress: replySvr.URL,
+		// The reply lacks the required attribute.
+		ReplyRequiredAttributes: []string{"dataschema"},
+		RetryQueue: &config.Queue{
+			Topic: "test-retry-topic",
+		},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient:      http.DefaultClient,
+		Targets:            testTargets,
+		RetryOnFailure:     true,
+		DeliverRetryClient: deliverRetryClient,
+		StatsReporter:      r,
+	}
+
+	// The event is acked, so it's neither redelivered nor retried.
+	if err := p.Process(ctx, newSampleEvent()); err != nil {
+		t.Errorf("Process got unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&deliveries); got != 1 {
+		t.Errorf("deliveries to target got=%d, want=1", got)
+	}
+	if got := len(srv.Messages()); got != 0 {
+		t.Errorf("events sent to retry topic got=%d, want=0", got)
+	}
+}
+
 type targetWithFailureHandler struct {
 	t              *testing.T
 	delay          time.Duration
@@ -228,6 +472,7 @@ func TestDeliverFailure(t *testing.T) {
 	cases := []struct {
 		name          string
 		withRetry     bool
+		ordered       bool
 		targetHandler *targetWithFailureHandler
 		failRetry     bool
 		wantErr       bool
@@ -259,6 +504,14 @@ func TestDeliverFailure(t *testing.T) {
 		targetHandler: &targetWithFailureHandler{delay: time.Second, respCode: http.StatusOK},
 		failRetry:     true,
 		wantErr:       true,
+	}, {
+		// Without an ordered retry, ordered events are nacked instead of
+		// being sent to the retry topic.
+		name:          "ordered delivery error retry nacked",
+		targetHandler: &targetWithFailureHandler{respCode: http.StatusInternalServerError},
+		withRetry:     true,
+		ordered:       true,
+		wantErr:       true,
 	}, {
 		name: "malformed reply failure",
 		// Return 2xx but with a malformed event should be considered error.
@@ -306,6 +559,9 @@ func TestDeliverFailure(t *testing.T) {
 			testTargets := memory.NewEmptyTargets()
 			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
 				bm.UpsertTargets(target)
+				if tc.ordered {
+					bm.SetOrderingKeyAttribute("partitionkey")
+				}
 			})
 			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
 			ctx = handlerctx.WithTargetKey(ctx, target.Key())
@@ -332,6 +588,796 @@ func TestDeliverFailure(t *testing.T) {
 	}
 }
 
+func TestDeliverFailureRetriesOriginalEvent(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	targetSvr := httptest.NewServer(&targetWithFailureHandler{t: t, respCode: http.StatusInternalServerError})
+	defer targetSvr.Close()
+
+	_, c, close := testPubsubClient(ctx, t, "test-project")
+	defer close()
+	topic, err := c.CreateTopic(ctx, "test-retry-topic")
+	if err != nil {
+		t.Fatalf("failed to create test pubsub topc: %v", err)
+	}
+	sub, err := c.CreateSubscription(ctx, "test-retry-sub", pubsub.SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatalf("failed to create test pubsub subscription: %v", err)
+	}
+
+	ps, err := cepubsub.New(ctx, cepubsub.WithClient(c), cepubsub.WithProjectID("test-project"))
+	if err != nil {
+		t.Fatalf("failed to create pubsub protocol: %v", err)
+	}
+	deliverRetryClient, err := ceclient.New(ps)
+	if err != nil {
+		t.Fatalf("failed to create cloudevents client: %v", err)
+	}
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace: "ns",
+		Name:      "target",
+		Broker:    "broker",
+		Address:   targetSvr.URL,
+		RetryQueue: &config.Queue{
+			Topic: "test-retry-topic",
+		},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient:      http.DefaultClient,
+		Targets:            testTargets,
+		RetryOnFailure:     true,
+		DeliverRetryClient: deliverRetryClient,
+		DeliverTimeout:     500 * time.Millisecond,
+		StatsReporter:      r,
+	}
+
+	// The event was transformed for the target, but the retry topic
+	// must receive it as it was before.
+	origin := newSampleEvent()
+	transformed := origin.Clone()
+	transformed.SetType("transformed.type")
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+	ctx = handlerctx.WithOriginalEvent(ctx, origin)
+	if err := p.Process(ctx, &transformed); err != nil {
+		t.Fatalf("processing got unexpected error: %v", err)
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var got *event.Event
+	if err := sub.Receive(rctx, func(ctx context.Context, msg *pubsub.Message) {
+		msg.Ack()
+		got, _ = binding.ToEvent(ctx, cepubsub.NewMessage(msg))
+		cancel()
+	}); err != nil {
+		t.Fatalf("failed to receive the retried event: %v", err)
+	}
+	if got == nil {
+		t.Fatal("retried event not received")
+	}
+	if got.Type() != origin.Type() {
+		t.Errorf("retried event type got=%v, want=%v", got.Type(), origin.Type())
+	}
+}
+
+func TestDeliverOrderedFailureRetriesPerTarget(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+
+	var okCalls, failCalls int32
+	okSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&okCalls, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer okSvr.Close()
+	failSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&failCalls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failSvr.Close()
+
+	srv, c, close := testPubsubClient(ctx, t, "test-project")
+	defer close()
+	for _, id := range []string{"ok-retry-topic", "fail-retry-topic"} {
+		if _, err := c.CreateTopic(ctx, id); err != nil {
+			t.Fatalf("failed to create test pubsub topic: %v", err)
+		}
+	}
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	okTarget := &config.Target{
+		Namespace:  "ns",
+		Name:       "ok",
+		Broker:     "broker",
+		Address:    okSvr.URL,
+		RetryQueue: &config.Queue{Topic: "ok-retry-topic"},
+	}
+	failTarget := &config.Target{
+		Namespace:  "ns",
+		Name:       "fail",
+		Broker:     "broker",
+		Address:    failSvr.URL,
+		RetryQueue: &config.Queue{Topic: "fail-retry-topic"},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(okTarget, failTarget)
+		bm.SetOrderingKeyAttribute("partitionkey")
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderedRetry := NewOrderedRetry(c, time.Hour)
+	defer orderedRetry.Stop()
+	p := processors.ChainProcessors(
+		&fanout.Processor{MaxConcurrency: 2, Targets: testTargets},
+		&Processor{
+			DeliverClient:  http.DefaultClient,
+			Targets:        testTargets,
+			RetryOnFailure: true,
+			DeliverTimeout: 500 * time.Millisecond,
+			StatsReporter:  r,
+			OrderedRetry:   orderedRetry,
+		},
+	)
+
+	// The failed target must not make the broker event fail, which would
+	// redeliver it to the target that succeeded.
+	first := newSampleEvent()
+	first.SetExtension("partitionkey", "key")
+	if err := p.Process(ctx, first); err != nil {
+		t.Fatalf("processing got unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&okCalls); got != 1 {
+		t.Errorf("successful target calls got=%d, want=1", got)
+	}
+	if got := atomic.LoadInt32(&failCalls); got != 1 {
+		t.Errorf("failing target calls got=%d, want=1", got)
+	}
+
+	// A later event with the same ordering key is queued behind the retried
+	// event of the failing target, but still delivered to the other target.
+	second := newSampleEvent()
+	second.SetID("id2")
+	second.SetExtension("partitionkey", "key")
+	if err := p.Process(ctx, second); err != nil {
+		t.Fatalf("processing got unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&okCalls); got != 2 {
+		t.Errorf("successful target calls got=%d, want=2", got)
+	}
+	if got := atomic.LoadInt32(&failCalls); got != 1 {
+		t.Errorf("failing target calls got=%d, want=1", got)
+	}
+
+	var gotIDs []string
+	for _, m := range srv.Messages() {
+		if m.OrderingKey != "key" {
+			t.Errorf("retried message ordering key got=%q, want=%q", m.OrderingKey, "key")
+		}
+		e, err := binding.ToEvent(ctx, cepubsub.NewMessage(toFakePubsubMessage(m)))
+		if err != nil {
+			t.Fatalf("failed to convert retried message to an event: %v", err)
+		}
+		gotIDs = append(gotIDs, e.ID())
+	}
+	if diff := cmp.Diff([]string{"id", "id2"}, gotIDs); diff != "" {
+		t.Errorf("retried events (-want, +got) = %v", diff)
+	}
+}
+
+func TestDeliverRateLimit(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	var requests int32
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer targetSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace: "ns",
+		Name:      "target",
+		Broker:    "broker",
+		Address:   targetSvr.URL,
+		// Refill slowly enough that the burst is the only allowance in the test.
+		RateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 1},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient:  http.DefaultClient,
+		Targets:        testTargets,
+		DeliverTimeout: 500 * time.Millisecond,
+		StatsReporter:  r,
+		RateLimiters:   ratelimit.NewLimiters(),
+	}
+
+	if err := p.Process(ctx, newSampleEvent()); err != nil {
+		t.Fatalf("Process got unexpected error: %v", err)
+	}
+	if err := p.Process(ctx, newSampleEvent()); !errors.Is(err, errRateLimited) {
+		t.Errorf("Process over the rate limit got error=%v, want=%v", err, errRateLimited)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("target requests got=%d, want=1", got)
+	}
+}
+
+func TestDeliverRateLimitRetriesWithoutWaiting(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	var requests int32
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer targetSvr.Close()
+
+	srv, c, close := testPubsubClient(ctx, t, "test-project")
+	defer close()
+	if _, err := c.CreateTopic(ctx, "test-retry-topic"); err != nil {
+		t.Fatalf("failed to create test pubsub topic: %v", err)
+	}
+	ps, err := cepubsub.New(ctx, cepubsub.WithClient(c), cepubsub.WithProjectID("test-project"))
+	if err != nil {
+		t.Fatalf("failed to create pubsub protocol: %v", err)
+	}
+	deliverRetryClient, err := ceclient.New(ps)
+	if err != nil {
+		t.Fatalf("failed to create cloudevents client: %v", err)
+	}
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace:  "ns",
+		Name:       "target",
+		Broker:     "broker",
+		Address:    targetSvr.URL,
+		RetryQueue: &config.Queue{Topic: "test-retry-topic"},
+		// Refill slowly enough that the burst is the only allowance in the test.
+		RateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 1},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient:      http.DefaultClient,
+		Targets:            testTargets,
+		RetryOnFailure:     true,
+		DeliverRetryClient: deliverRetryClient,
+		DeliverTimeout:     time.Minute,
+		StatsReporter:      r,
+		RateLimiters:       ratelimit.NewLimiters(),
+	}
+
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		if err := p.Process(ctx, newSampleEvent()); err != nil {
+			t.Fatalf("Process got unexpected error: %v", err)
+		}
+		if d := time.Since(start); d > 10*time.Second {
+			t.Errorf("Process took %v, want it not to wait for the rate limit", d)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("target requests got=%d, want=1", got)
+	}
+	if got := len(srv.Messages()); got != 1 {
+		t.Errorf("retried events got=%d, want=1", got)
+	}
+}
+
+func TestDeliverInFlightLimit(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	received := make(chan struct{})
+	unblock := make(chan struct{})
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer targetSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace:   "ns",
+		Name:        "target",
+		Broker:      "broker",
+		Address:     targetSvr.URL,
+		MaxInFlight: 1,
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient:  http.DefaultClient,
+		Targets:        testTargets,
+		DeliverTimeout: 5 * time.Second,
+		StatsReporter:  r,
+		InFlight:       ratelimit.NewInFlight(),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.Process(ctx, newSampleEvent())
+	}()
+	<-received
+	if err := p.Process(ctx, newSampleEvent()); !errors.Is(err, errTooManyInFlight) {
+		t.Errorf("Process over the in-flight limit got error=%v, want=%v", err, errTooManyInFlight)
+	}
+	close(unblock)
+	if err := <-errCh; err != nil {
+		t.Fatalf("Process got unexpected error: %v", err)
+	}
+
+	// The slot is released once the delivery finishes.
+	go func() { <-received }()
+	if err := p.Process(ctx, newSampleEvent()); err != nil {
+		t.Errorf("Process after the in-flight delivery got unexpected error: %v", err)
+	}
+}
+
+func TestDeliverCircuitBreaker(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	var requests int32
+	targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer targetSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace:      "ns",
+		Name:           "target",
+		Broker:         "broker",
+		Address:        targetSvr.URL,
+		CircuitBreaker: &config.CircuitBreaker{FailureThreshold: 2, CoolDownSeconds: 60},
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err = r.AddTags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err = metrics.AddTargetTags(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	breakers := circuitbreaker.NewBreakers()
+	p := &Processor{
+		DeliverClient:   http.DefaultClient,
+		Targets:         testTargets,
+		DeliverTimeout:  500 * time.Millisecond,
+		StatsReporter:   r,
+		CircuitBreakers: breakers,
+	}
+
+	for i := 0; i < 2; i++ {
+		var de *deliveryError
+		if err := p.Process(ctx, newSampleEvent()); !errors.As(err, &de) {
+			t.Fatalf("Process got error=%v, want a delivery error", err)
+		}
+	}
+	if err := p.Process(ctx, newSampleEvent()); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("Process with an open circuit got error=%v, want=%v", err, errCircuitOpen)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("target requests got=%d, want=2", got)
+	}
+	if diff := cmp.Diff([]string{target.Key()}, breakers.OpenKeys()); diff != "" {
+		t.Errorf("open circuits (-want,+got): %v", diff)
+	}
+	metricstest.CheckLastValueData(t, "circuit_breaker_open", map[string]string{
+		metricskey.LabelNamespaceName: "ns",
+		metricskey.LabelBrokerName:    "broker",
+		metricskey.LabelTriggerName:   "target",
+		metricskey.LabelFilterType:    "any",
+		metricskey.PodName:            "pod",
+		metricskey.ContainerName:      "container",
+	}, 1)
+}
+
+func TestDeliverAuth(t *testing.T) {
+	cases := []struct {
+		name       string
+		auth       config.DeliveryAuth
+		withTokens bool
+		wantHeader string
+		wantErr    bool
+	}{{
+		name:       "no auth",
+		withTokens: true,
+	}, {
+		name:       "kubernetes token",
+		auth:       config.DeliveryAuth_KUBERNETES_TOKEN,
+		withTokens: true,
+		wantHeader: "Bearer kube-token",
+	}, {
+		name:    "tokens not configured",
+		auth:    config.DeliveryAuth_KUBERNETES_TOKEN,
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetDeliveryMetrics()
+			ctx := logtest.TestContextWithLogger(t)
+			var gotHeader string
+			targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				gotHeader = req.Header.Get("Authorization")
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer targetSvr.Close()
+
+			broker := &config.Broker{Namespace: "ns", Name: "broker"}
+			target := &config.Target{
+				Namespace:    "ns",
+				Name:         "target",
+				Broker:       "broker",
+				Address:      targetSvr.URL,
+				DeliveryAuth: tc.auth,
+			}
+			testTargets := memory.NewEmptyTargets()
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(target)
+			})
+			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+			ctx = handlerctx.WithTargetKey(ctx, target.Key())
+
+			r, err := metrics.NewDeliveryReporter("pod", "container")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{
+				DeliverClient: http.DefaultClient,
+				Targets:       testTargets,
+				StatsReporter: r,
+			}
+			if tc.withTokens {
+				client := fake.NewSimpleClientset()
+				client.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, k8sruntime.Object, error) {
+					tr := action.(clienttesting.CreateAction).GetObject().(*authv1.TokenRequest).DeepCopy()
+					if len(tr.Spec.Audiences) != 1 || tr.Spec.Audiences[0] != targetSvr.URL {
+						t.Errorf("token audiences got=%v, want=[%s]", tr.Spec.Audiences, targetSvr.URL)
+					}
+					tr.Status = authv1.TokenRequestStatus{
+						Token:               "kube-token",
+						ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+					}
+					return true, tr, nil
+				})
+				p.Tokens = deliveryauth.NewTokens(client, "ns", "broker")
+			}
+
+			err = p.Process(ctx, newSampleEvent())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Process got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if gotHeader != tc.wantHeader {
+				t.Errorf("Authorization header got=%q, want=%q", gotHeader, tc.wantHeader)
+			}
+		})
+	}
+}
+
+func TestDeliverToDeadLetterSinkAuth(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+	ctx := logtest.TestContextWithLogger(t)
+	var gotHeader string
+	dlsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotHeader = req.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer dlsSvr.Close()
+
+	broker := &config.Broker{Namespace: "ns", Name: "broker"}
+	target := &config.Target{
+		Namespace:         "ns",
+		Name:              "target",
+		Broker:            "broker",
+		Address:           "http://target.example.com",
+		DeadLetterAddress: dlsSvr.URL,
+		DeliveryAuth:      config.DeliveryAuth_KUBERNETES_TOKEN,
+	}
+	testTargets := memory.NewEmptyTargets()
+	testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+	ctx = handlerctx.WithTargetKey(ctx, target.Key())
+	// Events failing their transformation are sent straight to the dead letter sink.
+	ctx = handlerctx.WithTransformationError(ctx, errors.New("transformation failed"))
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, k8sruntime.Object, error) {
+		tr := action.(clienttesting.CreateAction).GetObject().(*authv1.TokenRequest).DeepCopy()
+		if len(tr.Spec.Audiences) != 1 || tr.Spec.Audiences[0] != dlsSvr.URL {
+			t.Errorf("token audiences got=%v, want=[%s]", tr.Spec.Audiences, dlsSvr.URL)
+		}
+		tr.Status = authv1.TokenRequestStatus{
+			Token:               "dls-token",
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+		}
+		return true, tr, nil
+	})
+	r, err := metrics.NewDeliveryReporter("pod", "container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{
+		DeliverClient: http.DefaultClient,
+		Targets:       testTargets,
+		StatsReporter: r,
+		Tokens:        deliveryauth.NewTokens(client, "ns", "broker"),
+	}
+
+	if err := p.Process(ctx, newSampleEvent()); err != nil {
+		t.Errorf("Process got unexpected error: %v", err)
+	}
+	if want := "Bearer dls-token"; gotHeader != want {
+		t.Errorf("Authorization header got=%q, want=%q", gotHeader, want)
+	}
+}
+
+func TestDeliverToDeadLetterSink(t *testing.T) {
+	cases := []struct {
+		name        string
+		withRetry   bool
+		maxAttempts int32
+		attempt     int
+		dlsRespCode int
+		wantDLS     bool
+		wantErr     bool
+	}{{
+		name:        "retry attempts not exhausted",
+		maxAttempts: 3,
+		attempt:     1,
+		dlsRespCode: http.StatusAccepted,
+		wantErr:     true,
+	}, {
+		name:        "retry attempts exhausted",
+		maxAttempts: 3,
+		attempt:     2,
+		dlsRespCode: http.StatusAccepted,
+		wantDLS:     true,
+	}, {
+		name:        "initial delivery with single attempt",
+		withRetry:   true,
+		maxAttempts: 1,
+		dlsRespCode: http.StatusAccepted,
+		wantDLS:     true,
+	}, {
+		name:        "dead letter sink failure",
+		maxAttempts: 2,
+		attempt:     1,
+		dlsRespCode: http.StatusInternalServerError,
+		wantDLS:     true,
+		wantErr:     true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetDeliveryMetrics()
+			ctx := logtest.TestContextWithLogger(t)
+			targetSvr := httptest.NewServer(&targetWithFailureHandler{t: t, respCode: http.StatusInternalServerError})
+			defer targetSvr.Close()
+
+			dlsCh := make(chan *event.Event, 1)
+			dlsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				e, err := binding.ToEvent(req.Context(), cehttp.NewMessageFromHttpRequest(req))
+				if err != nil {
+					t.Errorf("dead letter sink received message cannot be converted to an event: %v", err)
+				}
+				dlsCh <- e
+				w.WriteHeader(tc.dlsRespCode)
+			}))
+			defer dlsSvr.Close()
+
+			broker := &config.Broker{Namespace: "ns", Name: "broker"}
+			target := &config.Target{
+				Namespace:         "ns",
+				Name:              "target",
+				Broker:            "broker",
+				Address:           targetSvr.URL,
+				DeadLetterAddress: dlsSvr.URL,
+				MaxAttempts:       tc.maxAttempts,
+			}
+			testTargets := memory.NewEmptyTargets()
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(target)
+			})
+			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+			ctx = handlerctx.WithTargetKey(ctx, target.Key())
+			ctx = handlerctx.WithDeliveryAttempt(ctx, tc.attempt)
+
+			r, err := metrics.NewDeliveryReporter("pod", "container")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{
+				DeliverClient:  http.DefaultClient,
+				Targets:        testTargets,
+				RetryOnFailure: tc.withRetry,
+				StatsReporter:  r,
+			}
+
+			origin := newSampleEvent()
+			err = p.Process(ctx, origin)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("processing got error=%v, want=%v", err, tc.wantErr)
+			}
+
+			select {
+			case got := <-dlsCh:
+				if !tc.wantDLS {
+					t.Fatalf("unexpected event sent to dead letter sink: %v", got)
+				}
+				if got.ID() != origin.ID() {
+					t.Errorf("dead letter event ID got=%v, want=%v", got.ID(), origin.ID())
+				}
+				ext := got.Extensions()
+				if ext[extensionErrorDest] != targetSvr.URL {
+					t.Errorf("%s extension got=%v, want=%v", extensionErrorDest, ext[extensionErrorDest], targetSvr.URL)
+				}
+				if ext[extensionErrorCode] != "500" {
+					t.Errorf("%s extension got=%v, want=500", extensionErrorCode, ext[extensionErrorCode])
+				}
+				if _, ok := ext[extensionErrorData]; !ok {
+					t.Errorf("%s extension is missing", extensionErrorData)
+				}
+			default:
+				if tc.wantDLS {
+					t.Error("event was not sent to dead letter sink")
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverTransformationFailure(t *testing.T) {
+	cases := []struct {
+		name    string
+		withDLS bool
+		wantDLS bool
+		wantErr bool
+	}{{
+		name:    "sent to dead letter sink",
+		withDLS: true,
+		wantDLS: true,
+	}, {
+		name:    "no dead letter sink",
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetDeliveryMetrics()
+			ctx := logtest.TestContextWithLogger(t)
+			targetSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				t.Error("event failing its transformation was delivered to the target")
+			}))
+			defer targetSvr.Close()
+
+			dlsCh := make(chan *event.Event, 1)
+			dlsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				e, err := binding.ToEvent(req.Context(), cehttp.NewMessageFromHttpRequest(req))
+				if err != nil {
+					t.Errorf("dead letter sink received message cannot be converted to an event: %v", err)
+				}
+				dlsCh <- e
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer dlsSvr.Close()
+
+			broker := &config.Broker{Namespace: "ns", Name: "broker"}
+			target := &config.Target{
+				Namespace:   "ns",
+				Name:        "target",
+				Broker:      "broker",
+				Address:     targetSvr.URL,
+				MaxAttempts: 3,
+			}
+			if tc.withDLS {
+				target.DeadLetterAddress = dlsSvr.URL
+			}
+			testTargets := memory.NewEmptyTargets()
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(target)
+			})
+			ctx = handlerctx.WithBrokerKey(ctx, broker.Key())
+			ctx = handlerctx.WithTargetKey(ctx, target.Key())
+			ctx = handlerctx.WithTransformationError(ctx, errors.New("transformation failed"))
+
+			r, err := metrics.NewDeliveryReporter("pod", "container")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{
+				DeliverClient: http.DefaultClient,
+				Targets:       testTargets,
+				StatsReporter: r,
+			}
+
+			origin := newSampleEvent()
+			err = p.Process(ctx, origin)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("processing got error=%v, want=%v", err, tc.wantErr)
+			}
+
+			select {
+			case got := <-dlsCh:
+				if !tc.wantDLS {
+					t.Fatalf("unexpected event sent to dead letter sink: %v", got)
+				}
+				if got.ID() != origin.ID() {
+					t.Errorf("dead letter event ID got=%v, want=%v", got.ID(), origin.ID())
+				}
+				if got, want := got.Extensions()[extensionErrorData], "transformation failed"; got != want {
+					t.Errorf("%s extension got=%v, want=%v", extensionErrorData, got, want)
+				}
+			default:
+				if tc.wantDLS {
+					t.Error("event was not sent to dead letter sink")
+				}
+			}
+		})
+	}
+}
+
 type NoReplyHandler struct{}
 
 func (NoReplyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -68,6 +68,10 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 	go func() {
 		defer close(tc)
 		for _, target := range broker.Targets {
+			// Isolated targets get the event from their own subscription.
+			if target.IsolatedQueue != nil {
+				continue
+			}
 			tc <- target
 		}
 	}()
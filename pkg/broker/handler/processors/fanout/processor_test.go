@@ -142,6 +142,50 @@ func TestFanoutPartialFailure(t *testing.T) {
 	close(ch)
 }
 
+func TestFanoutSkipsIsolatedTargets(t *testing.T) {
+	ch := make(chan *event.Event, 4)
+	ns, broker := "ns", "broker"
+	bk := config.BrokerKey(ns, broker)
+	targets := memory.NewEmptyTargets()
+	targets.MutateBroker(ns, broker, func(bm config.BrokerMutation) {
+		bm.UpsertTargets(&config.Target{Name: "shared", Id: "shared"})
+		bm.UpsertTargets(&config.Target{
+			Name:          "isolated",
+			Id:            "isolated",
+			IsolatedQueue: &config.Queue{Topic: "decouple", Subscription: "isolated"},
+		})
+	})
+	var gotTargets []string
+
+	next := &processors.FakeProcessor{
+		PrevEventsCh: ch,
+		InterceptFunc: func(ctx context.Context, e *event.Event) *event.Event {
+			t, _ := handlerctx.GetTargetKey(ctx)
+			gotTargets = append(gotTargets, t)
+			return e
+		},
+	}
+
+	p := &Processor{MaxConcurrency: 1, Targets: targets}
+	p.WithNext(next)
+
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("type")
+
+	ctx := handlerctx.WithBrokerKey(context.Background(), bk)
+	if err := p.Process(ctx, &e); err != nil {
+		t.Errorf("unexpected error from processing: %v", err)
+	}
+	close(ch)
+
+	wantTargets := []string{config.TriggerKey(ns, broker, "shared")}
+	if diff := cmp.Diff(wantTargets, gotTargets); diff != "" {
+		t.Errorf("got target keys (-want,+got): %v", diff)
+	}
+}
+
 func newTestTargets(ns, broker string, num int) config.ReadonlyTargets {
 	targets := memory.NewEmptyTargets()
 	targets.MutateBroker(ns, broker, func(bm config.BrokerMutation) {
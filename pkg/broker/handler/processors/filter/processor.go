@@ -27,6 +27,7 @@ import (
 	"knative.dev/eventing/pkg/logging"
 	kntracing "knative.dev/eventing/pkg/tracing"
 
+	"github.com/google/knative-gcp/pkg/broker/cesql"
 	"github.com/google/knative-gcp/pkg/broker/config"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
@@ -64,6 +65,15 @@ func (p *Processor) Process(ctx context.Context, event *event.Event) error {
 	ctx, span := startSpan(ctx, trigger, event)
 	defer span.End()
 
+	// Advanced filters take precedence over the filter attributes.
+	if len(target.Filters) > 0 {
+		if p.passCompiledFilter(ctx, tk, event) {
+			return p.Next().Process(ctx, event)
+		}
+		logging.FromContext(ctx).Debug("event does not pass filters for target", zap.Any("target", target))
+		return nil
+	}
+
 	if target.FilterAttributes == nil {
 		return p.Next().Process(ctx, event)
 	}
@@ -93,6 +103,26 @@ func startSpan(ctx context.Context, trigger types.NamespacedName, event *event.E
 	return ctx, span
 }
 
+func (p *Processor) passCompiledFilter(ctx context.Context, targetKey string, event *event.Event) bool {
+	f, ok := p.Targets.GetTargetFilter(targetKey)
+	if !ok {
+		// The filters are compiled with the config, so this can only happen
+		// if the target was updated concurrently.
+		logging.FromContext(ctx).Warn("compiled filter not found for target", zap.String("target", targetKey))
+		trace.FromContext(ctx).Annotate(nil, "event dropped: compiled filter not found")
+		return false
+	}
+	pass, reason := f.Match(cesql.EventAttributes(event))
+	if !pass {
+		logging.FromContext(ctx).Debug("Event rejected by filter", zap.String("reason", reason))
+		trace.FromContext(ctx).Annotate(
+			[]trace.Attribute{trace.StringAttribute("filter.reason", reason)},
+			"event rejected by filter",
+		)
+	}
+	return pass
+}
+
 func (p *Processor) passFilter(ctx context.Context, attrs map[string]string, event *event.Event) bool {
 	// Set standard context attributes. The attributes available may not be
 	// exactly the same as the attributes defined in the current version of the
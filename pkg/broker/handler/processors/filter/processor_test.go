@@ -311,6 +311,87 @@ func TestFilterProcessor(t *testing.T) {
 	}
 }
 
+func TestFilterProcessorWithFilters(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("/orders/eu")
+	e.SetType("com.example.order.created")
+	e.SetExtension("priority", 5)
+
+	cases := []struct {
+		name       string
+		filters    []*config.Filter
+		attributes map[string]string
+		shouldPass bool
+	}{{
+		name: "prefix pass",
+		filters: []*config.Filter{
+			{Dialect: &config.Filter_Prefix{Prefix: &config.AttributesFilter{Attributes: map[string]string{"type": "com.example."}}}},
+		},
+		shouldPass: true,
+	}, {
+		name: "suffix not pass",
+		filters: []*config.Filter{
+			{Dialect: &config.Filter_Suffix{Suffix: &config.AttributesFilter{Attributes: map[string]string{"source": "/us"}}}},
+		},
+		shouldPass: false,
+	}, {
+		name: "cesql pass",
+		filters: []*config.Filter{
+			{Dialect: &config.Filter_Cesql{Cesql: "priority > 3 AND source LIKE '/orders/%'"}},
+		},
+		shouldPass: true,
+	}, {
+		name: "not not pass",
+		filters: []*config.Filter{
+			{Dialect: &config.Filter_Not{Not: &config.Filter{Dialect: &config.Filter_Cesql{Cesql: "priority > 3"}}}},
+		},
+		shouldPass: false,
+	}, {
+		name: "filters take precedence over attributes",
+		filters: []*config.Filter{
+			{Dialect: &config.Filter_Exact{Exact: &config.AttributesFilter{Attributes: map[string]string{"id": "id"}}}},
+		},
+		attributes: map[string]string{"type": "other"},
+		shouldPass: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTarget := &config.Target{
+				Name:             "target",
+				Broker:           "broker",
+				Namespace:        "ns",
+				FilterAttributes: tc.attributes,
+				Filters:          tc.filters,
+			}
+			testTargets := memory.NewEmptyTargets()
+			testTargets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(testTarget)
+			})
+			ctx := handlerctx.WithTargetKey(context.Background(), testTarget.Key())
+
+			next := &processors.FakeProcessor{}
+			p := &Processor{Targets: testTargets}
+			p.WithNext(next)
+			ch := make(chan *event.Event, 1)
+			next.PrevEventsCh = ch
+
+			if err := p.Process(ctx, &e); err != nil {
+				t.Errorf("unexpected error from processing: %v", err)
+			}
+			close(ch)
+			gotEvent := <-ch
+			if tc.shouldPass && gotEvent == nil {
+				t.Error("event did not pass filters")
+			}
+			if !tc.shouldPass && gotEvent != nil {
+				t.Errorf("unexpected event %v passed filters", gotEvent)
+			}
+		})
+	}
+}
+
 func newTestTargets(filter map[string]string) (context.Context, config.Targets) {
 	testTarget := &config.Target{
 		Name:             "target",
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"knative.dev/eventing/pkg/logging"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+)
+
+// Processor is the processor to transform events based on trigger transformations.
+type Processor struct {
+	processors.BaseProcessor
+
+	// Targets is the targets from config.
+	Targets config.ReadonlyTargets
+}
+
+var _ processors.Interface = (*Processor)(nil)
+
+// Process passes a transformed copy of the event to the next processor, or the
+// event itself if the target has no transformations. Events failing a
+// transformation are passed on untransformed with the error in the context, so
+// the delivery sends them to the dead letter sink of the target or fails them.
+func (p *Processor) Process(ctx context.Context, event *event.Event) error {
+	tk, err := handlerctx.GetTargetKey(ctx)
+	if err != nil {
+		return err
+	}
+	op, ok := p.Targets.GetTargetTransformation(tk)
+	if !ok {
+		return p.Next().Process(ctx, event)
+	}
+
+	// The event is shared by the targets of the broker.
+	transformed := event.Clone()
+	if err := op.Apply(&transformed); err != nil {
+		return p.transformationFailed(ctx, tk, event, fmt.Errorf("failed to transform event: %w", err))
+	}
+	if err := transformed.Validate(); err != nil {
+		return p.transformationFailed(ctx, tk, event, fmt.Errorf("transformed event is invalid: %w", err))
+	}
+	return p.Next().Process(handlerctx.WithOriginalEvent(ctx, event), &transformed)
+}
+
+func (p *Processor) transformationFailed(ctx context.Context, tk string, event *event.Event, err error) error {
+	logging.FromContext(ctx).Error("event transformation failed", zap.String("target", tk), zap.String("eventID", event.ID()), zap.Error(err))
+	trace.FromContext(ctx).Annotate(
+		[]trace.Attribute{trace.StringAttribute("error_message", err.Error())},
+		"event transformation failed",
+	)
+	return p.Next().Process(handlerctx.WithTransformationError(ctx, err), event)
+}
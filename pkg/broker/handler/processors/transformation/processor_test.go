@@ -0,0 +1,165 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+)
+
+func TestInvalidContext(t *testing.T) {
+	p := &Processor{Targets: memory.NewEmptyTargets()}
+	e := event.New()
+	err := p.Process(context.Background(), &e)
+	if err != handlerctx.ErrTargetKeyNotPresent {
+		t.Errorf("Process error got=%v, want=%v", err, handlerctx.ErrTargetKeyNotPresent)
+	}
+}
+
+func set(values map[string]string) *config.Transformation {
+	return &config.Transformation{Operation: &config.Transformation_Set{Set: &config.AttributeValues{Values: values}}}
+}
+
+func TestTransformationProcessor(t *testing.T) {
+	newEvent := func() *event.Event {
+		e := event.New()
+		e.SetID("id")
+		e.SetSource("source")
+		e.SetType("type")
+		return &e
+	}
+
+	cases := []struct {
+		name            string
+		transformations []*config.Transformation
+		want            func() *event.Event
+		wantErr         bool
+	}{{
+		name: "no transformations",
+		want: newEvent,
+	}, {
+		name:            "transformed event",
+		transformations: []*config.Transformation{set(map[string]string{"type": "new.type", "ext": "value"})},
+		want: func() *event.Event {
+			e := newEvent()
+			e.SetType("new.type")
+			e.SetExtension("ext", "value")
+			return e
+		},
+	}, {
+		name: "failed transformation",
+		transformations: []*config.Transformation{{
+			Operation: &config.Transformation_Extract{Extract: &config.AttributeValues{Values: map[string]string{"ext": "{.id}"}}},
+		}},
+		want:    newEvent,
+		wantErr: true,
+	}, {
+		name:            "invalid transformed event",
+		transformations: []*config.Transformation{set(map[string]string{"type": " "})},
+		want:            newEvent,
+		wantErr:         true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := &config.Target{
+				Name:            "target",
+				Broker:          "broker",
+				Namespace:       "ns",
+				Transformations: tc.transformations,
+			}
+			targets := memory.NewEmptyTargets()
+			targets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+				bm.UpsertTargets(target)
+			})
+			ctx := handlerctx.WithTargetKey(context.Background(), target.Key())
+
+			next := &recordContext{}
+			p := &Processor{Targets: targets}
+			p.WithNext(next)
+
+			e := newEvent()
+			if err := p.Process(ctx, e); err != nil {
+				t.Fatalf("Process got unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(newEvent(), e); diff != "" {
+				t.Errorf("original event was modified (-want,+got): %v", diff)
+			}
+
+			if next.event == nil {
+				t.Fatal("event was not passed to the next processor")
+			}
+			if diff := cmp.Diff(tc.want(), next.event); diff != "" {
+				t.Errorf("processed event (-want,+got): %v", diff)
+			}
+			if (next.transformationErr != nil) != tc.wantErr {
+				t.Errorf("transformation error in context got=%v, want error=%v", next.transformationErr, tc.wantErr)
+			}
+		})
+	}
+}
+
+// recordContext records the event and the transformation context it was passed.
+type recordContext struct {
+	processors.BaseProcessor
+	event             *event.Event
+	original          *event.Event
+	transformationErr error
+}
+
+func (p *recordContext) Process(ctx context.Context, e *event.Event) error {
+	p.event = e
+	p.original, _ = handlerctx.GetOriginalEvent(ctx)
+	p.transformationErr = handlerctx.GetTransformationError(ctx)
+	return nil
+}
+
+func TestOriginalEventInContext(t *testing.T) {
+	target := &config.Target{
+		Name:            "target",
+		Broker:          "broker",
+		Namespace:       "ns",
+		Transformations: []*config.Transformation{set(map[string]string{"type": "new.type"})},
+	}
+	targets := memory.NewEmptyTargets()
+	targets.MutateBroker("ns", "broker", func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+
+	next := &recordContext{}
+	p := &Processor{Targets: targets}
+	p.WithNext(next)
+
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("type")
+	if err := p.Process(handlerctx.WithTargetKey(context.Background(), target.Key()), &e); err != nil {
+		t.Fatalf("Process got unexpected error: %v", err)
+	}
+	if next.original != &e {
+		t.Errorf("original event in context got=%v, want=%v", next.original, &e)
+	}
+}
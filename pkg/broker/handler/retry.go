@@ -29,8 +29,11 @@ import (
 	"github.com/google/knative-gcp/pkg/broker/config"
 	handlerctx "github.com/google/knative-gcp/pkg/broker/handler/context"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/dedup"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/deliver"
 	"github.com/google/knative-gcp/pkg/broker/handler/processors/filter"
+	"github.com/google/knative-gcp/pkg/broker/handler/processors/transformation"
+	"github.com/google/knative-gcp/pkg/broker/ratelimit"
 	"github.com/google/knative-gcp/pkg/metrics"
 )
 
@@ -48,6 +51,10 @@ type RetryPool struct {
 	// And we can set target address dynamically.
 	deliverClient *http.Client
 	statsReporter *metrics.DeliveryReporter
+	// rateLimiters are shared by the handlers to enforce the target rate limits.
+	rateLimiters *ratelimit.Limiters
+	// inFlight is shared by the handlers to enforce the target in-flight limits.
+	inFlight *ratelimit.InFlight
 }
 
 type retryHandlerCache struct {
@@ -91,6 +98,8 @@ func NewRetryPool(
 		pubsubClient:  pubsubClient,
 		deliverClient: deliverClient,
 		statsReporter: statsReporter,
+		rateLimiters:  ratelimit.NewLimiters(),
+		inFlight:      ratelimit.NewInFlight(),
 	}
 	return p, nil
 }
@@ -105,20 +114,30 @@ func (p *RetryPool) SyncOnce(ctx context.Context) error {
 	p.pool.Range(func(key, value interface{}) bool {
 		// Each target represents a trigger.
 		if _, ok := p.targets.GetTargetByKey(key.(string)); !ok {
-			value.(*retryHandlerCache).Stop()
+			go value.(*retryHandlerCache).Drain(p.options.DrainTimeout)
 			p.pool.Delete(key)
 		}
 		return true
 	})
 
+	// Forget the circuit breakers, rate limiters and delivery tokens of deleted targets.
+	targetExists := func(key string) bool {
+		_, ok := p.targets.GetTargetByKey(key)
+		return ok
+	}
+	p.options.CircuitBreakers.Prune(targetExists)
+	p.rateLimiters.Prune(targetExists)
+	p.options.DeliveryTokens.Prune(deliveryTokenInUse(p.targets))
+
 	p.targets.RangeAllTargets(func(t *config.Target) bool {
 		if value, ok := p.pool.Load(t.Key()); ok {
 			// Skip if we don't need to renew the handler.
 			if !value.(*retryHandlerCache).shouldRenew(t) {
 				return true
 			}
-			// Stop and clean up the old handler before we start a new one.
-			value.(*retryHandlerCache).Stop()
+			// Drain the old handler in the background while the new one takes over,
+			// so that its in-flight events aren't redelivered as duplicates.
+			go value.(*retryHandlerCache).Drain(p.options.DrainTimeout)
 			p.pool.Delete(t.Key())
 		}
 
@@ -135,10 +154,16 @@ func (p *RetryPool) SyncOnce(ctx context.Context) error {
 			sub,
 			processors.ChainProcessors(
 				&filter.Processor{Targets: p.targets},
+				&dedup.Processor{Store: p.options.DedupStore},
+				&transformation.Processor{Targets: p.targets},
 				&deliver.Processor{
-					DeliverClient: p.deliverClient,
-					Targets:       p.targets,
-					StatsReporter: p.statsReporter,
+					DeliverClient:   p.deliverClient,
+					Targets:         p.targets,
+					StatsReporter:   p.statsReporter,
+					RateLimiters:    p.rateLimiters,
+					Tokens:          p.options.DeliveryTokens,
+					InFlight:        p.inFlight,
+					CircuitBreakers: p.options.CircuitBreakers,
 				},
 			),
 			p.options.TimeoutPerEvent,
@@ -173,3 +198,8 @@ func (p *RetryPool) SyncOnce(ctx context.Context) error {
 
 	return nil
 }
+
+// Drain drains all handlers of the pool, e.g. on shutdown. See Handler.Drain.
+func (p *RetryPool) Drain() {
+	drainAll(p.options.DrainTimeout, &p.pool)
+}
@@ -286,6 +286,33 @@ func (h *Helper) RenewTarget(ctx context.Context, t *testing.T, targetKey string
 	return target
 }
 
+// IsolateTarget gives a target its own subscription on the decouple topic of its broker.
+func (h *Helper) IsolateTarget(ctx context.Context, t *testing.T, targetKey string) *config.Target {
+	t.Helper()
+	target, ok := h.Targets.GetTargetByKey(targetKey)
+	if !ok {
+		t.Fatalf("target with key %q doesn't exist", targetKey)
+	}
+	b, ok := h.Targets.GetBrokerByKey(config.BrokerKey(target.Namespace, target.Broker))
+	if !ok {
+		t.Fatalf("broker of target %q doesn't exist", targetKey)
+	}
+
+	sub := "isolated-sub-" + uuid.New().String()
+	if _, err := h.PubsubClient.CreateSubscription(ctx, sub, pubsub.SubscriptionConfig{Topic: h.PubsubClient.Topic(b.DecoupleQueue.Topic)}); err != nil {
+		t.Fatalf("failed to create test target isolated subscription: %v", err)
+	}
+	target.IsolatedQueue = &config.Queue{
+		Topic:        b.DecoupleQueue.Topic,
+		Subscription: sub,
+	}
+
+	h.Targets.MutateBroker(target.Namespace, target.Broker, func(bm config.BrokerMutation) {
+		bm.UpsertTargets(target)
+	})
+	return target
+}
+
 // DeleteTarget deletes a target and test resources used by it.
 func (h *Helper) DeleteTarget(ctx context.Context, t *testing.T, targetKey string) {
 	t.Helper()
@@ -0,0 +1,302 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/idtoken"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+const (
+	bearerPrefix = "Bearer "
+
+	// publisherAttribute is the trace attribute of the authenticated publisher.
+	publisherAttribute = "publisher"
+
+	// tokenCacheTTL is how long the results of token reviews are cached.
+	tokenCacheTTL = time.Minute
+	// tokenCacheCapacity is the max number of token review results cached.
+	tokenCacheCapacity = 10000
+)
+
+type publisherKey struct{}
+
+// Issuers of Google ID tokens. Tokens from other issuers are verified by the
+// Kubernetes API server.
+var googleIssuers = map[string]bool{
+	"accounts.google.com":         true,
+	"https://accounts.google.com": true,
+}
+
+// TokenVerifier verifies bearer tokens of publishers.
+type TokenVerifier interface {
+	// Verify verifies that the token is valid and issued for the audience, and
+	// returns the identity of the token subject. It returns ErrAuthUnavailable if the token
+	// could not be verified because the verifier itself failed.
+	Verify(ctx context.Context, token, audience string) (string, error)
+}
+
+// Authenticator authenticates publishers of brokers that restrict their publishers.
+type Authenticator struct {
+	targets  config.ReadonlyTargets
+	verifier TokenVerifier
+}
+
+// NewAuthenticator creates a new Authenticator.
+func NewAuthenticator(targets config.ReadonlyTargets, verifier TokenVerifier) *Authenticator {
+	return &Authenticator{targets: targets, verifier: verifier}
+}
+
+// Authenticate returns the identity of the publisher of the request to the broker.
+// It returns an empty identity if the broker doesn't restrict its publishers, ErrUnauthenticated
+// if the request doesn't have a valid token, ErrUnauthorized if the publisher is not allowed, and
+// ErrAuthUnavailable if the token could not be verified.
+func (a *Authenticator) Authenticate(ctx context.Context, broker types.NamespacedName, request *nethttp.Request) (string, error) {
+	b, ok := a.targets.GetBroker(broker.Namespace, broker.Name)
+	if !ok || len(b.AllowedPublishers) == 0 {
+		// Unknown brokers are rejected by the decouple sink.
+		return "", nil
+	}
+
+	auth := request.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, bearerPrefix) {
+		return "", fmt.Errorf("missing bearer token: %w", ErrUnauthenticated)
+	}
+	// The audience of the token must be the broker address so that a token for
+	// one broker cannot be replayed to another.
+	identity, err := a.verifier.Verify(ctx, strings.TrimPrefix(auth, bearerPrefix), b.Address)
+	if errors.Is(err, ErrAuthUnavailable) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", err, ErrUnauthenticated)
+	}
+	if containsString(b.AllowedPublishers, identity) {
+		return identity, nil
+	}
+	return identity, fmt.Errorf("publisher %q of %q: %w", identity, broker, ErrUnauthorized)
+}
+
+func withPublisher(ctx context.Context, publisher string) context.Context {
+	return context.WithValue(ctx, publisherKey{}, publisher)
+}
+
+// publisherFromContext returns the authenticated publisher of the request, or an
+// empty string if the request was not authenticated.
+func publisherFromContext(ctx context.Context) string {
+	publisher, _ := ctx.Value(publisherKey{}).(string)
+	return publisher
+}
+
+// NewTokenVerifier creates a TokenVerifier that verifies Google ID tokens with the Google
+// public keys and other tokens, e.g. Kubernetes service account tokens, with the TokenReview API.
+func NewTokenVerifier(kubeClient kubernetes.Interface) TokenVerifier {
+	return &issuerTokenVerifier{
+		google: &googleTokenVerifier{validate: idtoken.Validate},
+		kube:   newCachingTokenVerifier(&kubeTokenVerifier{client: kubeClient}, tokenCacheCapacity, tokenCacheTTL),
+	}
+}
+
+// issuerTokenVerifier dispatches tokens to verifiers based on their issuers.
+type issuerTokenVerifier struct {
+	google TokenVerifier
+	kube   TokenVerifier
+}
+
+func (v *issuerTokenVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	issuer, err := unverifiedIssuer(token)
+	if err != nil {
+		return "", err
+	}
+	if googleIssuers[issuer] {
+		return v.google.Verify(ctx, token, audience)
+	}
+	return v.kube.Verify(ctx, token, audience)
+}
+
+// unverifiedIssuer returns the issuer claim of a JWT without verifying it.
+func unverifiedIssuer(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed token payload: %v", err)
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("malformed token payload: %v", err)
+	}
+	return claims.Issuer, nil
+}
+
+// googleTokenVerifier verifies Google ID tokens. The identity is the verified email claim.
+type googleTokenVerifier struct {
+	validate func(ctx context.Context, token, audience string) (*idtoken.Payload, error)
+}
+
+func (v *googleTokenVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	payload, err := v.validate(ctx, token, audience)
+	if err != nil {
+		return "", err
+	}
+	email, _ := payload.Claims["email"].(string)
+	verified, _ := payload.Claims["email_verified"].(bool)
+	if email == "" || !verified {
+		return "", errors.New("token has no verified email")
+	}
+	return email, nil
+}
+
+// kubeTokenVerifier verifies tokens with the Kubernetes TokenReview API. The identity is
+// the Kubernetes user name, e.g. system:serviceaccount:<namespace>:<name>.
+type kubeTokenVerifier struct {
+	client kubernetes.Interface
+}
+
+func (v *kubeTokenVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	review, err := v.client.AuthenticationV1().TokenReviews().CreateContext(ctx, &authv1.TokenReview{
+		Spec: authv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: []string{audience},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to review token: %v: %w", err, ErrAuthUnavailable)
+	}
+	if !review.Status.Authenticated {
+		return "", fmt.Errorf("token not authenticated: %s", review.Status.Error)
+	}
+	// API servers that don't support audiences leave the status audiences empty.
+	if !containsString(review.Status.Audiences, audience) {
+		return "", fmt.Errorf("token audience is not %q", audience)
+	}
+	return review.Status.User.Username, nil
+}
+
+type tokenCacheKey [sha256.Size]byte
+
+type tokenCacheEntry struct {
+	key      tokenCacheKey
+	identity string
+	err      error
+	expires  time.Time
+}
+
+// cachingTokenVerifier caches the results of a TokenVerifier so that the tokens of
+// publishers are not verified again on every request. Tokens are cached by their hash.
+// Failures of the verifier itself are not cached. The cache is bounded in size; the
+// least recently verified tokens are evicted once the capacity is reached.
+type cachingTokenVerifier struct {
+	verifier TokenVerifier
+	capacity int
+	ttl      time.Duration
+
+	mu sync.Mutex
+	// ll holds the entries from the most to the least recently verified.
+	ll      *list.List
+	entries map[tokenCacheKey]*list.Element
+	now     func() time.Time
+}
+
+func newCachingTokenVerifier(verifier TokenVerifier, capacity int, ttl time.Duration) *cachingTokenVerifier {
+	return &cachingTokenVerifier{
+		verifier: verifier,
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		entries:  make(map[tokenCacheKey]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (v *cachingTokenVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	key := sha256.Sum256([]byte(audience + "\x00" + token))
+	if e := v.get(key); e != nil {
+		return e.identity, e.err
+	}
+	identity, err := v.verifier.Verify(ctx, token, audience)
+	if !errors.Is(err, ErrAuthUnavailable) {
+		v.add(key, identity, err)
+	}
+	return identity, err
+}
+
+// get returns the unexpired cache entry of the key, or nil if there is none.
+func (v *cachingTokenVerifier) get(key tokenCacheKey) *tokenCacheEntry {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	el, ok := v.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*tokenCacheEntry)
+	if v.now().After(e.expires) {
+		v.remove(el)
+		return nil
+	}
+	v.ll.MoveToFront(el)
+	return e
+}
+
+func (v *cachingTokenVerifier) add(key tokenCacheKey, identity string, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	e := &tokenCacheEntry{key: key, identity: identity, err: err, expires: v.now().Add(v.ttl)}
+	if el, ok := v.entries[key]; ok {
+		el.Value = e
+		v.ll.MoveToFront(el)
+		return
+	}
+	v.entries[key] = v.ll.PushFront(e)
+	for v.ll.Len() > v.capacity {
+		v.remove(v.ll.Back())
+	}
+}
+
+func (v *cachingTokenVerifier) remove(el *list.Element) {
+	v.ll.Remove(el)
+	delete(v.entries, el.Value.(*tokenCacheEntry).key)
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
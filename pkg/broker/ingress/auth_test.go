@@ -0,0 +1,480 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"google.golang.org/api/idtoken"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/logging"
+	logtest "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/metrics"
+	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+)
+
+const (
+	authBrokerAddress = "http://ingress.example.com/ns1/broker1"
+	allowedPublisher  = "system:serviceaccount:ns1:allowed"
+	deniedPublisher   = "system:serviceaccount:ns1:denied"
+)
+
+// unavailableToken is a token that the fakeTokenVerifier fails to verify.
+const unavailableToken = "unavailable-token"
+
+// fakeTokenVerifier maps valid tokens to their identities.
+type fakeTokenVerifier struct {
+	audience   string
+	identities map[string]string
+}
+
+func (v *fakeTokenVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	if audience != v.audience {
+		return "", fmt.Errorf("audience got=%q, want=%q", audience, v.audience)
+	}
+	if token == unavailableToken {
+		return "", fmt.Errorf("verifier failed: %w", ErrAuthUnavailable)
+	}
+	identity, ok := v.identities[token]
+	if !ok {
+		return "", errors.New("invalid token")
+	}
+	return identity, nil
+}
+
+func newAuthTestTargets() config.Targets {
+	return memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"ns1/broker1": {
+				Name:              "broker1",
+				Namespace:         "ns1",
+				Address:           authBrokerAddress,
+				State:             config.State_READY,
+				DecoupleQueue:     &config.Queue{Topic: topicID},
+				AllowedPublishers: []string{allowedPublisher},
+			},
+			"ns2/broker2": {
+				Name:          "broker2",
+				Namespace:     "ns2",
+				State:         config.State_READY,
+				DecoupleQueue: &config.Queue{Topic: topicID},
+			},
+		},
+	})
+}
+
+func newAuthTestAuthenticator() *Authenticator {
+	return NewAuthenticator(newAuthTestTargets(), &fakeTokenVerifier{
+		audience: authBrokerAddress,
+		identities: map[string]string{
+			"allowed-token": allowedPublisher,
+			"denied-token":  deniedPublisher,
+		},
+	})
+}
+
+func TestAuthenticator(t *testing.T) {
+	cases := []struct {
+		name         string
+		broker       types.NamespacedName
+		header       string
+		wantIdentity string
+		wantErr      error
+	}{{
+		name:   "unrestricted broker",
+		broker: types.NamespacedName{Namespace: "ns2", Name: "broker2"},
+	}, {
+		name:   "unknown broker",
+		broker: types.NamespacedName{Namespace: "ns3", Name: "broker3"},
+	}, {
+		name:    "missing token",
+		broker:  types.NamespacedName{Namespace: "ns1", Name: "broker1"},
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:    "not a bearer token",
+		broker:  types.NamespacedName{Namespace: "ns1", Name: "broker1"},
+		header:  "Basic allowed-token",
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:    "invalid token",
+		broker:  types.NamespacedName{Namespace: "ns1", Name: "broker1"},
+		header:  "Bearer invalid-token",
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:    "token verifier failed",
+		broker:  types.NamespacedName{Namespace: "ns1", Name: "broker1"},
+		header:  "Bearer " + unavailableToken,
+		wantErr: ErrAuthUnavailable,
+	}, {
+		name:         "publisher not allowed",
+		broker:       types.NamespacedName{Namespace: "ns1", Name: "broker1"},
+		header:       "Bearer denied-token",
+		wantIdentity: deniedPublisher,
+		wantErr:      ErrUnauthorized,
+	}, {
+		name:         "publisher allowed",
+		broker:       types.NamespacedName{Namespace: "ns1", Name: "broker1"},
+		header:       "Bearer allowed-token",
+		wantIdentity: allowedPublisher,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(nethttp.MethodPost, "/"+tc.broker.Namespace+"/"+tc.broker.Name, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			identity, err := newAuthTestAuthenticator().Authenticate(context.Background(), tc.broker, req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Authenticate error got=%v, want=%v", err, tc.wantErr)
+			}
+			if identity != tc.wantIdentity {
+				t.Errorf("Authenticate identity got=%q, want=%q", identity, tc.wantIdentity)
+			}
+		})
+	}
+}
+
+func TestKubeTokenVerifier(t *testing.T) {
+	cases := []struct {
+		name         string
+		status       authv1.TokenReviewStatus
+		reviewErr    error
+		wantIdentity string
+		wantErr      error
+	}{{
+		name: "authenticated",
+		status: authv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          authv1.UserInfo{Username: allowedPublisher},
+			Audiences:     []string{authBrokerAddress},
+		},
+		wantIdentity: allowedPublisher,
+	}, {
+		name:    "not authenticated",
+		status:  authv1.TokenReviewStatus{Error: "invalid token"},
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:      "token review failed",
+		reviewErr: errors.New("api server unavailable"),
+		wantErr:   ErrAuthUnavailable,
+	}, {
+		name: "audiences not supported",
+		status: authv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          authv1.UserInfo{Username: allowedPublisher},
+		},
+		wantErr: ErrUnauthenticated,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				review := action.(clienttesting.CreateAction).GetObject().(*authv1.TokenReview).DeepCopy()
+				if review.Spec.Token != "token" {
+					t.Errorf("token got=%q, want=%q", review.Spec.Token, "token")
+				}
+				if tc.reviewErr != nil {
+					return true, &authv1.TokenReview{}, tc.reviewErr
+				}
+				review.Status = tc.status
+				return true, review, nil
+			})
+			v := &kubeTokenVerifier{client: client}
+			identity, err := v.Verify(context.Background(), "token", authBrokerAddress)
+			if (err != nil) != (tc.wantErr != nil) {
+				t.Errorf("Verify got error=%v, want error=%v", err, tc.wantErr)
+			}
+			// Rejected tokens must not be reported as verifier failures.
+			if gotUnavailable, wantUnavailable := errors.Is(err, ErrAuthUnavailable), errors.Is(tc.wantErr, ErrAuthUnavailable); gotUnavailable != wantUnavailable {
+				t.Errorf("Verify error got=%v, want=%v", err, tc.wantErr)
+			}
+			if identity != tc.wantIdentity {
+				t.Errorf("Verify identity got=%q, want=%q", identity, tc.wantIdentity)
+			}
+		})
+	}
+}
+
+// countingTokenVerifier counts the verifications of a fakeTokenVerifier.
+type countingTokenVerifier struct {
+	fakeTokenVerifier
+	count int
+}
+
+func (v *countingTokenVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	v.count++
+	return v.fakeTokenVerifier.Verify(ctx, token, audience)
+}
+
+func TestCachingTokenVerifier(t *testing.T) {
+	fake := &countingTokenVerifier{fakeTokenVerifier: fakeTokenVerifier{
+		audience:   authBrokerAddress,
+		identities: map[string]string{"allowed-token": allowedPublisher},
+	}}
+	now := time.Now()
+	v := newCachingTokenVerifier(fake, 2, time.Minute)
+	v.now = func() time.Time { return now }
+
+	verify := func(token string, wantCount int) (string, error) {
+		t.Helper()
+		identity, err := v.Verify(context.Background(), token, authBrokerAddress)
+		if fake.count != wantCount {
+			t.Errorf("verifications of %q got=%d, want=%d", token, fake.count, wantCount)
+		}
+		return identity, err
+	}
+
+	if identity, err := verify("allowed-token", 1); err != nil || identity != allowedPublisher {
+		t.Errorf("Verify got=(%q, %v), want=(%q, nil)", identity, err, allowedPublisher)
+	}
+	// Verified tokens are cached.
+	if identity, err := verify("allowed-token", 1); err != nil || identity != allowedPublisher {
+		t.Errorf("Verify got=(%q, %v), want=(%q, nil)", identity, err, allowedPublisher)
+	}
+	// Rejected tokens are cached.
+	verify("invalid-token", 2)
+	if _, err := verify("invalid-token", 2); err == nil {
+		t.Error("Verify of cached invalid token got no error")
+	}
+	// Tokens are cached per audience.
+	if _, err := v.Verify(context.Background(), "allowed-token", "http://other.example.com"); err == nil {
+		t.Error("Verify of token for another audience got no error")
+	}
+	if fake.count != 3 {
+		t.Errorf("verifications got=%d, want=3", fake.count)
+	}
+	// Failures of the verifier are not cached.
+	verify(unavailableToken, 4)
+	if _, err := verify(unavailableToken, 5); !errors.Is(err, ErrAuthUnavailable) {
+		t.Errorf("Verify error got=%v, want=%v", err, ErrAuthUnavailable)
+	}
+	// The cache is bounded; the least recently verified tokens are evicted.
+	if len(v.entries) != 2 {
+		t.Errorf("cached tokens got=%d, want=2", len(v.entries))
+	}
+	verify("allowed-token", 6)
+	// Tokens expire after the TTL.
+	now = now.Add(2 * time.Minute)
+	verify("allowed-token", 7)
+}
+
+func TestGoogleTokenVerifier(t *testing.T) {
+	cases := []struct {
+		name         string
+		claims       map[string]interface{}
+		validateErr  error
+		wantIdentity string
+		wantErr      bool
+	}{{
+		name:         "verified email",
+		claims:       map[string]interface{}{"email": "publisher@my-project.iam.gserviceaccount.com", "email_verified": true},
+		wantIdentity: "publisher@my-project.iam.gserviceaccount.com",
+	}, {
+		name:    "unverified email",
+		claims:  map[string]interface{}{"email": "publisher@my-project.iam.gserviceaccount.com"},
+		wantErr: true,
+	}, {
+		name:        "invalid token",
+		validateErr: errors.New("invalid token"),
+		wantErr:     true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &googleTokenVerifier{
+				validate: func(ctx context.Context, token, audience string) (*idtoken.Payload, error) {
+					if audience != authBrokerAddress {
+						t.Errorf("audience got=%q, want=%q", audience, authBrokerAddress)
+					}
+					if tc.validateErr != nil {
+						return nil, tc.validateErr
+					}
+					return &idtoken.Payload{Audience: audience, Claims: tc.claims}, nil
+				},
+			}
+			identity, err := v.Verify(context.Background(), "token", authBrokerAddress)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Verify got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if identity != tc.wantIdentity {
+				t.Errorf("Verify identity got=%q, want=%q", identity, tc.wantIdentity)
+			}
+		})
+	}
+}
+
+// issuerVerifier returns the name of the verifier as the identity.
+type issuerVerifier string
+
+func (v issuerVerifier) Verify(ctx context.Context, token, audience string) (string, error) {
+	return string(v), nil
+}
+
+func TestIssuerTokenVerifier(t *testing.T) {
+	jwt := func(payload string) string {
+		return "header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+	}
+	cases := []struct {
+		name         string
+		token        string
+		wantIdentity string
+		wantErr      bool
+	}{{
+		name:         "google issuer",
+		token:        jwt(`{"iss":"https://accounts.google.com"}`),
+		wantIdentity: "google",
+	}, {
+		name:         "kubernetes issuer",
+		token:        jwt(`{"iss":"kubernetes/serviceaccount"}`),
+		wantIdentity: "kube",
+	}, {
+		name:    "malformed token",
+		token:   "token",
+		wantErr: true,
+	}, {
+		name:    "malformed payload",
+		token:   jwt(`not json`),
+		wantErr: true,
+	}}
+
+	v := &issuerTokenVerifier{google: issuerVerifier("google"), kube: issuerVerifier("kube")}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := v.Verify(context.Background(), tc.token, authBrokerAddress)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Verify got error=%v, want error=%v", err, tc.wantErr)
+			}
+			if identity != tc.wantIdentity {
+				t.Errorf("Verify identity got=%q, want=%q", identity, tc.wantIdentity)
+			}
+		})
+	}
+}
+
+// acceptingSink is a DecoupleSink that accepts all events.
+type acceptingSink struct{}
+
+func (acceptingSink) Send(ctx context.Context, broker types.NamespacedName, event cloudevents.Event) protocol.Result {
+	return nil
+}
+
+func TestHandlerAuth(t *testing.T) {
+	cases := []struct {
+		name           string
+		path           string
+		token          string
+		wantCode       int
+		wantPublisher  string
+		wantAuthMetric bool
+	}{{
+		name:     "unrestricted broker",
+		path:     "/ns2/broker2",
+		wantCode: nethttp.StatusAccepted,
+	}, {
+		name:           "missing token",
+		path:           "/ns1/broker1",
+		wantCode:       nethttp.StatusUnauthorized,
+		wantPublisher:  "unknown",
+		wantAuthMetric: true,
+	}, {
+		name:           "token verifier failed",
+		path:           "/ns1/broker1",
+		token:          unavailableToken,
+		wantCode:       nethttp.StatusServiceUnavailable,
+		wantPublisher:  "unknown",
+		wantAuthMetric: true,
+	}, {
+		name:           "publisher not allowed",
+		path:           "/ns1/broker1",
+		token:          "denied-token",
+		wantCode:       nethttp.StatusForbidden,
+		wantPublisher:  deniedPublisher,
+		wantAuthMetric: true,
+	}, {
+		name:           "publisher allowed",
+		path:           "/ns1/broker1",
+		token:          "allowed-token",
+		wantCode:       nethttp.StatusAccepted,
+		wantPublisher:  allowedPublisher,
+		wantAuthMetric: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetIngressMetrics()
+			ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+			statsReporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := NewHandler(ctx, nil, acceptingSink{}, newAuthTestAuthenticator(), statsReporter)
+
+			req := httptest.NewRequest(nethttp.MethodPost, tc.path, nil)
+			if err := http.WriteRequest(ctx, binding.ToMessage(createTestEvent("test-event")), req); err != nil {
+				t.Fatal(err)
+			}
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if got := w.Result().StatusCode; got != tc.wantCode {
+				t.Errorf("StatusCode got=%v, want=%v", got, tc.wantCode)
+			}
+			if !tc.wantAuthMetric {
+				metricstest.CheckStatsNotReported(t, "publisher_auth_count")
+				return
+			}
+			authCode := tc.wantCode
+			if authCode == nethttp.StatusAccepted {
+				authCode = nethttp.StatusOK
+			}
+			metricstest.CheckCountData(t, "publisher_auth_count", map[string]string{
+				metricskey.LabelNamespaceName:     "ns1",
+				metricskey.LabelBrokerName:        "broker1",
+				"publisher":                       tc.wantPublisher,
+				metricskey.LabelResponseCode:      fmt.Sprint(authCode),
+				metricskey.LabelResponseCodeClass: fmt.Sprintf("%dxx", authCode/100),
+				metricskey.ContainerName:          container,
+				metricskey.PodName:                pod,
+			}, 1)
+		})
+	}
+}
@@ -28,3 +28,19 @@ var ErrIncomplete = errors.New("incomplete config")
 
 // ErrNotReady is the error when a broker is not ready.
 var ErrNotReady = errors.New("not ready")
+
+// ErrOverloaded is the error when the ingress has too many outstanding publishes to accept an event.
+var ErrOverloaded = errors.New("overloaded")
+
+// ErrRateLimited is the error when a broker has exceeded its ingress rate limit.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrUnauthenticated is the error when a request to a broker restricting its publishers has no valid token.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// ErrUnauthorized is the error when the publisher is not allowed to publish to a broker.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrAuthUnavailable is the error when the token of a publisher cannot be verified because
+// the token verifier, e.g. the Kubernetes TokenReview API, failed.
+var ErrAuthUnavailable = errors.New("authentication unavailable")
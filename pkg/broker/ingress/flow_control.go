@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import "sync"
+
+// FlowControlSettings limits the publishes outstanding in the ingress.
+// A zero value means no limit.
+type FlowControlSettings struct {
+	// MaxOutstandingMessages is the max number of messages being published.
+	MaxOutstandingMessages int64
+	// MaxOutstandingBytes is the max number of bytes being published.
+	MaxOutstandingBytes int64
+}
+
+// flowController tracks outstanding publishes and rejects new ones over the limits.
+// Rejecting instead of blocking keeps the ingress from piling up goroutines
+// waiting on a saturated publisher.
+type flowController struct {
+	settings FlowControlSettings
+
+	mu       sync.Mutex
+	messages int64
+	bytes    int64
+}
+
+func newFlowController(settings FlowControlSettings) *flowController {
+	return &flowController{settings: settings}
+}
+
+// tryAcquire reserves one message of size bytes. It returns false without
+// reserving anything if the reservation would exceed a limit. A message larger
+// than MaxOutstandingBytes is still accepted when nothing else is outstanding,
+// otherwise it could never be published.
+func (f *flowController) tryAcquire(size int64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.settings.MaxOutstandingMessages > 0 && f.messages+1 > f.settings.MaxOutstandingMessages {
+		return false
+	}
+	if f.settings.MaxOutstandingBytes > 0 && f.messages > 0 && f.bytes+size > f.settings.MaxOutstandingBytes {
+		return false
+	}
+	f.messages++
+	f.bytes += size
+	return true
+}
+
+// release releases a message of size bytes reserved by tryAcquire.
+func (f *flowController) release(size int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages--
+	f.bytes -= size
+}
+
+// outstandingBytes returns the number of bytes currently reserved.
+func (f *flowController) outstandingBytes() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bytes
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import "testing"
+
+func TestFlowController(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings FlowControlSettings
+		acquire  []int64
+		want     []bool
+	}{{
+		name:    "unlimited",
+		acquire: []int64{100, 100, 100},
+		want:    []bool{true, true, true},
+	}, {
+		name:     "max messages",
+		settings: FlowControlSettings{MaxOutstandingMessages: 2},
+		acquire:  []int64{1, 1, 1},
+		want:     []bool{true, true, false},
+	}, {
+		name:     "max bytes",
+		settings: FlowControlSettings{MaxOutstandingBytes: 10},
+		acquire:  []int64{4, 6, 1},
+		want:     []bool{true, true, false},
+	}, {
+		name:     "oversized message with nothing outstanding",
+		settings: FlowControlSettings{MaxOutstandingBytes: 10},
+		acquire:  []int64{20, 1},
+		want:     []bool{true, false},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFlowController(tc.settings)
+			for i, size := range tc.acquire {
+				if got := f.tryAcquire(size); got != tc.want[i] {
+					t.Errorf("tryAcquire(%d) #%d got=%v, want=%v", size, i, got, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFlowControllerRelease(t *testing.T) {
+	f := newFlowController(FlowControlSettings{MaxOutstandingMessages: 1, MaxOutstandingBytes: 10})
+	if !f.tryAcquire(5) {
+		t.Fatal("tryAcquire got=false, want=true")
+	}
+	if got := f.outstandingBytes(); got != 5 {
+		t.Errorf("outstandingBytes got=%d, want=5", got)
+	}
+	if f.tryAcquire(5) {
+		t.Fatal("tryAcquire over limit got=true, want=false")
+	}
+	f.release(5)
+	if got := f.outstandingBytes(); got != 0 {
+		t.Errorf("outstandingBytes after release got=%d, want=0", got)
+	}
+	if !f.tryAcquire(5) {
+		t.Error("tryAcquire after release got=false, want=true")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	nethttp "net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	ceclient "github.com/cloudevents/sdk-go/v2/client"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/knative-gcp/pkg/kncloudevents/batch"
 	"github.com/google/knative-gcp/pkg/metrics"
 	"github.com/google/knative-gcp/pkg/tracing"
 	"github.com/google/knative-gcp/pkg/utils/clients"
@@ -54,6 +56,10 @@ const (
 
 	// For probes.
 	heathCheckPath = "/healthz"
+
+	// overloadedRetryAfter is the Retry-After sent to clients when the
+	// outstanding publishes are over the flow control limits.
+	overloadedRetryAfter = time.Second
 )
 
 // HandlerSet provides a handler with a real HTTPMessageReceiver and pubsub MultiTopicDecoupleSink.
@@ -63,6 +69,8 @@ var HandlerSet wire.ProviderSet = wire.NewSet(
 	wire.Bind(new(HttpMessageReceiver), new(*kncloudevents.HttpMessageReceiver)),
 	NewMultiTopicDecoupleSink,
 	wire.Bind(new(DecoupleSink), new(*multiTopicDecoupleSink)),
+	NewAuthenticator,
+	NewTokenVerifier,
 	clients.NewPubsubClient,
 	metrics.NewIngressReporter,
 )
@@ -84,17 +92,20 @@ type Handler struct {
 	httpReceiver HttpMessageReceiver
 	// decouple is the client to send events to a decouple sink.
 	decouple DecoupleSink
-	logger   *zap.Logger
-	reporter *metrics.IngressReporter
+	// authenticator authenticates publishers of brokers. If nil, requests are not authenticated.
+	authenticator *Authenticator
+	logger        *zap.Logger
+	reporter      *metrics.IngressReporter
 }
 
 // NewHandler creates a new ingress handler.
-func NewHandler(ctx context.Context, httpReceiver HttpMessageReceiver, decouple DecoupleSink, reporter *metrics.IngressReporter) *Handler {
+func NewHandler(ctx context.Context, httpReceiver HttpMessageReceiver, decouple DecoupleSink, authenticator *Authenticator, reporter *metrics.IngressReporter) *Handler {
 	return &Handler{
-		httpReceiver: httpReceiver,
-		decouple:     decouple,
-		reporter:     reporter,
-		logger:       logging.FromContext(ctx),
+		httpReceiver:  httpReceiver,
+		decouple:      decouple,
+		authenticator: authenticator,
+		reporter:      reporter,
+		logger:        logging.FromContext(ctx),
 	}
 }
 
@@ -106,8 +117,9 @@ func (h *Handler) Start(ctx context.Context) error {
 // ServeHTTP implements net/http Handler interface method.
 // 1. Performs basic validation of the request.
 // 2. Parse request URL to get namespace and broker.
-// 3. Convert request to event.
-// 4. Send event to decouple sink.
+// 3. Authenticate the publisher if the broker restricts its publishers.
+// 4. Convert request to event, or to a list of events in batch mode.
+// 5. Send event to decouple sink.
 func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Request) {
 	if request.URL.Path == heathCheckPath {
 		response.WriteHeader(nethttp.StatusOK)
@@ -134,12 +146,68 @@ func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Re
 		Name:      pieces[2],
 	}
 
+	ctx, ok := h.authenticate(ctx, response, request, broker)
+	if !ok {
+		return
+	}
+
+	if batch.IsRequest(request) {
+		h.serveBatch(ctx, response, request, broker)
+		return
+	}
+
 	event, err := h.toEvent(request)
 	if err != nil {
 		nethttp.Error(response, err.Error(), nethttp.StatusBadRequest)
 		return
 	}
 
+	statusCode, res := h.sendEvent(ctx, broker, event)
+	if statusCode != nethttp.StatusAccepted {
+		msg := fmt.Sprintf("Error publishing to PubSub for broker %s. event: %+v, err: %v.", broker, event, res)
+		h.logger.Error(msg)
+		if statusCode == nethttp.StatusTooManyRequests {
+			setRetryAfter(response)
+		}
+		nethttp.Error(response, msg, statusCode)
+		return
+	}
+
+	response.WriteHeader(statusCode)
+}
+
+// serveBatch sends each event of a batch request to the decouple sink and responds
+// with the status of each event.
+func (h *Handler) serveBatch(ctx context.Context, response nethttp.ResponseWriter, request *nethttp.Request, broker types.NamespacedName) {
+	entries, err := batch.Read(response, request)
+	if err != nil {
+		nethttp.Error(response, err.Error(), batch.ReadErrorStatus(err))
+		return
+	}
+
+	// Send the events concurrently so that the pubsub client can batch them.
+	results := batch.SendAll(entries, func(event *cev2.Event) (int, error) {
+		statusCode, res := h.sendEvent(ctx, broker, event)
+		if statusCode != nethttp.StatusAccepted {
+			h.logger.Error("Error publishing batched event to PubSub", zap.String("broker", broker.String()), zap.String("id", event.ID()), zap.Error(res))
+		}
+		return statusCode, res
+	})
+
+	for _, r := range results {
+		if r.Status == nethttp.StatusTooManyRequests {
+			setRetryAfter(response)
+			break
+		}
+	}
+	if err := batch.WriteResponse(response, results); err != nil {
+		h.logger.Warn("Failed to write batch response", zap.Error(err))
+	}
+}
+
+// sendEvent stamps the event with its arrival time, sends it to the decouple sink and
+// returns the resulting HTTP status code.
+func (h *Handler) sendEvent(ctx context.Context, broker types.NamespacedName, event *cev2.Event) (int, protocol.Result) {
 	event.SetExtension(EventArrivalTime, cev2.Timestamp{Time: time.Now()})
 
 	ctx, span := trace.StartSpan(ctx, kntracing.BrokerMessagingDestination(broker))
@@ -154,29 +222,84 @@ func (h *Handler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Re
 				kntracing.MessagingMessageIDAttribute(event.ID()),
 			)...,
 		)
+		if publisher := publisherFromContext(ctx); publisher != "" {
+			span.AddAttributes(trace.StringAttribute(publisherAttribute, publisher))
+		}
 	}
 
 	// Optimistically set status code to StatusAccepted. It will be updated if there is an error.
 	// According to the data plane spec (https://github.com/knative/eventing/blob/master/docs/spec/data-plane.md), a
 	// non-callable SINK (which broker is) MUST respond with 202 Accepted if the request is accepted.
 	statusCode := nethttp.StatusAccepted
-	ctx, cancel := context.WithTimeout(ctx, decoupleSinkTimeout)
+	defer func() { h.reportMetrics(ctx, broker, event, statusCode) }()
+	sctx, cancel := context.WithTimeout(ctx, decoupleSinkTimeout)
 	defer cancel()
-	defer func() { h.reportMetrics(request.Context(), broker, event, statusCode) }()
-	if res := h.decouple.Send(ctx, broker, *event); !cev2.IsACK(res) {
-		msg := fmt.Sprintf("Error publishing to PubSub for broker %s. event: %+v, err: %v.", broker, event, res)
-		h.logger.Error(msg)
+	res := h.decouple.Send(sctx, broker, *event)
+	if !cev2.IsACK(res) {
 		statusCode = nethttp.StatusInternalServerError
 		if errors.Is(res, ErrNotFound) {
 			statusCode = nethttp.StatusNotFound
 		} else if errors.Is(res, ErrNotReady) {
 			statusCode = nethttp.StatusServiceUnavailable
+		} else if errors.Is(res, ErrOverloaded) || errors.Is(res, ErrRateLimited) {
+			statusCode = nethttp.StatusTooManyRequests
 		}
-		nethttp.Error(response, msg, statusCode)
-		return
 	}
+	return statusCode, res
+}
 
-	response.WriteHeader(statusCode)
+// authenticate authenticates the publisher of the request to the broker and records the
+// publisher in the returned context. It responds with an error and returns false if the
+// request is rejected.
+func (h *Handler) authenticate(ctx context.Context, response nethttp.ResponseWriter, request *nethttp.Request, broker types.NamespacedName) (context.Context, bool) {
+	if h.authenticator == nil {
+		return ctx, true
+	}
+	publisher, err := h.authenticator.Authenticate(ctx, broker, request)
+	if err == nil && publisher == "" {
+		// The broker doesn't restrict its publishers.
+		return ctx, true
+	}
+
+	statusCode := nethttp.StatusOK
+	if errors.Is(err, ErrUnauthenticated) {
+		statusCode = nethttp.StatusUnauthorized
+	} else if errors.Is(err, ErrUnauthorized) {
+		statusCode = nethttp.StatusForbidden
+	} else if errors.Is(err, ErrAuthUnavailable) {
+		statusCode = nethttp.StatusServiceUnavailable
+	}
+	args := metrics.IngressAuthReportArgs{
+		Namespace:    broker.Namespace,
+		Broker:       broker.Name,
+		Publisher:    publisher,
+		ResponseCode: statusCode,
+	}
+	if err := h.reporter.ReportAuthCount(ctx, args); err != nil {
+		h.logger.Warn("Failed to record auth metrics.", zap.Any("namespace", broker.Namespace), zap.Any("broker", broker.Name), zap.Error(err))
+	}
+	if err != nil {
+		h.logger.Info("Rejected unauthorized request", zap.String("broker", broker.String()), zap.String("publisher", publisher), zap.Error(err))
+		trace.FromContext(ctx).Annotate(
+			[]trace.Attribute{
+				trace.StringAttribute(publisherAttribute, publisher),
+				trace.StringAttribute("error_message", err.Error()),
+			},
+			"request rejected",
+		)
+		if statusCode == nethttp.StatusServiceUnavailable {
+			setRetryAfter(response)
+		}
+		nethttp.Error(response, err.Error(), statusCode)
+		return ctx, false
+	}
+	return withPublisher(ctx, publisher), true
+}
+
+// setRetryAfter tells the client when to retry events rejected by the flow control
+// or the broker rate limit.
+func setRetryAfter(response nethttp.ResponseWriter) {
+	response.Header().Set("Retry-After", strconv.Itoa(int(overloadedRetryAfter.Seconds())))
 }
 
 // toEvent converts an http request to an event.
@@ -33,9 +33,11 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/knative-gcp/pkg/broker/config"
 	"github.com/google/knative-gcp/pkg/broker/config/memory"
+	"github.com/google/knative-gcp/pkg/kncloudevents/batch"
 	"github.com/google/knative-gcp/pkg/metrics"
 	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
 	kgcptesting "github.com/google/knative-gcp/pkg/testing"
@@ -43,6 +45,7 @@ import (
 	"go.uber.org/zap/zaptest"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/pkg/logging"
 	logtest "knative.dev/pkg/logging/testing"
@@ -317,6 +320,122 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestHandlerBatch(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+	ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+
+	url := createAndStartIngress(ctx, t, psSrv)
+	rec := setupTestReceiver(ctx, t, psSrv)
+
+	body := `[
+		{"specversion":"1.0","id":"event-1","source":"test-source","type":"` + eventType + `"},
+		{"specversion":"1.0","id":"event-2","source":"test-source","type":"` + eventType + `"},
+		{"specversion":"1.0","id":"event-3","source":"test-source"}
+	]`
+	req, _ := nethttp.NewRequest("POST", url+"/ns1/broker1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", cloudevents.ApplicationCloudEventsBatchJSON)
+	client := nethttp.Client{}
+	defer client.CloseIdleConnections()
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error from http client: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != nethttp.StatusMultiStatus {
+		t.Errorf("StatusCode got=%v, want=%v", res.StatusCode, nethttp.StatusMultiStatus)
+	}
+	var results []batch.Result
+	if err := json.NewDecoder(res.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	wantStatus := map[string]int{
+		"event-1": nethttp.StatusAccepted,
+		"event-2": nethttp.StatusAccepted,
+		"event-3": nethttp.StatusBadRequest,
+	}
+	if len(results) != len(wantStatus) {
+		t.Fatalf("got %d results, want %d", len(results), len(wantStatus))
+	}
+	for _, r := range results {
+		if r.Status != wantStatus[r.ID] {
+			t.Errorf("status of %q got=%v, want=%v", r.ID, r.Status, wantStatus[r.ID])
+		}
+	}
+
+	// The valid events are stored in the decouple sink.
+	got := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		m, err := rec.Receive(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := binding.ToEvent(ctx, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := e.Extensions()[EventArrivalTime]; !ok {
+			t.Errorf("event %q is missing the %s extension", e.ID(), EventArrivalTime)
+		}
+		got[e.ID()] = true
+	}
+	if !got["event-1"] || !got["event-2"] {
+		t.Errorf("decouple sink got events %v, want event-1 and event-2", got)
+	}
+}
+
+// rejectingSink is a DecoupleSink that rejects all events with the given error.
+type rejectingSink struct {
+	err error
+}
+
+func (s rejectingSink) Send(ctx context.Context, broker types.NamespacedName, event cloudevents.Event) protocol.Result {
+	return fmt.Errorf("%q: %w", broker, s.err)
+}
+
+func TestHandlerOverloaded(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{{
+		name: "flow control",
+		err:  ErrOverloaded,
+	}, {
+		name: "rate limit",
+		err:  ErrRateLimited,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetIngressMetrics()
+			ctx := logging.WithLogger(context.Background(), logtest.TestLogger(t))
+			statsReporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := NewHandler(ctx, nil, rejectingSink{err: tc.err}, nil, statsReporter)
+
+			req := httptest.NewRequest("POST", "/ns1/broker1", nil)
+			if err := http.WriteRequest(ctx, binding.ToMessage(createTestEvent("test-event")), req); err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			res := w.Result()
+			if res.StatusCode != nethttp.StatusTooManyRequests {
+				t.Errorf("StatusCode got=%v, want=%v", res.StatusCode, nethttp.StatusTooManyRequests)
+			}
+			if got := res.Header.Get("Retry-After"); got != "1" {
+				t.Errorf("Retry-After got=%q, want=%q", got, "1")
+			}
+		})
+	}
+}
+
 func BenchmarkIngressHandler(b *testing.B) {
 	for _, eventSize := range kgcptesting.BenchmarkEventSizes {
 		b.Run(fmt.Sprintf("%d bytes", eventSize), func(b *testing.B) {
@@ -338,12 +457,12 @@ func runIngressHandlerBenchmark(b *testing.B, eventSize int) {
 	defer psSrv.Close()
 
 	psClient := createPubsubClient(ctx, b, psSrv)
-	decouple := NewMultiTopicDecoupleSink(ctx, memory.NewTargets(brokerConfig), psClient)
 	statsReporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
 	if err != nil {
 		b.Fatal(err)
 	}
-	h := NewHandler(ctx, nil, decouple, statsReporter)
+	decouple := NewMultiTopicDecoupleSink(ctx, memory.NewTargets(brokerConfig), psClient, statsReporter)
+	h := NewHandler(ctx, nil, decouple, nil, statsReporter)
 
 	if _, err := psClient.CreateTopic(ctx, topicID); err != nil {
 		b.Fatal(err)
@@ -409,14 +528,13 @@ func setupTestReceiver(ctx context.Context, t testing.TB, psSrv *pstest.Server)
 
 // createAndStartIngress creates an ingress and calls its Start() method in a goroutine.
 func createAndStartIngress(ctx context.Context, t testing.TB, psSrv *pstest.Server) string {
-	decouple := NewMultiTopicDecoupleSink(ctx, memory.NewTargets(brokerConfig), createPubsubClient(ctx, t, psSrv))
-
 	receiver := &testHttpMessageReceiver{urlCh: make(chan string)}
 	statsReporter, err := metrics.NewIngressReporter(metrics.PodName(pod), metrics.ContainerName(container))
 	if err != nil {
 		t.Fatal(err)
 	}
-	h := NewHandler(ctx, receiver, decouple, statsReporter)
+	decouple := NewMultiTopicDecoupleSink(ctx, memory.NewTargets(brokerConfig), createPubsubClient(ctx, t, psSrv), statsReporter)
+	h := NewHandler(ctx, receiver, decouple, nil, statsReporter)
 
 	errCh := make(chan error, 1)
 	go func() {
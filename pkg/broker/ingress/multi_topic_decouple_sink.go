@@ -18,12 +18,15 @@ package ingress
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"google.golang.org/api/support/bundler"
 	"k8s.io/apimachinery/pkg/types"
 
 	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
@@ -32,19 +35,83 @@ import (
 	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/eventutil"
+	"github.com/google/knative-gcp/pkg/broker/ratelimit"
+	"github.com/google/knative-gcp/pkg/metrics"
 	"knative.dev/eventing/pkg/logging"
 )
 
-const projectEnvKey = "PROJECT_ID"
+const (
+	projectEnvKey = "PROJECT_ID"
+
+	// rateLimitersPruneInterval is the interval at which the rate limiters of deleted
+	// brokers are removed.
+	rateLimitersPruneInterval = time.Minute
+)
+
+// MultiTopicDecoupleSinkOption configures a multiTopicDecoupleSink.
+type MultiTopicDecoupleSinkOption func(*multiTopicDecoupleSink)
+
+// WithPublishSettings sets the pubsub publish settings used by the decouple topics.
+func WithPublishSettings(settings pubsub.PublishSettings) MultiTopicDecoupleSinkOption {
+	return func(m *multiTopicDecoupleSink) {
+		m.publishSettings = settings
+	}
+}
+
+// WithFlowControl limits the publishes outstanding in the sink. Events over the
+// limits are rejected with ErrOverloaded.
+func WithFlowControl(settings FlowControlSettings) MultiTopicDecoupleSinkOption {
+	return func(m *multiTopicDecoupleSink) {
+		m.flowControl = newFlowController(settings)
+	}
+}
 
 // NewMultiTopicDecoupleSink creates a new multiTopicDecoupleSink.
-func NewMultiTopicDecoupleSink(ctx context.Context, brokerConfig config.ReadonlyTargets, client *pubsub.Client) *multiTopicDecoupleSink {
-	return &multiTopicDecoupleSink{
+func NewMultiTopicDecoupleSink(
+	ctx context.Context,
+	brokerConfig config.ReadonlyTargets,
+	client *pubsub.Client,
+	reporter *metrics.IngressReporter,
+	opts ...MultiTopicDecoupleSinkOption,
+) *multiTopicDecoupleSink {
+	m := &multiTopicDecoupleSink{
 		logger:       logging.FromContext(ctx),
 		pubsub:       client,
 		brokerConfig: brokerConfig,
 		// TODO(#1118): remove Topic when broker config is removed
-		topics: make(map[types.NamespacedName]*pubsub.Topic),
+		topics:          make(map[types.NamespacedName]*pubsub.Topic),
+		publishSettings: pubsub.DefaultPublishSettings,
+		flowControl:     newFlowController(FlowControlSettings{}),
+		rateLimiters:    ratelimit.NewLimiters(),
+		reporter:        reporter,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	// Let the flow control reject events before the pubsub client buffer overflows.
+	if max := m.flowControl.settings.MaxOutstandingBytes; max > int64(m.publishSettings.BufferedByteLimit) {
+		m.publishSettings.BufferedByteLimit = int(max)
+	}
+	go m.pruneRateLimiters(ctx)
+	return m
+}
+
+// pruneRateLimiters periodically removes the rate limiters of deleted brokers until
+// the context is done.
+func (m *multiTopicDecoupleSink) pruneRateLimiters(ctx context.Context) {
+	ticker := time.NewTicker(rateLimitersPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.rateLimiters.Prune(func(key string) bool {
+				_, ok := m.brokerConfig.GetBrokerByKey(key)
+				return ok
+			})
+		}
 	}
 }
 
@@ -56,15 +123,22 @@ type multiTopicDecoupleSink struct {
 	// map from brokers to topics
 	topics    map[types.NamespacedName]*pubsub.Topic
 	topicsMut sync.RWMutex
+	// publishSettings are applied to all topics.
+	publishSettings pubsub.PublishSettings
+	// flowControl limits the outstanding publishes.
+	flowControl *flowController
+	// rateLimiters limit the rate of events accepted per broker.
+	rateLimiters *ratelimit.Limiters
 	// brokerConfig holds configurations for all brokers. It's a view of a configmap populated by
 	// the broker controller.
 	brokerConfig config.ReadonlyTargets
 	logger       *zap.Logger
+	reporter     *metrics.IngressReporter
 }
 
 // Send sends incoming event to its corresponding pubsub topic based on which broker it belongs to.
 func (m *multiTopicDecoupleSink) Send(ctx context.Context, broker types.NamespacedName, event cev2.Event) protocol.Result {
-	topic, err := m.getTopicForBroker(broker)
+	topic, brokerConfig, err := m.getTopicForBroker(broker)
 	if err != nil {
 		trace.FromContext(ctx).Annotate(
 			[]trace.Attribute{
@@ -74,28 +148,75 @@ func (m *multiTopicDecoupleSink) Send(ctx context.Context, broker types.Namespac
 		)
 		return err
 	}
+	if lim := m.rateLimiters.Get(brokerConfig.Key(), brokerConfig.RateLimit); lim != nil && !lim.Allow() {
+		trace.FromContext(ctx).Annotate(nil, "event rejected: broker rate limit exceeded")
+		return fmt.Errorf("%q: %w", broker, ErrRateLimited)
+	}
 
 	dt := extensions.FromSpanContext(trace.FromContext(ctx).SpanContext())
 	msg := new(pubsub.Message)
 	if err := cepubsub.WritePubSubMessage(ctx, binding.ToMessage(&event), msg, dt.WriteTransformer()); err != nil {
 		return err
 	}
+	msg.OrderingKey = eventutil.OrderingKey(&event, brokerConfig.OrderingKeyAttribute)
+
+	size := messageSize(msg)
+	if !m.flowControl.tryAcquire(size) {
+		return fmt.Errorf("%q: %w", broker, ErrOverloaded)
+	}
+	m.reportOutstandingBytes(ctx)
+	defer func() {
+		m.flowControl.release(size)
+		m.reportOutstandingBytes(ctx)
+	}()
 
+	// The pubsub client batches messages published concurrently to the same topic
+	// according to the publish settings, so the wait is bounded by the delay threshold
+	// plus a single publish round trip shared by the whole batch. The event is only
+	// accepted once it's durably published, so that the sender retries it otherwise;
+	// acknowledging it before the publish completes would silently drop failed events.
 	_, err = topic.Publish(ctx, msg).Get(ctx)
+	if err != nil && msg.OrderingKey != "" {
+		// A failed publish pauses publishing for the ordering key. Resume it so that
+		// the sender can retry the event.
+		topic.ResumePublish(msg.OrderingKey)
+	}
+	if errors.Is(err, bundler.ErrOverflow) {
+		return fmt.Errorf("%q: %w", broker, ErrOverloaded)
+	}
 	return err
 }
 
+// messageSize approximates the number of bytes a message takes in a publish request.
+func messageSize(msg *pubsub.Message) int64 {
+	size := len(msg.Data) + len(msg.OrderingKey)
+	for k, v := range msg.Attributes {
+		size += len(k) + len(v)
+	}
+	return int64(size)
+}
+
+func (m *multiTopicDecoupleSink) reportOutstandingBytes(ctx context.Context) {
+	if m.reporter == nil {
+		return
+	}
+	if err := m.reporter.ReportOutstandingPublishBytes(ctx, m.flowControl.outstandingBytes()); err != nil {
+		m.logger.Warn("Failed to record outstanding publish bytes metric.", zap.Error(err))
+	}
+}
+
 // getTopicForBroker finds the corresponding decouple topic for the broker from the mounted broker configmap volume.
-func (m *multiTopicDecoupleSink) getTopicForBroker(broker types.NamespacedName) (*pubsub.Topic, error) {
-	topicID, err := m.getTopicIDForBroker(broker)
+// It also returns the broker config the topic was looked up from.
+func (m *multiTopicDecoupleSink) getTopicForBroker(broker types.NamespacedName) (*pubsub.Topic, *config.Broker, error) {
+	brokerConfig, err := m.getBrokerConfig(broker)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if topic, ok := m.getExistingTopic(broker); ok {
-		// Check that the broker's topic ID hasn't changed.
-		if topic.ID() == topicID {
-			return topic, nil
+		// Check that the broker's topic settings haven't changed.
+		if topicMatches(topic, brokerConfig) {
+			return topic, brokerConfig, nil
 		}
 	}
 
@@ -103,46 +224,55 @@ func (m *multiTopicDecoupleSink) getTopicForBroker(broker types.NamespacedName)
 	return m.updateTopicForBroker(broker)
 }
 
-func (m *multiTopicDecoupleSink) updateTopicForBroker(broker types.NamespacedName) (*pubsub.Topic, error) {
+func (m *multiTopicDecoupleSink) updateTopicForBroker(broker types.NamespacedName) (*pubsub.Topic, *config.Broker, error) {
 	m.topicsMut.Lock()
 	defer m.topicsMut.Unlock()
-	// Fetch latest decouple topic ID under lock.
-	topicID, err := m.getTopicIDForBroker(broker)
+	// Fetch latest broker config under lock.
+	brokerConfig, err := m.getBrokerConfig(broker)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if topic, ok := m.topics[broker]; ok {
-		if topic.ID() == topicID {
+		if topicMatches(topic, brokerConfig) {
 			// Topic already updated.
-			return topic, nil
+			return topic, brokerConfig, nil
 		}
 		// Stop old topic.
 		m.topics[broker].Stop()
 	}
-	topic := m.pubsub.Topic(topicID)
+	topic := m.pubsub.Topic(brokerConfig.DecoupleQueue.Topic)
+	topic.PublishSettings = m.publishSettings
+	topic.EnableMessageOrdering = brokerConfig.OrderingKeyAttribute != ""
 	m.topics[broker] = topic
-	return topic, nil
+	return topic, brokerConfig, nil
+}
+
+// topicMatches returns true if the topic was created with the broker's current decouple
+// topic ID and ordering setting.
+func topicMatches(topic *pubsub.Topic, brokerConfig *config.Broker) bool {
+	return topic.ID() == brokerConfig.DecoupleQueue.Topic &&
+		topic.EnableMessageOrdering == (brokerConfig.OrderingKeyAttribute != "")
 }
 
-func (m *multiTopicDecoupleSink) getTopicIDForBroker(broker types.NamespacedName) (string, error) {
+func (m *multiTopicDecoupleSink) getBrokerConfig(broker types.NamespacedName) (*config.Broker, error) {
 	brokerConfig, ok := m.brokerConfig.GetBroker(broker.Namespace, broker.Name)
 	if !ok {
 		// There is an propagation delay between the controller reconciles the broker config and
 		// the config being pushed to the configmap volume in the ingress pod. So sometimes we return
 		// an error even if the request is valid.
 		m.logger.Warn("config is not found for", zap.String("broker", broker.String()))
-		return "", fmt.Errorf("%q: %w", broker, ErrNotFound)
+		return nil, fmt.Errorf("%q: %w", broker, ErrNotFound)
 	}
 	if brokerConfig.State != config.State_READY {
 		m.logger.Debug("broker is not ready", zap.Any("ns", broker.Namespace), zap.Any("broker", broker))
-		return "", fmt.Errorf("%q: %w", broker, ErrNotReady)
+		return nil, fmt.Errorf("%q: %w", broker, ErrNotReady)
 	}
 	if brokerConfig.DecoupleQueue == nil || brokerConfig.DecoupleQueue.Topic == "" {
 		m.logger.Error("DecoupleQueue or topic missing for broker, this should NOT happen.", zap.Any("brokerConfig", brokerConfig))
-		return "", fmt.Errorf("decouple queue of %q: %w", broker, ErrIncomplete)
+		return nil, fmt.Errorf("decouple queue of %q: %w", broker, ErrIncomplete)
 	}
-	return brokerConfig.DecoupleQueue.Topic, nil
+	return brokerConfig, nil
 }
 
 func (m *multiTopicDecoupleSink) getExistingTopic(broker types.NamespacedName) (*pubsub.Topic, bool) {
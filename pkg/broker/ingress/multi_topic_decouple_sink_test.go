@@ -18,6 +18,7 @@ package ingress
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -183,7 +184,7 @@ func TestMultiTopicDecoupleSink(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient)
+				sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, nil)
 				// Send events
 				event := createTestEvent(uuid.New().String())
 				err = sink.Send(context.Background(), testCase.broker, *event)
@@ -220,6 +221,129 @@ func TestMultiTopicDecoupleSink(t *testing.T) {
 	}
 }
 
+func TestMultiTopicDecoupleSinkOrderingKey(t *testing.T) {
+	cases := []struct {
+		name            string
+		orderingKeyAttr string
+		extensions      map[string]interface{}
+		wantOrderingKey string
+	}{{
+		name:            "unordered broker",
+		extensions:      map[string]interface{}{"partitionkey": "key1"},
+		wantOrderingKey: "",
+	}, {
+		name:            "ordered broker",
+		orderingKeyAttr: "partitionkey",
+		extensions:      map[string]interface{}{"partitionkey": "key1"},
+		wantOrderingKey: "key1",
+	}, {
+		name:            "ordered broker with non-string attribute",
+		orderingKeyAttr: "partitionkey",
+		extensions:      map[string]interface{}{"partitionkey": 42},
+		wantOrderingKey: "42",
+	}, {
+		name:            "ordered broker event without attribute",
+		orderingKeyAttr: "partitionkey",
+		wantOrderingKey: "",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logtest.TestContextWithLogger(t)
+			psSrv := pstest.NewServer()
+			defer psSrv.Close()
+			psClient := createPubsubClient(ctx, t, psSrv)
+			topic, err := psClient.CreateTopic(ctx, "test_topic")
+			if err != nil {
+				t.Fatal(err)
+			}
+			subscription, err := psClient.CreateSubscription(ctx, "test-sub", pubsub.SubscriptionConfig{
+				Topic:                 topic,
+				EnableMessageOrdering: tc.orderingKeyAttr != "",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			brokerConfig := memory.NewTargets(&config.TargetsConfig{
+				Brokers: map[string]*config.Broker{
+					"test_ns/test_broker": {
+						State:                config.State_READY,
+						DecoupleQueue:        &config.Queue{Topic: "test_topic"},
+						OrderingKeyAttribute: tc.orderingKeyAttr,
+					},
+				},
+			})
+
+			sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, nil)
+			event := createTestEvent(uuid.New().String())
+			for k, v := range tc.extensions {
+				event.SetExtension(k, v)
+			}
+			if err := sink.Send(context.Background(), types.NamespacedName{Namespace: "test_ns", Name: "test_broker"}, *event); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			rctx, cancel := context.WithCancel(ctx)
+			msgCh := make(chan *pubsub.Message, 1)
+			subscription.Receive(rctx,
+				func(ctx context.Context, m *pubsub.Message) {
+					select {
+					case msgCh <- m:
+						cancel()
+					case <-ctx.Done():
+					}
+					m.Ack()
+				},
+			)
+			msg := <-msgCh
+			if msg.OrderingKey != tc.wantOrderingKey {
+				t.Errorf("ordering key got=%q, want=%q", msg.OrderingKey, tc.wantOrderingKey)
+			}
+		})
+	}
+}
+
+func TestMultiTopicDecoupleSinkRateLimit(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+	psClient := createPubsubClient(ctx, t, psSrv)
+	if _, err := psClient.CreateTopic(ctx, "test_topic"); err != nil {
+		t.Fatal(err)
+	}
+	brokerConfig := memory.NewTargets(&config.TargetsConfig{
+		Brokers: map[string]*config.Broker{
+			"test_ns/test_broker": {
+				Name:          "test_broker",
+				Namespace:     "test_ns",
+				State:         config.State_READY,
+				DecoupleQueue: &config.Queue{Topic: "test_topic"},
+				// Refill slowly enough that the burst is the only allowance in the test.
+				RateLimit: &config.RateLimit{EventsPerSecond: 0.001, Burst: 2},
+			},
+		},
+	})
+	broker := types.NamespacedName{Namespace: "test_ns", Name: "test_broker"}
+
+	sink := NewMultiTopicDecoupleSink(ctx, brokerConfig, psClient, nil)
+	for i := 0; i < 2; i++ {
+		if err := sink.Send(ctx, broker, *createTestEvent(uuid.New().String())); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := sink.Send(ctx, broker, *createTestEvent(uuid.New().String())); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Send over the rate limit got error=%v, want=%v", err, ErrRateLimited)
+	}
+
+	// Removing the rate limit from the config takes effect on the next event.
+	brokerConfig.MutateBroker("test_ns", "test_broker", func(m config.BrokerMutation) {
+		m.SetRateLimit(nil)
+	})
+	if err := sink.Send(ctx, broker, *createTestEvent(uuid.New().String())); err != nil {
+		t.Errorf("Send without rate limit got unexpected error: %v", err)
+	}
+}
+
 type fakePubsubClient struct {
 	t *testing.T
 	// topics is the mapping from topic name to corresponding channel which contains the event.
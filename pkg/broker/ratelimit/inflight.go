@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+)
+
+// InFlight counts the in-flight requests per key, e.g. a target key, to cap
+// the number of concurrent requests.
+type InFlight struct {
+	mu     sync.Mutex
+	counts map[string]int32
+}
+
+// NewInFlight creates an empty InFlight.
+func NewInFlight() *InFlight {
+	return &InFlight{counts: make(map[string]int32)}
+}
+
+// Acquire reserves one of max in-flight slots of the key. It returns false if
+// all slots are taken. Otherwise the returned release func must be called once
+// the request finishes. A non-positive max or nil f doesn't limit anything.
+func (f *InFlight) Acquire(key string, max int32) (release func(), ok bool) {
+	if f == nil || max <= 0 {
+		return func() {}, true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts[key] >= max {
+		return nil, false
+	}
+	f.counts[key]++
+	var once sync.Once
+	return func() {
+		once.Do(func() { f.release(key) })
+	}, true
+}
+
+func (f *InFlight) release(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts[key] <= 1 {
+		delete(f.counts, key)
+		return
+	}
+	f.counts[key]--
+}
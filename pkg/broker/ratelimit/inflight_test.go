@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+)
+
+func TestInFlightAcquire(t *testing.T) {
+	f := NewInFlight()
+	release1, ok := f.Acquire("key", 2)
+	if !ok {
+		t.Fatal("Acquire got=false, want=true")
+	}
+	if _, ok := f.Acquire("key", 2); !ok {
+		t.Fatal("Acquire got=false, want=true")
+	}
+	if _, ok := f.Acquire("key", 2); ok {
+		t.Fatal("Acquire over max got=true, want=false")
+	}
+	// Other keys have their own slots.
+	if _, ok := f.Acquire("other", 2); !ok {
+		t.Error("Acquire other key got=false, want=true")
+	}
+
+	release1()
+	// Releasing twice frees only one slot.
+	release1()
+	if _, ok := f.Acquire("key", 2); !ok {
+		t.Fatal("Acquire after release got=false, want=true")
+	}
+	if _, ok := f.Acquire("key", 2); ok {
+		t.Error("Acquire over max after release got=true, want=false")
+	}
+}
+
+func TestInFlightUnlimited(t *testing.T) {
+	var nilInFlight *InFlight
+	for _, f := range []*InFlight{nilInFlight, NewInFlight()} {
+		for i := 0; i < 10; i++ {
+			release, ok := f.Acquire("key", 0)
+			if !ok {
+				t.Fatal("Acquire unlimited got=false, want=true")
+			}
+			release()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimit provides token bucket rate limiters and in-flight request
+// limits for brokers and targets configured in the broker targets config.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+// Limiters holds a rate limiter per key, e.g. a broker or target key.
+// A limiter follows changes of its configured rate limit without losing
+// the tokens it has accumulated, so config updates take effect without restarts.
+//
+// The limiters are local to the process, so a rate limit is enforced by each
+// data plane pod separately: the effective limit of a broker or target scales
+// with the number of ingress, fanout or retry replicas.
+type Limiters struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewLimiters creates an empty Limiters.
+func NewLimiters() *Limiters {
+	return &Limiters{limiters: make(map[string]*rate.Limiter)}
+}
+
+// Get returns the limiter of the key updated to the given rate limit.
+// It returns nil if the rate limit doesn't limit anything or l is nil.
+func (l *Limiters) Get(key string, rl *config.RateLimit) *rate.Limiter {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rl == nil || rl.EventsPerSecond <= 0 {
+		delete(l.limiters, key)
+		return nil
+	}
+
+	limit := rate.Limit(rl.EventsPerSecond)
+	burst := int(rl.Burst)
+	if burst <= 0 {
+		burst = int(math.Ceil(rl.EventsPerSecond))
+	}
+	lim, ok := l.limiters[key]
+	if !ok {
+		lim = rate.NewLimiter(limit, burst)
+		l.limiters[key] = lim
+		return lim
+	}
+	if lim.Limit() != limit {
+		lim.SetLimit(limit)
+	}
+	if lim.Burst() != burst {
+		lim.SetBurst(burst)
+	}
+	return lim
+}
+
+// Prune removes the limiters of the keys for which exists returns false,
+// e.g. the keys of deleted brokers or targets.
+func (l *Limiters) Prune(exists func(key string) bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key := range l.limiters {
+		if !exists(key) {
+			delete(l.limiters, key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestLimitersGet(t *testing.T) {
+	cases := []struct {
+		name      string
+		rl        *config.RateLimit
+		wantNil   bool
+		wantLimit rate.Limit
+		wantBurst int
+	}{{
+		name:    "no rate limit",
+		wantNil: true,
+	}, {
+		name:    "zero rate",
+		rl:      &config.RateLimit{},
+		wantNil: true,
+	}, {
+		name:      "default burst",
+		rl:        &config.RateLimit{EventsPerSecond: 2.5},
+		wantLimit: 2.5,
+		wantBurst: 3,
+	}, {
+		name:      "explicit burst",
+		rl:        &config.RateLimit{EventsPerSecond: 10, Burst: 20},
+		wantLimit: 10,
+		wantBurst: 20,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lim := NewLimiters().Get("key", tc.rl)
+			if tc.wantNil {
+				if lim != nil {
+					t.Errorf("Get got=%v, want=nil", lim)
+				}
+				return
+			}
+			if lim.Limit() != tc.wantLimit {
+				t.Errorf("limit got=%v, want=%v", lim.Limit(), tc.wantLimit)
+			}
+			if lim.Burst() != tc.wantBurst {
+				t.Errorf("burst got=%v, want=%v", lim.Burst(), tc.wantBurst)
+			}
+		})
+	}
+}
+
+func TestLimitersUpdate(t *testing.T) {
+	l := NewLimiters()
+	lim := l.Get("key", &config.RateLimit{EventsPerSecond: 1, Burst: 1})
+	if !lim.Allow() {
+		t.Fatal("Allow got=false, want=true")
+	}
+	if lim.Allow() {
+		t.Fatal("Allow over burst got=true, want=false")
+	}
+
+	// The same limiter is updated with the new limit.
+	updated := l.Get("key", &config.RateLimit{EventsPerSecond: 5, Burst: 10})
+	if updated != lim {
+		t.Error("Get returned a new limiter, want the existing one updated")
+	}
+	if updated.Limit() != 5 || updated.Burst() != 10 {
+		t.Errorf("updated limiter got limit=%v burst=%v, want limit=5 burst=10", updated.Limit(), updated.Burst())
+	}
+
+	// Removing the limit drops the limiter.
+	if got := l.Get("key", nil); got != nil {
+		t.Errorf("Get without limit got=%v, want=nil", got)
+	}
+	if got := l.Get("key", &config.RateLimit{EventsPerSecond: 1, Burst: 1}); got == lim {
+		t.Error("Get after the limit was removed returned the old limiter")
+	}
+}
+
+func TestLimitersPrune(t *testing.T) {
+	l := NewLimiters()
+	rl := &config.RateLimit{EventsPerSecond: 1, Burst: 1}
+	kept := l.Get("kept", rl)
+	pruned := l.Get("pruned", rl)
+
+	l.Prune(func(key string) bool { return key == "kept" })
+
+	if got := l.Get("kept", rl); got != kept {
+		t.Error("Get after prune returned a new limiter for a kept key")
+	}
+	if got := l.Get("pruned", rl); got == pruned {
+		t.Error("Get after prune returned the old limiter for a pruned key")
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package transform implements the declarative transformations applied to
+// events before their delivery to trigger subscribers: setting, removing and
+// renaming attributes, extracting fields of the JSON data into attributes with
+// JSONPath expressions (https://kubernetes.io/docs/reference/kubectl/jsonpath/)
+// and rendering the data with Go templates (https://golang.org/pkg/text/template/).
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/google/knative-gcp/pkg/broker/cesql"
+)
+
+// Operation is a compiled transformation of events.
+type Operation interface {
+	// Apply transforms the event in place.
+	Apply(e *event.Event) error
+}
+
+// Operations applies a list of operations in order.
+type Operations []Operation
+
+// Apply implements Operation.
+func (ops Operations) Apply(e *event.Event) error {
+	for _, op := range ops {
+		if err := op.Apply(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// See https://github.com/cloudevents/spec/blob/v1.0/spec.md#attribute-naming-convention
+var extensionNameRegexp = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+// requiredAttributes can be set but not removed.
+var requiredAttributes = map[string]bool{
+	"id":     true,
+	"source": true,
+	"type":   true,
+}
+
+// optionalAttributes can be set and removed.
+var optionalAttributes = map[string]bool{
+	"subject":         true,
+	"time":            true,
+	"dataschema":      true,
+	"datacontenttype": true,
+}
+
+func validateSettable(name string) error {
+	if requiredAttributes[name] || optionalAttributes[name] {
+		return nil
+	}
+	return validateExtension(name)
+}
+
+func validateRemovable(name string) error {
+	if requiredAttributes[name] {
+		return fmt.Errorf("required attribute %q cannot be removed", name)
+	}
+	if optionalAttributes[name] {
+		return nil
+	}
+	return validateExtension(name)
+}
+
+func validateExtension(name string) error {
+	if name == "specversion" || name == "data" {
+		return fmt.Errorf("attribute %q cannot be changed", name)
+	}
+	if !extensionNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid attribute name %q", name)
+	}
+	return nil
+}
+
+func setAttribute(e *event.Event, name, value string) error {
+	var err error
+	switch name {
+	case "id":
+		err = e.Context.SetID(value)
+	case "source":
+		err = e.Context.SetSource(value)
+	case "type":
+		err = e.Context.SetType(value)
+	case "subject":
+		err = e.Context.SetSubject(value)
+	case "dataschema":
+		err = e.Context.SetDataSchema(value)
+	case "datacontenttype":
+		err = e.Context.SetDataContentType(value)
+	case "time":
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339, value); err == nil {
+			err = e.Context.SetTime(t)
+		}
+	default:
+		err = e.Context.SetExtension(name, value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set attribute %q: %w", name, err)
+	}
+	return nil
+}
+
+func removeAttribute(e *event.Event, name string) error {
+	// Setting optional attributes to their zero value unsets them.
+	switch name {
+	case "subject":
+		return e.Context.SetSubject("")
+	case "dataschema":
+		return e.Context.SetDataSchema("")
+	case "datacontenttype":
+		return e.Context.SetDataContentType("")
+	case "time":
+		return e.Context.SetTime(time.Time{})
+	}
+	return e.Context.SetExtension(name, nil)
+}
+
+type setOp map[string]string
+
+// Set returns the operation setting attributes to the given values.
+func Set(values map[string]string) (Operation, error) {
+	for name, value := range values {
+		if err := validateSettable(name); err != nil {
+			return nil, err
+		}
+		if name == "time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return nil, fmt.Errorf("invalid time %q: %w", value, err)
+			}
+		}
+	}
+	return setOp(values), nil
+}
+
+func (op setOp) Apply(e *event.Event) error {
+	for name, value := range op {
+		if err := setAttribute(e, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type removeOp []string
+
+// Remove returns the operation removing attributes. Required attributes cannot be removed.
+func Remove(names []string) (Operation, error) {
+	for _, name := range names {
+		if err := validateRemovable(name); err != nil {
+			return nil, err
+		}
+	}
+	return removeOp(names), nil
+}
+
+func (op removeOp) Apply(e *event.Event) error {
+	for _, name := range op {
+		if err := removeAttribute(e, name); err != nil {
+			return fmt.Errorf("failed to remove attribute %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+type renameOp map[string]string
+
+// Rename returns the operation renaming attributes, keyed by their current name.
+// Attributes missing from an event are skipped.
+func Rename(names map[string]string) (Operation, error) {
+	for from, to := range names {
+		if err := validateRemovable(from); err != nil {
+			return nil, err
+		}
+		if err := validateSettable(to); err != nil {
+			return nil, err
+		}
+	}
+	return renameOp(names), nil
+}
+
+func (op renameOp) Apply(e *event.Event) error {
+	// Read all the values first, so that attributes can be swapped.
+	attrs := cesql.EventAttributes(e)
+	for from := range op {
+		if _, ok := attrs[from]; !ok {
+			continue
+		}
+		if err := removeAttribute(e, from); err != nil {
+			return fmt.Errorf("failed to remove attribute %q: %w", from, err)
+		}
+	}
+	for from, to := range op {
+		v, ok := attrs[from]
+		if !ok {
+			continue
+		}
+		if err := setAttribute(e, to, fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type extractOp map[string]*jsonpath.JSONPath
+
+// Extract returns the operation setting attributes to the fields of the JSON
+// data selected by the given JSONPath expressions, e.g. "{.order.id}". String
+// fields are set as is, other fields JSON-encoded. Attributes whose expression
+// selects nothing are left unchanged.
+func Extract(paths map[string]string) (Operation, error) {
+	op := make(extractOp, len(paths))
+	for name, path := range paths {
+		if err := validateSettable(name); err != nil {
+			return nil, err
+		}
+		j := jsonpath.New(name).AllowMissingKeys(true)
+		if err := j.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid JSONPath expression %q: %w", path, err)
+		}
+		op[name] = j
+	}
+	return op, nil
+}
+
+func (op extractOp) Apply(e *event.Event) error {
+	var data interface{}
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		return fmt.Errorf("failed to extract attributes from data: %w", err)
+	}
+	for name, j := range op {
+		results, err := j.FindResults(data)
+		if err != nil {
+			return fmt.Errorf("failed to extract attribute %q: %w", name, err)
+		}
+		if len(results) == 0 || len(results[0]) == 0 {
+			continue
+		}
+		value, err := formatValue(results[0][0])
+		if err != nil {
+			return fmt.Errorf("failed to extract attribute %q: %w", name, err)
+		}
+		if err := setAttribute(e, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "null", nil
+	}
+	if s, ok := v.Interface().(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v.Interface())
+	return string(b), err
+}
+
+var funcs = template.FuncMap{
+	// json encodes a value as JSON, e.g. {{ json .data.items }}.
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+type dataOp struct {
+	tmpl *template.Template
+}
+
+// Data returns the operation replacing the data with the output of a Go template.
+// The template is executed on the attributes of the event, with the data under
+// "data": parsed if it is JSON, a string otherwise. Referencing a missing key
+// fails the operation. The data content type is kept, so set it along if the
+// template changes the format.
+func Data(text string) (Operation, error) {
+	tmpl, err := template.New("data").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data template: %w", err)
+	}
+	return &dataOp{tmpl: tmpl}, nil
+}
+
+func (op *dataOp) Apply(e *event.Event) error {
+	vars := cesql.EventAttributes(e)
+	var data interface{}
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		data = string(e.Data())
+	}
+	vars["data"] = data
+
+	var buf bytes.Buffer
+	if err := op.tmpl.Execute(&buf, vars); err != nil {
+		return fmt.Errorf("failed to render data template: %w", err)
+	}
+	e.DataEncoded = buf.Bytes()
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testEvent(t *testing.T) *event.Event {
+	t.Helper()
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("type")
+	e.SetSubject("subject")
+	e.SetExtension("ext", "value")
+	if err := e.SetData(event.ApplicationJSON, map[string]interface{}{
+		"order": map[string]interface{}{"id": "order-1", "items": []int{1, 2}},
+	}); err != nil {
+		t.Fatalf("failed to set data: %v", err)
+	}
+	return &e
+}
+
+func TestOperations(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       func() (Operation, error)
+		wantErr  bool
+		want     func(*event.Event)
+		applyErr bool
+	}{{
+		name: "set attributes",
+		op: func() (Operation, error) {
+			return Set(map[string]string{"type": "new.type", "time": "2020-08-01T10:00:00Z", "newext": "new"})
+		},
+		want: func(e *event.Event) {
+			e.SetType("new.type")
+			e.SetTime(time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC))
+			e.SetExtension("newext", "new")
+		},
+	}, {
+		name:    "set specversion",
+		op:      func() (Operation, error) { return Set(map[string]string{"specversion": "0.3"}) },
+		wantErr: true,
+	}, {
+		name:    "set invalid time",
+		op:      func() (Operation, error) { return Set(map[string]string{"time": "yesterday"}) },
+		wantErr: true,
+	}, {
+		name:    "set invalid extension name",
+		op:      func() (Operation, error) { return Set(map[string]string{"Ext-Name": "value"}) },
+		wantErr: true,
+	}, {
+		name: "remove attributes",
+		op:   func() (Operation, error) { return Remove([]string{"subject", "ext", "missing"}) },
+		want: func(e *event.Event) {
+			e.SetSubject("")
+			e.SetExtension("ext", nil)
+		},
+	}, {
+		name:    "remove required attribute",
+		op:      func() (Operation, error) { return Remove([]string{"id"}) },
+		wantErr: true,
+	}, {
+		name: "rename attributes",
+		op: func() (Operation, error) {
+			return Rename(map[string]string{"ext": "subject", "subject": "oldsubject", "missing": "other"})
+		},
+		want: func(e *event.Event) {
+			e.SetSubject("value")
+			e.SetExtension("oldsubject", "subject")
+			e.SetExtension("ext", nil)
+		},
+	}, {
+		name:    "rename to invalid attribute",
+		op:      func() (Operation, error) { return Rename(map[string]string{"ext": "data"}) },
+		wantErr: true,
+	}, {
+		name: "extract data fields",
+		op: func() (Operation, error) {
+			return Extract(map[string]string{"orderid": "{.order.id}", "items": "{.order.items}", "missing": "{.order.missing}"})
+		},
+		want: func(e *event.Event) {
+			e.SetExtension("orderid", "order-1")
+			e.SetExtension("items", "[1,2]")
+		},
+	}, {
+		name:    "extract invalid JSONPath",
+		op:      func() (Operation, error) { return Extract(map[string]string{"orderid": "{.order.id"}) },
+		wantErr: true,
+	}, {
+		name: "render data",
+		op:   func() (Operation, error) { return Data(`{"id":{{ json .data.order.id }},"type":{{ json .type }}}`) },
+		want: func(e *event.Event) {
+			e.DataEncoded = []byte(`{"id":"order-1","type":"type"}`)
+		},
+	}, {
+		name:    "invalid data template",
+		op:      func() (Operation, error) { return Data(`{{ .data `) },
+		wantErr: true,
+	}, {
+		name:     "render data with missing key",
+		op:       func() (Operation, error) { return Data(`{{ .data.missing }}`) },
+		applyErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			op, err := tc.op()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("operation error got=%v, want error=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got := testEvent(t)
+			err = op.Apply(got)
+			if (err != nil) != tc.applyErr {
+				t.Fatalf("Apply() error got=%v, want error=%v", err, tc.applyErr)
+			}
+			if tc.applyErr {
+				return
+			}
+			want := testEvent(t)
+			tc.want(want)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Apply() (-want,+got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestExtractFromInvalidData(t *testing.T) {
+	op, err := Extract(map[string]string{"orderid": "{.order.id}"})
+	if err != nil {
+		t.Fatalf("Extract() got unexpected error: %v", err)
+	}
+	e := event.New()
+	e.SetData(event.TextPlain, "not json")
+	if err := op.Apply(&e); err == nil {
+		t.Error("Apply() on non-JSON data got no error")
+	}
+}
+
+func TestOperationsInOrder(t *testing.T) {
+	rename, _ := Rename(map[string]string{"subject": "topic"})
+	set, _ := Set(map[string]string{"subject": "new"})
+	got := testEvent(t)
+	if err := (Operations{rename, set}).Apply(got); err != nil {
+		t.Fatalf("Apply() got unexpected error: %v", err)
+	}
+	if got.Subject() != "new" {
+		t.Errorf("subject got=%v, want=%v", got.Subject(), "new")
+	}
+	if topic := got.Extensions()["topic"]; topic != "subject" {
+		t.Errorf("topic extension got=%v, want=%v", topic, "subject")
+	}
+}
@@ -67,6 +67,8 @@ func (c *pubsubClient) CreateSubscription(ctx context.Context, id string, cfg Su
 		RetainAckedMessages: cfg.RetainAckedMessages,
 		RetentionDuration:   cfg.RetentionDuration,
 		Labels:              cfg.Labels,
+		DeadLetterPolicy:    cfg.DeadLetterPolicy,
+		RetryPolicy:         cfg.RetryPolicy,
 	}
 	sub, err := c.client.CreateSubscription(ctx, id, pscfg)
 	if err != nil {
@@ -31,6 +31,8 @@ type SubscriptionConfig struct {
 	RetainAckedMessages bool
 	RetentionDuration   time.Duration
 	Labels              map[string]string
+	DeadLetterPolicy    *pubsub.DeadLetterPolicy
+	RetryPolicy         *pubsub.RetryPolicy
 }
 
 // pubsubSubscription wraps pubsub.Subscription. Is the subscription that will be used everywhere except unit tests.
@@ -58,6 +60,8 @@ func (s *pubsubSubscription) Config(ctx context.Context) (SubscriptionConfig, er
 		RetainAckedMessages: cfg.RetainAckedMessages,
 		RetentionDuration:   cfg.RetentionDuration,
 		Labels:              cfg.Labels,
+		DeadLetterPolicy:    cfg.DeadLetterPolicy,
+		RetryPolicy:         cfg.RetryPolicy,
 	}, nil
 }
 
@@ -68,6 +72,8 @@ func (s *pubsubSubscription) Update(ctx context.Context, cfg SubscriptionConfig)
 		RetainAckedMessages: cfg.RetainAckedMessages,
 		RetentionDuration:   cfg.RetentionDuration,
 		AckDeadline:         cfg.AckDeadline,
+		DeadLetterPolicy:    cfg.DeadLetterPolicy,
+		RetryPolicy:         cfg.RetryPolicy,
 	}
 	updatedConfig, err := s.sub.Update(ctx, config)
 	if err != nil {
@@ -79,6 +85,8 @@ func (s *pubsubSubscription) Update(ctx context.Context, cfg SubscriptionConfig)
 		RetainAckedMessages: updatedConfig.RetainAckedMessages,
 		RetentionDuration:   updatedConfig.RetentionDuration,
 		Labels:              updatedConfig.Labels,
+		DeadLetterPolicy:    updatedConfig.DeadLetterPolicy,
+		RetryPolicy:         updatedConfig.RetryPolicy,
 	}, err
 }
 
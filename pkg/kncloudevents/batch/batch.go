@@ -0,0 +1,174 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+)
+
+const (
+	// MaxBytes is the max size of a batch request body. It matches the max size
+	// of a Pub/Sub publish request.
+	MaxBytes = 10 * 1024 * 1024
+	// MaxEvents is the max number of events of a batch. It matches the max number
+	// of messages of a Pub/Sub publish request.
+	MaxEvents = 1000
+	// MaxConcurrency is the max number of events of a batch sent concurrently.
+	MaxConcurrency = 100
+)
+
+// ErrTooLarge is returned when a batch exceeds MaxBytes or MaxEvents.
+var ErrTooLarge = errors.New("batch too large")
+
+// Result is the delivery status of one event of a batch.
+type Result struct {
+	// ID is the ID of the event, if it could be read.
+	ID string `json:"id,omitempty"`
+	// Status is the HTTP status code the event would have got if sent alone.
+	Status int `json:"status"`
+	// Error describes why the event was not accepted.
+	Error string `json:"error,omitempty"`
+}
+
+// Entry is an entry read from a batch. Event is nil if the entry is not a valid
+// event, in which case Result holds the reason.
+type Entry struct {
+	Event  *cev2.Event
+	Result Result
+}
+
+// IsRequest returns true if the request carries events in the
+// CloudEvents JSON batch format.
+func IsRequest(request *nethttp.Request) bool {
+	mt, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	return err == nil && mt == cev2.ApplicationCloudEventsBatchJSON
+}
+
+// Read reads the entries of a batch request. It returns an error if the body is
+// not a JSON array, and an ErrTooLarge error if the batch exceeds MaxBytes or MaxEvents.
+// Entries that are not valid events are returned with a 400 result so that the rest of
+// the batch can still be processed. Events without a time are stamped with the current time.
+func Read(response nethttp.ResponseWriter, request *nethttp.Request) ([]Entry, error) {
+	// Reading one byte past MaxBytes tells a body of exactly MaxBytes from a larger one.
+	body, err := ioutil.ReadAll(nethttp.MaxBytesReader(response, request.Body, MaxBytes+1))
+	if len(body) > MaxBytes {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrTooLarge, MaxBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(body, &raws); err != nil {
+		return nil, fmt.Errorf("malformed batch: %w", err)
+	}
+	if len(raws) > MaxEvents {
+		return nil, fmt.Errorf("%w: %d events exceed %d", ErrTooLarge, len(raws), MaxEvents)
+	}
+
+	entries := make([]Entry, 0, len(raws))
+	for _, raw := range raws {
+		event := cev2.NewEvent()
+		if err := json.Unmarshal(raw, &event); err != nil {
+			entries = append(entries, Entry{Result: Result{
+				Status: nethttp.StatusBadRequest,
+				Error:  fmt.Sprintf("malformed event: %v", err),
+			}})
+			continue
+		}
+		if event.Time().IsZero() {
+			event.SetTime(time.Now())
+		}
+		if err := event.Validate(); err != nil {
+			entries = append(entries, Entry{Result: Result{
+				ID:     event.ID(),
+				Status: nethttp.StatusBadRequest,
+				Error:  fmt.Sprintf("invalid event: %v", err),
+			}})
+			continue
+		}
+		entries = append(entries, Entry{Event: &event, Result: Result{ID: event.ID()}})
+	}
+	return entries, nil
+}
+
+// ReadErrorStatus returns the HTTP status code of an error returned by Read.
+func ReadErrorStatus(err error) int {
+	if errors.Is(err, ErrTooLarge) {
+		return nethttp.StatusRequestEntityTooLarge
+	}
+	return nethttp.StatusBadRequest
+}
+
+// SendAll calls send for each event of the entries, with at most MaxConcurrency
+// concurrent calls, and returns the results of all entries. send returns the HTTP
+// status code the event would have got if sent alone, and the reason if it's not 202.
+func SendAll(entries []Entry, send func(event *cev2.Event) (int, error)) []Result {
+	results := make([]Result, len(entries))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < MaxConcurrency && w < len(entries); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				statusCode, err := send(entries[i].Event)
+				results[i].Status = statusCode
+				if statusCode != nethttp.StatusAccepted && err != nil {
+					results[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+	for i, e := range entries {
+		results[i] = e.Result
+		if e.Event != nil {
+			indexes <- i
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// WriteResponse writes the per-event results of a batch. The response status is
+// 202 if all events were accepted and 207 otherwise.
+func WriteResponse(response nethttp.ResponseWriter, results []Result) error {
+	statusCode := nethttp.StatusAccepted
+	for _, r := range results {
+		if r.Status != nethttp.StatusAccepted {
+			statusCode = nethttp.StatusMultiStatus
+			break
+		}
+	}
+	body, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(statusCode)
+	_, err = response.Write(body)
+	return err
+}
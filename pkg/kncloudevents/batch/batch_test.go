@@ -0,0 +1,245 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsRequest(t *testing.T) {
+	cases := []struct {
+		contentType string
+		want        bool
+	}{{
+		contentType: "application/cloudevents-batch+json",
+		want:        true,
+	}, {
+		contentType: "application/cloudevents-batch+json; charset=utf-8",
+		want:        true,
+	}, {
+		contentType: "application/cloudevents+json",
+	}, {
+		contentType: "",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			req.Header.Set("Content-Type", tc.contentType)
+			if got := IsRequest(req); got != tc.want {
+				t.Errorf("IsRequest got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        string
+		wantErr     bool
+		wantResults []Result
+	}{{
+		name:    "not an array",
+		body:    `{"specversion":"1.0"}`,
+		wantErr: true,
+	}, {
+		name:        "empty batch",
+		body:        `[]`,
+		wantResults: []Result{},
+	}, {
+		name: "valid and invalid events",
+		body: `[
+			{"specversion":"1.0","id":"1","source":"src","type":"type"},
+			{"specversion":"1.0","id":"2","source":"src"},
+			"not an event"
+		]`,
+		wantResults: []Result{
+			{ID: "1"},
+			{ID: "2", Status: nethttp.StatusBadRequest},
+			{Status: nethttp.StatusBadRequest},
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			entries, err := Read(httptest.NewRecorder(), req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Read got error=%v, want=%v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			results := make([]Result, 0, len(entries))
+			for _, e := range entries {
+				if (e.Event == nil) != (e.Result.Status != 0) {
+					t.Errorf("event %q got event=%v with status=%d", e.Result.ID, e.Event, e.Result.Status)
+				}
+				if e.Event != nil && e.Event.Time().IsZero() {
+					t.Errorf("event %q is not stamped with time", e.Event.ID())
+				}
+				// Only compare the ID and status.
+				results = append(results, Result{ID: e.Result.ID, Status: e.Result.Status})
+			}
+			if diff := cmp.Diff(tc.wantResults, results); diff != "" {
+				t.Errorf("Read results (-want,+got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestReadTooLarge(t *testing.T) {
+	events := make([]string, MaxEvents+1)
+	for i := range events {
+		events[i] = `{"specversion":"1.0","id":"1","source":"src","type":"type"}`
+	}
+	cases := []struct {
+		name string
+		body string
+	}{{
+		name: "too many events",
+		body: "[" + strings.Join(events, ",") + "]",
+	}, {
+		name: "body too large",
+		body: `["` + strings.Repeat("a", MaxBytes) + `"]`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			_, err := Read(httptest.NewRecorder(), req)
+			if !errors.Is(err, ErrTooLarge) {
+				t.Fatalf("Read got error=%v, want=%v", err, ErrTooLarge)
+			}
+			if got := ReadErrorStatus(err); got != nethttp.StatusRequestEntityTooLarge {
+				t.Errorf("ReadErrorStatus got=%d, want=%d", got, nethttp.StatusRequestEntityTooLarge)
+			}
+		})
+	}
+}
+
+func TestReadMaxBytes(t *testing.T) {
+	// A batch of a single event padded with whitespace to exactly MaxBytes.
+	event := `[{"specversion":"1.0","id":"1","source":"src","type":"type"}]`
+	body := event + strings.Repeat(" ", MaxBytes-len(event))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	entries, err := Read(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("Read of %d bytes got error=%v", MaxBytes, err)
+	}
+	if len(entries) != 1 || entries[0].Event == nil {
+		t.Errorf("Read got entries=%v, want a single event", entries)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(body+" "))
+	if _, err := Read(httptest.NewRecorder(), req); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Read of %d bytes got error=%v, want=%v", MaxBytes+1, err, ErrTooLarge)
+	}
+}
+
+func TestSendAll(t *testing.T) {
+	entries := []Entry{{Result: Result{Status: nethttp.StatusBadRequest, Error: "invalid event"}}}
+	for i := 0; i < 3*MaxConcurrency; i++ {
+		e := cev2.NewEvent()
+		e.SetID(strconv.Itoa(i))
+		entries = append(entries, Entry{Event: &e, Result: Result{ID: e.ID()}})
+	}
+
+	var inFlight, maxInFlight int32
+	results := SendAll(entries, func(event *cev2.Event) (int, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if event.ID() == "0" {
+			return nethttp.StatusInternalServerError, errors.New("boom")
+		}
+		return nethttp.StatusAccepted, nil
+	})
+
+	if got := atomic.LoadInt32(&maxInFlight); got > MaxConcurrency {
+		t.Errorf("concurrent sends got=%d, want at most %d", got, MaxConcurrency)
+	}
+	if len(results) != len(entries) {
+		t.Fatalf("results got=%d, want=%d", len(results), len(entries))
+	}
+	want := Result{Status: nethttp.StatusBadRequest, Error: "invalid event"}
+	if diff := cmp.Diff(want, results[0]); diff != "" {
+		t.Errorf("invalid entry result (-want,+got): %v", diff)
+	}
+	want = Result{ID: "0", Status: nethttp.StatusInternalServerError, Error: "boom"}
+	if diff := cmp.Diff(want, results[1]); diff != "" {
+		t.Errorf("failed entry result (-want,+got): %v", diff)
+	}
+	for _, r := range results[2:] {
+		if r.Status != nethttp.StatusAccepted || r.Error != "" {
+			t.Errorf("event %q got status=%d error=%q, want accepted", r.ID, r.Status, r.Error)
+		}
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	cases := []struct {
+		name       string
+		results    []Result
+		wantStatus int
+	}{{
+		name:       "all accepted",
+		results:    []Result{{ID: "1", Status: 202}, {ID: "2", Status: 202}},
+		wantStatus: nethttp.StatusAccepted,
+	}, {
+		name:       "partially accepted",
+		results:    []Result{{ID: "1", Status: 202}, {ID: "2", Status: 500, Error: "boom"}},
+		wantStatus: nethttp.StatusMultiStatus,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := WriteResponse(w, tc.results); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tc.wantStatus {
+				t.Errorf("status got=%d, want=%d", w.Code, tc.wantStatus)
+			}
+			var got []Result
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.results, got); diff != "" {
+				t.Errorf("response body (-want,+got): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+)
+
+func (r *ConfigReporter) register() error {
+	tagKeys := []tag.Key{
+		PodNameKey,
+		ContainerNameKey,
+	}
+
+	return metrics.RegisterResourceView(
+		&view.View{
+			Name:        r.generationM.Name(),
+			Description: r.generationM.Description(),
+			Measure:     r.generationM,
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Name:        r.propagationLatencyM.Name(),
+			Description: r.propagationLatencyM.Description(),
+			Measure:     r.propagationLatencyM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...), // 1, 2, 5, 10, 20, 50, 100, ..., 100000
+			TagKeys:     tagKeys,
+		},
+	)
+}
+
+// NewConfigReporter creates a new ConfigReporter.
+func NewConfigReporter(podName PodName, containerName ContainerName) (*ConfigReporter, error) {
+	r := &ConfigReporter{
+		podName:       podName,
+		containerName: containerName,
+		generationM: stats.Int64(
+			"targets_config_generation",
+			"The generation of the broker targets config applied by the data plane",
+			stats.UnitDimensionless,
+		),
+		propagationLatencyM: stats.Float64(
+			"targets_config_propagation_latencies",
+			"The time from the controller stamping a broker targets config generation to the data plane applying it",
+			stats.UnitMilliseconds,
+		),
+	}
+	if err := r.register(); err != nil {
+		return nil, fmt.Errorf("failed to register config stats: %w", err)
+	}
+	return r, nil
+}
+
+// ConfigReporter reports the propagation of the broker targets config to the data plane.
+type ConfigReporter struct {
+	podName       PodName
+	containerName ContainerName
+	// generationM is the generation of the applied targets config.
+	generationM *stats.Int64Measure
+	// propagationLatencyM is the delay of applying a new generation of the targets config.
+	propagationLatencyM *stats.Float64Measure
+}
+
+// ReportConfigApplied reports a new generation of the targets config applied by the data
+// plane after the given propagation delay.
+func (r *ConfigReporter) ReportConfigApplied(ctx context.Context, generation int64, delay time.Duration) error {
+	tag, err := tag.New(
+		ctx,
+		tag.Insert(PodNameKey, string(r.podName)),
+		tag.Insert(ContainerNameKey, string(r.containerName)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics tag: %v", err)
+	}
+	metrics.Record(tag, r.generationM.M(generation))
+	metrics.Record(tag, r.propagationLatencyM.M(float64(delay/time.Millisecond)))
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	_ "knative.dev/pkg/metrics/testing"
+
+	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestReportConfigApplied(t *testing.T) {
+	reportertest.ResetConfigMetrics()
+
+	wantTags := map[string]string{
+		metricskey.ContainerName: "testcontainer",
+		metricskey.PodName:       "testpod",
+	}
+
+	r, err := NewConfigReporter(PodName("testpod"), ContainerName("testcontainer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reportertest.ExpectMetrics(t, func() error {
+		return r.ReportConfigApplied(context.Background(), 1, 100*time.Millisecond)
+	})
+	reportertest.ExpectMetrics(t, func() error {
+		return r.ReportConfigApplied(context.Background(), 2, 300*time.Millisecond)
+	})
+	metricstest.CheckLastValueData(t, "targets_config_generation", wantTags, 2)
+	metricstest.CheckDistributionData(t, "targets_config_propagation_latencies", wantTags, 2, 100, 300)
+}
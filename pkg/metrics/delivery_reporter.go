@@ -35,11 +35,26 @@ const (
 	startDeliveryProcessingTime DeliveryMetricsKey = iota
 )
 
+// Results of handling a reply of a Trigger subscriber.
+const (
+	// ReplyResultSent means the reply was accepted by the reply destination.
+	ReplyResultSent = "sent"
+	// ReplyResultFailed means the reply destination didn't accept the reply.
+	ReplyResultFailed = "failed"
+	// ReplyResultInvalid means the reply was malformed or missed required attributes.
+	ReplyResultInvalid = "invalid"
+	// ReplyResultDropped means the reply was dropped because the event exhausted its hops or
+	// the replies of the target are disabled.
+	ReplyResultDropped = "dropped"
+)
+
 type DeliveryReporter struct {
 	podName               PodName
 	containerName         ContainerName
 	dispatchTimeInMsecM   *stats.Float64Measure
 	processingTimeInMsecM *stats.Float64Measure
+	circuitBreakerOpenM   *stats.Int64Measure
+	replyCountM           *stats.Int64Measure
 }
 
 func (r *DeliveryReporter) register() error {
@@ -90,6 +105,37 @@ func (r *DeliveryReporter) register() error {
 				ContainerNameKey,
 			},
 		},
+		&view.View{
+			Name:        r.circuitBreakerOpenM.Name(),
+			Description: r.circuitBreakerOpenM.Description(),
+			Measure:     r.circuitBreakerOpenM,
+			Aggregation: view.LastValue(),
+			TagKeys: []tag.Key{
+				NamespaceNameKey,
+				BrokerNameKey,
+				TriggerNameKey,
+				TriggerFilterTypeKey,
+				PodNameKey,
+				ContainerNameKey,
+			},
+		},
+		&view.View{
+			Name:        r.replyCountM.Name(),
+			Description: r.replyCountM.Description(),
+			Measure:     r.replyCountM,
+			Aggregation: view.Count(),
+			TagKeys: []tag.Key{
+				NamespaceNameKey,
+				BrokerNameKey,
+				TriggerNameKey,
+				TriggerFilterTypeKey,
+				ReplyResultKey,
+				ResponseCodeKey,
+				ResponseCodeClassKey,
+				PodNameKey,
+				ContainerNameKey,
+			},
+		},
 	)
 }
 
@@ -112,6 +158,19 @@ func NewDeliveryReporter(podName PodName, containerName ContainerName) (*Deliver
 			"The time spent processing an event before it is dispatched to a Trigger subscriber",
 			stats.UnitMilliseconds,
 		),
+		// circuitBreakerOpenM records whether the circuit breaker of a Trigger
+		// subscriber is open (1) or closed (0).
+		circuitBreakerOpenM: stats.Int64(
+			"circuit_breaker_open",
+			"Whether the circuit breaker of a Trigger subscriber is open",
+			stats.UnitDimensionless,
+		),
+		// replyCountM records the replies of Trigger subscribers.
+		replyCountM: stats.Int64(
+			"reply_count",
+			"Number of replies from Trigger subscribers",
+			stats.UnitDimensionless,
+		),
 	}
 
 	if err := r.register(); err != nil {
@@ -131,6 +190,28 @@ func (r *DeliveryReporter) ReportEventDispatchTime(ctx context.Context, d time.D
 	)
 }
 
+// ReportCircuitBreakerState captures whether the circuit breaker of the target in the context is open.
+func (r *DeliveryReporter) ReportCircuitBreakerState(ctx context.Context, open bool) {
+	var v int64
+	if open {
+		v = 1
+	}
+	metrics.Record(ctx, r.circuitBreakerOpenM.M(v))
+}
+
+// ReportReplyCount captures a reply of the target in the context with the result of handling it.
+// The response code of the reply destination is only reported if the reply was sent.
+func (r *DeliveryReporter) ReportReplyCount(ctx context.Context, result string, responseCode int) {
+	mutators := []tag.Mutator{tag.Insert(ReplyResultKey, result)}
+	if responseCode > 0 {
+		mutators = append(mutators,
+			tag.Insert(ResponseCodeKey, strconv.Itoa(responseCode)),
+			tag.Insert(ResponseCodeClassKey, metrics.ResponseCodeClass(responseCode)),
+		)
+	}
+	metrics.Record(ctx, r.replyCountM.M(1), stats.WithTags(mutators...))
+}
+
 // StartEventProcessing records the start of event processing for delivery within the given context.
 func StartEventProcessing(ctx context.Context) context.Context {
 	return context.WithValue(ctx, startDeliveryProcessingTime, time.Now())
@@ -161,3 +161,98 @@ func TestMetricsWithEmptySourceAndTypeFilter(t *testing.T) {
 	})
 	metricstest.CheckCountData(t, "event_count", wantTags, 1)
 }
+
+func TestReportCircuitBreakerState(t *testing.T) {
+	reportertest.ResetDeliveryMetrics()
+
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelBrokerName:    "testbroker",
+		metricskey.LabelTriggerName:   "testtrigger",
+		metricskey.LabelFilterType:    "any",
+		metricskey.PodName:            "testpod",
+		metricskey.ContainerName:      "testcontainer",
+	}
+
+	r, err := NewDeliveryReporter("testpod", "testcontainer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := r.AddTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err = AddTargetTags(ctx, &config.Target{
+		Namespace: "testns",
+		Broker:    "testbroker",
+		Name:      "testtrigger",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.ReportCircuitBreakerState(ctx, true)
+	metricstest.CheckLastValueData(t, "circuit_breaker_open", wantTags, 1)
+	r.ReportCircuitBreakerState(ctx, false)
+	metricstest.CheckLastValueData(t, "circuit_breaker_open", wantTags, 0)
+}
+
+func TestReportReplyCount(t *testing.T) {
+	cases := []struct {
+		name         string
+		result       string
+		responseCode int
+		wantTags     map[string]string
+	}{{
+		name:         "sent",
+		result:       ReplyResultSent,
+		responseCode: 202,
+		wantTags: map[string]string{
+			"reply_result":                    "sent",
+			metricskey.LabelResponseCode:      "202",
+			metricskey.LabelResponseCodeClass: "2xx",
+		},
+	}, {
+		name:   "invalid",
+		result: ReplyResultInvalid,
+		wantTags: map[string]string{
+			"reply_result": "invalid",
+		},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetDeliveryMetrics()
+			wantTags := map[string]string{
+				metricskey.LabelNamespaceName: "testns",
+				metricskey.LabelBrokerName:    "testbroker",
+				metricskey.LabelTriggerName:   "testtrigger",
+				metricskey.LabelFilterType:    "any",
+				metricskey.PodName:            "testpod",
+				metricskey.ContainerName:      "testcontainer",
+			}
+			for k, v := range tc.wantTags {
+				wantTags[k] = v
+			}
+
+			r, err := NewDeliveryReporter("testpod", "testcontainer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err := r.AddTags(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err = AddTargetTags(ctx, &config.Target{
+				Namespace: "testns",
+				Broker:    "testbroker",
+				Name:      "testtrigger",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.ReportReplyCount(ctx, tc.result, tc.responseCode)
+			metricstest.CheckCountData(t, "reply_count", wantTags, 1)
+		})
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"knative.dev/pkg/metrics"
 )
 
+const unknownPublisher = "unknown"
+
 // stats_exporter is adapted from knative.dev/eventing/pkg/broker/ingress/stats_reporter.go
 // with the following changes:
 // - Metric descriptions are updated to match GCP broker specifics.
@@ -39,6 +41,14 @@ type IngressReportArgs struct {
 	ResponseCode int
 }
 
+// IngressAuthReportArgs are the arguments to report an authenticated ingress request.
+type IngressAuthReportArgs struct {
+	Namespace    string
+	Broker       string
+	Publisher    string
+	ResponseCode int
+}
+
 func (r *IngressReporter) register() error {
 	tagKeys := []tag.Key{
 		NamespaceNameKey,
@@ -59,6 +69,28 @@ func (r *IngressReporter) register() error {
 			Aggregation: view.Count(),
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Name:        r.outstandingBytesM.Name(),
+			Description: r.outstandingBytesM.Description(),
+			Measure:     r.outstandingBytesM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{PodNameKey, ContainerNameKey},
+		},
+		&view.View{
+			Name:        r.authCountM.Name(),
+			Description: r.authCountM.Description(),
+			Measure:     r.authCountM,
+			Aggregation: view.Count(),
+			TagKeys: []tag.Key{
+				NamespaceNameKey,
+				BrokerNameKey,
+				PublisherKey,
+				ResponseCodeKey,
+				ResponseCodeClassKey,
+				PodNameKey,
+				ContainerNameKey,
+			},
+		},
 	)
 }
 
@@ -72,6 +104,16 @@ func NewIngressReporter(podName PodName, containerName ContainerName) (*IngressR
 			"Number of events received by a Broker",
 			stats.UnitDimensionless,
 		),
+		outstandingBytesM: stats.Int64(
+			"outstanding_publish_bytes",
+			"Number of bytes of events being published to Pub/Sub by the ingress",
+			stats.UnitBytes,
+		),
+		authCountM: stats.Int64(
+			"publisher_auth_count",
+			"Number of requests authenticated by the ingress of Brokers with allowed publishers",
+			stats.UnitDimensionless,
+		),
 	}
 	if err := r.register(); err != nil {
 		return nil, fmt.Errorf("failed to register ingress stats: %w", err)
@@ -84,6 +126,10 @@ type IngressReporter struct {
 	podName       PodName
 	containerName ContainerName
 	eventCountM   *stats.Int64Measure
+	// outstandingBytesM is the number of bytes being published.
+	outstandingBytesM *stats.Int64Measure
+	// authCountM is the number of authenticated requests.
+	authCountM *stats.Int64Measure
 }
 
 func (r *IngressReporter) ReportEventCount(ctx context.Context, args IngressReportArgs) error {
@@ -103,3 +149,41 @@ func (r *IngressReporter) ReportEventCount(ctx context.Context, args IngressRepo
 	metrics.Record(tag, r.eventCountM.M(1))
 	return nil
 }
+
+// ReportOutstandingPublishBytes reports the number of bytes being published.
+func (r *IngressReporter) ReportOutstandingPublishBytes(ctx context.Context, bytes int64) error {
+	tag, err := tag.New(
+		ctx,
+		tag.Insert(PodNameKey, string(r.podName)),
+		tag.Insert(ContainerNameKey, string(r.containerName)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics tag: %v", err)
+	}
+	metrics.Record(tag, r.outstandingBytesM.M(bytes))
+	return nil
+}
+
+// ReportAuthCount reports a request authenticated by the ingress. Requests without a
+// verified identity are reported with the "unknown" publisher.
+func (r *IngressReporter) ReportAuthCount(ctx context.Context, args IngressAuthReportArgs) error {
+	publisher := args.Publisher
+	if publisher == "" {
+		publisher = unknownPublisher
+	}
+	tag, err := tag.New(
+		ctx,
+		tag.Insert(PodNameKey, string(r.podName)),
+		tag.Insert(ContainerNameKey, string(r.containerName)),
+		tag.Insert(NamespaceNameKey, args.Namespace),
+		tag.Insert(BrokerNameKey, args.Broker),
+		tag.Insert(PublisherKey, publisher),
+		tag.Insert(ResponseCodeKey, strconv.Itoa(args.ResponseCode)),
+		tag.Insert(ResponseCodeClassKey, metrics.ResponseCodeClass(args.ResponseCode)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics tag: %v", err)
+	}
+	metrics.Record(tag, r.authCountM.M(1))
+	return nil
+}
@@ -18,11 +18,13 @@ package metrics
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	_ "knative.dev/pkg/metrics/testing"
 
 	reportertest "github.com/google/knative-gcp/pkg/metrics/testing"
+	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 )
@@ -94,3 +96,71 @@ func TestStatsReporterWithCustomEventType(t *testing.T) {
 	})
 	metricstest.CheckCountData(t, "event_count", wantTags, 2)
 }
+
+func TestReportOutstandingPublishBytes(t *testing.T) {
+	reportertest.ResetIngressMetrics()
+
+	wantTags := map[string]string{
+		metricskey.ContainerName: "testcontainer",
+		metricskey.PodName:       "testpod",
+	}
+
+	r, err := NewIngressReporter(PodName("testpod"), ContainerName("testcontainer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reportertest.ExpectMetrics(t, func() error {
+		return r.ReportOutstandingPublishBytes(context.Background(), 100)
+	})
+	reportertest.ExpectMetrics(t, func() error {
+		return r.ReportOutstandingPublishBytes(context.Background(), 42)
+	})
+	metricstest.CheckLastValueData(t, "outstanding_publish_bytes", wantTags, 42)
+}
+
+func TestReportAuthCount(t *testing.T) {
+	cases := []struct {
+		name          string
+		publisher     string
+		responseCode  int
+		wantPublisher string
+	}{{
+		name:          "authenticated publisher",
+		publisher:     "system:serviceaccount:testns:publisher",
+		responseCode:  202,
+		wantPublisher: "system:serviceaccount:testns:publisher",
+	}, {
+		name:          "unauthenticated request",
+		responseCode:  401,
+		wantPublisher: "unknown",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reportertest.ResetIngressMetrics()
+
+			r, err := NewIngressReporter(PodName("testpod"), ContainerName("testcontainer"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := IngressAuthReportArgs{
+				Namespace:    "testns",
+				Broker:       "testbroker",
+				Publisher:    tc.publisher,
+				ResponseCode: tc.responseCode,
+			}
+			reportertest.ExpectMetrics(t, func() error {
+				return r.ReportAuthCount(context.Background(), args)
+			})
+			metricstest.CheckCountData(t, "publisher_auth_count", map[string]string{
+				metricskey.LabelNamespaceName:     "testns",
+				metricskey.LabelBrokerName:        "testbroker",
+				"publisher":                       tc.wantPublisher,
+				metricskey.LabelResponseCode:      strconv.Itoa(tc.responseCode),
+				metricskey.LabelResponseCodeClass: metrics.ResponseCodeClass(tc.responseCode),
+				metricskey.ContainerName:          "testcontainer",
+				metricskey.PodName:                "testpod",
+			}, 1)
+		})
+	}
+}
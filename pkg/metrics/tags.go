@@ -44,6 +44,12 @@ var (
 	ResponseCodeKey      = tag.MustNewKey(metricskey.LabelResponseCode)
 	ResponseCodeClassKey = tag.MustNewKey(metricskey.LabelResponseCodeClass)
 
+	// PublisherKey is the identity of the authenticated publisher of an event.
+	PublisherKey = tag.MustNewKey("publisher")
+
+	// ReplyResultKey is the result of handling a reply of a Trigger subscriber.
+	ReplyResultKey = tag.MustNewKey("reply_result")
+
 	PodNameKey       = tag.MustNewKey(metricskey.PodName)
 	ContainerNameKey = tag.MustNewKey(metricskey.ContainerName)
 )
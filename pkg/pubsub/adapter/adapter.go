@@ -17,8 +17,11 @@ limitations under the License.
 package adapter
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	nethttp "net/http"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -30,6 +33,7 @@ import (
 	"github.com/google/knative-gcp/pkg/apis/messaging"
 	. "github.com/google/knative-gcp/pkg/pubsub/adapter/context"
 	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"github.com/google/knative-gcp/pkg/tracing"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"go.opencensus.io/trace"
@@ -55,6 +59,28 @@ type AdapterArgs struct {
 
 	// ConverterType use to select which converter to use.
 	ConverterType converters.ConverterType
+
+	// SendMode is the format of the events sent to the sink. Defaults to
+	// CloudEvents binary mode.
+	SendMode converters.ModeType
+
+	// MaxDeliveryAttempts is the number of attempts to deliver an event before
+	// giving up on it. Zero means the event is retried until it expires. Unless
+	// Pub/Sub reports the delivery attempts, they are counted per replica.
+	MaxDeliveryAttempts int
+
+	// DeadLetterSinkURI is the URI where to send the events that couldn't be
+	// delivered after MaxDeliveryAttempts. If empty, such events are dropped.
+	DeadLetterSinkURI string
+
+	// BatchMaxEvents is the maximum number of events delivered to the sink in
+	// a single batch. Zero means events are delivered one at a time. Batching
+	// isn't supported with a transformer.
+	BatchMaxEvents int
+
+	// BatchMaxLatency is the maximum time an event waits for its batch to
+	// fill up before the batch is delivered.
+	BatchMaxLatency time.Duration
 }
 
 // Adapter implements the Pub/Sub adapter to deliver Pub/Sub messages from a
@@ -87,6 +113,14 @@ type Adapter struct {
 	// cancel is function to stop pulling messages.
 	cancel context.CancelFunc
 
+	// attempts counts the delivery attempts of the messages when Pub/Sub
+	// doesn't report them.
+	attempts *deliveryAttempts
+
+	// batcher accumulates the events delivered in batches. Nil if events are
+	// delivered one at a time.
+	batcher *batcher
+
 	logger *zap.Logger
 }
 
@@ -102,7 +136,7 @@ func NewAdapter(
 	converter converters.Converter,
 	reporter StatsReporter,
 	args *AdapterArgs) *Adapter {
-	return &Adapter{
+	a := &Adapter{
 		subscription:   subscription,
 		projectID:      string(projectID),
 		namespacedName: types.NamespacedName{Namespace: string(namespace), Name: string(name)},
@@ -111,8 +145,13 @@ func NewAdapter(
 		converter:      converter,
 		reporter:       reporter,
 		args:           args,
+		attempts:       newDeliveryAttempts(deliveryAttemptsTTL),
 		logger:         logging.FromContext(ctx),
 	}
+	if args.BatchMaxEvents > 0 && args.TransformerURI == "" {
+		a.batcher = newBatcher(args.BatchMaxEvents, args.BatchMaxLatency, a.deliverBatch)
+	}
+	return a
 }
 
 func (a *Adapter) Start(ctx context.Context) error {
@@ -142,6 +181,24 @@ func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 		return
 	}
 
+	if a.args.SendMode == converters.Push {
+		if err := a.setPushData(event, msg); err != nil {
+			a.logger.Debug("Failed to set the push compatible data of the event", zap.Error(err))
+			// Ack the message so it won't be retried, we consider all errors to be non-retryable.
+			msg.Ack()
+			return
+		}
+	}
+
+	if a.batcher != nil {
+		// Apply CloudEvent override extensions to the outbound event.
+		for k, v := range a.args.Extensions {
+			event.SetExtension(k, v)
+		}
+		a.batcher.add(ctx, msg, event)
+		return
+	}
+
 	ctx, span := a.startSpan(ctx, event)
 	defer span.End()
 
@@ -161,7 +218,7 @@ func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 		resp, err := a.sendMsg(ctx, a.args.TransformerURI, (*binding.EventMessage)(event))
 		if err != nil {
 			a.logger.Error("Failed to send message to transformer", zap.String("address", a.args.TransformerURI), zap.Error(err))
-			msg.Nack()
+			a.nack(ctx, msg, event)
 			return
 		}
 
@@ -175,26 +232,27 @@ func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 
 		if resp.StatusCode/100 != 2 {
 			a.logger.Error("Event delivery failed", zap.Int("StatusCode", resp.StatusCode))
-			msg.Nack()
+			a.nack(ctx, msg, event)
 			return
 		}
 
 		respMsg := cehttp.NewMessageFromHttpResponse(resp)
 		if respMsg.ReadEncoding() == binding.EncodingUnknown {
 			// No reply
-			msg.Ack()
+			a.ack(msg)
 			return
 		}
 
 		// If there was a reply, we need to send it to the sink.
 		// We then overwrite the initial event we sent.
-		event, err = binding.ToEvent(ctx, respMsg)
+		replyEvent, err := binding.ToEvent(ctx, respMsg)
 		if err != nil {
 			a.logger.Error("Failed to convert response message to event",
 				zap.Any("response", respMsg), zap.Error(err))
-			msg.Nack()
+			a.nack(ctx, msg, event)
 			return
 		}
+		event = replyEvent
 
 		// Update the arguments used to report metrics
 		args.EventType = event.Type()
@@ -215,7 +273,7 @@ func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 	response, err := a.sendMsg(ctx, a.args.SinkURI, (*binding.EventMessage)(event))
 	if err != nil {
 		a.logger.Error("Failed to send message to sink", zap.String("address", a.args.SinkURI), zap.Error(err))
-		msg.Nack()
+		a.nack(ctx, msg, event)
 		return
 	}
 
@@ -229,14 +287,152 @@ func (a *Adapter) receive(ctx context.Context, msg *pubsub.Message) {
 
 	if response.StatusCode/100 != 2 {
 		a.logger.Error("Event delivery failed", zap.Int("StatusCode", response.StatusCode))
-		msg.Nack()
+		a.nack(ctx, msg, event)
+		return
+	}
+
+	a.ack(msg)
+}
+
+// deliverBatch sends a batch of events to the sink in the CloudEvents JSON
+// batch format, then acks or nacks all of their messages depending on the
+// response.
+func (a *Adapter) deliverBatch(ctx context.Context, batch []*batchedEvent) {
+	ctx, span := trace.StartSpan(ctx, a.spanName())
+	defer span.End()
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			kntracing.MessagingSystemAttribute,
+			tracing.PubSubProtocolAttribute,
+			trace.Int64Attribute("messaging.batch_size", int64(len(batch))),
+		)
+	}
+
+	events := make([]*cev2.Event, 0, len(batch))
+	for _, b := range batch {
+		events = append(events, b.event)
+	}
+	statusCode, err := a.sendBatch(ctx, events)
+	if err != nil {
+		a.logger.Error("Failed to send batch to sink", zap.String("address", a.args.SinkURI), zap.Int("size", len(batch)), zap.Error(err))
+		for _, b := range batch {
+			a.nack(ctx, b.msg, b.event)
+		}
+		return
+	}
+
+	a.reporter.ReportBatchSize(len(batch), statusCode)
+	for _, b := range batch {
+		a.reporter.ReportEventCount(&ReportArgs{
+			EventType:   b.event.Type(),
+			EventSource: b.event.Source(),
+		}, statusCode)
+	}
+
+	if statusCode/100 != 2 {
+		a.logger.Error("Batch delivery failed", zap.Int("StatusCode", statusCode), zap.Int("size", len(batch)))
+		for _, b := range batch {
+			a.nack(ctx, b.msg, b.event)
+		}
 		return
 	}
+	for _, b := range batch {
+		a.ack(b.msg)
+	}
+}
+
+// sendBatch posts events to the sink as a JSON array and returns the response
+// status code.
+func (a *Adapter) sendBatch(ctx context.Context, events []*cev2.Event) (int, error) {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return 0, err
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, a.args.SinkURI, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", cev2.ApplicationCloudEventsBatchJSON)
+	resp, err := a.outbound.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	if err := resp.Body.Close(); err != nil {
+		a.logger.Warn("Failed to close response body", zap.Error(err))
+	}
+	return resp.StatusCode, nil
+}
 
+// ack acks msg after its event has been delivered.
+func (a *Adapter) ack(msg *pubsub.Message) {
+	a.attempts.forget(msg.ID)
 	msg.Ack()
 }
 
+// nack nacks msg so that Pub/Sub redelivers it, unless its event has used up
+// all its delivery attempts. In that case the event is sent to the dead letter
+// sink, or dropped if there is none, and msg is acked.
+func (a *Adapter) nack(ctx context.Context, msg *pubsub.Message, event *cev2.Event) {
+	if a.args.MaxDeliveryAttempts <= 0 {
+		msg.Nack()
+		return
+	}
+	var attempt int
+	if msg.DeliveryAttempt != nil {
+		attempt = *msg.DeliveryAttempt
+	} else {
+		attempt = a.attempts.inc(msg.ID)
+	}
+	if attempt < a.args.MaxDeliveryAttempts {
+		msg.Nack()
+		return
+	}
+
+	if a.args.DeadLetterSinkURI == "" {
+		a.logger.Error("Dropping event after exhausting its delivery attempts",
+			zap.String("id", event.ID()), zap.Int("attempts", attempt))
+		a.ack(msg)
+		return
+	}
+
+	resp, err := a.sendMsg(ctx, a.args.DeadLetterSinkURI, (*binding.EventMessage)(event))
+	if err != nil {
+		a.logger.Error("Failed to send message to dead letter sink", zap.String("address", a.args.DeadLetterSinkURI), zap.Error(err))
+		msg.Nack()
+		return
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			a.logger.Warn("Failed to close response body", zap.Error(err))
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		a.logger.Error("Dead letter delivery failed", zap.Int("StatusCode", resp.StatusCode))
+		msg.Nack()
+		return
+	}
+	a.ack(msg)
+}
+
+// setPushData replaces the data of event with msg as Pub/Sub pushes it, so
+// that sinks written for Pub/Sub push subscriptions can handle it unchanged.
+// The subscription is the ID, as in the events converted from the message.
+func (a *Adapter) setPushData(event *cev2.Event, msg *pubsub.Message) error {
+	return event.SetData(cev2.ApplicationJSON, &schemasv1.PushMessage{
+		Subscription: a.subscription.ID(),
+		Message: &schemasv1.PubSubMessage{
+			ID:          msg.ID,
+			Data:        msg.Data,
+			Attributes:  msg.Attributes,
+			PublishTime: msg.PublishTime,
+		},
+	})
+}
+
 func (a *Adapter) sendMsg(ctx context.Context, address string, msg binding.Message) (*nethttp.Response, error) {
+	if a.args.SendMode == converters.Structured {
+		ctx = binding.WithForceStructured(ctx)
+	}
 	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, address, nil)
 	if err != nil {
 		return nil, err
@@ -247,13 +443,17 @@ func (a *Adapter) sendMsg(ctx context.Context, address string, msg binding.Messa
 	return a.outbound.Do(req)
 }
 
-func (a *Adapter) startSpan(ctx context.Context, event *cev2.Event) (context.Context, *trace.Span) {
-	spanName := tracing.SourceDestination(a.resourceGroup, a.namespacedName)
+func (a *Adapter) spanName() string {
 	// This receive adapter code is used both for Sources and Channels.
 	// An ugly way to identify whether it was created from a Channel is to look at the resourceGroup.
 	if a.resourceGroup == messaging.ChannelsResource.String() {
-		spanName = tracing.SubscriptionDestination(a.subscription.ID())
+		return tracing.SubscriptionDestination(a.subscription.ID())
 	}
+	return tracing.SourceDestination(a.resourceGroup, a.namespacedName)
+}
+
+func (a *Adapter) startSpan(ctx context.Context, event *cev2.Event) (context.Context, *trace.Span) {
+	spanName := a.spanName()
 	var span *trace.Span
 	if dt, ok := extensions.GetDistributedTracingExtension(*event); ok {
 		ctx, span = dt.StartChildSpan(ctx, spanName)
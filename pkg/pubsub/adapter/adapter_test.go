@@ -18,12 +18,17 @@ package adapter
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +39,7 @@ import (
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
+	schemasv1 "github.com/google/knative-gcp/pkg/schemas/v1"
 	"github.com/google/knative-gcp/pkg/utils/clients"
 	"golang.org/x/sync/errgroup"
 	logtest "knative.dev/pkg/logging/testing"
@@ -80,14 +86,25 @@ type metricLabels struct {
 }
 
 type statsReporterRecorder struct {
-	labels []metricLabels
+	mu         sync.Mutex
+	labels     []metricLabels
+	batchSizes []int
 }
 
 func (r *statsReporterRecorder) ReportEventCount(args *ReportArgs, responseCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.labels = append(r.labels, metricLabels{CeType: args.EventType, CeSource: args.EventSource, StatusCode: responseCode})
 	return nil
 }
 
+func (r *statsReporterRecorder) ReportBatchSize(size int, responseCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batchSizes = append(r.batchSizes, size)
+	return nil
+}
+
 type mockConverter struct {
 	converted *cev2.Event
 }
@@ -96,7 +113,10 @@ func (c *mockConverter) Convert(ctx context.Context, msg *pubsub.Message, conver
 	if c.converted == nil {
 		return nil, errors.New("induced error")
 	}
-	return c.converted, nil
+	// Each message is converted into a separate event, as the adapter may
+	// modify it.
+	converted := c.converted.Clone()
+	return &converted, nil
 }
 
 func TestAdapter(t *testing.T) {
@@ -283,6 +303,386 @@ func TestAdapter(t *testing.T) {
 	}
 }
 
+func TestAdapterDeliveryAttempts(t *testing.T) {
+	const maxDeliveryAttempts = 3
+
+	cases := []struct {
+		name       string
+		deadLetter bool
+	}{{
+		name:       "dead lettered after max attempts",
+		deadLetter: true,
+	}, {
+		name: "dropped after max attempts",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logtest.TestContextWithLogger(t)
+
+			var attempts int32
+			exhausted := make(chan struct{})
+			sinkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) == maxDeliveryAttempts {
+					close(exhausted)
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer sinkSvr.Close()
+
+			deadLetterClient, err := cehttp.New()
+			if err != nil {
+				t.Fatalf("failed to create dead letter sink cloudevents client: %v", err)
+			}
+			deadLetterSvr := httptest.NewServer(deadLetterClient)
+			defer deadLetterSvr.Close()
+
+			c, close := testPubsubClient(ctx, t, testProjectID)
+			defer close()
+
+			topic, err := c.CreateTopic(ctx, testTopic)
+			if err != nil {
+				t.Fatalf("failed to create topic: %v", err)
+			}
+			sub, err := c.CreateSubscription(ctx, testSub, pubsub.SubscriptionConfig{
+				Topic: topic,
+			})
+			if err != nil {
+				t.Fatalf("failed to create subscription: %v", err)
+			}
+
+			p, err := cepubsub.New(context.Background(),
+				cepubsub.WithClient(c),
+				cepubsub.WithProjectID(testProjectID),
+				cepubsub.WithTopicID(testTopic),
+			)
+			if err != nil {
+				t.Fatalf("failed to create cloudevents pubsub protocol: %v", err)
+			}
+
+			args := &AdapterArgs{
+				TopicID:             testTopic,
+				SinkURI:             sinkSvr.URL,
+				Extensions:          map[string]string{},
+				ConverterType:       converters.ConverterType(testConverterType),
+				MaxDeliveryAttempts: maxDeliveryAttempts,
+			}
+			if tc.deadLetter {
+				args.DeadLetterSinkURI = deadLetterSvr.URL
+			}
+
+			sampleEvent := newSampleEvent()
+			adapter := NewAdapter(ctx,
+				clients.ProjectID(testProjectID),
+				Namespace(testNamespace),
+				Name(testName),
+				ResourceGroup(testResourceGroup),
+				sub,
+				http.DefaultClient,
+				&mockConverter{converted: sampleEvent},
+				&statsReporterRecorder{},
+				args)
+
+			rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			go adapter.Start(rctx)
+			defer adapter.Stop()
+
+			if err := p.Send(rctx, binding.ToMessage(sampleEvent)); err != nil {
+				t.Fatalf("failed to seed event to pubsub: %v", err)
+			}
+
+			if tc.deadLetter {
+				msg, err := deadLetterClient.Receive(rctx)
+				if err != nil {
+					t.Fatalf("dead letter sink did not receive the event: %v", err)
+				}
+				defer msg.Finish(nil)
+				gotEvent, err := binding.ToEvent(rctx, msg)
+				if err != nil {
+					t.Fatalf("dead letter sink received message that cannot be converted to an event: %v", err)
+				}
+				if diff := cmp.Diff(sampleEvent, gotEvent); diff != "" {
+					t.Errorf("dead letter sink received event (-want,+got): %v", diff)
+				}
+			}
+
+			select {
+			case <-exhausted:
+			case <-rctx.Done():
+				t.Fatalf("sink got %d delivery attempts, want %d", atomic.LoadInt32(&attempts), maxDeliveryAttempts)
+			}
+			// Give Pub/Sub a chance to redeliver the message if it wasn't acked.
+			time.Sleep(500 * time.Millisecond)
+			if got := atomic.LoadInt32(&attempts); got != maxDeliveryAttempts {
+				t.Errorf("delivery attempts got=%d, want=%d", got, maxDeliveryAttempts)
+			}
+		})
+	}
+}
+
+func TestAdapterBatching(t *testing.T) {
+	cases := []struct {
+		name           string
+		maxEvents      int
+		maxLatency     time.Duration
+		wantBatchSizes []int
+	}{{
+		name:           "full batch",
+		maxEvents:      3,
+		maxLatency:     time.Minute,
+		wantBatchSizes: []int{3},
+	}, {
+		name:           "batch after max latency",
+		maxEvents:      10,
+		maxLatency:     200 * time.Millisecond,
+		wantBatchSizes: []int{3},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logtest.TestContextWithLogger(t)
+
+			batches := make(chan []*cev2.Event, 10)
+			sinkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Content-Type"); got != cev2.ApplicationCloudEventsBatchJSON {
+					t.Errorf("batch content type got=%q, want=%q", got, cev2.ApplicationCloudEventsBatchJSON)
+				}
+				var events []*cev2.Event
+				if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+					t.Errorf("sink received a batch that cannot be decoded: %v", err)
+				}
+				batches <- events
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer sinkSvr.Close()
+
+			c, close := testPubsubClient(ctx, t, testProjectID)
+			defer close()
+
+			topic, err := c.CreateTopic(ctx, testTopic)
+			if err != nil {
+				t.Fatalf("failed to create topic: %v", err)
+			}
+			sub, err := c.CreateSubscription(ctx, testSub, pubsub.SubscriptionConfig{
+				Topic: topic,
+			})
+			if err != nil {
+				t.Fatalf("failed to create subscription: %v", err)
+			}
+
+			p, err := cepubsub.New(context.Background(),
+				cepubsub.WithClient(c),
+				cepubsub.WithProjectID(testProjectID),
+				cepubsub.WithTopicID(testTopic),
+			)
+			if err != nil {
+				t.Fatalf("failed to create cloudevents pubsub protocol: %v", err)
+			}
+
+			args := &AdapterArgs{
+				TopicID:         testTopic,
+				SinkURI:         sinkSvr.URL,
+				Extensions:      map[string]string{"foo": "bar"},
+				ConverterType:   converters.ConverterType(testConverterType),
+				BatchMaxEvents:  tc.maxEvents,
+				BatchMaxLatency: tc.maxLatency,
+			}
+
+			sampleEvent := newSampleEvent()
+			adapter := NewAdapter(ctx,
+				clients.ProjectID(testProjectID),
+				Namespace(testNamespace),
+				Name(testName),
+				ResourceGroup(testResourceGroup),
+				sub,
+				http.DefaultClient,
+				&mockConverter{converted: sampleEvent},
+				&statsReporterRecorder{},
+				args)
+
+			rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			wantEvent := sampleEvent.Clone()
+			wantEvent.SetExtension("foo", "bar")
+
+			go adapter.Start(rctx)
+			defer adapter.Stop()
+
+			for i := 0; i < 3; i++ {
+				if err := p.Send(rctx, binding.ToMessage(newSampleEvent())); err != nil {
+					t.Fatalf("failed to seed event to pubsub: %v", err)
+				}
+			}
+
+			select {
+			case events := <-batches:
+				if len(events) != 3 {
+					t.Fatalf("sink received a batch of %d events, want 3", len(events))
+				}
+				for _, e := range events {
+					if diff := cmp.Diff(&wantEvent, e); diff != "" {
+						t.Errorf("sink received event (-want,+got): %v", diff)
+					}
+				}
+			case <-rctx.Done():
+				t.Fatal("sink did not receive a batch")
+			}
+
+			// Give Pub/Sub a chance to redeliver the messages if they weren't acked.
+			time.Sleep(500 * time.Millisecond)
+			select {
+			case events := <-batches:
+				t.Errorf("sink received an unexpected batch of %d events", len(events))
+			default:
+			}
+
+			recorder := adapter.reporter.(*statsReporterRecorder)
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if diff := cmp.Diff(tc.wantBatchSizes, recorder.batchSizes); diff != "" {
+				t.Errorf("batch sizes reported (-want,+got): %v", diff)
+			}
+			if got := len(recorder.labels); got != 3 {
+				t.Errorf("event counts reported got=%d, want=3", got)
+			}
+		})
+	}
+}
+
+func TestAdapterSendMode(t *testing.T) {
+	cases := []struct {
+		name      string
+		mode      converters.ModeType
+		checkFunc func(t *testing.T, r *http.Request, body []byte)
+	}{{
+		name: "binary",
+		mode: converters.Binary,
+		checkFunc: func(t *testing.T, r *http.Request, body []byte) {
+			if got := r.Header.Get("ce-id"); got != "id" {
+				t.Errorf("ce-id header got=%q, want=%q", got, "id")
+			}
+		},
+	}, {
+		name: "structured",
+		mode: converters.Structured,
+		checkFunc: func(t *testing.T, r *http.Request, body []byte) {
+			if got := r.Header.Get("Content-Type"); got != cev2.ApplicationCloudEventsJSON {
+				t.Errorf("content type got=%q, want=%q", got, cev2.ApplicationCloudEventsJSON)
+			}
+			var got cev2.Event
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("sink received a structured event that cannot be decoded: %v", err)
+			}
+			if got.ID() != "id" {
+				t.Errorf("event id got=%q, want=%q", got.ID(), "id")
+			}
+		},
+	}, {
+		name: "push",
+		mode: converters.Push,
+		checkFunc: func(t *testing.T, r *http.Request, body []byte) {
+			if got := r.Header.Get("ce-id"); got != "id" {
+				t.Errorf("ce-id header got=%q, want=%q", got, "id")
+			}
+			var got schemasv1.PushMessage
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("sink received a push message that cannot be decoded: %v", err)
+			}
+			if got.Subscription != testSub {
+				t.Errorf("push subscription got=%q, want=%q", got.Subscription, testSub)
+			}
+			if got.Message == nil || got.Message.ID == "" {
+				t.Fatalf("push message got=%+v, want a message with an ID", got.Message)
+			}
+			// The data of push messages is base64 encoded.
+			if want := base64.StdEncoding.EncodeToString([]byte("test data")); got.Message.Data != want {
+				t.Errorf("push message data got=%v, want=%v", got.Message.Data, want)
+			}
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logtest.TestContextWithLogger(t)
+
+			received := make(chan struct{})
+			sinkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("failed to read the request body: %v", err)
+				}
+				tc.checkFunc(t, r, body)
+				w.WriteHeader(http.StatusAccepted)
+				close(received)
+			}))
+			defer sinkSvr.Close()
+
+			c, close := testPubsubClient(ctx, t, testProjectID)
+			defer close()
+
+			topic, err := c.CreateTopic(ctx, testTopic)
+			if err != nil {
+				t.Fatalf("failed to create topic: %v", err)
+			}
+			sub, err := c.CreateSubscription(ctx, testSub, pubsub.SubscriptionConfig{
+				Topic: topic,
+			})
+			if err != nil {
+				t.Fatalf("failed to create subscription: %v", err)
+			}
+
+			p, err := cepubsub.New(context.Background(),
+				cepubsub.WithClient(c),
+				cepubsub.WithProjectID(testProjectID),
+				cepubsub.WithTopicID(testTopic),
+			)
+			if err != nil {
+				t.Fatalf("failed to create cloudevents pubsub protocol: %v", err)
+			}
+
+			args := &AdapterArgs{
+				TopicID:       testTopic,
+				SinkURI:       sinkSvr.URL,
+				Extensions:    map[string]string{},
+				ConverterType: converters.ConverterType(testConverterType),
+				SendMode:      tc.mode,
+			}
+
+			adapter := NewAdapter(ctx,
+				clients.ProjectID(testProjectID),
+				Namespace(testNamespace),
+				Name(testName),
+				ResourceGroup(testResourceGroup),
+				sub,
+				http.DefaultClient,
+				&mockConverter{converted: newSampleEvent()},
+				&statsReporterRecorder{},
+				args)
+
+			rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			go adapter.Start(rctx)
+			defer adapter.Stop()
+
+			original := newSampleEvent()
+			original.SetData(cev2.TextPlain, []byte("test data"))
+			if err := p.Send(rctx, binding.ToMessage(original)); err != nil {
+				t.Fatalf("failed to seed event to pubsub: %v", err)
+			}
+
+			select {
+			case <-received:
+			case <-rctx.Done():
+				t.Fatal("sink did not receive the event")
+			}
+		})
+	}
+}
+
 func newSampleEvent() *event.Event {
 	sampleEvent := event.New()
 	sampleEvent.SetID("id")
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"sync"
+	"time"
+)
+
+// deliveryAttemptsTTL is how long the attempts of a message are counted after
+// its last failed delivery. Pub/Sub redelivers nacked messages well within it,
+// as its retry backoff is at most 10 minutes.
+const deliveryAttemptsTTL = time.Hour
+
+// deliveryAttempts counts the delivery attempts of the messages received by
+// this adapter. The counts are per replica: messages are redelivered to any
+// replica of the adapter, so an event may be attempted up to MaxDeliveryAttempts
+// times by each replica before it is dead lettered. The counts of the messages
+// that weren't redelivered to this replica within the TTL are evicted.
+type deliveryAttempts struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	counts    map[string]*deliveryAttempt
+	lastEvict time.Time
+}
+
+type deliveryAttempt struct {
+	count int
+	last  time.Time
+}
+
+func newDeliveryAttempts(ttl time.Duration) *deliveryAttempts {
+	return &deliveryAttempts{
+		ttl:    ttl,
+		now:    time.Now,
+		counts: make(map[string]*deliveryAttempt),
+	}
+}
+
+// inc records a failed delivery attempt of the message with the given ID and
+// returns its number of attempts so far.
+func (d *deliveryAttempts) inc(id string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	if now.Sub(d.lastEvict) >= d.ttl {
+		d.evict(now)
+	}
+	a, ok := d.counts[id]
+	if !ok || now.Sub(a.last) >= d.ttl {
+		a = &deliveryAttempt{}
+		d.counts[id] = a
+	}
+	a.count++
+	a.last = now
+	return a.count
+}
+
+// forget stops counting the attempts of the message with the given ID.
+func (d *deliveryAttempts) forget(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.counts, id)
+}
+
+// evict removes the counts of the messages whose last attempt is older than
+// the TTL. It must be called with the lock held.
+func (d *deliveryAttempts) evict(now time.Time) {
+	for id, a := range d.counts {
+		if now.Sub(a.last) >= d.ttl {
+			delete(d.counts, id)
+		}
+	}
+	d.lastEvict = now
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeliveryAttempts(t *testing.T) {
+	now := time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)
+	d := newDeliveryAttempts(time.Hour)
+	d.now = func() time.Time { return now }
+
+	if got := d.inc("a"); got != 1 {
+		t.Errorf("inc(a) got=%d, want=1", got)
+	}
+	if got := d.inc("a"); got != 2 {
+		t.Errorf("inc(a) got=%d, want=2", got)
+	}
+	d.forget("a")
+	if got := d.inc("a"); got != 1 {
+		t.Errorf("inc(a) after forget got=%d, want=1", got)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if got := d.inc("b"); got != 1 {
+		t.Errorf("inc(b) got=%d, want=1", got)
+	}
+
+	// The count of a expired, b is still within the TTL.
+	now = now.Add(45 * time.Minute)
+	if got := d.inc("b"); got != 2 {
+		t.Errorf("inc(b) got=%d, want=2", got)
+	}
+	if _, ok := d.counts["a"]; ok {
+		t.Error("count of a was not evicted")
+	}
+	if got := d.inc("a"); got != 1 {
+		t.Errorf("inc(a) after its TTL got=%d, want=1", got)
+	}
+}
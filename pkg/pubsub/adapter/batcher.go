@@ -0,0 +1,102 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+)
+
+// batchedEvent is an event waiting for its batch to be delivered, along with
+// the message it was converted from.
+type batchedEvent struct {
+	msg   *pubsub.Message
+	event *cev2.Event
+}
+
+// batcher accumulates the events received by the adapter and hands them over
+// in batches of up to maxEvents events. A batch that doesn't fill up is handed
+// over maxLatency after its first event was added.
+type batcher struct {
+	maxEvents  int
+	maxLatency time.Duration
+	deliver    func(context.Context, []*batchedEvent)
+
+	mu      sync.Mutex
+	pending []*batchedEvent
+	timer   *time.Timer
+	// generation identifies the pending batch, so that the timer of a batch
+	// that has already been handed over doesn't hand over the next one.
+	generation uint64
+}
+
+func newBatcher(maxEvents int, maxLatency time.Duration, deliver func(context.Context, []*batchedEvent)) *batcher {
+	return &batcher{
+		maxEvents:  maxEvents,
+		maxLatency: maxLatency,
+		deliver:    deliver,
+	}
+}
+
+// add adds the event converted from msg to the pending batch. If that fills up
+// the batch, it's delivered on the calling goroutine.
+func (b *batcher) add(ctx context.Context, msg *pubsub.Message, event *cev2.Event) {
+	b.mu.Lock()
+	b.pending = append(b.pending, &batchedEvent{msg: msg, event: event})
+	if len(b.pending) < b.maxEvents {
+		if len(b.pending) == 1 {
+			generation := b.generation
+			b.timer = time.AfterFunc(b.maxLatency, func() {
+				b.flush(ctx, generation)
+			})
+		}
+		b.mu.Unlock()
+		return
+	}
+	batch := b.take()
+	b.mu.Unlock()
+	b.deliver(ctx, batch)
+}
+
+// flush delivers the pending batch if it's still the given generation.
+func (b *batcher) flush(ctx context.Context, generation uint64) {
+	b.mu.Lock()
+	if generation != b.generation || len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	batch := b.take()
+	b.mu.Unlock()
+	b.deliver(ctx, batch)
+}
+
+// take returns the pending batch and starts a new one. It must be called with
+// b.mu held.
+func (b *batcher) take() []*batchedEvent {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	batch := b.pending
+	b.pending = nil
+	b.generation++
+	return batch
+}
@@ -20,7 +20,9 @@ package converters
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"cloud.google.com/go/pubsub"
 	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
@@ -52,7 +54,46 @@ const (
 	PubSubPull     ConverterType = "pubsub_pull"
 )
 
-type converterFn func(context.Context, *pubsub.Message) (*cev2.Event, error)
+// ConverterFunc converts a Pub/Sub message into a CloudEvent.
+type ConverterFunc func(context.Context, *pubsub.Message) (*cev2.Event, error)
+
+var (
+	registryMu sync.RWMutex
+	// registry holds the converters available to the Pub/Sub converters created
+	// afterwards, keyed by the adapter type.
+	registry = map[ConverterType]ConverterFunc{
+		CloudPubSub:    convertCloudPubSub,
+		CloudAuditLogs: convertCloudAuditLogs,
+		CloudStorage:   convertCloudStorage,
+		CloudScheduler: convertCloudScheduler,
+		CloudBuild:     convertCloudBuild,
+		PubSubPull:     convertPubSubPull,
+	}
+)
+
+// Register makes fn the converter of converterType for the Pub/Sub converters
+// created afterwards. It returns an error if converterType already has a
+// converter.
+func Register(converterType ConverterType, fn ConverterFunc) error {
+	if converterType == "" || fn == nil {
+		return errors.New("converter type and function must be set")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[converterType]; ok {
+		return fmt.Errorf("converter type %q is already registered", converterType)
+	}
+	registry[converterType] = fn
+	return nil
+}
+
+// IsRegistered returns whether converterType has a converter.
+func IsRegistered(converterType ConverterType) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[converterType]
+	return ok
+}
 
 type Converter interface {
 	Convert(ctx context.Context, msg *pubsub.Message, converterType ConverterType) (*cev2.Event, error)
@@ -65,19 +106,19 @@ type PubSubConverter struct {
 	// this map will be the adapter type. If not present,
 	// we assume it's a native PubSub message and a default
 	// one will be used.
-	converters map[ConverterType]converterFn
+	converters map[ConverterType]ConverterFunc
 }
 
+// NewPubSubConverter creates a converter with the converters registered so far.
 func NewPubSubConverter() Converter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	converters := make(map[ConverterType]ConverterFunc, len(registry))
+	for t, fn := range registry {
+		converters[t] = fn
+	}
 	return &PubSubConverter{
-		converters: map[ConverterType]converterFn{
-			CloudPubSub:    convertCloudPubSub,
-			CloudAuditLogs: convertCloudAuditLogs,
-			CloudStorage:   convertCloudStorage,
-			CloudScheduler: convertCloudScheduler,
-			CloudBuild:     convertCloudBuild,
-			PubSubPull:     convertPubSubPull,
-		},
+		converters: converters,
 	}
 }
 
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestRegister(t *testing.T) {
+	const converterType ConverterType = "com.example.register"
+	fn := func(context.Context, *pubsub.Message) (*cev2.Event, error) {
+		e := cev2.NewEvent(cev2.VersionV1)
+		e.SetType(string(converterType))
+		return &e, nil
+	}
+
+	before := NewPubSubConverter()
+	if IsRegistered(converterType) {
+		t.Fatalf("IsRegistered(%q) = true before registering", converterType)
+	}
+	if err := Register(converterType, fn); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if !IsRegistered(converterType) {
+		t.Errorf("IsRegistered(%q) = false after registering", converterType)
+	}
+	if err := Register(converterType, fn); err == nil {
+		t.Error("Register() of a duplicate converter type succeeded")
+	}
+	if err := Register(CloudStorage, fn); err == nil {
+		t.Error("Register() of a built-in converter type succeeded")
+	}
+	if err := Register("", fn); err == nil {
+		t.Error("Register() of an empty converter type succeeded")
+	}
+
+	msg := &pubsub.Message{
+		ID:         "id",
+		Data:       []byte("test data"),
+		Attributes: map[string]string{"knative-gcp": string(converterType)},
+	}
+	got, err := NewPubSubConverter().Convert(context.Background(), msg, converterType)
+	if err != nil {
+		t.Fatalf("Convert() = %v", err)
+	}
+	if got.Type() != string(converterType) {
+		t.Errorf("Convert() type = %q, want %q", got.Type(), converterType)
+	}
+	// Converters created before registering keep their converters.
+	if got, err := before.Convert(context.Background(), msg, converterType); err == nil && got.Type() == string(converterType) {
+		t.Error("Convert() of a converter created before registering used the registered converter")
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	. "github.com/cloudevents/sdk-go/v2/event"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	. "github.com/google/knative-gcp/pkg/pubsub/adapter/context"
+)
+
+const (
+	// ConvertersConfigMapName is the name of the ConfigMap declaring the
+	// generic converters, keyed by their adapter type.
+	ConvertersConfigMapName = "config-converters"
+)
+
+// GenericConfig declares how a generic converter builds a CloudEvent out of a
+// Pub/Sub message. Each attribute is a JSONPath template
+// (https://kubernetes.io/docs/reference/kubectl/jsonpath/) evaluated against
+// an object holding the message id, publishTime and attributes, the project,
+// topic and subscription it was pulled from, and its data parsed as JSON if
+// it is JSON. For example,
+// "//firestore.googleapis.com/projects/{.project}/databases/{.attributes.database}"
+// or "{.data.name}".
+type GenericConfig struct {
+	// Type is the template of the event type. Required.
+	Type string `json:"type"`
+
+	// Source is the template of the event source. Required.
+	Source string `json:"source"`
+
+	// Subject is the template of the event subject. Optional.
+	Subject string `json:"subject,omitempty"`
+
+	// ID is the template of the event ID. Defaults to the message ID.
+	ID string `json:"id,omitempty"`
+
+	// DataSchema is the template of the event data schema. Optional.
+	DataSchema string `json:"dataSchema,omitempty"`
+
+	// DataContentType is the content type of the message data. Defaults to
+	// application/json.
+	DataContentType string `json:"dataContentType,omitempty"`
+
+	// Extensions are the templates of the event extensions, keyed by their
+	// names.
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// GenericConfigs are the configs of the generic converters keyed by their
+// adapter type.
+type GenericConfigs map[ConverterType]GenericConfig
+
+// NewGenericConfigsFromConfigMap parses the generic converters declared in a
+// ConfigMap. Every key other than _example is an adapter type whose value is a
+// YAML or JSON GenericConfig. The adapter types of the built-in converters
+// cannot be redeclared.
+func NewGenericConfigsFromConfigMap(cm *corev1.ConfigMap) (GenericConfigs, error) {
+	configs := make(GenericConfigs, len(cm.Data))
+	for k, v := range cm.Data {
+		if k == "_example" {
+			continue
+		}
+		converterType := ConverterType(k)
+		if IsRegistered(converterType) {
+			return nil, fmt.Errorf("converter type %q is already registered", k)
+		}
+		var cfg GenericConfig
+		if err := yaml.UnmarshalStrict([]byte(v), &cfg); err != nil {
+			return nil, fmt.Errorf("invalid converter %q: %w", k, err)
+		}
+		if _, err := NewGenericConverter(cfg); err != nil {
+			return nil, fmt.Errorf("invalid converter %q: %w", k, err)
+		}
+		configs[converterType] = cfg
+	}
+	return configs, nil
+}
+
+// NewGenericConverter compiles the converter declared by cfg.
+func NewGenericConverter(cfg GenericConfig) (ConverterFunc, error) {
+	if cfg.Type == "" {
+		return nil, errors.New("type must be set")
+	}
+	if cfg.Source == "" {
+		return nil, errors.New("source must be set")
+	}
+	c := &genericConverter{
+		dataContentType: cfg.DataContentType,
+		extensions:      make(map[string]*jsonpath.JSONPath, len(cfg.Extensions)),
+	}
+	if c.dataContentType == "" {
+		c.dataContentType = cev2.ApplicationJSON
+	}
+	var err error
+	if c.typ, err = parseTemplate("type", cfg.Type); err != nil {
+		return nil, err
+	}
+	if c.source, err = parseTemplate("source", cfg.Source); err != nil {
+		return nil, err
+	}
+	if c.subject, err = parseTemplate("subject", cfg.Subject); err != nil {
+		return nil, err
+	}
+	if c.id, err = parseTemplate("id", cfg.ID); err != nil {
+		return nil, err
+	}
+	if c.dataSchema, err = parseTemplate("dataSchema", cfg.DataSchema); err != nil {
+		return nil, err
+	}
+	for name, tmpl := range cfg.Extensions {
+		if !IsAlphaNumeric(name) {
+			return nil, fmt.Errorf("invalid extension name %q", name)
+		}
+		if c.extensions[name], err = parseTemplate(name, tmpl); err != nil {
+			return nil, err
+		}
+	}
+	return c.convert, nil
+}
+
+// parseTemplate parses a JSONPath template, or returns nil if it's empty.
+func parseTemplate(name, tmpl string) (*jsonpath.JSONPath, error) {
+	if tmpl == "" {
+		return nil, nil
+	}
+	j := jsonpath.New(name).AllowMissingKeys(true)
+	if err := j.Parse(tmpl); err != nil {
+		return nil, fmt.Errorf("invalid %s template %q: %w", name, tmpl, err)
+	}
+	return j, nil
+}
+
+type genericConverter struct {
+	typ             *jsonpath.JSONPath
+	source          *jsonpath.JSONPath
+	subject         *jsonpath.JSONPath
+	id              *jsonpath.JSONPath
+	dataSchema      *jsonpath.JSONPath
+	dataContentType string
+	extensions      map[string]*jsonpath.JSONPath
+}
+
+func (c *genericConverter) convert(ctx context.Context, msg *pubsub.Message) (*cev2.Event, error) {
+	input, err := templateInput(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	event := cev2.NewEvent(cev2.VersionV1)
+	event.SetID(msg.ID)
+	event.SetTime(msg.PublishTime)
+
+	var value string
+	if value, err = execute(c.typ, input); err != nil {
+		return nil, err
+	}
+	event.SetType(value)
+	if value, err = execute(c.source, input); err != nil {
+		return nil, err
+	}
+	event.SetSource(value)
+	if value, err = execute(c.subject, input); err != nil {
+		return nil, err
+	} else if value != "" {
+		event.SetSubject(value)
+	}
+	if value, err = execute(c.id, input); err != nil {
+		return nil, err
+	} else if value != "" {
+		event.SetID(value)
+	}
+	if value, err = execute(c.dataSchema, input); err != nil {
+		return nil, err
+	} else if value != "" {
+		event.SetDataSchema(value)
+	}
+	for name, j := range c.extensions {
+		if value, err = execute(j, input); err != nil {
+			return nil, err
+		} else if value != "" {
+			event.SetExtension(name, value)
+		}
+	}
+
+	if err := event.SetData(c.dataContentType, msg.Data); err != nil {
+		return nil, err
+	}
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// templateInput returns the object the templates are evaluated against.
+func templateInput(ctx context.Context, msg *pubsub.Message) (map[string]interface{}, error) {
+	project, err := GetProjectKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	topic, err := GetTopicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := GetSubscriptionKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]interface{}, len(msg.Attributes))
+	for k, v := range msg.Attributes {
+		attributes[k] = v
+	}
+	input := map[string]interface{}{
+		"id":           msg.ID,
+		"publishTime":  msg.PublishTime.Format(time.RFC3339Nano),
+		"attributes":   attributes,
+		"project":      project,
+		"topic":        topic,
+		"subscription": subscription,
+	}
+	// The data is only available to the templates if it's JSON.
+	var data interface{}
+	if err := json.Unmarshal(msg.Data, &data); err == nil {
+		input["data"] = data
+	}
+	return input, nil
+}
+
+func execute(j *jsonpath.JSONPath, input interface{}) (string, error) {
+	if j == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := j.Execute(&buf, input); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/google/knative-gcp/pkg/pubsub/adapter/context"
+)
+
+func TestGenericConverter(t *testing.T) {
+	publishTime := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		config      GenericConfig
+		message     *pubsub.Message
+		wantEventFn func() *cev2.Event
+		wantErr     string
+	}{{
+		name: "attributes and data",
+		config: GenericConfig{
+			Type:       "com.example.{.attributes.eventType}",
+			Source:     "//example.googleapis.com/projects/{.project}/topics/{.topic}",
+			Subject:    "items/{.data.name}",
+			DataSchema: "https://example.com/schema.json",
+			Extensions: map[string]string{
+				"subscription": "{.subscription}",
+				"missing":      "{.attributes.missing}",
+			},
+		},
+		message: &pubsub.Message{
+			ID:          "id",
+			PublishTime: publishTime,
+			Data:        []byte(`{"name":"foo"}`),
+			Attributes: map[string]string{
+				"eventType": "created",
+			},
+		},
+		wantEventFn: func() *cev2.Event {
+			e := cev2.NewEvent(cev2.VersionV1)
+			e.SetID("id")
+			e.SetTime(publishTime)
+			e.SetType("com.example.created")
+			e.SetSource("//example.googleapis.com/projects/testproject/topics/testtopic")
+			e.SetSubject("items/foo")
+			e.SetDataSchema("https://example.com/schema.json")
+			e.SetExtension("subscription", "testsubscription")
+			e.SetData(cev2.ApplicationJSON, []byte(`{"name":"foo"}`))
+			return &e
+		},
+	}, {
+		name: "id and content type",
+		config: GenericConfig{
+			Type:            "com.example.event",
+			Source:          "//example.googleapis.com/{.attributes.source}",
+			ID:              "{.attributes.eventId}",
+			DataContentType: "text/plain",
+		},
+		message: &pubsub.Message{
+			ID:          "id",
+			PublishTime: publishTime,
+			Data:        []byte("test data"),
+			Attributes: map[string]string{
+				"source":  "foo",
+				"eventId": "event-id",
+			},
+		},
+		wantEventFn: func() *cev2.Event {
+			e := cev2.NewEvent(cev2.VersionV1)
+			e.SetID("event-id")
+			e.SetTime(publishTime)
+			e.SetType("com.example.event")
+			e.SetSource("//example.googleapis.com/foo")
+			e.SetData("text/plain", []byte("test data"))
+			return &e
+		},
+	}, {
+		name: "empty source",
+		config: GenericConfig{
+			Type:   "com.example.event",
+			Source: "{.attributes.missing}",
+		},
+		message: &pubsub.Message{
+			ID:          "id",
+			PublishTime: publishTime,
+			Data:        []byte("test data"),
+		},
+		wantErr: "source: REQUIRED",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := NewGenericConverter(test.config)
+			if err != nil {
+				t.Fatalf("NewGenericConverter() = %v", err)
+			}
+
+			ctx := WithProjectKey(context.Background(), "testproject")
+			ctx = WithTopicKey(ctx, "testtopic")
+			ctx = WithSubscriptionKey(ctx, "testsubscription")
+			gotEvent, err := fn(ctx, test.message)
+
+			if test.wantErr != "" || err != nil {
+				var gotErr string
+				if err != nil {
+					gotErr = err.Error()
+				}
+				if test.wantErr == "" || !strings.Contains(gotErr, test.wantErr) {
+					t.Errorf("unexpected error, want %q, got %q", test.wantErr, gotErr)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(test.wantEventFn(), gotEvent); diff != "" {
+				t.Errorf("generic converter got unexpected cev2.Event (-want +got) %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewGenericConverterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  GenericConfig
+		wantErr string
+	}{{
+		name:    "missing type",
+		config:  GenericConfig{Source: "source"},
+		wantErr: "type must be set",
+	}, {
+		name:    "missing source",
+		config:  GenericConfig{Type: "type"},
+		wantErr: "source must be set",
+	}, {
+		name:    "invalid template",
+		config:  GenericConfig{Type: "{.attributes", Source: "source"},
+		wantErr: "invalid type template",
+	}, {
+		name: "invalid extension name",
+		config: GenericConfig{
+			Type:       "type",
+			Source:     "source",
+			Extensions: map[string]string{"not-valid": "value"},
+		},
+		wantErr: `invalid extension name "not-valid"`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewGenericConverter(test.config)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("NewGenericConverter() = %v, want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewGenericConfigsFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    GenericConfigs
+		wantErr string
+	}{{
+		name: "empty",
+		data: map[string]string{},
+		want: GenericConfigs{},
+	}, {
+		name: "example is ignored",
+		data: map[string]string{
+			"_example": "anything",
+			"com.example.foo": `
+type: com.example.foo
+source: //example.googleapis.com/{.attributes.source}
+extensions:
+  bar: "{.data.bar}"
+`,
+		},
+		want: GenericConfigs{
+			"com.example.foo": {
+				Type:       "com.example.foo",
+				Source:     "//example.googleapis.com/{.attributes.source}",
+				Extensions: map[string]string{"bar": "{.data.bar}"},
+			},
+		},
+	}, {
+		name: "built-in converter",
+		data: map[string]string{
+			string(CloudStorage): "type: foo\nsource: bar",
+		},
+		wantErr: `converter type "storage" is already registered`,
+	}, {
+		name: "unknown field",
+		data: map[string]string{
+			"com.example.foo": "type: foo\nsource: bar\nunknown: baz",
+		},
+		wantErr: `invalid converter "com.example.foo"`,
+	}, {
+		name: "invalid config",
+		data: map[string]string{
+			"com.example.foo": "type: foo",
+		},
+		wantErr: "source must be set",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewGenericConfigsFromConfigMap(&corev1.ConfigMap{Data: test.data})
+			if test.wantErr != "" || err != nil {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("NewGenericConfigsFromConfigMap() = %v, want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("NewGenericConfigsFromConfigMap() (-want +got) %s", diff)
+			}
+		})
+	}
+}
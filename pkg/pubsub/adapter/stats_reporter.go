@@ -37,6 +37,14 @@ var (
 		stats.UnitDimensionless,
 	)
 
+	// batchSizeM is a distribution of the number of events in the batches
+	// sent.
+	batchSizeM = stats.Int64(
+		"batch_size",
+		"Number of events in the batches sent",
+		stats.UnitDimensionless,
+	)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -60,6 +68,10 @@ type ReportArgs struct {
 type StatsReporter interface {
 	// ReportEventCount captures the event count. It records one per call.
 	ReportEventCount(args *ReportArgs, responseCode int) error
+
+	// ReportBatchSize captures the number of events of a batch. It records
+	// one per call.
+	ReportBatchSize(size int, responseCode int) error
 }
 
 var _ StatsReporter = (*reporter)(nil)
@@ -94,6 +106,21 @@ func (r *reporter) ReportEventCount(args *ReportArgs, responseCode int) error {
 	return nil
 }
 
+func (r *reporter) ReportBatchSize(size int, responseCode int) error {
+	ctx, err := tag.New(
+		emptyContext,
+		tag.Insert(namespaceKey, r.namespace),
+		tag.Insert(nameKey, r.name),
+		tag.Insert(resourceGroupKey, r.resourceGroup),
+		tag.Insert(responseCodeKey, strconv.Itoa(responseCode)),
+		tag.Insert(responseCodeClassKey, metrics.ResponseCodeClass(responseCode)))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, batchSizeM.M(int64(size)))
+	return nil
+}
+
 func (r *reporter) generateTag(args *ReportArgs, responseCode int) (context.Context, error) {
 	return tag.New(
 		emptyContext,
@@ -124,5 +151,16 @@ func (r *reporter) register() error {
 			Aggregation: view.Count(),
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Description: batchSizeM.Description(),
+			Measure:     batchSizeM,
+			Aggregation: view.Distribution(1, 2, 5, 10, 20, 50, 100, 200, 500, 1000),
+			TagKeys: []tag.Key{
+				namespaceKey,
+				nameKey,
+				resourceGroupKey,
+				responseCodeKey,
+				responseCodeClassKey},
+		},
 	)
 }
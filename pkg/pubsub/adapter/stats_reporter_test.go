@@ -55,6 +55,23 @@ func TestStatsReporter(t *testing.T) {
 		return r.ReportEventCount(args, http.StatusAccepted)
 	})
 	metricstest.CheckCountData(t, "event_count", wantTags, 2)
+
+	wantBatchTags := map[string]string{
+		metricskey.LabelNamespaceName:     "testns",
+		metricskey.LabelName:              "testobject",
+		metricskey.LabelResourceGroup:     "testresourcegroup",
+		metricskey.LabelResponseCode:      "202",
+		metricskey.LabelResponseCodeClass: "2xx",
+	}
+
+	// test ReportBatchSize
+	expectSuccess(t, func() error {
+		return r.ReportBatchSize(10, http.StatusAccepted)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportBatchSize(100, http.StatusAccepted)
+	})
+	metricstest.CheckDistributionData(t, "batch_size", wantBatchTags, 2, 10, 100)
 }
 
 func expectSuccess(t *testing.T, f func() error) {
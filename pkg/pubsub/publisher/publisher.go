@@ -36,6 +36,8 @@ import (
 	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
 	"github.com/cloudevents/sdk-go/v2/extensions"
 	"go.opencensus.io/trace"
+
+	"github.com/google/knative-gcp/pkg/kncloudevents/batch"
 )
 
 const (
@@ -78,7 +80,7 @@ func (p *Publisher) Start(ctx context.Context) error {
 
 // ServeHTTP implements net/http Publisher interface method.
 // 1. Performs basic validation of the request.
-// 2. Converts the request to an event.
+// 2. Converts the request to an event, or to a list of events in batch mode.
 // 3. Sends the event to pubsub.
 func (p *Publisher) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Request) {
 	ctx := request.Context()
@@ -88,6 +90,11 @@ func (p *Publisher) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.
 		return
 	}
 
+	if batch.IsRequest(request) {
+		p.serveBatch(ctx, response, request)
+		return
+	}
+
 	event, err := p.toEvent(request)
 	if err != nil {
 		nethttp.Error(response, err.Error(), nethttp.StatusBadRequest)
@@ -110,6 +117,31 @@ func (p *Publisher) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.
 	response.WriteHeader(statusCode)
 }
 
+// serveBatch publishes each event of a batch request and responds with the status
+// of each event.
+func (p *Publisher) serveBatch(ctx context.Context, response nethttp.ResponseWriter, request *nethttp.Request) {
+	entries, err := batch.Read(response, request)
+	if err != nil {
+		nethttp.Error(response, err.Error(), batch.ReadErrorStatus(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sinkTimeout)
+	defer cancel()
+	// Publish the events concurrently so that the pubsub client can batch them.
+	results := batch.SendAll(entries, func(event *cev2.Event) (int, error) {
+		if res := p.Publish(ctx, event); !cev2.IsACK(res) {
+			p.logger.Error("Error publishing batched event to PubSub", zap.String("id", event.ID()), zap.Error(res))
+			return nethttp.StatusInternalServerError, res
+		}
+		return nethttp.StatusAccepted, nil
+	})
+
+	if err := batch.WriteResponse(response, results); err != nil {
+		p.logger.Warn("Failed to write batch response", zap.Error(err))
+	}
+}
+
 // Publish publishes an incoming event to a pubsub topic.
 func (p *Publisher) Publish(ctx context.Context, event *cev2.Event) protocol.Result {
 	dt := extensions.FromSpanContext(trace.FromContext(ctx).SpanContext())
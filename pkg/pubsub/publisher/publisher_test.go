@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publisher
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	logtest "knative.dev/pkg/logging/testing"
+
+	"github.com/google/knative-gcp/pkg/kncloudevents/batch"
+)
+
+func TestPublisherBatch(t *testing.T) {
+	ctx := logtest.TestContextWithLogger(t)
+	psSrv := pstest.NewServer()
+	defer psSrv.Close()
+	conn, err := grpc.Dial(psSrv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c, err := pubsub.NewClient(ctx, "test-project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic, err := c.CreateTopic(ctx, "test-topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer topic.Stop()
+
+	p := NewPublisher(ctx, nil, topic)
+	body := `[
+		{"specversion":"1.0","id":"event-1","source":"test-source","type":"test-type"},
+		{"specversion":"1.0","id":"event-2","source":"test-source","type":"test-type"}
+	]`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", cev2.ApplicationCloudEventsBatchJSON)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	if w.Code != nethttp.StatusAccepted {
+		t.Errorf("StatusCode got=%v, want=%v", w.Code, nethttp.StatusAccepted)
+	}
+	var results []batch.Result
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if got := len(psSrv.Messages()); got != 2 {
+		t.Errorf("published messages got=%d, want=2", got)
+	}
+}
+
+func TestPublisherBatchMalformed(t *testing.T) {
+	p := NewPublisher(logtest.TestContextWithLogger(t), nil, nil)
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"not":"a batch"}`))
+	req.Header.Set("Content-Type", cev2.ApplicationCloudEventsBatchJSON)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	if w.Code != nethttp.StatusBadRequest {
+		t.Errorf("StatusCode got=%v, want=%v", w.Code, nethttp.StatusBadRequest)
+	}
+}
@@ -155,17 +155,26 @@ func (r *Reconciler) reconcileDecouplingTopicAndSubscription(ctx context.Context
 
 	// Check if PullSub exists, and if not, create it.
 	subID := resources.GenerateDecouplingSubscriptionName(b)
+	retention, _ := b.GetRetentionDuration()
 	subConfig := pubsub.SubscriptionConfig{
 		Topic:  topic,
-		Labels: labels,
+		Labels: resources.SubscriptionLabels(labels, b, resources.FanoutSubscriptionComponent),
+		// Message ordering can only be set when the subscription is created.
+		EnableMessageOrdering: b.GetOrderingKeyAttribute() != "",
+		// Acked events are only retained for replay if the broker has a retention duration.
+		RetainAckedMessages: retention != 0,
+		RetentionDuration:   retention,
 		//TODO(grantr): configure these settings?
 		// AckDeadline
-		// RetentionDuration
 	}
 	if _, err := pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, b, &b.Status); err != nil {
 		return err
 	}
 
+	if err := reconcileReplay(ctx, pubsubReconciler, subID, b); err != nil {
+		return err
+	}
+
 	// TODO(grantr): this isn't actually persisted due to webhook issues.
 	//TODO uncomment when eventing webhook allows this
 	//b.Status.SubscriptionID = sub.ID()
@@ -173,6 +182,26 @@ func (r *Reconciler) reconcileDecouplingTopicAndSubscription(ctx context.Context
 	return nil
 }
 
+// reconcileReplay seeks the decoupling subscription to the replay requested on the broker,
+// unless it was already applied. Seeking the decoupling subscription replays the events to
+// all triggers of the broker.
+func reconcileReplay(ctx context.Context, pubsubReconciler *reconcilerutilspubsub.Reconciler, subID string, b *brokerv1beta1.Broker) error {
+	replay, _ := b.GetReplay()
+	if replay == nil {
+		b.Status.ClearReplayed()
+		return nil
+	}
+	if b.Status.IsReplayed(replay) {
+		return nil
+	}
+	if err := pubsubReconciler.SeekSubscription(ctx, subID, replay.Time, replay.Snapshot, b); err != nil {
+		b.Status.MarkReplayFailed("SubscriptionSeekFailed", "Failed to seek subscription to %s: %v", replay, err)
+		return err
+	}
+	b.Status.MarkReplayed(replay)
+	return nil
+}
+
 func (r *Reconciler) deleteDecouplingTopicAndSubscription(ctx context.Context, b *brokerv1beta1.Broker) error {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Deleting decoupling topic")
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,6 +36,7 @@ import (
 	. "knative.dev/pkg/reconciler/testing"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	inteventsv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 	"github.com/google/knative-gcp/pkg/client/injection/ducks/duck/v1alpha1/resource"
 	brokerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/broker"
 	"github.com/google/knative-gcp/pkg/reconciler"
@@ -67,6 +69,11 @@ var (
 		Host:   fmt.Sprintf("%s.%s.svc.%s", ingressServiceName, systemNS, utils.GetClusterDomainName()),
 		Path:   fmt.Sprintf("/%s/%s", testNS, brokerName),
 	}
+	namespaceBrokerAddress = &apis.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s.%s.svc.%s", brokercellresources.Name("testnamespace-ns", brokercellresources.IngressName), systemNS, utils.GetClusterDomainName()),
+		Path:   fmt.Sprintf("/%s/%s", testNS, brokerName),
+	}
 )
 
 func init() {
@@ -164,6 +171,156 @@ func TestAllCases(t *testing.T) {
 			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
 			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
 		},
+	}, {
+		Name: "Broker with retention replays its events, broker subscription is seeked",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.BrokerRetentionDurationAnnotationKey, "24h"),
+				WithBrokerAnnotation(brokerv1beta1.BrokerReplayTimeAnnotationKey, "2020-08-01T10:00:00Z"),
+				WithBrokerSetDefaults),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellReady,
+				WithBrokerCellSetDefaults),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.BrokerRetentionDurationAnnotationKey, "24h"),
+				WithBrokerAnnotation(brokerv1beta1.BrokerReplayTimeAnnotationKey, "2020-08-01T10:00:00Z"),
+				WithBrokerReadyURI(brokerAddress),
+				WithBrokerReplayed(&brokerv1beta1.Replay{Time: time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)}),
+				WithBrokerSetDefaults,
+			),
+		}},
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeNormal, "TopicCreated", `Created PubSub topic "cre-bkr_testnamespace_test-broker_abc123"`),
+			Eventf(corev1.EventTypeNormal, "SubscriptionCreated", `Created PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			Eventf(corev1.EventTypeNormal, "SubscriptionSeeked", `Seeked PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			brokerReconciledEvent,
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
+			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
+		},
+	}, {
+		Name: "Broker replay already applied, broker subscription is not seeked again",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.BrokerReplayTimeAnnotationKey, "2020-08-01T10:00:00Z"),
+				WithBrokerFinalizers(brokerFinalizerName),
+				WithBrokerReadyURI(brokerAddress),
+				WithBrokerReplayed(&brokerv1beta1.Replay{Time: time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)}),
+				WithBrokerSetDefaults),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellReady,
+				WithBrokerCellSetDefaults),
+		},
+		WantEvents: []string{
+			// The subscription created without the brokercell labels is labeled.
+			Eventf(corev1.EventTypeNormal, "SubscriptionUpdated", `Updated PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			brokerReconciledEvent,
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{
+				TopicAndSub("cre-bkr_testnamespace_test-broker_abc123", "cre-bkr_testnamespace_test-broker_abc123"),
+			},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
+			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
+		},
+	}, {
+		Name: "Targets config propagating to data plane pods",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerSetDefaults),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellReady,
+				WithTargetsConfigGeneration(2),
+				WithTargetsConfigAppliedPods(1, 2),
+				WithBrokerCellSetDefaults),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerReadyURI(brokerAddress),
+				WithBrokerTargetsConfigPropagating("TargetsConfigPropagating", "Targets config generation 2 applied on 1/2 pods"),
+				WithBrokerSetDefaults,
+			),
+		}},
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeNormal, "TopicCreated", `Created PubSub topic "cre-bkr_testnamespace_test-broker_abc123"`),
+			Eventf(corev1.EventTypeNormal, "SubscriptionCreated", `Created PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			brokerReconciledEvent,
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
+			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
+		},
+	}, {
+		Name: "Targets config applied on all data plane pods",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerSetDefaults),
+			NewBrokerCell(resources.DefaultBrokerCellName, systemNS,
+				WithBrokerCellReady,
+				WithTargetsConfigGeneration(2),
+				WithTargetsConfigAppliedPods(2, 2),
+				WithBrokerCellSetDefaults),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerReadyURI(brokerAddress),
+				WithBrokerTargetsConfigApplied,
+				WithBrokerSetDefaults,
+			),
+		}},
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeNormal, "TopicCreated", `Created PubSub topic "cre-bkr_testnamespace_test-broker_abc123"`),
+			Eventf(corev1.EventTypeNormal, "SubscriptionCreated", `Created PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			brokerReconciledEvent,
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
+			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
+		},
 	}, {
 		Name: "Create broker with unready brokercell, broker is created",
 		Key:  testKey,
@@ -227,7 +384,7 @@ func TestAllCases(t *testing.T) {
 				),
 			},
 		},
-		WantCreates:             []runtime.Object{resources.CreateBrokerCell(nil) /*Currently brokercell doesn't require broker information*/},
+		WantCreates:             []runtime.Object{resources.CreateBrokerCell(NewBroker(brokerName, testNS))},
 		SkipNamespaceValidation: true, // The brokercell resource is created in a different namespace (system namespace) than the broker
 		WantEvents: []string{
 			brokerFinalizerUpdatedEvent,
@@ -258,7 +415,7 @@ func TestAllCases(t *testing.T) {
 				),
 			},
 		},
-		WantCreates:             []runtime.Object{resources.CreateBrokerCell(nil) /*Currently brokercell doesn't require broker information*/},
+		WantCreates:             []runtime.Object{resources.CreateBrokerCell(NewBroker(brokerName, testNS))},
 		SkipNamespaceValidation: true, // The brokercell resource is created in a different namespace (system namespace) than the broker
 		WantEvents: []string{
 			brokerFinalizerUpdatedEvent,
@@ -277,6 +434,118 @@ func TestAllCases(t *testing.T) {
 			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
 			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
 		},
+	}, {
+		Name: "Create broker placed on its namespace brokercell, both broker and brokercell are created",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.BrokerCellScopeAnnotationKey, brokerv1beta1.BrokerCellScopeNamespace),
+				WithBrokerSetDefaults,
+			),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{
+				Object: NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithBrokerUID(testUID),
+					WithBrokerAnnotation(brokerv1beta1.BrokerCellScopeAnnotationKey, brokerv1beta1.BrokerCellScopeNamespace),
+					WithBrokerReadyURI(namespaceBrokerAddress),
+					WithBrokerBrokerCellUnknown("BrokerCellNotReady", "Brokercell knative-testing/testnamespace-ns is not ready"),
+					WithBrokerSetDefaults,
+				),
+			},
+		},
+		WantCreates: []runtime.Object{
+			NewBrokerCell("testnamespace-ns", systemNS,
+				WithBrokerCellAnnotations(map[string]string{inteventsv1alpha1.CreatorKey: inteventsv1alpha1.Creator}),
+				WithBrokerCellBrokerNamespace(testNS)),
+		},
+		SkipNamespaceValidation: true, // The brokercell resource is created in a different namespace (system namespace) than the broker
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeNormal, "BrokerCellCreated", `Created brokercell knative-testing/testnamespace-ns`),
+			Eventf(corev1.EventTypeNormal, "TopicCreated", `Created PubSub topic "cre-bkr_testnamespace_test-broker_abc123"`),
+			Eventf(corev1.EventTypeNormal, "SubscriptionCreated", `Created PubSub subscription "cre-bkr_testnamespace_test-broker_abc123"`),
+			brokerReconciledEvent,
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		OtherTestData: map[string]interface{}{
+			"pre": []PubsubAction{},
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			TopicExists("cre-bkr_testnamespace_test-broker_abc123"),
+			SubscriptionExists("cre-bkr_testnamespace_test-broker_abc123"),
+		},
+	}, {
+		Name: "Broker placed on a brokercell of another namespace, brokercell not allowed",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.BrokerCellAnnotationKey, "team-b"),
+				WithBrokerSetDefaults,
+			),
+			NewBrokerCell("team-b", systemNS,
+				WithBrokerCellReady,
+				WithBrokerCellBrokerNamespace("other"),
+				WithBrokerCellSetDefaults),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{
+				Object: NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithBrokerUID(testUID),
+					WithBrokerAnnotation(brokerv1beta1.BrokerCellAnnotationKey, "team-b"),
+					WithInitBrokerConditions,
+					WithBrokerBrokerCellFailed("BrokerCellNotAllowed", "Brokercell knative-testing/team-b only accepts brokers in namespace other"),
+					WithBrokerSetDefaults,
+				),
+			},
+		},
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeWarning, "InternalError", `failed to reconcile broker: brokercell reconcile failed: brokercell knative-testing/team-b doesn't accept brokers in namespace testnamespace`),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		WantErr: true,
+	}, {
+		Name: "Broker placed on a missing brokercell by annotation, brokercell not created",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewBroker(brokerName, testNS,
+				WithBrokerClass(brokerv1beta1.BrokerClass),
+				WithBrokerUID(testUID),
+				WithBrokerAnnotation(brokerv1beta1.BrokerCellAnnotationKey, "team-b"),
+				WithBrokerSetDefaults,
+			),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{
+				Object: NewBroker(brokerName, testNS,
+					WithBrokerClass(brokerv1beta1.BrokerClass),
+					WithBrokerUID(testUID),
+					WithBrokerAnnotation(brokerv1beta1.BrokerCellAnnotationKey, "team-b"),
+					WithInitBrokerConditions,
+					WithBrokerBrokerCellFailed("BrokerCellNotFound", "Brokercell knative-testing/team-b doesn't exist"),
+					WithBrokerSetDefaults,
+				),
+			},
+		},
+		WantEvents: []string{
+			brokerFinalizerUpdatedEvent,
+			Eventf(corev1.EventTypeWarning, "InternalError", `failed to reconcile broker: brokercell reconcile failed: brokercell knative-testing/team-b doesn't exist`),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, brokerName, brokerFinalizerName),
+		},
+		WantErr: true,
 	}}
 
 	defer logtesting.ClearAll()
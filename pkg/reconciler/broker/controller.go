@@ -30,6 +30,7 @@ import (
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	inteventsv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
@@ -38,6 +39,7 @@ import (
 	brokerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/broker"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
+	"github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	"github.com/google/knative-gcp/pkg/utils"
 )
 
@@ -91,23 +93,28 @@ func NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl
 		reconciler.DefaultResyncPeriod,
 	)
 
-	bcInformer.Informer().AddEventHandler(controller.HandleAll(
-		func(obj interface{}) {
-			if _, ok := obj.(*inteventsv1alpha1.BrokerCell); ok {
-				// TODO(#866) Only select brokers that point to this brokercell by label selector once the
-				// webhook assigns the brokercell label, i.e.,
-				// r.brokerLister.List(labels.SelectorFromSet(map[string]string{"brokercell":bc.Name, "brokercellns":bc.Namespace}))
-				brokers, err := brokerInformer.Lister().List(labels.Everything())
-				if err != nil {
-					r.Logger.Error("Failed to list brokers", zap.Error(err))
-					return
-				}
-				for _, broker := range brokers {
-					impl.Enqueue(broker)
-				}
+	// enqueueBrokersOn enqueues the brokers placed on the brokercell in the system namespace.
+	enqueueBrokersOn := func(bcName string) {
+		brokers, err := brokerInformer.Lister().List(labels.Everything())
+		if err != nil {
+			r.Logger.Error("Failed to list brokers", zap.Error(err))
+			return
+		}
+		for _, broker := range brokers {
+			if resources.BrokerCellName(broker) == bcName {
+				impl.Enqueue(broker)
 			}
-		},
-	))
+		}
+	}
+
+	bcInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.NamespaceFilterFunc(system.Namespace()),
+		Handler: controller.HandleAll(func(obj interface{}) {
+			if bc, ok := obj.(*inteventsv1alpha1.BrokerCell); ok {
+				enqueueBrokersOn(bc.Name)
+			}
+		}),
+	})
 
 	return impl
 }
@@ -31,6 +31,7 @@ import (
 	// Fake injection informers
 	_ "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/broker/fake"
 	_ "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1alpha1/brokercell/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
 )
 
 func TestNew(t *testing.T) {
@@ -35,19 +35,28 @@ import (
 	brokercellresources "github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
 )
 
-// ensureBrokerCellExists creates a BrokerCell if it doesn't exist, and update broker status based on brokercell status.
+// ensureBrokerCellExists creates the BrokerCell the broker is placed on if it doesn't exist and isn't chosen by annotation, and update broker status based on brokercell status.
 func (r *Reconciler) ensureBrokerCellExists(ctx context.Context, b *brokerv1beta1.Broker) error {
 	var bc *inteventsv1alpha1.BrokerCell
 	var err error
-	// TODO(#866) Get brokercell based on the label (or annotation) on the broker.
-	bc, err = r.brokerCellLister.BrokerCells(system.Namespace()).Get(resources.DefaultBrokerCellName)
+	bcName := resources.BrokerCellName(b)
+	if brokerv1beta1.IsReservedBrokerCellName(b.GetBrokerCell()) {
+		b.Status.MarkBrokerCelllFailed("BrokerCellNotAllowed", "Brokercell name %s is reserved for namespace-scoped brokercells", bcName)
+		return fmt.Errorf("brokercell name %s is reserved for namespace-scoped brokercells", bcName)
+	}
+	bc, err = r.brokerCellLister.BrokerCells(system.Namespace()).Get(bcName)
 
 	if err != nil && !apierrs.IsNotFound(err) {
 		logging.FromContext(ctx).Error("Error reconciling brokercell", zap.String("namespace", b.Namespace), zap.String("broker", b.Name), zap.Error(err))
-		b.Status.MarkBrokerCelllUnknown("BrokerCellUnknown", "Failed to get brokercell %s/%s", bc.Namespace, bc.Name)
+		b.Status.MarkBrokerCelllUnknown("BrokerCellUnknown", "Failed to get brokercell %s/%s", system.Namespace(), bcName)
 		return err
 	}
 
+	if apierrs.IsNotFound(err) && !resources.IsCreatedFor(b) {
+		b.Status.MarkBrokerCelllFailed("BrokerCellNotFound", "Brokercell %s/%s doesn't exist", system.Namespace(), bcName)
+		return fmt.Errorf("brokercell %s/%s doesn't exist", system.Namespace(), bcName)
+	}
+
 	if apierrs.IsNotFound(err) {
 		want := resources.CreateBrokerCell(b)
 		bc, err = r.RunClientSet.InternalV1alpha1().BrokerCells(want.Namespace).Create(want)
@@ -72,6 +81,11 @@ func (r *Reconciler) ensureBrokerCellExists(ctx context.Context, b *brokerv1beta
 		}
 	}
 
+	if !resources.IsPlacedOn(b, bc) {
+		b.Status.MarkBrokerCelllFailed("BrokerCellNotAllowed", "Brokercell %s/%s only accepts brokers in namespace %s", bc.Namespace, bc.Name, bc.Spec.BrokerNamespace)
+		return fmt.Errorf("brokercell %s/%s doesn't accept brokers in namespace %s", bc.Namespace, bc.Name, b.Namespace)
+	}
+
 	if bc.Status.IsReady() {
 		b.Status.MarkBrokerCellReady()
 	} else {
@@ -86,5 +100,24 @@ func (r *Reconciler) ensureBrokerCellExists(ctx context.Context, b *brokerv1beta
 		Path:   fmt.Sprintf("/%s/%s", b.Namespace, b.Name),
 	})
 
+	propagateTargetsConfigStatus(b, bc)
 	return nil
 }
+
+// propagateTargetsConfigStatus reflects how many data plane pods of the brokercell applied
+// its latest targets config generation.
+func propagateTargetsConfigStatus(b *brokerv1beta1.Broker, bc *inteventsv1alpha1.BrokerCell) {
+	// The brokercell reconciler summarizes the propagation to its pods in its status. No pods
+	// are counted when they don't report the generation they applied.
+	generation := bc.Status.TargetsConfigGeneration
+	applied, total := bc.Status.TargetsConfigAppliedPods, bc.Status.TargetsConfigPods
+	if generation == 0 || total == 0 {
+		b.Status.ClearTargetsConfigApplied()
+		return
+	}
+	if applied < total {
+		b.Status.MarkTargetsConfigPropagating("TargetsConfigPropagating", "Targets config generation %d applied on %d/%d pods", generation, applied, total)
+		return
+	}
+	b.Status.MarkTargetsConfigApplied()
+}
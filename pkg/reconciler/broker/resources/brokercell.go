@@ -19,22 +19,67 @@ package resources
 import (
 	"github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/system"
 
 	inteventsv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 )
 
-// Right now we only support one brokercell in the system namespace in the cluster.
-// TODO(#866) Delete hard-coded brokercell once we can dynamically assign brokercell to brokers.
+// DefaultBrokerCellName is the name of the brokercell that brokers without placement
+// annotations are placed on. All brokercells live in the system namespace.
 const DefaultBrokerCellName = "default"
 
+// BrokerCellName returns the name of the brokercell in the system namespace that the
+// broker is placed on.
+func BrokerCellName(b *v1beta1.Broker) string {
+	if b.HasNamespaceScopedBrokerCell() {
+		return NamespaceBrokerCellName(b.Namespace)
+	}
+	if name := b.GetBrokerCell(); name != "" {
+		return name
+	}
+	return DefaultBrokerCellName
+}
+
+// NamespaceBrokerCellName returns the name of the brokercell dedicated to the brokers in
+// the namespace.
+func NamespaceBrokerCellName(namespace string) string {
+	return kmeta.ChildName(namespace, v1beta1.NamespaceBrokerCellSuffix)
+}
+
+// IsPlacedOn returns true if the broker is placed on the brokercell and the brokercell
+// accepts brokers in the namespace of the broker. Brokers can't be placed on a brokercell
+// with a reserved name by annotation.
+func IsPlacedOn(b *v1beta1.Broker, bc *inteventsv1alpha1.BrokerCell) bool {
+	if bc.Namespace != system.Namespace() || bc.Name != BrokerCellName(b) {
+		return false
+	}
+	if v1beta1.IsReservedBrokerCellName(b.GetBrokerCell()) {
+		return false
+	}
+	return bc.Spec.BrokerNamespace == "" || bc.Spec.BrokerNamespace == b.Namespace
+}
+
+// CreateBrokerCell returns the brokercell that the broker is placed on. A namespace-scoped
+// brokercell only accepts brokers in the namespace of the broker. Only the default and
+// namespace-scoped brokercells are created for brokers, see IsCreatedFor.
 func CreateBrokerCell(b *v1beta1.Broker) *inteventsv1alpha1.BrokerCell {
-	// TODO(#866) Get brokercell from the label (or annotation) from the broker.
-	return &inteventsv1alpha1.BrokerCell{
+	bc := &inteventsv1alpha1.BrokerCell{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:   system.Namespace(),
-			Name:        DefaultBrokerCellName,
+			Name:        BrokerCellName(b),
 			Annotations: map[string]string{inteventsv1alpha1.CreatorKey: inteventsv1alpha1.Creator},
 		},
 	}
+	if b.HasNamespaceScopedBrokerCell() {
+		bc.Spec.BrokerNamespace = b.Namespace
+	}
+	return bc
+}
+
+// IsCreatedFor returns true if the brokercell the broker is placed on is created for the
+// broker when it doesn't exist. The brokercells chosen by annotation are created by the
+// operator, so that an annotation can't create data plane deployments.
+func IsCreatedFor(b *v1beta1.Broker) bool {
+	return b.GetBrokerCell() == ""
 }
@@ -19,10 +19,105 @@ package resources
 import (
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
 	_ "knative.dev/pkg/system/testing"
+
+	"github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	inteventsv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 )
 
-// This is already tested in broker_test.go, this test is just to make coverage tool happy.
-func TestBrokerCellCreation(t *testing.T) {
-	CreateBrokerCell(nil)
+func TestBrokerCellPlacement(t *testing.T) {
+	broker := func(annotations map[string]string) *v1beta1.Broker {
+		return &v1beta1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "broker", Annotations: annotations}}
+	}
+	cases := []struct {
+		name              string
+		broker            *v1beta1.Broker
+		wantName          string
+		wantNamespaceOnly bool
+		wantCreated       bool
+	}{{
+		name:        "default",
+		broker:      broker(nil),
+		wantName:    DefaultBrokerCellName,
+		wantCreated: true,
+	}, {
+		name:     "named brokercell",
+		broker:   broker(map[string]string{v1beta1.BrokerCellAnnotationKey: "shared"}),
+		wantName: "shared",
+	}, {
+		name:              "namespace-scoped brokercell",
+		broker:            broker(map[string]string{v1beta1.BrokerCellScopeAnnotationKey: v1beta1.BrokerCellScopeNamespace}),
+		wantName:          "team-a-ns",
+		wantNamespaceOnly: true,
+		wantCreated:       true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := BrokerCellName(tc.broker); got != tc.wantName {
+				t.Errorf("BrokerCellName got=%q, want=%q", got, tc.wantName)
+			}
+			if got := IsCreatedFor(tc.broker); got != tc.wantCreated {
+				t.Errorf("IsCreatedFor got=%v, want=%v", got, tc.wantCreated)
+			}
+			bc := CreateBrokerCell(tc.broker)
+			if bc.Namespace != system.Namespace() || bc.Name != tc.wantName {
+				t.Errorf("CreateBrokerCell got=%s/%s, want=%s/%s", bc.Namespace, bc.Name, system.Namespace(), tc.wantName)
+			}
+			if got := bc.Spec.BrokerNamespace != ""; got != tc.wantNamespaceOnly {
+				t.Errorf("CreateBrokerCell restricted to broker namespace got=%v, want=%v", got, tc.wantNamespaceOnly)
+			}
+			if !IsPlacedOn(tc.broker, bc) {
+				t.Errorf("IsPlacedOn got=false, want=true")
+			}
+		})
+	}
+}
+
+func TestIsPlacedOn(t *testing.T) {
+	b := &v1beta1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "broker"}}
+	cases := []struct {
+		name string
+		bc   *inteventsv1alpha1.BrokerCell
+		want bool
+	}{{
+		name: "placed",
+		bc:   &inteventsv1alpha1.BrokerCell{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: DefaultBrokerCellName}},
+		want: true,
+	}, {
+		name: "other brokercell",
+		bc:   &inteventsv1alpha1.BrokerCell{ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: "other"}},
+	}, {
+		name: "other namespace",
+		bc:   &inteventsv1alpha1.BrokerCell{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: DefaultBrokerCellName}},
+	}, {
+		name: "restricted to other broker namespace",
+		bc: &inteventsv1alpha1.BrokerCell{
+			ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: DefaultBrokerCellName},
+			Spec:       inteventsv1alpha1.BrokerCellSpec{BrokerNamespace: "team-b"},
+		},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsPlacedOn(b, tc.bc); got != tc.want {
+				t.Errorf("IsPlacedOn got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsPlacedOnReservedName(t *testing.T) {
+	b := &v1beta1.Broker{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "team-a",
+		Name:        "broker",
+		Annotations: map[string]string{v1beta1.BrokerCellAnnotationKey: "team-a-ns"},
+	}}
+	bc := &inteventsv1alpha1.BrokerCell{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: "team-a-ns"},
+		Spec:       inteventsv1alpha1.BrokerCellSpec{BrokerNamespace: "team-a"},
+	}
+	if IsPlacedOn(b, bc) {
+		t.Errorf("IsPlacedOn got=true, want=false")
+	}
 }
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+)
+
+const (
+	// SubscriptionBrokerCellLabelKey is the Pub/Sub label of the subscriptions pulled by the
+	// data plane of a brokercell, set to the name of the brokercell. It lets the backlog of
+	// all the subscriptions of a brokercell be queried at once.
+	SubscriptionBrokerCellLabelKey = "broker_cell"
+	// SubscriptionComponentLabelKey is the Pub/Sub label of the subscriptions pulled by the
+	// data plane of a brokercell, set to the component pulling them.
+	SubscriptionComponentLabelKey = "broker_cell_component"
+
+	// FanoutSubscriptionComponent labels the decouple subscriptions of the brokers and the
+	// subscriptions of the isolated triggers.
+	FanoutSubscriptionComponent = "fanout"
+	// RetrySubscriptionComponent labels the retry subscriptions of the triggers.
+	RetrySubscriptionComponent = "retry"
+)
+
+// SubscriptionLabels returns the labels with the brokercell the broker is placed on and the
+// component pulling the subscription added.
+func SubscriptionLabels(labels map[string]string, b *brokerv1beta1.Broker, component string) map[string]string {
+	subLabels := make(map[string]string, len(labels)+2)
+	for k, v := range labels {
+		subLabels[k] = v
+	}
+	subLabels[SubscriptionBrokerCellLabelKey] = BrokerCellName(b)
+	subLabels[SubscriptionComponentLabelKey] = component
+	return subLabels
+}
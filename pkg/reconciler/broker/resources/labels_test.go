@@ -0,0 +1,47 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+)
+
+func TestSubscriptionLabels(t *testing.T) {
+	b := &v1beta1.Broker{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "team-a",
+		Name:        "broker",
+		Annotations: map[string]string{v1beta1.BrokerCellScopeAnnotationKey: v1beta1.BrokerCellScopeNamespace},
+	}}
+	labels := map[string]string{"resource": "triggers"}
+	got := SubscriptionLabels(labels, b, RetrySubscriptionComponent)
+	want := map[string]string{
+		"resource":                     "triggers",
+		SubscriptionBrokerCellLabelKey: "team-a-ns",
+		SubscriptionComponentLabelKey:  RetrySubscriptionComponent,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected SubscriptionLabels (-want, +got): %s", diff)
+	}
+	if len(labels) != 1 {
+		t.Errorf("SubscriptionLabels modified the given labels: %v", labels)
+	}
+}
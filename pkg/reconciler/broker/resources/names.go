@@ -60,3 +60,11 @@ func GenerateRetryTopicName(t *brokerv1beta1.Trigger) string {
 func GenerateRetrySubscriptionName(t *brokerv1beta1.Trigger) string {
 	return naming.TruncatedPubsubResourceName("cre-tgr", t.Namespace, t.Name, t.UID)
 }
+
+// GenerateIsolatedSubscriptionName generates a deterministic name for the
+// subscription of an isolated Trigger on the decoupling topic of its Broker. If
+// the subscription name would be longer than allowed by PubSub, the Trigger name
+// is truncated to fit.
+func GenerateIsolatedSubscriptionName(t *brokerv1beta1.Trigger) string {
+	return naming.TruncatedPubsubResourceName("cre-tgr-iso", t.Namespace, t.Name, t.UID)
+}
@@ -193,6 +193,35 @@ func TestGenerateRetrySubscriptionName(t *testing.T) {
 	}
 }
 
+func TestGenerateIsolatedSubscriptionName(t *testing.T) {
+	testCases := []struct {
+		ns   string
+		n    string
+		uid  string
+		want string
+	}{{
+		ns:   "default",
+		n:    "default",
+		uid:  testUID,
+		want: fmt.Sprintf("cre-tgr-iso_default_default_%s", testUID),
+	}, {
+		ns:   maxNamespace,
+		n:    maxName,
+		uid:  testUID,
+		want: fmt.Sprintf("cre-tgr-iso_%s_%s_%s", maxNamespace, strings.Repeat("n", truncatedNameMax-4), testUID),
+	}}
+
+	for _, tc := range testCases {
+		got := GenerateIsolatedSubscriptionName(trigger(tc.ns, tc.n, tc.uid))
+		if len(got) > naming.PubsubMax {
+			t.Errorf("name length %d is greater than %d", len(got), naming.PubsubMax)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("unexpected (want, +got) = %v", diff)
+		}
+	}
+}
+
 func broker(ns, n, uid string) *brokerv1beta1.Broker {
 	return &brokerv1beta1.Broker{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,38 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"cloud.google.com/go/pubsub"
+)
+
+// RetryMaxDeliveryAttempts is the max delivery attempts of the dead letter policy of
+// the retry subscriptions. It is the largest value allowed by Pub/Sub, so that the
+// delivery attempts of the broker delivery spec are honored before it applies.
+const RetryMaxDeliveryAttempts = 100
+
+// MakeRetryDeadLetterPolicy makes the dead letter policy of a trigger retry subscription.
+// The dead letter policy makes Pub/Sub track the delivery attempts of each message
+// across all data plane pods and restarts. Messages that exceed the max delivery attempts
+// are published back to the retry topic, which restarts their delivery attempt count, so
+// events are still retried until they expire unless the broker has a dead letter sink.
+func MakeRetryDeadLetterPolicy(retryTopic *pubsub.Topic) *pubsub.DeadLetterPolicy {
+	return &pubsub.DeadLetterPolicy{
+		DeadLetterTopic:     retryTopic.String(),
+		MaxDeliveryAttempts: RetryMaxDeliveryAttempts,
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/eventing/pkg/apis/eventing"
+
+	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
+	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+)
+
+// The backlog autoscaling defaults are the same as the KEDA autoscaling defaults of
+// PullSubscriptions.
+const (
+	defaultKedaSubscriptionSize = 100
+	defaultKedaPollingInterval  = 15 * time.Second
+	defaultKedaCooldownPeriod   = 120 * time.Second
+)
+
+// makeFanoutScaledObject returns the ScaledObject scaling the fanout deployment on the
+// backlog of the decouple subscriptions of the brokers and of the subscriptions of the
+// isolated triggers, or nil if the fanout is scaled by its HPA.
+func (r *Reconciler) makeFanoutScaledObject(bc *intv1alpha1.BrokerCell, fd *appsv1.Deployment) (*unstructured.Unstructured, error) {
+	if bc.Spec.Fanout == nil || bc.Spec.Fanout.BacklogAutoscaling == nil {
+		return nil, nil
+	}
+	brokers, err := r.backlogBrokers(bc)
+	if err != nil {
+		return nil, err
+	}
+	return r.makeScaledObject(bc, fd, r.makeFanoutHPAArgs(bc), bc.Spec.Fanout.BacklogAutoscaling, brokerresources.FanoutSubscriptionComponent, len(brokers) > 0)
+}
+
+// makeRetryScaledObject returns the ScaledObject scaling the retry deployment on the
+// backlog of the retry subscriptions of the triggers, or nil if the retry is scaled by
+// its HPA.
+func (r *Reconciler) makeRetryScaledObject(bc *intv1alpha1.BrokerCell, rd *appsv1.Deployment) (*unstructured.Unstructured, error) {
+	if bc.Spec.Retry == nil || bc.Spec.Retry.BacklogAutoscaling == nil {
+		return nil, nil
+	}
+	brokers, err := r.backlogBrokers(bc)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := r.backlogTriggers(brokers)
+	if err != nil {
+		return nil, err
+	}
+	return r.makeScaledObject(bc, rd, r.makeRetryHPAArgs(bc), bc.Spec.Retry.BacklogAutoscaling, brokerresources.RetrySubscriptionComponent, len(triggers) > 0)
+}
+
+// backlogBrokers returns the brokers placed on the brokercell whose decouple subscription
+// exists, so that KEDA doesn't fail on a subscription that's yet to be created.
+func (r *Reconciler) backlogBrokers(bc *intv1alpha1.BrokerCell) ([]*brokerv1beta1.Broker, error) {
+	brokers, err := r.brokersOf(bc)
+	if err != nil {
+		return nil, err
+	}
+	var ready []*brokerv1beta1.Broker
+	for _, b := range brokers {
+		if b.GetDeletionTimestamp().IsZero() && b.Status.GetCondition(brokerv1beta1.BrokerConditionSubscription).IsTrue() {
+			ready = append(ready, b)
+		}
+	}
+	return ready, nil
+}
+
+// backlogTriggers returns the triggers of the brokers whose subscriptions exist.
+func (r *Reconciler) backlogTriggers(brokers []*brokerv1beta1.Broker) ([]*brokerv1beta1.Trigger, error) {
+	var ready []*brokerv1beta1.Trigger
+	for _, b := range brokers {
+		triggers, err := r.triggerLister.Triggers(b.Namespace).List(labels.SelectorFromSet(map[string]string{eventing.BrokerLabelKey: b.Name}))
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range triggers {
+			if t.Status.GetCondition(brokerv1beta1.TriggerConditionSubscription).IsTrue() {
+				ready = append(ready, t)
+			}
+		}
+	}
+	return ready, nil
+}
+
+// makeScaledObject makes the ScaledObject of the component deployment with the replica
+// bounds of its HPA. It returns nil if there's no subscription to scale on, the component
+// is then scaled by its HPA until there is.
+func (r *Reconciler) makeScaledObject(bc *intv1alpha1.BrokerCell, d *appsv1.Deployment, hpaArgs resources.AutoscalingArgs, ba *intv1alpha1.BacklogAutoscaling, component string, hasSubscriptions bool) (*unstructured.Unstructured, error) {
+	if !hasSubscriptions {
+		return nil, nil
+	}
+	if r.projectID == "" {
+		return nil, errors.New("the project ID of the subscriptions is unknown")
+	}
+	args := resources.ScaledObjectArgs{
+		ComponentName:          hpaArgs.ComponentName,
+		BrokerCell:             hpaArgs.BrokerCell,
+		MinReplicas:            hpaArgs.MinReplicas,
+		MaxReplicas:            hpaArgs.MaxReplicas,
+		ProjectID:              r.projectID,
+		SubscriptionComponent:  component,
+		TriggerAuthentication:  resources.TriggerAuthenticationName(bc),
+		SubscriptionSize:       defaultKedaSubscriptionSize,
+		PollingIntervalSeconds: int64(defaultKedaPollingInterval.Seconds()),
+		CooldownPeriodSeconds:  int64(defaultKedaCooldownPeriod.Seconds()),
+	}
+	if ba.TriggerAuthentication != "" {
+		args.TriggerAuthentication = ba.TriggerAuthentication
+	}
+	if ba.SubscriptionSize != nil {
+		args.SubscriptionSize = *ba.SubscriptionSize
+	}
+	if ba.OldestUnackedMessageAge != nil {
+		args.OldestUnackedMessageAgeSeconds = int64(ba.OldestUnackedMessageAge.Seconds())
+	}
+	if ba.PollingInterval != nil {
+		args.PollingIntervalSeconds = int64(ba.PollingInterval.Seconds())
+	}
+	if ba.CooldownPeriod != nil {
+		args.CooldownPeriodSeconds = int64(ba.CooldownPeriod.Seconds())
+	}
+	return resources.MakeScaledObject(d, args), nil
+}
+
+// makeTriggerAuthentication returns the pod identity TriggerAuthentication of the brokercell,
+// or nil if no component uses it.
+func makeTriggerAuthentication(bc *intv1alpha1.BrokerCell) *unstructured.Unstructured {
+	for _, p := range []*intv1alpha1.ComponentParameters{bc.Spec.Fanout, bc.Spec.Retry} {
+		if p != nil && p.BacklogAutoscaling != nil && p.BacklogAutoscaling.TriggerAuthentication == "" {
+			return resources.MakeTriggerAuthentication(bc)
+		}
+	}
+	return nil
+}
+
+// reconcileKedaObject creates or updates the KEDA object of the kind with the given name, or
+// deletes it if desired is nil.
+func (r *Reconciler) reconcileKedaObject(ctx context.Context, bc *intv1alpha1.BrokerCell, gvk schema.GroupVersionKind, name string, desired *unstructured.Unstructured) error {
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	client := r.DynamicClientSet.Resource(gvr).Namespace(bc.Namespace)
+	if desired == nil {
+		// KEDA may not be installed, the ScaledObject then isn't found either.
+		if _, err := client.Get(name, metav1.GetOptions{}); apierrs.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := client.Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+		r.Recorder.Eventf(bc, corev1.EventTypeNormal, gvk.Kind+"Deleted", "Deleted %s %s/%s", gvk.Kind, bc.Namespace, name)
+		return nil
+	}
+
+	if err := r.discoveryFn(r.KubeClientSet.Discovery(), resources.KedaSchemeGroupVersion); err != nil {
+		return fmt.Errorf("failed to discover KEDA, backlog autoscaling requires KEDA 2 to be installed: %w", err)
+	}
+	existing, err := client.Get(name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		_, err = client.Create(desired, metav1.CreateOptions{})
+		if apierrs.IsAlreadyExists(err) {
+			return nil
+		}
+		if err == nil {
+			r.Recorder.Eventf(bc, corev1.EventTypeNormal, gvk.Kind+"Created", "Created %s %s/%s", gvk.Kind, bc.Namespace, name)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepDerivative(desired.Object["spec"], existing.Object["spec"]) {
+		existing.Object["spec"] = desired.Object["spec"]
+		_, err := client.Update(existing, metav1.UpdateOptions{})
+		if err == nil {
+			r.Recorder.Eventf(bc, corev1.EventTypeNormal, gvk.Kind+"Updated", "Updated %s %s/%s", gvk.Kind, bc.Namespace, name)
+		}
+		return err
+	}
+	return nil
+}
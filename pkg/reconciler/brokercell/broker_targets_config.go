@@ -19,9 +19,16 @@ package brokercell
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/eventing/pkg/logging"
@@ -40,10 +47,7 @@ const (
 )
 
 func (r *Reconciler) reconcileConfig(ctx context.Context, bc *intv1alpha1.BrokerCell) error {
-	// TODO(#866) Only select brokers that point to this brokercell by label selector once the
-	// webhook assigns the brokercell label, i.e.,
-	// r.brokerLister.List(labels.SelectorFromSet(map[string]string{"brokercell":bc.Name, "brokercellns":bc.Namespace}))
-	brokers, err := r.brokerLister.List(labels.Everything())
+	brokers, err := r.brokersOf(bc)
 	if err != nil {
 		logging.FromContext(ctx).Error("Failed to list brokers", zap.Error(err))
 		bc.Status.MarkTargetsConfigFailed(configFailed, "failed to list brokers: %v", err)
@@ -65,15 +69,84 @@ func (r *Reconciler) reconcileConfig(ctx context.Context, bc *intv1alpha1.Broker
 		}
 		r.addToConfig(ctx, broker, triggers, brokerTargets)
 	}
-	if err := r.updateTargetsConfig(ctx, bc, brokerTargets); err != nil {
+	if r.targetsServer != nil {
+		return r.reconcileStreamedConfig(ctx, bc, brokerTargets)
+	}
+	shards, err := r.stampTargetsConfig(bc, brokerTargets)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to stamp broker targets config", zap.Error(err))
+		bc.Status.MarkTargetsConfigFailed(configFailed, "failed to stamp targets config: %v", err)
+		return err
+	}
+	merged, err := config.MergeShards(shards...)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to merge broker targets config", zap.Error(err))
+		bc.Status.MarkTargetsConfigFailed(configFailed, "failed to merge targets config: %v", err)
+		return err
+	}
+	logging.FromContext(ctx).Debug("Current targets config", zap.Int64("generation", merged.Generation), zap.Any("targetsConfig", brokerTargets.String()))
+	if err := r.updateTargetsConfig(ctx, bc, shards); err != nil {
 		logging.FromContext(ctx).Error("Failed to update broker targets configmap", zap.Error(err))
 		bc.Status.MarkTargetsConfigFailed(configFailed, "failed to update configmap: %v", err)
 		return err
 	}
+	bc.Status.TargetsConfigGeneration = merged.Generation
 	bc.Status.MarkTargetsConfigReady()
 	return nil
 }
 
+// propagateTargetsConfigPods counts the data plane pods that applied the targets config
+// generation, so that brokers and triggers reflect its propagation without watching the pods.
+// The pods report the generation they applied on the targets config stream, so no pods are
+// counted when the targets config isn't streamed.
+func (r *Reconciler) propagateTargetsConfigPods(bc *intv1alpha1.BrokerCell) error {
+	if r.targetsServer == nil {
+		bc.Status.TargetsConfigAppliedPods, bc.Status.TargetsConfigPods = 0, 0
+		return nil
+	}
+	pods, err := r.podLister.Pods(bc.Namespace).List(labels.SelectorFromSet(resources.CommonLabels(bc.Name)))
+	if err != nil {
+		return fmt.Errorf("listing data plane pods: %w", err)
+	}
+	nodes := r.targetsServer.Nodes(bc.Name)
+	var applied, total int32
+	for _, pod := range pods {
+		// Pods being deleted are not counted.
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		total++
+		if node, ok := nodes[pod.Name]; ok && node.Version != 0 && node.Generation >= bc.Status.TargetsConfigGeneration {
+			applied++
+		}
+	}
+	bc.Status.TargetsConfigAppliedPods, bc.Status.TargetsConfigPods = applied, total
+	return nil
+}
+
+// propagateOpenCircuitBreakers collects the circuit breakers that the data plane pods report
+// open on the targets config stream, so that triggers reflect them without watching the pods.
+// The pods don't report them when the targets config isn't streamed.
+func (r *Reconciler) propagateOpenCircuitBreakers(bc *intv1alpha1.BrokerCell) {
+	if r.targetsServer == nil {
+		bc.Status.OpenCircuitBreakers = nil
+		return
+	}
+	pods := make(map[string][]string)
+	for node, report := range r.targetsServer.Nodes(bc.Name) {
+		for _, key := range report.OpenCircuitBreakers {
+			pods[key] = append(pods[key], node)
+		}
+	}
+	var open []intv1alpha1.OpenCircuitBreaker
+	for key, names := range pods {
+		sort.Strings(names)
+		open = append(open, intv1alpha1.OpenCircuitBreaker{Target: key, Pods: names})
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].Target < open[j].Target })
+	bc.Status.OpenCircuitBreakers = open
+}
+
 // addToConfig reconstructs the data entry for the given broker and add it to targets-config.
 func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, triggers []*brokerv1beta1.Trigger, brokerTargets config.Targets) {
 	// TODO Maybe get rid of BrokerMutation and add Delete() and Upsert(broker) methods to TargetsConfig. Now we always
@@ -90,12 +163,23 @@ func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, t
 			Topic:        brokerresources.GenerateDecouplingTopicName(b),
 			Subscription: brokerresources.GenerateDecouplingSubscriptionName(b),
 		})
+		m.SetOrderingKeyAttribute(b.GetOrderingKeyAttribute())
+		if rl, err := b.GetRateLimit(); err != nil {
+			// The broker validation should prevent this from happening.
+			logging.FromContext(ctx).Error("Failed to get broker rate limit", zap.String("broker", b.Name), zap.Error(err))
+		} else {
+			m.SetRateLimit(toConfigRateLimit(rl))
+		}
+		m.SetAllowedPublishers(b.GetAllowedPublishers())
 		if b.Status.IsReady() {
 			m.SetState(config.State_READY)
 		} else {
 			m.SetState(config.State_UNKNOWN)
 		}
 
+		// The delivery spec of the broker applies to all of its triggers.
+		deadLetterAddress, maxAttempts := r.resolveDelivery(ctx, b)
+
 		// Insert each Trigger to the config.
 		for _, t := range triggers {
 			if t.Spec.Broker == b.Name {
@@ -109,10 +193,64 @@ func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, t
 						Topic:        brokerresources.GenerateRetryTopicName(t),
 						Subscription: brokerresources.GenerateRetrySubscriptionName(t),
 					},
+					DeadLetterAddress: deadLetterAddress,
+					MaxAttempts:       maxAttempts,
+				}
+				if t.IsIsolated() {
+					target.IsolatedQueue = &config.Queue{
+						Topic:        brokerresources.GenerateDecouplingTopicName(b),
+						Subscription: brokerresources.GenerateIsolatedSubscriptionName(t),
+					}
 				}
 				if t.Spec.Filter != nil && t.Spec.Filter.Attributes != nil {
 					target.FilterAttributes = t.Spec.Filter.Attributes
 				}
+				filters, err := t.GetFilters()
+				if err != nil {
+					// Skip the trigger rather than delivering events it didn't ask for.
+					// The trigger validation should prevent this from happening.
+					logging.FromContext(ctx).Error("Failed to get trigger filters", zap.String("trigger", t.Name), zap.Error(err))
+					continue
+				}
+				target.Filters = toConfigFilters(filters)
+				transformations, err := t.GetTransformations()
+				if err != nil {
+					// Skip the trigger rather than delivering events in a shape it didn't ask for.
+					// The trigger validation should prevent this from happening.
+					logging.FromContext(ctx).Error("Failed to get trigger transformations", zap.String("trigger", t.Name), zap.Error(err))
+					continue
+				}
+				target.Transformations = toConfigTransformations(transformations)
+				rl, err := t.GetRateLimit()
+				if err != nil {
+					// The trigger validation should prevent this from happening.
+					logging.FromContext(ctx).Error("Failed to get trigger rate limit", zap.String("trigger", t.Name), zap.Error(err))
+				}
+				target.RateLimit = toConfigRateLimit(rl)
+				target.DeliveryAuth = toConfigDeliveryAuth(t.GetDeliveryAuth())
+				maxInFlight, err := t.GetMaxInFlight()
+				if err != nil {
+					// The trigger validation should prevent this from happening.
+					logging.FromContext(ctx).Error("Failed to get trigger max in-flight deliveries", zap.String("trigger", t.Name), zap.Error(err))
+				}
+				target.MaxInFlight = maxInFlight
+				cb, err := t.GetCircuitBreaker()
+				if err != nil {
+					// The trigger validation should prevent this from happening.
+					logging.FromContext(ctx).Error("Failed to get trigger circuit breaker", zap.String("trigger", t.Name), zap.Error(err))
+				}
+				target.CircuitBreaker = toConfigCircuitBreaker(cb)
+				replyAddress, err := r.resolveReply(t)
+				if err != nil {
+					// Keep delivering to the trigger, but don't send its replies to the wrong destination.
+					// They're sent to the address the trigger reconciler last resolved, or dropped. The
+					// trigger reconciler reports the failure in the ReplyResolved condition.
+					logging.FromContext(ctx).Error("Unable to resolve the reply destination URI", zap.String("trigger", t.Name), zap.Error(err))
+					replyAddress = t.Status.ReplyAddress()
+					target.RepliesDisabled = replyAddress == ""
+				}
+				target.ReplyAddress = replyAddress
+				target.ReplyRequiredAttributes = t.GetReplyRequiredAttributes()
 				// TODO(#939) May need to use "data plane readiness" for trigger in stead of the
 				//  overall status, see https://github.com/google/knative-gcp/issues/939#issuecomment-644337937
 				if t.Status.IsReady() {
@@ -126,23 +264,304 @@ func (r *Reconciler) addToConfig(ctx context.Context, b *brokerv1beta1.Broker, t
 	})
 }
 
-//TODO all this stuff should be in a configmap variant of the config object
-func (r *Reconciler) updateTargetsConfig(ctx context.Context, bc *intv1alpha1.BrokerCell, brokerTargets config.Targets) error {
-	desired, err := resources.MakeTargetsConfig(bc, brokerTargets)
+// toConfigFilters converts the trigger filters to the filters of the targets config.
+func toConfigFilters(filters []brokerv1beta1.SubscriptionsAPIFilter) []*config.Filter {
+	if len(filters) == 0 {
+		return nil
+	}
+	out := make([]*config.Filter, 0, len(filters))
+	for i := range filters {
+		out = append(out, toConfigFilter(&filters[i]))
+	}
+	return out
+}
+
+func toConfigFilter(f *brokerv1beta1.SubscriptionsAPIFilter) *config.Filter {
+	switch {
+	case len(f.All) > 0:
+		return &config.Filter{Dialect: &config.Filter_All{All: &config.FilterList{Filters: toConfigFilters(f.All)}}}
+	case len(f.Any) > 0:
+		return &config.Filter{Dialect: &config.Filter_Any{Any: &config.FilterList{Filters: toConfigFilters(f.Any)}}}
+	case f.Not != nil:
+		return &config.Filter{Dialect: &config.Filter_Not{Not: toConfigFilter(f.Not)}}
+	case len(f.Exact) > 0:
+		return &config.Filter{Dialect: &config.Filter_Exact{Exact: &config.AttributesFilter{Attributes: f.Exact}}}
+	case len(f.Prefix) > 0:
+		return &config.Filter{Dialect: &config.Filter_Prefix{Prefix: &config.AttributesFilter{Attributes: f.Prefix}}}
+	case len(f.Suffix) > 0:
+		return &config.Filter{Dialect: &config.Filter_Suffix{Suffix: &config.AttributesFilter{Attributes: f.Suffix}}}
+	case f.SQL != "":
+		return &config.Filter{Dialect: &config.Filter_Cesql{Cesql: f.SQL}}
+	}
+	// A filter without dialect never matches in the data plane.
+	return &config.Filter{}
+}
+
+// toConfigTransformations converts the trigger transformations to the transformations of the targets config.
+func toConfigTransformations(transformations []brokerv1beta1.EventTransformation) []*config.Transformation {
+	if len(transformations) == 0 {
+		return nil
+	}
+	out := make([]*config.Transformation, 0, len(transformations))
+	for _, t := range transformations {
+		out = append(out, toConfigTransformation(t))
+	}
+	return out
+}
+
+func toConfigTransformation(t brokerv1beta1.EventTransformation) *config.Transformation {
+	switch {
+	case len(t.Set) > 0:
+		return &config.Transformation{Operation: &config.Transformation_Set{Set: &config.AttributeValues{Values: t.Set}}}
+	case len(t.Remove) > 0:
+		return &config.Transformation{Operation: &config.Transformation_Remove{Remove: &config.AttributeNames{Names: t.Remove}}}
+	case len(t.Rename) > 0:
+		return &config.Transformation{Operation: &config.Transformation_Rename{Rename: &config.AttributeValues{Values: t.Rename}}}
+	case len(t.Extract) > 0:
+		return &config.Transformation{Operation: &config.Transformation_Extract{Extract: &config.AttributeValues{Values: t.Extract}}}
+	case t.Data != "":
+		return &config.Transformation{Operation: &config.Transformation_DataTemplate{DataTemplate: t.Data}}
+	}
+	// A transformation without operation fails to apply in the data plane.
+	return &config.Transformation{}
+}
+
+// toConfigRateLimit converts the rate limit from annotations to the rate limit of the targets config.
+func toConfigRateLimit(rl *brokerv1beta1.RateLimit) *config.RateLimit {
+	if rl == nil {
+		return nil
+	}
+	return &config.RateLimit{EventsPerSecond: rl.EventsPerSecond, Burst: rl.Burst}
+}
+
+// toConfigDeliveryAuth converts the delivery auth annotation value to the delivery auth of the targets config.
+func toConfigDeliveryAuth(auth string) config.DeliveryAuth {
+	switch auth {
+	case brokerv1beta1.DeliveryAuthGoogleIDToken:
+		return config.DeliveryAuth_GOOGLE_ID_TOKEN
+	case brokerv1beta1.DeliveryAuthKubernetesToken:
+		return config.DeliveryAuth_KUBERNETES_TOKEN
+	}
+	return config.DeliveryAuth_NO_AUTH
+}
+
+// toConfigCircuitBreaker converts the circuit breaker from annotations to the circuit breaker of the targets config.
+func toConfigCircuitBreaker(cb *brokerv1beta1.CircuitBreaker) *config.CircuitBreaker {
+	if cb == nil {
+		return nil
+	}
+	return &config.CircuitBreaker{
+		FailureThreshold: cb.FailureThreshold,
+		CoolDownSeconds:  int64(cb.CoolDown / time.Second),
+	}
+}
+
+// resolveDelivery resolves the dead letter sink address and the max delivery attempts from the broker's delivery
+// spec. If the broker has no dead letter sink or it cannot be resolved, events are retried indefinitely.
+func (r *Reconciler) resolveDelivery(ctx context.Context, b *brokerv1beta1.Broker) (string, int32) {
+	if b.Spec.Delivery == nil || b.Spec.Delivery.DeadLetterSink == nil {
+		return "", 0
+	}
+	dest := *b.Spec.Delivery.DeadLetterSink
+	if dest.Ref != nil {
+		// The dead letter sink ref must be in the same namespace as the broker.
+		ref := *dest.Ref
+		ref.Namespace = b.Namespace
+		dest.Ref = &ref
+	}
+	uri, err := r.uriResolver.URIFromDestinationV1(dest, b)
 	if err != nil {
-		return fmt.Errorf("error creating targets config: %w", err)
+		logging.FromContext(ctx).Error("Unable to resolve the dead letter sink URI", zap.String("broker", b.Name), zap.Error(err))
+		return "", 0
+	}
+	// Retry is the number of retries after the initial delivery.
+	maxAttempts := int32(1)
+	if b.Spec.Delivery.Retry != nil && *b.Spec.Delivery.Retry > 0 {
+		maxAttempts += *b.Spec.Delivery.Retry
+	}
+	// The retries are counted by the dead letter policy of the retry subscription,
+	// which tracks at most brokerresources.RetryMaxDeliveryAttempts attempts.
+	if maxAttempts > brokerresources.RetryMaxDeliveryAttempts {
+		maxAttempts = brokerresources.RetryMaxDeliveryAttempts
 	}
+	return uri.String(), maxAttempts
+}
+
+// resolveReply resolves the reply destination address of the trigger. An empty address means the replies are
+// sent to the broker of the trigger.
+func (r *Reconciler) resolveReply(t *brokerv1beta1.Trigger) (string, error) {
+	dest, err := t.GetReplyDestination()
+	if err != nil || dest == nil {
+		return "", err
+	}
+	if dest.Ref != nil {
+		// The reply destination ref must be in the same namespace as the trigger.
+		ref := *dest.Ref
+		ref.Namespace = t.Namespace
+		dest.Ref = &ref
+	}
+	uri, err := r.uriResolver.URIFromDestinationV1(*dest, t)
+	if err != nil {
+		return "", err
+	}
+	return uri.String(), nil
+}
 
-	logging.FromContext(ctx).Debug("Current targets config", zap.Any("targetsConfig", brokerTargets.String()))
+// reconcileStreamedConfig streams the targets config to the data plane pods of the brokercell.
+// The streamed targets config isn't written to configmaps, so it isn't bound by the configmap
+// size limit. The configmaps left from before the targets config was streamed are deleted.
+func (r *Reconciler) reconcileStreamedConfig(ctx context.Context, bc *intv1alpha1.BrokerCell, brokerTargets config.Targets) error {
+	cfg, err := r.stampStreamedTargetsConfig(bc, brokerTargets)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to stamp broker targets config", zap.Error(err))
+		bc.Status.MarkTargetsConfigFailed(configFailed, "failed to stamp targets config: %v", err)
+		return err
+	}
+	logging.FromContext(ctx).Debug("Current targets config", zap.Int64("generation", cfg.Generation), zap.Any("targetsConfig", brokerTargets.String()))
+	r.targetsServer.Publish(bc.Name, cfg)
+	if err := r.deleteStaleTargetsConfig(bc, sets.NewString()); err != nil {
+		logging.FromContext(ctx).Error("Failed to delete broker targets configmaps", zap.Error(err))
+		bc.Status.MarkTargetsConfigFailed(configFailed, "failed to delete configmaps: %v", err)
+		return err
+	}
+	bc.Status.TargetsConfigGeneration = cfg.Generation
+	bc.Status.MarkTargetsConfigReady()
+	return nil
+}
 
+// stampStreamedTargetsConfig stamps the generation of the streamed targets config. A config
+// with the same brokers as the one streamed keeps its stamp so that it isn't streamed again.
+// Otherwise it's stamped with the next generation.
+func (r *Reconciler) stampStreamedTargetsConfig(bc *intv1alpha1.BrokerCell, brokerTargets config.Targets) (*config.TargetsConfig, error) {
+	cfg, err := targetsConfigOf(brokerTargets)
+	if err != nil {
+		return nil, err
+	}
+	latest := bc.Status.TargetsConfigGeneration
+	if _, current := r.targetsServer.Current(bc.Name); current != nil {
+		if proto.Equal(&config.TargetsConfig{Brokers: current.Brokers}, &config.TargetsConfig{Brokers: cfg.Brokers}) {
+			cfg.Generation, cfg.UpdateTime = current.Generation, current.UpdateTime
+			return cfg, nil
+		}
+		if current.Generation > latest {
+			latest = current.Generation
+		}
+	}
+	cfg.Generation, cfg.UpdateTime = latest+1, timestamppb.New(r.clock.Now())
+	return cfg, nil
+}
+
+// targetsConfigOf returns a copy of the targets config.
+func targetsConfigOf(brokerTargets config.Targets) (*config.TargetsConfig, error) {
+	data, err := brokerTargets.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("error serializing targets config: %w", err)
+	}
+	var val config.TargetsConfig
+	if err := proto.Unmarshal(data, &val); err != nil {
+		return nil, fmt.Errorf("error deserializing targets config: %w", err)
+	}
+	return &val, nil
+}
+
+// stampTargetsConfig splits the targets config into shards and stamps the generation of each
+// shard. A shard with the same brokers as its configmap keeps the stamp of the configmap so
+// that it isn't rewritten. The other shards are stamped with the next generation.
+func (r *Reconciler) stampTargetsConfig(bc *intv1alpha1.BrokerCell, brokerTargets config.Targets) ([]*config.TargetsConfig, error) {
+	val, err := targetsConfigOf(brokerTargets)
+	if err != nil {
+		return nil, err
+	}
+	shards := config.SplitShards(val, r.env.TargetsConfigShards)
+
+	latest := bc.Status.TargetsConfigGeneration
+	var changed []*config.TargetsConfig
+	for i, shard := range shards {
+		current, err := r.currentTargetsConfig(bc, i)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil {
+			if current.Generation > latest {
+				latest = current.Generation
+			}
+			if proto.Equal(&config.TargetsConfig{Brokers: current.Brokers}, &config.TargetsConfig{Brokers: shard.Brokers}) {
+				shard.Generation, shard.UpdateTime = current.Generation, current.UpdateTime
+				continue
+			}
+		}
+		changed = append(changed, shard)
+	}
+	now := timestamppb.New(r.clock.Now())
+	for _, shard := range changed {
+		shard.Generation, shard.UpdateTime = latest+1, now
+	}
+	return shards, nil
+}
+
+// currentTargetsConfig returns the shard of the targets config in its configmap, or nil if
+// the configmap doesn't exist or can't be parsed.
+func (r *Reconciler) currentTargetsConfig(bc *intv1alpha1.BrokerCell, shard int) (*config.TargetsConfig, error) {
+	cm, err := r.configMapLister.ConfigMaps(bc.Namespace).Get(resources.TargetsConfigName(bc, shard))
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting targets config: %w", err)
+	}
+	current, err := resources.ParseTargetsConfig(cm)
+	if err != nil {
+		// The configmap is rewritten with a new generation.
+		return nil, nil
+	}
+	return current, nil
+}
+
+// TODO all this stuff should be in a configmap variant of the config object
+func (r *Reconciler) updateTargetsConfig(ctx context.Context, bc *intv1alpha1.BrokerCell, shards []*config.TargetsConfig) error {
+	desired, err := resources.MakeTargetsConfig(bc, shards)
+	if err != nil {
+		return fmt.Errorf("error creating targets config: %w", err)
+	}
+
+	// Only the shards that changed are rewritten, and the pods are refreshed once.
+	changed := false
 	handlerFuncs := cache.ResourceEventHandlerFuncs{
-		AddFunc:    func(obj interface{}) { r.refreshPodVolume(ctx, bc) },
-		UpdateFunc: func(oldObj, newObj interface{}) { r.refreshPodVolume(ctx, bc) },
+		AddFunc:    func(obj interface{}) { changed = true },
+		UpdateFunc: func(oldObj, newObj interface{}) { changed = true },
 		DeleteFunc: nil,
 	}
+	names := sets.NewString()
+	for _, cm := range desired {
+		names.Insert(cm.Name)
+		if _, err := r.cmRec.ReconcileConfigMap(bc, cm, handlerFuncs); err != nil {
+			return err
+		}
+	}
+	if changed {
+		r.refreshPodVolume(ctx, bc)
+	}
+	return r.deleteStaleTargetsConfig(bc, names)
+}
 
-	_, err = r.cmRec.ReconcileConfigMap(bc, desired, handlerFuncs)
-	return err
+// deleteStaleTargetsConfig deletes the targets config shards other than the named ones, e.g.
+// the shards left from a larger number of shards. The pods that still project the deleted
+// shards keep running since the projections are optional, and the remaining shards hold
+// the whole config.
+func (r *Reconciler) deleteStaleTargetsConfig(bc *intv1alpha1.BrokerCell, names sets.String) error {
+	cms, err := r.configMapLister.ConfigMaps(bc.Namespace).List(labels.SelectorFromSet(resources.TargetsConfigLabels(bc)))
+	if err != nil {
+		return fmt.Errorf("error listing targets config: %w", err)
+	}
+	for _, cm := range cms {
+		if names.Has(cm.Name) || !metav1.IsControlledBy(cm, bc) {
+			continue
+		}
+		if err := r.KubeClientSet.CoreV1().ConfigMaps(cm.Namespace).Delete(cm.Name, nil); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("error deleting stale targets config %s: %w", cm.Name, err)
+		}
+	}
+	return nil
 }
 
 func (r *Reconciler) refreshPodVolume(ctx context.Context, bc *intv1alpha1.BrokerCell) {
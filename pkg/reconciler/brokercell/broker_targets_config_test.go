@@ -0,0 +1,129 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercell
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config"
+)
+
+func TestToConfigFilters(t *testing.T) {
+	filters := []brokerv1beta1.SubscriptionsAPIFilter{{
+		Prefix: map[string]string{"type": "com.example."},
+	}, {
+		Any: []brokerv1beta1.SubscriptionsAPIFilter{{
+			Exact: map[string]string{"source": "a"},
+		}, {
+			Not: &brokerv1beta1.SubscriptionsAPIFilter{SQL: "priority > 3"},
+		}},
+	}, {
+		All: []brokerv1beta1.SubscriptionsAPIFilter{{
+			Suffix: map[string]string{"subject": ".png"},
+		}},
+	}}
+	want := []*config.Filter{{
+		Dialect: &config.Filter_Prefix{Prefix: &config.AttributesFilter{Attributes: map[string]string{"type": "com.example."}}},
+	}, {
+		Dialect: &config.Filter_Any{Any: &config.FilterList{Filters: []*config.Filter{{
+			Dialect: &config.Filter_Exact{Exact: &config.AttributesFilter{Attributes: map[string]string{"source": "a"}}},
+		}, {
+			Dialect: &config.Filter_Not{Not: &config.Filter{Dialect: &config.Filter_Cesql{Cesql: "priority > 3"}}},
+		}}}},
+	}, {
+		Dialect: &config.Filter_All{All: &config.FilterList{Filters: []*config.Filter{{
+			Dialect: &config.Filter_Suffix{Suffix: &config.AttributesFilter{Attributes: map[string]string{"subject": ".png"}}},
+		}}}},
+	}}
+	if diff := cmp.Diff(want, toConfigFilters(filters), protocmp.Transform()); diff != "" {
+		t.Errorf("toConfigFilters() (-want,+got): %s", diff)
+	}
+	if got := toConfigFilters(nil); got != nil {
+		t.Errorf("toConfigFilters(nil) got=%v, want=nil", got)
+	}
+}
+
+func TestToConfigTransformations(t *testing.T) {
+	transformations := []brokerv1beta1.EventTransformation{{
+		Set: map[string]string{"type": "com.example.order"},
+	}, {
+		Remove: []string{"subject"},
+	}, {
+		Rename: map[string]string{"time": "sent"},
+	}, {
+		Extract: map[string]string{"orderid": "{.order.id}"},
+	}, {
+		Data: "{{ json .data.order }}",
+	}}
+	want := []*config.Transformation{{
+		Operation: &config.Transformation_Set{Set: &config.AttributeValues{Values: map[string]string{"type": "com.example.order"}}},
+	}, {
+		Operation: &config.Transformation_Remove{Remove: &config.AttributeNames{Names: []string{"subject"}}},
+	}, {
+		Operation: &config.Transformation_Rename{Rename: &config.AttributeValues{Values: map[string]string{"time": "sent"}}},
+	}, {
+		Operation: &config.Transformation_Extract{Extract: &config.AttributeValues{Values: map[string]string{"orderid": "{.order.id}"}}},
+	}, {
+		Operation: &config.Transformation_DataTemplate{DataTemplate: "{{ json .data.order }}"},
+	}}
+	if diff := cmp.Diff(want, toConfigTransformations(transformations), protocmp.Transform()); diff != "" {
+		t.Errorf("toConfigTransformations() (-want,+got): %s", diff)
+	}
+	if got := toConfigTransformations(nil); got != nil {
+		t.Errorf("toConfigTransformations(nil) got=%v, want=nil", got)
+	}
+}
+
+func TestToConfigRateLimit(t *testing.T) {
+	want := &config.RateLimit{EventsPerSecond: 2.5, Burst: 5}
+	got := toConfigRateLimit(&brokerv1beta1.RateLimit{EventsPerSecond: 2.5, Burst: 5})
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("toConfigRateLimit() (-want,+got): %s", diff)
+	}
+	if got := toConfigRateLimit(nil); got != nil {
+		t.Errorf("toConfigRateLimit(nil) got=%v, want=nil", got)
+	}
+}
+
+func TestToConfigCircuitBreaker(t *testing.T) {
+	want := &config.CircuitBreaker{FailureThreshold: 5, CoolDownSeconds: 90}
+	got := toConfigCircuitBreaker(&brokerv1beta1.CircuitBreaker{FailureThreshold: 5, CoolDown: 90 * time.Second})
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("toConfigCircuitBreaker() (-want,+got): %s", diff)
+	}
+	if got := toConfigCircuitBreaker(nil); got != nil {
+		t.Errorf("toConfigCircuitBreaker(nil) got=%v, want=nil", got)
+	}
+}
+
+func TestToConfigDeliveryAuth(t *testing.T) {
+	cases := map[string]config.DeliveryAuth{
+		"":                                      config.DeliveryAuth_NO_AUTH,
+		brokerv1beta1.DeliveryAuthGoogleIDToken: config.DeliveryAuth_GOOGLE_ID_TOKEN,
+		brokerv1beta1.DeliveryAuthKubernetesToken: config.DeliveryAuth_KUBERNETES_TOKEN,
+	}
+	for auth, want := range cases {
+		if got := toConfigDeliveryAuth(auth); got != want {
+			t.Errorf("toConfigDeliveryAuth(%q) got=%v, want=%v", auth, got, want)
+		}
+	}
+}
@@ -26,22 +26,35 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/discovery"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	hpav2beta2listers "k8s.io/client-go/listers/autoscaling/v2beta2"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/eventing/pkg/logging"
 	"knative.dev/eventing/pkg/reconciler/names"
 	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/system"
 
+	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	bcreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/intevents/v1alpha1/brokercell"
 	brokerlisters "github.com/google/knative-gcp/pkg/client/listers/broker/v1beta1"
 	"github.com/google/knative-gcp/pkg/reconciler"
+	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+	"github.com/google/knative-gcp/pkg/reconciler/intevents/pullsubscription/keda"
 	reconcilerutils "github.com/google/knative-gcp/pkg/reconciler/utils"
 )
 
+// targetsServiceName is the name of the service of the controller streaming the broker
+// targets config.
+const targetsServiceName = "broker-targets"
+
 type envConfig struct {
 	IngressImage       string `envconfig:"INGRESS_IMAGE" required:"true"`
 	FanoutImage        string `envconfig:"FANOUT_IMAGE" required:"true"`
@@ -49,6 +62,14 @@ type envConfig struct {
 	ServiceAccountName string `envconfig:"SERVICE_ACCOUNT" default:"broker"`
 	IngressPort        int    `envconfig:"INGRESS_PORT" default:"8080"`
 	MetricsPort        int    `envconfig:"METRICS_PORT" default:"9090"`
+
+	// TargetsServicePort is the port the controller streams the broker targets config
+	// on. If it's 0, data plane pods only load the targets config from the configmap.
+	TargetsServicePort int `envconfig:"TARGETS_SERVICE_PORT"`
+
+	// TargetsConfigShards is the number of configmaps the broker targets config is
+	// sharded across, so that large configs fit within the configmap size limit.
+	TargetsConfigShards int `envconfig:"TARGETS_CONFIG_SHARDS" default:"1"`
 }
 
 type listers struct {
@@ -91,6 +112,8 @@ func NewReconciler(base *reconciler.Base, ls listers) (*Reconciler, error) {
 		svcRec:        svcRec,
 		deploymentRec: deploymentRec,
 		cmRec:         cmRec,
+		clock:         clock.RealClock{},
+		discoveryFn:   discovery.ServerSupportsVersion,
 	}
 	return r, nil
 }
@@ -105,6 +128,24 @@ type Reconciler struct {
 	deploymentRec *reconcilerutils.DeploymentReconciler
 	cmRec         *reconcilerutils.ConfigMapReconciler
 
+	// targetsServer streams the broker targets config to data plane pods. It's nil if
+	// the targets config is only distributed by the configmap.
+	targetsServer *stream.Server
+
+	// uriResolver resolves the dead letter sinks of brokers and the reply destinations of triggers.
+	uriResolver *resolver.URIResolver
+
+	// clock stamps the update time of the targets config generations.
+	clock clock.Clock
+
+	// discoveryFn is the function used to discover whether KEDA is installed for backlog
+	// autoscaling. Needed for UTs purposes.
+	discoveryFn keda.DiscoverFunc
+
+	// projectID is the project of the Pub/Sub subscriptions that the backlog autoscaling
+	// queries the metrics of.
+	projectID string
+
 	env envConfig
 }
 
@@ -132,6 +173,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, bc *intv1alpha1.BrokerCe
 	if err := r.reconcileConfig(ctx, bc); err != nil {
 		return err
 	}
+	if err := r.propagateTargetsConfigPods(bc); err != nil {
+		logging.FromContext(ctx).Error("Failed to count the pods that applied the targets config", zap.Error(err))
+		return err
+	}
+	r.propagateOpenCircuitBreakers(bc)
 
 	// Reconcile ingress deployment, HPA and service.
 	ingressArgs := r.makeIngressArgs(bc)
@@ -159,7 +205,14 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, bc *intv1alpha1.BrokerCe
 	hostName := names.ServiceHostName(endpoints.GetName(), endpoints.GetNamespace())
 	bc.Status.IngressTemplate = fmt.Sprintf("http://%s/{namespace}/{name}", hostName)
 
-	// Reconcile fanout deployment and HPA.
+	// The ScaledObjects of the fanout and retry share the TriggerAuthentication of the brokercell.
+	if err := r.reconcileKedaObject(ctx, bc, resources.TriggerAuthenticationGVK, resources.TriggerAuthenticationName(bc), makeTriggerAuthentication(bc)); err != nil {
+		logging.FromContext(ctx).Error("Failed to reconcile TriggerAuthentication", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
+		bc.Status.MarkFanoutFailed("TriggerAuthenticationFailed", "Failed to reconcile KEDA TriggerAuthentication: %v", err)
+		return err
+	}
+
+	// Reconcile fanout deployment and autoscaling.
 	fd, err := r.deploymentRec.ReconcileDeployment(bc, resources.MakeFanoutDeployment(r.makeFanoutArgs(bc)))
 	if err != nil {
 		logging.FromContext(ctx).Error("Failed to reconcile fanout deployment", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
@@ -167,15 +220,31 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, bc *intv1alpha1.BrokerCe
 		return err
 	}
 
+	// The fanout is scaled either by its HPA or by a ScaledObject on the backlog of its
+	// subscriptions, the other one is deleted as they'd fight over the replicas.
 	fanoutHPA := resources.MakeHorizontalPodAutoscaler(fd, r.makeFanoutHPAArgs(bc))
-	if err := r.reconcileAutoscaling(ctx, bc, fanoutHPA); err != nil {
+	fanoutSO, err := r.makeFanoutScaledObject(bc, fd)
+	if err == nil {
+		err = r.reconcileKedaObject(ctx, bc, resources.ScaledObjectGVK, resources.ScaledObjectName(fd), fanoutSO)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to reconcile fanout ScaledObject", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
+		bc.Status.MarkFanoutFailed("ScaledObjectFailed", "Failed to reconcile fanout ScaledObject: %v", err)
+		return err
+	}
+	if fanoutSO != nil {
+		err = r.deleteAutoscaling(ctx, bc, fanoutHPA)
+	} else {
+		err = r.reconcileAutoscaling(ctx, bc, fanoutHPA)
+	}
+	if err != nil {
 		logging.FromContext(ctx).Error("Failed to reconcile fanout HPA", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
 		bc.Status.MarkFanoutFailed("HorizontalPodAutoscalerFailed", "Failed to reconcile fanout HorizontalPodAutoscaler: %v", err)
 		return err
 	}
 	bc.Status.PropagateFanoutAvailability(fd)
 
-	// Reconcile retry deployment and HPA.
+	// Reconcile retry deployment and autoscaling.
 	rd, err := r.deploymentRec.ReconcileDeployment(bc, resources.MakeRetryDeployment(r.makeRetryArgs(bc)))
 	if err != nil {
 		logging.FromContext(ctx).Error("Failed to reconcile retry deployment", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
@@ -184,7 +253,21 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, bc *intv1alpha1.BrokerCe
 	}
 
 	retryHPA := resources.MakeHorizontalPodAutoscaler(rd, r.makeRetryHPAArgs(bc))
-	if err := r.reconcileAutoscaling(ctx, bc, retryHPA); err != nil {
+	retrySO, err := r.makeRetryScaledObject(bc, rd)
+	if err == nil {
+		err = r.reconcileKedaObject(ctx, bc, resources.ScaledObjectGVK, resources.ScaledObjectName(rd), retrySO)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to reconcile retry ScaledObject", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
+		bc.Status.MarkRetryFailed("ScaledObjectFailed", "Failed to reconcile retry ScaledObject: %v", err)
+		return err
+	}
+	if retrySO != nil {
+		err = r.deleteAutoscaling(ctx, bc, retryHPA)
+	} else {
+		err = r.reconcileAutoscaling(ctx, bc, retryHPA)
+	}
+	if err != nil {
 		logging.FromContext(ctx).Error("Failed to reconcile retry HPA", zap.Any("namespace", bc.Namespace), zap.Any("name", bc.Name), zap.Error(err))
 		bc.Status.MarkRetryFailed("HorizontalPodAutoscalerFailed", "Failed to reconcile retry HorizontalPodAutoscaler: %v", err)
 		return err
@@ -198,7 +281,7 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, bc *intv1alpha1.BrokerCe
 // shouldGC returns true if
 // 1. the brokercell was automatically created by GCP broker controller (with annotation
 // internal.events.cloud.google.com/creator: googlecloud), and
-// 2. there is no brokers pointing to it
+// 2. there is no brokers placed on it
 func (r *Reconciler) shouldGC(ctx context.Context, bc *intv1alpha1.BrokerCell) bool {
 	// TODO use the constants in #1132 once it's merged
 	// We only garbage collect brokercells that were automatically created by the GCP broker controller.
@@ -206,10 +289,7 @@ func (r *Reconciler) shouldGC(ctx context.Context, bc *intv1alpha1.BrokerCell) b
 		return false
 	}
 
-	// TODO(#866) Only select brokers that point to this brokercell by label selector once the
-	// webhook assigns the brokercell label, i.e.,
-	// r.brokerLister.List(labels.SelectorFromSet(map[string]string{"brokercell":bc.Name, "brokercellns":bc.Namespace}))
-	brokers, err := r.brokerLister.List(labels.Everything())
+	brokers, err := r.brokersOf(bc)
 	if err != nil {
 		logging.FromContext(ctx).Error("Failed to list brokers, skipping garbage collection logic", zap.String("brokercell", bc.Name), zap.String("Namespace", bc.Namespace))
 		return false
@@ -218,6 +298,21 @@ func (r *Reconciler) shouldGC(ctx context.Context, bc *intv1alpha1.BrokerCell) b
 	return len(brokers) == 0
 }
 
+// brokersOf returns the brokers placed on the brokercell.
+func (r *Reconciler) brokersOf(bc *intv1alpha1.BrokerCell) ([]*brokerv1beta1.Broker, error) {
+	brokers, err := r.brokerLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var placed []*brokerv1beta1.Broker
+	for _, b := range brokers {
+		if brokerresources.IsPlacedOn(b, bc) {
+			placed = append(placed, b)
+		}
+	}
+	return placed, nil
+}
+
 func (r *Reconciler) delete(ctx context.Context, bc *intv1alpha1.BrokerCell) pkgreconciler.Event {
 	if err := r.RunClientSet.InternalV1alpha1().BrokerCells(bc.Namespace).Delete(bc.Name, nil); err != nil {
 		return fmt.Errorf("failed to garbage collect brokercell: %w", err)
@@ -225,81 +320,72 @@ func (r *Reconciler) delete(ctx context.Context, bc *intv1alpha1.BrokerCell) pkg
 	return pkgreconciler.NewEvent(corev1.EventTypeNormal, "BrokerCellGarbageCollected", "BrokerCell garbage collected: \"%s/%s\"", bc.Namespace, bc.Name)
 }
 
+// targetsConfigService returns the address of the service streaming the broker targets
+// config, or "" if it's disabled.
+func (r *Reconciler) targetsConfigService() string {
+	if r.env.TargetsServicePort == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", names.ServiceHostName(targetsServiceName, system.Namespace()), r.env.TargetsServicePort)
+}
+
 func (r *Reconciler) makeIngressArgs(bc *intv1alpha1.BrokerCell) resources.IngressArgs {
-	return resources.IngressArgs{
-		Args: resources.Args{
-			ComponentName:      resources.IngressName,
-			BrokerCell:         bc,
-			Image:              r.env.IngressImage,
-			ServiceAccountName: r.env.ServiceAccountName,
-			MetricsPort:        r.env.MetricsPort,
-		},
+	args := resources.IngressArgs{
+		Args: withComponentParameters(resources.Args{
+			ComponentName:        resources.IngressName,
+			BrokerCell:           bc,
+			Image:                r.env.IngressImage,
+			ServiceAccountName:   r.env.ServiceAccountName,
+			MetricsPort:          r.env.MetricsPort,
+			TargetsConfigService: r.targetsConfigService(),
+			TargetsConfigShards:  r.env.TargetsConfigShards,
+		}, ingressDefaults, ingressParameters(bc)),
 		Port: r.env.IngressPort,
 	}
+	if bc.Spec.Ingress != nil {
+		args.PublishSettings = bc.Spec.Ingress.PublishSettings
+	}
+	return args
 }
 
 func (r *Reconciler) makeIngressHPAArgs(bc *intv1alpha1.BrokerCell) resources.AutoscalingArgs {
-	return resources.AutoscalingArgs{
-		ComponentName:     resources.IngressName,
-		BrokerCell:        bc,
-		AvgCPUUtilization: 95,
-		AvgMemoryUsage:    "700Mi",
-		MaxReplicas:       10,
-	}
+	return makeAutoscalingArgs(bc, resources.IngressName, ingressDefaults, ingressParameters(bc))
 }
 
 func (r *Reconciler) makeFanoutArgs(bc *intv1alpha1.BrokerCell) resources.FanoutArgs {
 	return resources.FanoutArgs{
-		Args: resources.Args{
-			ComponentName:      resources.FanoutName,
-			BrokerCell:         bc,
-			Image:              r.env.FanoutImage,
-			ServiceAccountName: r.env.ServiceAccountName,
-			MetricsPort:        r.env.MetricsPort,
-		},
+		Args: withComponentParameters(resources.Args{
+			ComponentName:        resources.FanoutName,
+			BrokerCell:           bc,
+			Image:                r.env.FanoutImage,
+			ServiceAccountName:   r.env.ServiceAccountName,
+			MetricsPort:          r.env.MetricsPort,
+			TargetsConfigService: r.targetsConfigService(),
+			TargetsConfigShards:  r.env.TargetsConfigShards,
+		}, fanoutDefaults, bc.Spec.Fanout),
 	}
 }
 
 func (r *Reconciler) makeFanoutHPAArgs(bc *intv1alpha1.BrokerCell) resources.AutoscalingArgs {
-	return resources.AutoscalingArgs{
-		ComponentName:     resources.FanoutName,
-		BrokerCell:        bc,
-		AvgCPUUtilization: 95,
-		// The limit we set is 3000Mi which is mostly used to prevent surging
-		// memory usage causing OOM.
-		// Here we only set half of the limit so that in case of surging memory
-		// usage, HPA could have enough time to kick in.
-		// See: https://github.com/google/knative-gcp/issues/1265
-		AvgMemoryUsage: "1500Mi",
-		MaxReplicas:    10,
-	}
+	return makeAutoscalingArgs(bc, resources.FanoutName, fanoutDefaults, bc.Spec.Fanout)
 }
 
 func (r *Reconciler) makeRetryArgs(bc *intv1alpha1.BrokerCell) resources.RetryArgs {
 	return resources.RetryArgs{
-		Args: resources.Args{
-			ComponentName:      resources.RetryName,
-			BrokerCell:         bc,
-			Image:              r.env.RetryImage,
-			ServiceAccountName: r.env.ServiceAccountName,
-			MetricsPort:        r.env.MetricsPort,
-		},
+		Args: withComponentParameters(resources.Args{
+			ComponentName:        resources.RetryName,
+			BrokerCell:           bc,
+			Image:                r.env.RetryImage,
+			ServiceAccountName:   r.env.ServiceAccountName,
+			MetricsPort:          r.env.MetricsPort,
+			TargetsConfigService: r.targetsConfigService(),
+			TargetsConfigShards:  r.env.TargetsConfigShards,
+		}, retryDefaults, bc.Spec.Retry),
 	}
 }
 
 func (r *Reconciler) makeRetryHPAArgs(bc *intv1alpha1.BrokerCell) resources.AutoscalingArgs {
-	return resources.AutoscalingArgs{
-		ComponentName:     resources.RetryName,
-		BrokerCell:        bc,
-		AvgCPUUtilization: 95,
-		// The limit we set is 3000Mi which is mostly used to prevent surging
-		// memory usage causing OOM.
-		// Here we only set half of the limit so that in case of surging memory
-		// usage, HPA could have enough time to kick in.
-		// See: https://github.com/google/knative-gcp/issues/1265
-		AvgMemoryUsage: "1500Mi",
-		MaxReplicas:    10,
-	}
+	return makeAutoscalingArgs(bc, resources.RetryName, retryDefaults, bc.Spec.Retry)
 }
 
 func (r *Reconciler) reconcileAutoscaling(ctx context.Context, bc *intv1alpha1.BrokerCell, desired *hpav2beta2.HorizontalPodAutoscaler) error {
@@ -330,3 +416,20 @@ func (r *Reconciler) reconcileAutoscaling(ctx context.Context, bc *intv1alpha1.B
 	}
 	return nil
 }
+
+// deleteAutoscaling deletes the HPA if it exists.
+func (r *Reconciler) deleteAutoscaling(ctx context.Context, bc *intv1alpha1.BrokerCell, hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	if _, err := r.hpaLister.HorizontalPodAutoscalers(hpa.Namespace).Get(hpa.Name); apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	err := r.KubeClientSet.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace).Delete(hpa.Name, &metav1.DeleteOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	if err == nil {
+		r.Recorder.Eventf(bc, corev1.EventTypeNormal, "HorizontalPodAutoscalerDeleted", "Deleted HPA %s/%s", hpa.Namespace, hpa.Name)
+	}
+	return err
+}
@@ -19,74 +19,104 @@ package brokercell
 import (
 	"context"
 	"fmt"
+	"net"
 	"testing"
+	"time"
 
+	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	clientgotesting "k8s.io/client-go/testing"
 
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/client/injection/ducks/duck/v1/addressable"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	logtesting "knative.dev/pkg/logging/testing"
 	. "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/system"
 
 	"github.com/google/go-cmp/cmp"
+	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	bcreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/intevents/v1alpha1/brokercell"
 	"github.com/google/knative-gcp/pkg/reconciler"
+	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/brokercell/testingdata"
 	. "github.com/google/knative-gcp/pkg/reconciler/testing"
 
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 )
 
 const (
 	testNS         = "testnamespace"
+	systemNS       = "knative-testing"
 	brokerCellName = "test-brokercell"
 	targetsCMName  = "broker-targets"
 	targetsCMKey   = "targets"
+	testProject    = "test-project"
 )
 
 var (
-	testKey = fmt.Sprintf("%s/%s", testNS, brokerCellName)
+	testKey = fmt.Sprintf("%s/%s", systemNS, brokerCellName)
+
+	// withPlacement places the broker on the brokercell under test.
+	withPlacement = WithBrokerAnnotation(brokerv1beta1.BrokerCellAnnotationKey, brokerCellName)
+
+	// testTime is the time of the fake clock stamping the targets config.
+	testTime = time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
 
 	creatorAnnotation = map[string]string{"internal.events.cloud.google.com/creator": "googlecloud"}
 
-	brokerCellReconciledEvent     = Eventf(corev1.EventTypeNormal, "BrokerCellReconciled", `BrokerCell reconciled: "testnamespace/test-brokercell"`)
-	brokerCellGCEvent             = Eventf(corev1.EventTypeNormal, "BrokerCellGarbageCollected", `BrokerCell garbage collected: "testnamespace/test-brokercell"`)
-	brokerCellGCFailedEvent       = Eventf(corev1.EventTypeWarning, "InternalError", `failed to garbage collect brokercell: inducing failure for delete brokercells`)
-	brokerCellUpdateFailedEvent   = Eventf(corev1.EventTypeWarning, "UpdateFailed", `Failed to update status for "test-brokercell": inducing failure for update brokercells`)
-	ingressDeploymentCreatedEvent = Eventf(corev1.EventTypeNormal, "DeploymentCreated", "Created deployment testnamespace/test-brokercell-brokercell-ingress")
-	ingressDeploymentUpdatedEvent = Eventf(corev1.EventTypeNormal, "DeploymentUpdated", "Updated deployment testnamespace/test-brokercell-brokercell-ingress")
-	ingressHPACreatedEvent        = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerCreated", "Created HPA testnamespace/test-brokercell-brokercell-ingress-hpa")
-	ingressHPAUpdatedEvent        = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerUpdated", "Updated HPA testnamespace/test-brokercell-brokercell-ingress-hpa")
-	fanoutDeploymentCreatedEvent  = Eventf(corev1.EventTypeNormal, "DeploymentCreated", "Created deployment testnamespace/test-brokercell-brokercell-fanout")
-	fanoutDeploymentUpdatedEvent  = Eventf(corev1.EventTypeNormal, "DeploymentUpdated", "Updated deployment testnamespace/test-brokercell-brokercell-fanout")
-	fanoutHPACreatedEvent         = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerCreated", "Created HPA testnamespace/test-brokercell-brokercell-fanout-hpa")
-	fanoutHPAUpdatedEvent         = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerUpdated", "Updated HPA testnamespace/test-brokercell-brokercell-fanout-hpa")
-	retryDeploymentCreatedEvent   = Eventf(corev1.EventTypeNormal, "DeploymentCreated", "Created deployment testnamespace/test-brokercell-brokercell-retry")
-	retryDeploymentUpdatedEvent   = Eventf(corev1.EventTypeNormal, "DeploymentUpdated", "Updated deployment testnamespace/test-brokercell-brokercell-retry")
-	retryHPACreatedEvent          = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerCreated", "Created HPA testnamespace/test-brokercell-brokercell-retry-hpa")
-	retryHPAUpdatedEvent          = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerUpdated", "Updated HPA testnamespace/test-brokercell-brokercell-retry-hpa")
-	ingressServiceCreatedEvent    = Eventf(corev1.EventTypeNormal, "ServiceCreated", "Created service testnamespace/test-brokercell-brokercell-ingress")
-	ingressServiceUpdatedEvent    = Eventf(corev1.EventTypeNormal, "ServiceUpdated", "Updated service testnamespace/test-brokercell-brokercell-ingress")
-	deploymentCreationFailedEvent = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create deployments")
-	deploymentUpdateFailedEvent   = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update deployments")
-	serviceCreationFailedEvent    = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create services")
-	serviceUpdateFailedEvent      = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update services")
-	hpaCreationFailedEvent        = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create horizontalpodautoscalers")
-	hpaUpdateFailedEvent          = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update horizontalpodautoscalers")
-	configmapCreationFailedEvent  = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create configmaps")
-	configmapUpdateFailedEvent    = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update configmaps")
-	configmapCreatedEvent         = Eventf(corev1.EventTypeNormal, "ConfigMapCreated", "Created configmap testnamespace/test-brokercell-brokercell-broker-targets")
-	configmapUpdatedEvent         = Eventf(corev1.EventTypeNormal, "ConfigMapUpdated", "Updated configmap testnamespace/test-brokercell-brokercell-broker-targets")
+	brokerCellReconciledEvent      = Eventf(corev1.EventTypeNormal, "BrokerCellReconciled", `BrokerCell reconciled: "knative-testing/test-brokercell"`)
+	brokerCellGCEvent              = Eventf(corev1.EventTypeNormal, "BrokerCellGarbageCollected", `BrokerCell garbage collected: "knative-testing/test-brokercell"`)
+	brokerCellGCFailedEvent        = Eventf(corev1.EventTypeWarning, "InternalError", `failed to garbage collect brokercell: inducing failure for delete brokercells`)
+	brokerCellUpdateFailedEvent    = Eventf(corev1.EventTypeWarning, "UpdateFailed", `Failed to update status for "test-brokercell": inducing failure for update brokercells`)
+	ingressDeploymentCreatedEvent  = Eventf(corev1.EventTypeNormal, "DeploymentCreated", "Created deployment knative-testing/test-brokercell-brokercell-ingress")
+	ingressDeploymentUpdatedEvent  = Eventf(corev1.EventTypeNormal, "DeploymentUpdated", "Updated deployment knative-testing/test-brokercell-brokercell-ingress")
+	ingressHPACreatedEvent         = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerCreated", "Created HPA knative-testing/test-brokercell-brokercell-ingress-hpa")
+	ingressHPAUpdatedEvent         = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerUpdated", "Updated HPA knative-testing/test-brokercell-brokercell-ingress-hpa")
+	fanoutDeploymentCreatedEvent   = Eventf(corev1.EventTypeNormal, "DeploymentCreated", "Created deployment knative-testing/test-brokercell-brokercell-fanout")
+	fanoutDeploymentUpdatedEvent   = Eventf(corev1.EventTypeNormal, "DeploymentUpdated", "Updated deployment knative-testing/test-brokercell-brokercell-fanout")
+	fanoutHPACreatedEvent          = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerCreated", "Created HPA knative-testing/test-brokercell-brokercell-fanout-hpa")
+	fanoutHPAUpdatedEvent          = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerUpdated", "Updated HPA knative-testing/test-brokercell-brokercell-fanout-hpa")
+	retryDeploymentCreatedEvent    = Eventf(corev1.EventTypeNormal, "DeploymentCreated", "Created deployment knative-testing/test-brokercell-brokercell-retry")
+	retryDeploymentUpdatedEvent    = Eventf(corev1.EventTypeNormal, "DeploymentUpdated", "Updated deployment knative-testing/test-brokercell-brokercell-retry")
+	retryHPACreatedEvent           = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerCreated", "Created HPA knative-testing/test-brokercell-brokercell-retry-hpa")
+	retryHPAUpdatedEvent           = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerUpdated", "Updated HPA knative-testing/test-brokercell-brokercell-retry-hpa")
+	ingressServiceCreatedEvent     = Eventf(corev1.EventTypeNormal, "ServiceCreated", "Created service knative-testing/test-brokercell-brokercell-ingress")
+	ingressServiceUpdatedEvent     = Eventf(corev1.EventTypeNormal, "ServiceUpdated", "Updated service knative-testing/test-brokercell-brokercell-ingress")
+	deploymentCreationFailedEvent  = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create deployments")
+	deploymentUpdateFailedEvent    = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update deployments")
+	serviceCreationFailedEvent     = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create services")
+	serviceUpdateFailedEvent       = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update services")
+	hpaCreationFailedEvent         = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create horizontalpodautoscalers")
+	hpaUpdateFailedEvent           = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update horizontalpodautoscalers")
+	configmapCreationFailedEvent   = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create configmaps")
+	configmapUpdateFailedEvent     = Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for update configmaps")
+	configmapCreatedEvent          = Eventf(corev1.EventTypeNormal, "ConfigMapCreated", "Created configmap knative-testing/test-brokercell-brokercell-broker-targets")
+	configmapUpdatedEvent          = Eventf(corev1.EventTypeNormal, "ConfigMapUpdated", "Updated configmap knative-testing/test-brokercell-brokercell-broker-targets")
+	kedaAuthCreatedEvent           = Eventf(corev1.EventTypeNormal, "TriggerAuthenticationCreated", "Created TriggerAuthentication knative-testing/test-brokercell-brokercell-keda-auth")
+	fanoutScaledObjectCreatedEvent = Eventf(corev1.EventTypeNormal, "ScaledObjectCreated", "Created ScaledObject knative-testing/test-brokercell-brokercell-fanout-scaledobject")
+	fanoutHPADeletedEvent          = Eventf(corev1.EventTypeNormal, "HorizontalPodAutoscalerDeleted", "Deleted HPA knative-testing/test-brokercell-brokercell-fanout-hpa")
 )
 
 func init() {
@@ -108,7 +138,7 @@ func TestAllCases(t *testing.T) {
 			Name: "BrokerCell is being deleted",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS,
+				NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithBrokerCellDeletionTimestamp,
 					WithBrokerCellSetDefaults,
@@ -119,54 +149,54 @@ func TestAllCases(t *testing.T) {
 			Name: "ConfigMap.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
 			},
 			WithReactors: []clientgotesting.ReactionFunc{InduceFailure("create", "configmaps")},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigFailed(configFailed, "failed to update configmap: inducing failure for create configmaps"),
 					WithBrokerCellSetDefaults,
 				),
 			}},
 			WantEvents:  []string{configmapCreationFailedEvent},
-			WantCreates: []runtime.Object{testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults))},
+			WantCreates: []runtime.Object{stampedConfig(t, testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)), 1)},
 			WantErr:     true,
 		},
 		{
 			Name: "ConfigMap.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewBroker("broker", testNS, WithBrokerSetDefaults),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
 			},
 			WithReactors: []clientgotesting.ReactionFunc{InduceFailure("update", "configmaps")},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigFailed(configFailed, "failed to update configmap: inducing failure for update configmaps"),
 					WithBrokerCellSetDefaults,
 				),
 			}},
 			WantEvents: []string{configmapUpdateFailedEvent},
-			WantUpdates: []clientgotesting.UpdateActionImpl{{Object: testingdata.Config(t,
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				NewBroker("broker", testNS, WithBrokerSetDefaults))}},
+			WantUpdates: []clientgotesting.UpdateActionImpl{{Object: stampedConfig(t, testingdata.Config(t,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults)), 1)}},
 			WantErr: true,
 		},
 		{
 			Name: "Ingress Deployment.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 			},
 			WithReactors: []clientgotesting.ReactionFunc{
 				InduceFailure("create", "deployments"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressFailed("IngressDeploymentFailed", `Failed to reconcile ingress deployment: inducing failure for create deployments`),
@@ -185,21 +215,21 @@ func TestAllCases(t *testing.T) {
 			Name: "Ingress Deployment.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
 				// Create an deployment such that only the spec is different from expected deployment to trigger an update.
-				NewDeployment(brokerCellName+"-brokercell-ingress", testNS,
+				NewDeployment(brokerCellName+"-brokercell-ingress", systemNS,
 					func(d *appsv1.Deployment) {
 						d.TypeMeta = testingdata.IngressDeployment(t).TypeMeta
 						d.ObjectMeta = testingdata.IngressDeployment(t).ObjectMeta
 					},
 				),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 			},
 			WithReactors: []clientgotesting.ReactionFunc{
 				InduceFailure("update", "deployments"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressFailed("IngressDeploymentFailed", `Failed to reconcile ingress deployment: inducing failure for update deployments`),
@@ -218,15 +248,15 @@ func TestAllCases(t *testing.T) {
 			Name: "Ingress HorizontalPodAutoscaler.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressDeploymentWithStatus(t),
 			},
 			WithReactors: []clientgotesting.ReactionFunc{
 				InduceFailure("create", "horizontalpodautoscalers"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressFailed("HorizontalPodAutoscalerFailed", `Failed to reconcile ingress HorizontalPodAutoscaler: inducing failure for create horizontalpodautoscalers`),
@@ -245,8 +275,8 @@ func TestAllCases(t *testing.T) {
 			Name: "Ingress HorizontalPodAutoscaler.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressDeploymentWithStatus(t),
 				emptyHPASpec(testingdata.IngressHPA(t)),
 			},
@@ -254,7 +284,7 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "horizontalpodautoscalers"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressFailed("HorizontalPodAutoscalerFailed", `Failed to reconcile ingress HorizontalPodAutoscaler: inducing failure for update horizontalpodautoscalers`),
@@ -273,18 +303,18 @@ func TestAllCases(t *testing.T) {
 			Name: "Ingress Service.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressHPA(t),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 			},
 			WithReactors: []clientgotesting.ReactionFunc{
 				InduceFailure("create", "services"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressFailed("IngressServiceFailed", `Failed to reconcile ingress service: inducing failure for create services`),
@@ -303,13 +333,13 @@ func TestAllCases(t *testing.T) {
 			Name: "Ingress Service.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressHPA(t),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
-				NewService(brokerCellName+"-brokercell-ingress", testNS,
+				NewService(brokerCellName+"-brokercell-ingress", systemNS,
 					func(s *corev1.Service) {
 						s.TypeMeta = testingdata.IngressService(t).TypeMeta
 						s.ObjectMeta = testingdata.IngressService(t).ObjectMeta
@@ -319,7 +349,7 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "services"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressFailed("IngressServiceFailed", `Failed to reconcile ingress service: inducing failure for update services`),
@@ -338,10 +368,10 @@ func TestAllCases(t *testing.T) {
 			Name: "Fanout Deployment.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressHPA(t),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -350,11 +380,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("create", "deployments"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutFailed("FanoutDeploymentFailed", `Failed to reconcile fanout deployment: inducing failure for create deployments`),
 					WithBrokerCellSetDefaults,
 				),
@@ -371,15 +401,15 @@ func TestAllCases(t *testing.T) {
 			Name: "Fanout Deployment.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressHPA(t),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
 				// Create an deployment such that only the spec is different from expected deployment to trigger an update.
-				NewDeployment(brokerCellName+"-brokercell-fanout", testNS,
+				NewDeployment(brokerCellName+"-brokercell-fanout", systemNS,
 					func(d *appsv1.Deployment) {
 						d.TypeMeta = testingdata.FanoutDeployment(t).TypeMeta
 						d.ObjectMeta = testingdata.FanoutDeployment(t).ObjectMeta
@@ -390,11 +420,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "deployments"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutFailed("FanoutDeploymentFailed", `Failed to reconcile fanout deployment: inducing failure for update deployments`),
 					WithBrokerCellSetDefaults,
 				),
@@ -411,10 +441,10 @@ func TestAllCases(t *testing.T) {
 			Name: "Fanout HorizontalPodAutoscaler.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressHPA(t),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -424,11 +454,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("create", "horizontalpodautoscalers"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutFailed("HorizontalPodAutoscalerFailed", `Failed to reconcile fanout HorizontalPodAutoscaler: inducing failure for create horizontalpodautoscalers`),
 					WithBrokerCellSetDefaults,
 				),
@@ -445,10 +475,10 @@ func TestAllCases(t *testing.T) {
 			Name: "Fanout HorizontalPodAutoscaler.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
 				testingdata.IngressHPA(t),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -459,11 +489,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "horizontalpodautoscalers"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutFailed("HorizontalPodAutoscalerFailed", `Failed to reconcile fanout HorizontalPodAutoscaler: inducing failure for update horizontalpodautoscalers`),
 					WithBrokerCellSetDefaults,
 				),
@@ -480,9 +510,9 @@ func TestAllCases(t *testing.T) {
 			Name: "Retry Deployment.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -494,11 +524,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("create", "deployments"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutAvailable(),
 					WithBrokerCellRetryFailed("RetryDeploymentFailed", `Failed to reconcile retry deployment: inducing failure for create deployments`),
 					WithBrokerCellSetDefaults,
@@ -517,9 +547,9 @@ func TestAllCases(t *testing.T) {
 			Name: "Retry Deployment.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -527,7 +557,7 @@ func TestAllCases(t *testing.T) {
 				testingdata.IngressHPA(t),
 				testingdata.FanoutHPA(t),
 				// Create an deployment such that only the spec is different from expected deployment to trigger an update.
-				NewDeployment(brokerCellName+"-brokercell-retry", testNS,
+				NewDeployment(brokerCellName+"-brokercell-retry", systemNS,
 					func(d *appsv1.Deployment) {
 						d.TypeMeta = testingdata.RetryDeployment(t).TypeMeta
 						d.ObjectMeta = testingdata.RetryDeployment(t).ObjectMeta
@@ -538,11 +568,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "deployments"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutAvailable(),
 					WithBrokerCellRetryFailed("RetryDeploymentFailed", `Failed to reconcile retry deployment: inducing failure for update deployments`),
 					WithBrokerCellSetDefaults,
@@ -560,9 +590,9 @@ func TestAllCases(t *testing.T) {
 			Name: "Retry HorizontalPodAutoscaler.Create error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -575,11 +605,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("create", "horizontalpodautoscalers"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutAvailable(),
 					WithBrokerCellRetryFailed("HorizontalPodAutoscalerFailed", `Failed to reconcile retry HorizontalPodAutoscaler: inducing failure for create horizontalpodautoscalers`),
 					WithBrokerCellSetDefaults,
@@ -597,9 +627,9 @@ func TestAllCases(t *testing.T) {
 			Name: "Retry HorizontalPodAutoscaler.Update error",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -613,11 +643,11 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "horizontalpodautoscalers"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-				Object: NewBrokerCell(brokerCellName, testNS,
+				Object: NewBrokerCell(brokerCellName, systemNS,
 					WithInitBrokerCellConditions,
 					WithTargetsCofigReady(),
 					WithBrokerCellIngressAvailable(),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellFanoutAvailable(),
 					WithBrokerCellRetryFailed("HorizontalPodAutoscalerFailed", `Failed to reconcile retry HorizontalPodAutoscaler: inducing failure for update horizontalpodautoscalers`),
 					WithBrokerCellSetDefaults,
@@ -635,11 +665,11 @@ func TestAllCases(t *testing.T) {
 			Name: "BrokerCell created, resources created but some resource status not ready",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS),
 			},
 			WantCreates: []runtime.Object{
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
+				stampedConfig(t, testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)), 1),
 				testingdata.IngressDeployment(t),
 				testingdata.IngressHPA(t),
 				testingdata.IngressService(t),
@@ -649,7 +679,7 @@ func TestAllCases(t *testing.T) {
 				testingdata.RetryHPA(t),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-				{Object: NewBrokerCell(brokerCellName, testNS,
+				{Object: NewBrokerCell(brokerCellName, systemNS,
 					// optimistically set everything to be ready, the following options will override individual conditions
 					WithBrokerCellReady,
 					// For newly created deployments and services, there statues are not ready because
@@ -658,7 +688,8 @@ func TestAllCases(t *testing.T) {
 					WithBrokerCellIngressFailed("EndpointsUnavailable", `Endpoints "test-brokercell-brokercell-ingress" is unavailable.`),
 					WithBrokerCellFanoutUnknown("DeploymentUnavailable", `Deployment "test-brokercell-brokercell-fanout" is unavailable.`),
 					WithBrokerCellRetryUnknown("DeploymentUnavailable", `Deployment "test-brokercell-brokercell-retry" is unavailable.`),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
+					WithTargetsConfigGeneration(1),
 					WithBrokerCellSetDefaults,
 				)},
 			},
@@ -678,29 +709,29 @@ func TestAllCases(t *testing.T) {
 			Name: "BrokerCell created, resources updated but some resource status not ready",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				NewBroker("broker", testNS, WithBrokerSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS),
-				NewDeployment(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS),
+				NewDeployment(brokerCellName+"-brokercell-ingress", systemNS,
 					func(d *appsv1.Deployment) {
 						d.TypeMeta = testingdata.IngressDeployment(t).TypeMeta
 						d.ObjectMeta = testingdata.IngressDeployment(t).ObjectMeta
 					},
 				),
-				NewService(brokerCellName+"-brokercell-ingress", testNS,
+				NewService(brokerCellName+"-brokercell-ingress", systemNS,
 					func(s *corev1.Service) {
 						s.TypeMeta = testingdata.IngressService(t).TypeMeta
 						s.ObjectMeta = testingdata.IngressService(t).ObjectMeta
 					}),
-				NewDeployment(brokerCellName+"-brokercell-fanout", testNS,
+				NewDeployment(brokerCellName+"-brokercell-fanout", systemNS,
 					func(d *appsv1.Deployment) {
 						d.TypeMeta = testingdata.FanoutDeployment(t).TypeMeta
 						d.ObjectMeta = testingdata.FanoutDeployment(t).ObjectMeta
 					},
 				),
-				NewDeployment(brokerCellName+"-brokercell-retry", testNS,
+				NewDeployment(brokerCellName+"-brokercell-retry", systemNS,
 					func(d *appsv1.Deployment) {
 						d.TypeMeta = testingdata.RetryDeployment(t).TypeMeta
 						d.ObjectMeta = testingdata.RetryDeployment(t).ObjectMeta
@@ -711,9 +742,9 @@ func TestAllCases(t *testing.T) {
 				emptyHPASpec(testingdata.RetryHPA(t)),
 			},
 			WantUpdates: []clientgotesting.UpdateActionImpl{
-				{Object: testingdata.Config(t,
-					NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-					NewBroker("broker", testNS, WithBrokerSetDefaults))},
+				{Object: stampedConfig(t, testingdata.Config(t,
+					NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+					NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults)), 1)},
 				{Object: testingdata.IngressDeployment(t)},
 				{Object: testingdata.IngressHPA(t)},
 				{Object: testingdata.IngressService(t)},
@@ -723,7 +754,7 @@ func TestAllCases(t *testing.T) {
 				{Object: testingdata.RetryHPA(t)},
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-				{Object: NewBrokerCell(brokerCellName, testNS,
+				{Object: NewBrokerCell(brokerCellName, systemNS,
 					// optimistically set everything to be ready, the following options will override individual conditions
 					WithBrokerCellReady,
 					// For newly created deployments and services, there statues are not ready because
@@ -732,7 +763,8 @@ func TestAllCases(t *testing.T) {
 					WithBrokerCellIngressFailed("EndpointsUnavailable", `Endpoints "test-brokercell-brokercell-ingress" is unavailable.`),
 					WithBrokerCellFanoutUnknown("DeploymentUnavailable", `Deployment "test-brokercell-brokercell-fanout" is unavailable.`),
 					WithBrokerCellRetryUnknown("DeploymentUnavailable", `Deployment "test-brokercell-brokercell-retry" is unavailable.`),
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
+					WithTargetsConfigGeneration(1),
 					WithBrokerCellSetDefaults,
 				)},
 			},
@@ -752,9 +784,9 @@ func TestAllCases(t *testing.T) {
 			Name: "BrokerCell created successfully but status update failed",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -768,9 +800,9 @@ func TestAllCases(t *testing.T) {
 				InduceFailure("update", "brokercells"),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-				{Object: NewBrokerCell(brokerCellName, testNS,
+				{Object: NewBrokerCell(brokerCellName, systemNS,
 					WithBrokerCellReady,
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellSetDefaults,
 				)},
 			},
@@ -783,9 +815,9 @@ func TestAllCases(t *testing.T) {
 			Name: "BrokerCell created successfully",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				testingdata.EmptyConfig(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -796,9 +828,9 @@ func TestAllCases(t *testing.T) {
 				testingdata.RetryHPA(t),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-				{Object: NewBrokerCell(brokerCellName, testNS,
+				{Object: NewBrokerCell(brokerCellName, systemNS,
 					WithBrokerCellReady,
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellSetDefaults,
 				)},
 			},
@@ -806,17 +838,75 @@ func TestAllCases(t *testing.T) {
 				brokerCellReconciledEvent,
 			},
 		},
+		{
+			Name: "Fanout scaled on the backlog of the broker subscriptions",
+			Key:  testKey,
+			Objects: []runtime.Object{
+				NewBrokerCell(brokerCellName, systemNS, withFanoutBacklogAutoscaling, WithBrokerCellSetDefaults),
+				NewBroker("broker", testNS, withPlacement, WithBrokerSubscriptionReady, WithBrokerSetDefaults),
+				stampedConfig(t, testingdata.Config(t,
+					NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+					NewBroker("broker", testNS, withPlacement, WithBrokerSubscriptionReady, WithBrokerSetDefaults)), 1),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
+					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
+				testingdata.IngressDeploymentWithStatus(t),
+				testingdata.IngressServiceWithStatus(t),
+				testingdata.FanoutDeploymentWithStatus(t),
+				testingdata.RetryDeploymentWithStatus(t),
+				testingdata.IngressHPA(t),
+				testingdata.FanoutHPA(t),
+				testingdata.RetryHPA(t),
+			},
+			WantCreates: []runtime.Object{
+				resources.MakeTriggerAuthentication(NewBrokerCell(brokerCellName, systemNS)),
+				resources.MakeScaledObject(testingdata.FanoutDeployment(t), resources.ScaledObjectArgs{
+					ComponentName:          resources.FanoutName,
+					BrokerCell:             NewBrokerCell(brokerCellName, systemNS),
+					MinReplicas:            1,
+					MaxReplicas:            10,
+					ProjectID:              testProject,
+					SubscriptionComponent:  brokerresources.FanoutSubscriptionComponent,
+					TriggerAuthentication:  brokerCellName + "-brokercell-keda-auth",
+					SubscriptionSize:       100,
+					PollingIntervalSeconds: 15,
+					CooldownPeriodSeconds:  120,
+				}),
+			},
+			WantDeletes: []clientgotesting.DeleteActionImpl{{
+				Name: brokerCellName + "-brokercell-fanout-hpa",
+				ActionImpl: clientgotesting.ActionImpl{
+					Namespace: systemNS,
+					Verb:      "delete",
+					Resource:  hpav2beta2.SchemeGroupVersion.WithResource("horizontalpodautoscalers"),
+				},
+			}},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: NewBrokerCell(brokerCellName, systemNS,
+					withFanoutBacklogAutoscaling,
+					WithBrokerCellReady,
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
+					WithTargetsConfigGeneration(1),
+					WithBrokerCellSetDefaults,
+				)},
+			},
+			WantEvents: []string{
+				kedaAuthCreatedEvent,
+				fanoutScaledObjectCreatedEvent,
+				fanoutHPADeletedEvent,
+				brokerCellReconciledEvent,
+			},
+		},
 		{
 			Name: "googlecloud created BrokerCell shouldn't be gc'ed because there are brokers",
 			Key:  testKey,
 			Objects: []runtime.Object{
-				NewBrokerCell(brokerCellName, testNS,
+				NewBrokerCell(brokerCellName, systemNS,
 					WithBrokerCellAnnotations(creatorAnnotation),
 					WithBrokerCellSetDefaults),
-				testingdata.Config(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-					NewBroker("broker", testNS, WithBrokerSetDefaults)),
-				NewBroker("broker", testNS, WithBrokerSetDefaults),
-				NewEndpoints(brokerCellName+"-brokercell-ingress", testNS,
+				testingdata.Config(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+					NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults)),
+				NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
+				NewEndpoints(brokerCellName+"-brokercell-ingress", systemNS,
 					WithEndpointsAddresses(corev1.EndpointAddress{IP: "127.0.0.1"})),
 				testingdata.IngressDeploymentWithStatus(t),
 				testingdata.IngressServiceWithStatus(t),
@@ -827,10 +917,10 @@ func TestAllCases(t *testing.T) {
 				testingdata.RetryHPA(t),
 			},
 			WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-				{Object: NewBrokerCell(brokerCellName, testNS,
+				{Object: NewBrokerCell(brokerCellName, systemNS,
 					WithBrokerCellAnnotations(creatorAnnotation),
 					WithBrokerCellReady,
-					WithIngressTemplate("http://test-brokercell-brokercell-ingress.testnamespace.svc.cluster.local/{namespace}/{name}"),
+					WithIngressTemplate("http://test-brokercell-brokercell-ingress.knative-testing.svc.cluster.local/{namespace}/{name}"),
 					WithBrokerCellSetDefaults,
 				)},
 			},
@@ -841,7 +931,7 @@ func TestAllCases(t *testing.T) {
 		{
 			Name: "googlecloud created BrokerCell should be gc'ed if there is no broker, but deletion fails",
 			Key:  testKey,
-			Objects: []runtime.Object{NewBrokerCell(brokerCellName, testNS,
+			Objects: []runtime.Object{NewBrokerCell(brokerCellName, systemNS,
 				WithBrokerCellAnnotations(creatorAnnotation),
 				WithBrokerCellSetDefaults,
 				WithInitBrokerCellConditions,
@@ -851,7 +941,7 @@ func TestAllCases(t *testing.T) {
 				{
 					Name: brokerCellName,
 					ActionImpl: clientgotesting.ActionImpl{
-						Namespace: testNS,
+						Namespace: systemNS,
 						Verb:      "delete",
 						Resource:  intv1alpha1.SchemeGroupVersion.WithResource("brokercells"),
 					},
@@ -863,7 +953,7 @@ func TestAllCases(t *testing.T) {
 		{
 			Name: "googlecloud created BrokerCell is gc'ed successfully",
 			Key:  testKey,
-			Objects: []runtime.Object{NewBrokerCell(brokerCellName, testNS,
+			Objects: []runtime.Object{NewBrokerCell(brokerCellName, systemNS,
 				WithBrokerCellAnnotations(creatorAnnotation),
 				WithBrokerCellSetDefaults,
 				WithInitBrokerCellConditions,
@@ -872,7 +962,7 @@ func TestAllCases(t *testing.T) {
 				{
 					Name: brokerCellName,
 					ActionImpl: clientgotesting.ActionImpl{
-						Namespace: testNS,
+						Namespace: systemNS,
 						Verb:      "delete",
 						Resource:  intv1alpha1.SchemeGroupVersion.WithResource("brokercells"),
 					},
@@ -901,10 +991,25 @@ func TestAllCases(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to created BrokerCell reconciler: %v", err)
 		}
+		ctx = addressable.WithDuck(ctx)
+		r.uriResolver = resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+		r.clock = clock.NewFakeClock(testTime)
+		r.discoveryFn = func(discovery.DiscoveryInterface, schema.GroupVersion) error { return nil }
+		r.projectID = testProject
 		return bcreconciler.NewReconciler(ctx, r.Logger, r.RunClientSet, testingListers.GetBrokerCellLister(), r.Recorder, r)
 	}))
 }
 
+// stampedConfig returns the targets config stamped with the generation at the time of the fake clock.
+func stampedConfig(t *testing.T, cm *corev1.ConfigMap, generation int64) *corev1.ConfigMap {
+	return testingdata.Stamp(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults), cm, generation, testTime)
+}
+
+// withFanoutBacklogAutoscaling scales the fanout on the backlog of the broker subscriptions.
+var withFanoutBacklogAutoscaling = WithBrokerCellFanout(&intv1alpha1.ComponentParameters{
+	BacklogAutoscaling: &intv1alpha1.BacklogAutoscaling{},
+})
+
 func emptyHPASpec(template *hpav2beta2.HorizontalPodAutoscaler) *hpav2beta2.HorizontalPodAutoscaler {
 	template.Spec = hpav2beta2.HorizontalPodAutoscalerSpec{}
 	return template
@@ -915,16 +1020,125 @@ func emptyHPASpec(template *hpav2beta2.HorizontalPodAutoscaler) *hpav2beta2.Hori
 // to deserialization the binary data to a brokerTargets proto to compare, so it should be rewritten without using the tableTest Utility.
 func TestBrokerTargetsReconcileConfig(t *testing.T) {
 	setReconcilerEnv()
-	bc := NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults)
-	objects := []runtime.Object{
-		bc,
-		NewBroker("broker", testNS, WithBrokerSetDefaults),
-		NewTrigger("trigger1", testNS, "broker", WithTriggerSetDefaults),
-		NewTrigger("trigger2", testNS, "broker", WithTriggerSetDefaults),
+	retry := int32(2)
+	dlsURI, _ := apis.ParseURL("http://dls.example.com")
+	// The reply destination service doesn't exist.
+	unresolvableReplyDestination := `{"ref":{"apiVersion":"serving.knative.dev/v1","kind":"Service","name":"reply"}}`
+	cases := []struct {
+		name        string
+		broker      *brokerv1beta1.Broker
+		triggerOpts []TriggerOption
+	}{{
+		name:   "broker without delivery spec",
+		broker: NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
+	}, {
+		name: "broker with dead letter sink",
+		broker: NewBroker("broker", testNS, withPlacement,
+			WithBrokerDeliverySpec(&eventingduckv1beta1.DeliverySpec{
+				DeadLetterSink: &duckv1.Destination{URI: dlsURI},
+				Retry:          &retry,
+			}),
+			WithBrokerSetDefaults),
+	}, {
+		name:        "trigger with reply destination",
+		broker:      NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
+		triggerOpts: []TriggerOption{WithTriggerReplyDestinationAnnotation(`{"uri":"http://reply.example.com"}`)},
+	}, {
+		name:        "trigger with unresolvable reply destination",
+		broker:      NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
+		triggerOpts: []TriggerOption{WithTriggerReplyDestinationAnnotation(unresolvableReplyDestination)},
+	}, {
+		name:   "trigger with unresolvable reply destination, resolved before",
+		broker: NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults),
+		triggerOpts: []TriggerOption{
+			WithTriggerReplyDestinationAnnotation(unresolvableReplyDestination),
+			WithTriggerReplyResolved("http://reply.example.com"),
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)
+			objects := []runtime.Object{
+				bc,
+				tc.broker,
+				NewTrigger("trigger1", testNS, "broker", append(tc.triggerOpts, WithTriggerSetDefaults)...),
+				NewTrigger("trigger2", testNS, "broker", WithTriggerSetDefaults),
+			}
+			ctx, _ := SetupFakeContext(t)
+			cmw := configmap.NewStaticWatcher()
+			ctx, client := fakekubeclient.With(ctx)
+			base := reconciler.NewBase(ctx, controllerAgentName, cmw)
+			testingListers := NewListers(objects)
+			ls := listers{
+				brokerLister:     testingListers.GetBrokerLister(),
+				hpaLister:        testingListers.GetHPALister(),
+				triggerLister:    testingListers.GetTriggerLister(),
+				configMapLister:  testingListers.GetConfigMapLister(),
+				serviceLister:    testingListers.GetK8sServiceLister(),
+				endpointsLister:  testingListers.GetEndpointsLister(),
+				deploymentLister: testingListers.GetDeploymentLister(),
+				podLister:        testingListers.GetPodLister(),
+			}
+			r, err := NewReconciler(base, ls)
+			if err != nil {
+				t.Fatalf("Failed to create BrokerCell reconciler: %v", err)
+			}
+			ctx = addressable.WithDuck(ctx)
+			r.uriResolver = resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+			r.clock = clock.NewFakeClock(testTime)
+			// here we only want to test the functionality of the reconcileConfig that it should create a brokerTargets config successfully
+			r.reconcileConfig(ctx, bc)
+			wantMap := stampedConfig(t, testingdata.Config(t, NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults),
+				tc.broker,
+				NewTrigger("trigger1", testNS, "broker", append(tc.triggerOpts, WithTriggerSetDefaults)...),
+				NewTrigger("trigger2", testNS, "broker", WithTriggerSetDefaults)), 1)
+			gotMap, err := client.CoreV1().ConfigMaps(systemNS).Get(resources.Name(bc.Name, targetsCMName), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get ConfigMap from client: %v", err)
+			}
+			// compare the ObjectMeta field
+			if diff := cmp.Diff(wantMap.ObjectMeta, gotMap.ObjectMeta); diff != "" {
+				t.Fatalf("Unexpected ObjectMeta in ConfigMap(-want, +got): %s", diff)
+			}
+			// deserialize the binary data to a broker targets config proto
+			var wantBrokerTargets config.TargetsConfig
+			var gotBrokerTargets config.TargetsConfig
+			if err := proto.Unmarshal(wantMap.BinaryData[targetsCMKey], &wantBrokerTargets); err != nil {
+				t.Fatalf("Failed to deserialize the binary data in ConfigMap: %v", err)
+			}
+			if err := proto.Unmarshal(gotMap.BinaryData[targetsCMKey], &gotBrokerTargets); err != nil {
+				t.Fatalf("Failed to deserialize the binary data in ConfigMap: %v", err)
+			}
+			// compare the broker targets config
+			if diff := cmp.Diff(wantBrokerTargets.String(), gotBrokerTargets.String()); diff != "" {
+				t.Fatalf("Unexpected brokerTargets in ConfigMap(-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestBrokerTargetsReconcileShardedConfig(t *testing.T) {
+	setReconcilerEnv()
+	const shards = 4
+	bc := NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)
+	stale := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            resources.TargetsConfigName(bc, 7),
+			Namespace:       systemNS,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(bc)},
+			Labels:          resources.TargetsConfigLabels(bc),
+		},
+	}
+	objects := []runtime.Object{bc, stale}
+	for _, ns := range []string{"ns1", "ns2", "ns3", "ns4", "ns5"} {
+		objects = append(objects,
+			NewBroker("broker", ns, withPlacement, WithBrokerSetDefaults),
+			NewTrigger("trigger", ns, "broker", WithTriggerSetDefaults))
 	}
 	ctx, _ := SetupFakeContext(t)
 	cmw := configmap.NewStaticWatcher()
-	ctx, client := fakekubeclient.With(ctx)
+	ctx, client := fakekubeclient.With(ctx, stale)
 	base := reconciler.NewBase(ctx, controllerAgentName, cmw)
 	testingListers := NewListers(objects)
 	ls := listers{
@@ -941,31 +1155,337 @@ func TestBrokerTargetsReconcileConfig(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create BrokerCell reconciler: %v", err)
 	}
-	// here we only want to test the functionality of the reconcileConfig that it should create a brokerTargets config successfully
-	r.reconcileConfig(ctx, bc)
-	wantMap := testingdata.Config(t, NewBrokerCell(brokerCellName, testNS, WithBrokerCellSetDefaults),
-		NewBroker("broker", testNS, WithBrokerSetDefaults),
-		NewTrigger("trigger1", testNS, "broker", WithTriggerSetDefaults),
-		NewTrigger("trigger2", testNS, "broker", WithTriggerSetDefaults))
-	gotMap, err := client.CoreV1().ConfigMaps(testNS).Get(resources.Name(bc.Name, targetsCMName), metav1.GetOptions{})
+	ctx = addressable.WithDuck(ctx)
+	r.uriResolver = resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+	r.clock = clock.NewFakeClock(testTime)
+	r.env.TargetsConfigShards = shards
+	if err := r.reconcileConfig(ctx, bc); err != nil {
+		t.Fatalf("reconcileConfig got unexpected error: %v", err)
+	}
+
+	// The shards together hold the whole config, with each broker in the shard of its key.
+	var shardConfigs []*config.TargetsConfig
+	for i := 0; i < shards; i++ {
+		cm, err := client.CoreV1().ConfigMaps(systemNS).Get(resources.TargetsConfigName(bc, i), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get ConfigMap of shard %d from client: %v", i, err)
+		}
+		shard, err := resources.ParseTargetsConfig(cm)
+		if err != nil {
+			t.Fatalf("Failed to deserialize the binary data in ConfigMap: %v", err)
+		}
+		for key := range shard.Brokers {
+			if got := config.ShardOf(key, shards); got != i {
+				t.Errorf("broker %s in shard got=%d, want=%d", key, i, got)
+			}
+		}
+		if shard.Generation != 1 {
+			t.Errorf("shard %d generation got=%d, want=1", i, shard.Generation)
+		}
+		shardConfigs = append(shardConfigs, shard)
+	}
+	merged, err := config.MergeShards(shardConfigs...)
+	if err != nil {
+		t.Fatalf("MergeShards got unexpected error: %v", err)
+	}
+	if len(merged.Brokers) != 5 {
+		t.Errorf("number of brokers got=%d, want=5", len(merged.Brokers))
+	}
+	if bc.Status.TargetsConfigGeneration != 1 {
+		t.Errorf("TargetsConfigGeneration got=%d, want=1", bc.Status.TargetsConfigGeneration)
+	}
+
+	// The shard left from a larger number of shards is deleted.
+	if _, err := client.CoreV1().ConfigMaps(systemNS).Get(stale.Name, metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("Get stale shard got err=%v, want not found", err)
+	}
+}
+
+func TestBrokerTargetsReconcileStreamedConfig(t *testing.T) {
+	setReconcilerEnv()
+	bc := NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)
+	broker := NewBroker("broker", testNS, withPlacement, WithBrokerSetDefaults)
+	trigger := NewTrigger("trigger1", testNS, "broker", WithTriggerSetDefaults)
+	// The configmap written before the targets config was streamed.
+	stale := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            resources.TargetsConfigName(bc, 0),
+			Namespace:       systemNS,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(bc)},
+			Labels:          resources.TargetsConfigLabels(bc),
+		},
+	}
+	objects := []runtime.Object{bc, broker, trigger, stale}
+	ctx, _ := SetupFakeContext(t)
+	cmw := configmap.NewStaticWatcher()
+	ctx, client := fakekubeclient.With(ctx, stale)
+	base := reconciler.NewBase(ctx, controllerAgentName, cmw)
+	testingListers := NewListers(objects)
+	ls := listers{
+		brokerLister:     testingListers.GetBrokerLister(),
+		hpaLister:        testingListers.GetHPALister(),
+		triggerLister:    testingListers.GetTriggerLister(),
+		configMapLister:  testingListers.GetConfigMapLister(),
+		serviceLister:    testingListers.GetK8sServiceLister(),
+		endpointsLister:  testingListers.GetEndpointsLister(),
+		deploymentLister: testingListers.GetDeploymentLister(),
+		podLister:        testingListers.GetPodLister(),
+	}
+	r, err := NewReconciler(base, ls)
 	if err != nil {
-		t.Fatalf("Failed to get ConfigMap from client: %v", err)
+		t.Fatalf("Failed to create BrokerCell reconciler: %v", err)
 	}
-	// compare the ObjectMeta field
-	if diff := cmp.Diff(wantMap.ObjectMeta, gotMap.ObjectMeta); diff != "" {
-		t.Fatalf("Unexpected ObjectMeta in ConfigMap(-want, +got): %s", diff)
+	ctx = addressable.WithDuck(ctx)
+	r.uriResolver = resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+	r.targetsServer = stream.NewServer(zap.NewNop(), nil)
+	r.clock = clock.NewFakeClock(testTime)
+
+	reconcileGeneration := func(want int64) {
+		t.Helper()
+		if err := r.reconcileConfig(ctx, bc); err != nil {
+			t.Fatalf("reconcileConfig got unexpected error: %v", err)
+		}
+		if bc.Status.TargetsConfigGeneration != want {
+			t.Errorf("TargetsConfigGeneration got=%d, want=%d", bc.Status.TargetsConfigGeneration, want)
+		}
+		if _, published := r.targetsServer.Current(brokerCellName); published.GetGeneration() != want {
+			t.Errorf("published generation got=%d, want=%d", published.GetGeneration(), want)
+		}
 	}
-	// deserialize the binary data to a broker targets config proto
+	reconcileGeneration(1)
+
+	wantMap := stampedConfig(t, testingdata.Config(t, bc, broker, trigger), 1)
 	var wantBrokerTargets config.TargetsConfig
-	var gotBrokerTargets config.TargetsConfig
 	if err := proto.Unmarshal(wantMap.BinaryData[targetsCMKey], &wantBrokerTargets); err != nil {
 		t.Fatalf("Failed to deserialize the binary data in ConfigMap: %v", err)
 	}
-	if err := proto.Unmarshal(gotMap.BinaryData[targetsCMKey], &gotBrokerTargets); err != nil {
-		t.Fatalf("Failed to deserialize the binary data in ConfigMap: %v", err)
+	if _, published := r.targetsServer.Current(brokerCellName); !proto.Equal(&wantBrokerTargets, published) {
+		t.Errorf("Unexpected published brokerTargets, want=%v, got=%v", wantBrokerTargets.String(), published.String())
+	}
+	// The streamed targets config isn't written to configmaps.
+	if _, err := client.CoreV1().ConfigMaps(systemNS).Get(stale.Name, metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("Get targets configmap got err=%v, want not found", err)
+	}
+
+	// The same targets config keeps its generation.
+	reconcileGeneration(1)
+
+	// A changed targets config is streamed with the next generation.
+	trigger2 := NewTrigger("trigger2", testNS, "broker", WithTriggerSetDefaults)
+	updatedListers := NewListers(append(objects, trigger2))
+	r.triggerLister = updatedListers.GetTriggerLister()
+	reconcileGeneration(2)
+}
+
+func TestMakeTriggerAuthentication(t *testing.T) {
+	withRetryBacklogAutoscaling := func(ba *intv1alpha1.BacklogAutoscaling) BrokerCellOption {
+		return WithBrokerCellRetry(&intv1alpha1.ComponentParameters{BacklogAutoscaling: ba})
+	}
+	cases := []struct {
+		name string
+		bc   *intv1alpha1.BrokerCell
+		want bool
+	}{{
+		name: "no backlog autoscaling",
+		bc:   NewBrokerCell(brokerCellName, systemNS),
+	}, {
+		name: "pod identity",
+		bc:   NewBrokerCell(brokerCellName, systemNS, withRetryBacklogAutoscaling(&intv1alpha1.BacklogAutoscaling{})),
+		want: true,
+	}, {
+		name: "own TriggerAuthentication",
+		bc:   NewBrokerCell(brokerCellName, systemNS, withRetryBacklogAutoscaling(&intv1alpha1.BacklogAutoscaling{TriggerAuthentication: "keda-gcp-credentials"})),
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := makeTriggerAuthentication(tc.bc) != nil; got != tc.want {
+				t.Errorf("makeTriggerAuthentication got TriggerAuthentication=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPropagateTargetsConfigPods(t *testing.T) {
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: systemNS,
+				Name:      name,
+				Labels:    resources.Labels(brokerCellName, resources.FanoutName),
+			},
+		}
+	}
+	deleted := pod("deleted")
+	deleted.DeletionTimestamp = &metav1.Time{Time: testTime}
+	otherCell := pod("other-cell")
+	otherCell.Labels = resources.Labels("other", resources.FanoutName)
+	testingListers := NewListers([]runtime.Object{
+		pod("fanout-1"),
+		pod("fanout-2"),
+		pod("retry-1"),
+		pod("retry-2"),
+		deleted,
+		otherCell,
+	})
+	r := &Reconciler{listers: listers{podLister: testingListers.GetPodLister()}}
+
+	// The pods don't report the applied generation when the targets config isn't streamed.
+	bc := NewBrokerCell(brokerCellName, systemNS, WithTargetsConfigGeneration(2))
+	if err := r.propagateTargetsConfigPods(bc); err != nil {
+		t.Fatalf("propagateTargetsConfigPods got unexpected error: %v", err)
+	}
+	want := NewBrokerCell(brokerCellName, systemNS, WithTargetsConfigGeneration(2))
+	if diff := cmp.Diff(want.Status, bc.Status); diff != "" {
+		t.Errorf("Unexpected status (-want, +got): %s", diff)
+	}
+
+	// The pods report the applied generation by acknowledging the streamed targets config.
+	r.targetsServer = stream.NewServer(zap.NewNop(), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	address := serveTargets(ctx, t, r.targetsServer)
+	r.targetsServer.Publish(brokerCellName, &config.TargetsConfig{Generation: 1})
+	watchTargets(ctx, t, address, "fanout-2", true)
+	r.targetsServer.Publish(brokerCellName, &config.TargetsConfig{Generation: 2})
+	watchTargets(ctx, t, address, "fanout-1", true)
+	watchTargets(ctx, t, address, "deleted", true)
+	watchTargets(ctx, t, address, "retry-1", false)
+	deadline := time.Now().Add(10 * time.Second)
+	for r.targetsServer.AckedVersions(brokerCellName)["fanout-1"] != 2 || len(r.targetsServer.Nodes(brokerCellName)) != 4 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for the pods to report")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := r.propagateTargetsConfigPods(bc); err != nil {
+		t.Fatalf("propagateTargetsConfigPods got unexpected error: %v", err)
+	}
+	want = NewBrokerCell(brokerCellName, systemNS, WithTargetsConfigGeneration(2), WithTargetsConfigAppliedPods(1, 4))
+	if diff := cmp.Diff(want.Status, bc.Status); diff != "" {
+		t.Errorf("Unexpected status (-want, +got): %s", diff)
+	}
+}
+
+// serveTargets serves the targets config until ctx is done and returns its address.
+func serveTargets(ctx context.Context, t *testing.T, s *stream.Server) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(ctx, lis)
+	return lis.Addr().String()
+}
+
+// watchTargets connects a data plane pod to the targets config stream until ctx is done.
+// If ack is true, the pod acknowledges the first update.
+func watchTargets(ctx context.Context, t *testing.T, address, node string, ack bool) {
+	t.Helper()
+	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	client, err := config.NewTargetsDistributionClient(conn).WatchTargets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Send(&config.WatchTargetsRequest{Node: node, Cell: brokerCellName}); err != nil {
+		t.Fatal(err)
+	}
+	if !ack {
+		return
+	}
+	u, err := client.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Send(&config.WatchTargetsRequest{Node: node, Version: u.Version}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPropagateOpenCircuitBreakers(t *testing.T) {
+	r := &Reconciler{targetsServer: stream.NewServer(zap.NewNop(), nil)}
+	ctx, cancel := context.WithCancel(logtesting.TestContextWithLogger(t))
+	defer cancel()
+	address := serveTargets(ctx, t, r.targetsServer)
+
+	// The pods report their open circuit breakers on the targets config stream.
+	pods := map[string][]string{
+		"fanout-1": {"ns/broker/t1", "ns/broker/t2"},
+		"retry-1":  {"ns/broker/t1"},
+		"retry-2":  nil,
+	}
+	for pod, open := range pods {
+		open := open
+		if _, err := stream.NewTargets(ctx, stream.WithAddress(address), stream.WithNode(pod), stream.WithCell(brokerCellName),
+			stream.WithOpenCircuitBreakers(func() []string { return open }, time.Second)); err != nil {
+			t.Fatalf("NewTargets got unexpected error: %v", err)
+		}
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for len(r.targetsServer.Nodes(brokerCellName)) != len(pods) {
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for the pods to connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	bc := NewBrokerCell(brokerCellName, systemNS)
+	r.propagateOpenCircuitBreakers(bc)
+	want := NewBrokerCell(brokerCellName, systemNS,
+		WithOpenCircuitBreaker("ns/broker/t1", "fanout-1", "retry-1"),
+		WithOpenCircuitBreaker("ns/broker/t2", "fanout-1"))
+	if diff := cmp.Diff(want.Status, bc.Status); diff != "" {
+		t.Errorf("Unexpected status (-want, +got): %s", diff)
+	}
+
+	// The pods don't report open circuit breakers when the targets config isn't streamed.
+	r.targetsServer = nil
+	r.propagateOpenCircuitBreakers(bc)
+	if bc.Status.OpenCircuitBreakers != nil {
+		t.Errorf("OpenCircuitBreakers got=%v, want none", bc.Status.OpenCircuitBreakers)
+	}
+}
+
+func TestShardedTargetsConfigVolume(t *testing.T) {
+	r := &Reconciler{}
+	r.env.TargetsConfigShards = 3
+	bc := NewBrokerCell(brokerCellName, systemNS, WithBrokerCellSetDefaults)
+	d := resources.MakeFanoutDeployment(r.makeFanoutArgs(bc))
+	var projected *corev1.ProjectedVolumeSource
+	for _, v := range d.Spec.Template.Spec.Volumes {
+		if v.Name == "broker-config" {
+			projected = v.Projected
+		}
+	}
+	if projected == nil || len(projected.Sources) != 3 {
+		t.Fatalf("targets config volume got=%v, want 3 projected shards", projected)
+	}
+	for i, source := range projected.Sources {
+		if got, want := source.ConfigMap.Name, resources.TargetsConfigName(bc, i); got != want {
+			t.Errorf("shard %d configmap got=%q, want=%q", i, got, want)
+		}
+		// Deleting a shard must not keep the pods from starting.
+		if source.ConfigMap.Optional == nil || !*source.ConfigMap.Optional {
+			t.Errorf("shard %d projection is not optional", i)
+		}
+	}
+}
+
+func TestTargetsConfigService(t *testing.T) {
+	r := &Reconciler{}
+	if got := r.targetsConfigService(); got != "" {
+		t.Errorf("targetsConfigService got=%q, want empty", got)
 	}
-	// compare the broker targets config
-	if diff := cmp.Diff(wantBrokerTargets.String(), gotBrokerTargets.String()); diff != "" {
-		t.Fatalf("Unexpected brokerTargets in ConfigMap(-want, +got): %s", diff)
+	r.env.TargetsServicePort = 8090
+	want := "broker-targets." + system.Namespace() + ".svc.cluster.local:8090"
+	if got := r.targetsConfigService(); got != want {
+		t.Errorf("targetsConfigService got=%q, want=%q", got, want)
 	}
 }
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercell
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+)
+
+// componentDefaults are the settings of a BrokerCell component that are used unless the
+// BrokerCell spec overrides them.
+type componentDefaults struct {
+	resources         corev1.ResourceRequirements
+	minReplicas       int32
+	maxReplicas       int32
+	avgCPUUtilization int32
+	avgMemoryUsage    string
+}
+
+var (
+	ingressDefaults = componentDefaults{
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1000Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+				corev1.ResourceCPU:    resource.MustParse("1000m"),
+			},
+		},
+		minReplicas:       1,
+		maxReplicas:       10,
+		avgCPUUtilization: 95,
+		avgMemoryUsage:    "700Mi",
+	}
+
+	fanoutDefaults = componentDefaults{
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("3000Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+				corev1.ResourceCPU:    resource.MustParse("1500m"),
+			},
+		},
+		minReplicas:       1,
+		maxReplicas:       10,
+		avgCPUUtilization: 95,
+		// The limit we set is 3000Mi which is mostly used to prevent surging
+		// memory usage causing OOM.
+		// Here we only set half of the limit so that in case of surging memory
+		// usage, HPA could have enough time to kick in.
+		// See: https://github.com/google/knative-gcp/issues/1265
+		avgMemoryUsage: "1500Mi",
+	}
+
+	retryDefaults = componentDefaults{
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("3000Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+				corev1.ResourceCPU:    resource.MustParse("1000m"),
+			},
+		},
+		minReplicas:       1,
+		maxReplicas:       10,
+		avgCPUUtilization: 95,
+		// See the fanout memory usage target.
+		avgMemoryUsage: "1500Mi",
+	}
+)
+
+// ingressParameters returns the ingress settings of the BrokerCell spec, or nil if it has none.
+func ingressParameters(bc *intv1alpha1.BrokerCell) *intv1alpha1.ComponentParameters {
+	if bc.Spec.Ingress == nil {
+		return nil
+	}
+	return &bc.Spec.Ingress.ComponentParameters
+}
+
+// withComponentParameters sets the pod settings of the component deployment args from the
+// BrokerCell spec and the component defaults.
+func withComponentParameters(args resources.Args, d componentDefaults, p *intv1alpha1.ComponentParameters) resources.Args {
+	args.Resources = d.resources
+	if p == nil {
+		return args
+	}
+	args.Resources = mergeResources(d.resources, p.Resources)
+	args.PodAnnotations = p.PodAnnotations
+	args.NodeSelector = p.NodeSelector
+	args.Tolerations = p.Tolerations
+	return args
+}
+
+// makeAutoscalingArgs makes the HPA args of the component from the BrokerCell spec and the
+// component defaults.
+func makeAutoscalingArgs(bc *intv1alpha1.BrokerCell, componentName string, d componentDefaults, p *intv1alpha1.ComponentParameters) resources.AutoscalingArgs {
+	args := resources.AutoscalingArgs{
+		ComponentName:     componentName,
+		BrokerCell:        bc,
+		AvgCPUUtilization: d.avgCPUUtilization,
+		AvgMemoryUsage:    d.avgMemoryUsage,
+		MinReplicas:       d.minReplicas,
+		MaxReplicas:       d.maxReplicas,
+	}
+	if p == nil {
+		return args
+	}
+	if p.AvgCPUUtilization != nil {
+		args.AvgCPUUtilization = *p.AvgCPUUtilization
+	}
+	if p.AvgMemoryUsage != nil {
+		args.AvgMemoryUsage = p.AvgMemoryUsage.String()
+	}
+	if p.MinReplicas != nil {
+		args.MinReplicas = *p.MinReplicas
+	}
+	if p.MaxReplicas != nil {
+		args.MaxReplicas = *p.MaxReplicas
+	}
+	// A min replicas override above the default max replicas raises the max replicas.
+	if args.MaxReplicas < args.MinReplicas {
+		args.MaxReplicas = args.MinReplicas
+	}
+	args.Metrics = p.Metrics
+	return args
+}
+
+// mergeResources returns the default resources overridden by the given ones. A default
+// limit below an overridden request is raised to the request, and a default request above
+// an overridden limit is lowered to the limit.
+func mergeResources(defaults, overrides corev1.ResourceRequirements) corev1.ResourceRequirements {
+	merged := corev1.ResourceRequirements{
+		Limits:   mergeResourceList(defaults.Limits, overrides.Limits),
+		Requests: mergeResourceList(defaults.Requests, overrides.Requests),
+	}
+	for name, request := range overrides.Requests {
+		if limit, ok := merged.Limits[name]; ok && request.Cmp(limit) > 0 {
+			merged.Limits = mergeResourceList(merged.Limits, corev1.ResourceList{name: request})
+		}
+	}
+	for name, limit := range overrides.Limits {
+		if _, ok := overrides.Requests[name]; ok {
+			continue
+		}
+		if request, ok := merged.Requests[name]; ok && request.Cmp(limit) > 0 {
+			merged.Requests = mergeResourceList(merged.Requests, corev1.ResourceList{name: limit})
+		}
+	}
+	return merged
+}
+
+// mergeResourceList returns the default resources overridden by the given ones.
+func mergeResourceList(defaults, overrides corev1.ResourceList) corev1.ResourceList {
+	if len(overrides) == 0 {
+		return defaults
+	}
+	merged := make(corev1.ResourceList, len(defaults)+len(overrides))
+	for name, q := range defaults {
+		merged[name] = q
+	}
+	for name, q := range overrides {
+		merged[name] = q
+	}
+	return merged
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercell
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/pkg/ptr"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+)
+
+func TestWithComponentParameters(t *testing.T) {
+	tolerations := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "broker", Effect: corev1.TaintEffectNoSchedule}}
+	cases := []struct {
+		name   string
+		params *intv1alpha1.ComponentParameters
+		want   resources.Args
+	}{{
+		name: "defaults",
+		want: resources.Args{Resources: fanoutDefaults.resources},
+	}, {
+		name: "overrides",
+		params: &intv1alpha1.ComponentParameters{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+			PodAnnotations: map[string]string{"foo": "bar"},
+			NodeSelector:   map[string]string{"cloud.google.com/gke-nodepool": "broker"},
+			Tolerations:    tolerations,
+		},
+		want: resources.Args{
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("3000Mi")},
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("500Mi"),
+					corev1.ResourceCPU:    resource.MustParse("4"),
+				},
+			},
+			PodAnnotations: map[string]string{"foo": "bar"},
+			NodeSelector:   map[string]string{"cloud.google.com/gke-nodepool": "broker"},
+			Tolerations:    tolerations,
+		},
+	}, {
+		name: "request above default limit",
+		params: &intv1alpha1.ComponentParameters{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+		},
+		want: resources.Args{
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+					corev1.ResourceCPU:    resource.MustParse("1500m"),
+				},
+			},
+		},
+	}, {
+		name: "limit below default request",
+		params: &intv1alpha1.ComponentParameters{
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("400Mi"),
+					corev1.ResourceCPU:    resource.MustParse("1"),
+				},
+			},
+		},
+		want: resources.Args{
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("400Mi"),
+					corev1.ResourceCPU:    resource.MustParse("1"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("400Mi"),
+					corev1.ResourceCPU:    resource.MustParse("1"),
+				},
+			},
+		},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := withComponentParameters(resources.Args{}, fanoutDefaults, tc.params)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("withComponentParameters (-want,+got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeAutoscalingArgs(t *testing.T) {
+	bc := &intv1alpha1.BrokerCell{}
+	backlog := resource.MustParse("1000")
+	metrics := []hpav2beta2.MetricSpec{{
+		Type: hpav2beta2.ExternalMetricSourceType,
+		External: &hpav2beta2.ExternalMetricSource{
+			Metric: hpav2beta2.MetricIdentifier{Name: "pubsub.googleapis.com|subscription|num_undelivered_messages"},
+			Target: hpav2beta2.MetricTarget{Type: hpav2beta2.AverageValueMetricType, AverageValue: &backlog},
+		},
+	}}
+	memory := resource.MustParse("1Gi")
+	cases := []struct {
+		name   string
+		params *intv1alpha1.ComponentParameters
+		want   resources.AutoscalingArgs
+	}{{
+		name: "defaults",
+		want: resources.AutoscalingArgs{
+			ComponentName:     resources.IngressName,
+			BrokerCell:        bc,
+			AvgCPUUtilization: 95,
+			AvgMemoryUsage:    "700Mi",
+			MinReplicas:       1,
+			MaxReplicas:       10,
+		},
+	}, {
+		name: "overrides",
+		params: &intv1alpha1.ComponentParameters{
+			MinReplicas:       ptr.Int32(2),
+			MaxReplicas:       ptr.Int32(50),
+			AvgCPUUtilization: ptr.Int32(60),
+			AvgMemoryUsage:    &memory,
+			Metrics:           metrics,
+		},
+		want: resources.AutoscalingArgs{
+			ComponentName:     resources.IngressName,
+			BrokerCell:        bc,
+			AvgCPUUtilization: 60,
+			AvgMemoryUsage:    "1Gi",
+			MinReplicas:       2,
+			MaxReplicas:       50,
+			Metrics:           metrics,
+		},
+	}, {
+		name:   "min replicas above default max replicas",
+		params: &intv1alpha1.ComponentParameters{MinReplicas: ptr.Int32(20)},
+		want: resources.AutoscalingArgs{
+			ComponentName:     resources.IngressName,
+			BrokerCell:        bc,
+			AvgCPUUtilization: 95,
+			AvgMemoryUsage:    "700Mi",
+			MinReplicas:       20,
+			MaxReplicas:       20,
+		},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := makeAutoscalingArgs(bc, resources.IngressName, ingressDefaults, tc.params)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("makeAutoscalingArgs (-want,+got): %v", diff)
+			}
+		})
+	}
+}
@@ -20,18 +20,24 @@ package brokercell
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"go.uber.org/zap"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	brokerinformer "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/broker"
 	triggerinformer "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/trigger"
 	brokercellinformer "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1alpha1/brokercell"
 	hpainformer "github.com/google/knative-gcp/pkg/client/injection/kube/informers/autoscaling/v2beta2/horizontalpodautoscaler"
 	v1alpha1brokercell "github.com/google/knative-gcp/pkg/client/injection/reconciler/intevents/v1alpha1/brokercell"
+	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
 	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+	"github.com/google/knative-gcp/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/eventing/pkg/logging"
@@ -42,6 +48,9 @@ import (
 	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/system"
 )
 
 const (
@@ -73,31 +82,85 @@ func NewController(
 	if err != nil {
 		logger.Fatal("Failed to create BrokerCell reconciler", zap.Error(err))
 	}
+	// If there is an error, the projectID will be empty and the backlog autoscaling fails
+	// until the controller is restarted.
+	r.projectID, err = utils.ProjectID(os.Getenv(utils.ProjectIDEnvKey), metadataClient.NewDefaultMetadataClient())
+	if err != nil {
+		logger.Error("Failed to get project ID", zap.Error(err))
+	}
 	impl := v1alpha1brokercell.NewImpl(ctx, r)
+	if port := r.env.TargetsServicePort; port != 0 {
+		// Only the data plane pods may stream the targets config.
+		dataPlaneUser := fmt.Sprintf("system:serviceaccount:%s:%s", system.Namespace(), r.env.ServiceAccountName)
+		r.targetsServer = stream.NewServer(logger, stream.NewTokenReviewAuthorizer(r.KubeClientSet, dataPlaneUser))
+		// Reflect what the pods report on the stream, e.g. their open circuit breakers.
+		r.targetsServer.OnReport(func(cell string) {
+			impl.EnqueueKey(types.NamespacedName{Namespace: system.Namespace(), Name: cell})
+		})
+		go func() {
+			if err := r.targetsServer.ListenAndServe(ctx, fmt.Sprintf(":%d", port)); err != nil {
+				logger.Error("Failed to serve the broker targets config", zap.Error(err))
+			}
+		}()
+	}
+	// enqueueBrokerCellOf enqueues the brokercell that the broker is placed on.
+	enqueueBrokerCellOf := func(b *brokerv1beta1.Broker) {
+		impl.EnqueueKey(types.NamespacedName{Namespace: system.Namespace(), Name: brokerresources.BrokerCellName(b)})
+	}
+	// enqueueBrokerCellOfTrigger enqueues the brokercell that the broker of the trigger is placed on.
+	enqueueBrokerCellOfTrigger := func(t *brokerv1beta1.Trigger) {
+		b, err := ls.brokerLister.Brokers(t.Namespace).Get(t.Spec.Broker)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to get broker", zap.Error(err))
+			return
+		}
+		enqueueBrokerCellOf(b)
+	}
+
+	// The resolver tracks dead letter sinks on behalf of brokers and reply destinations on behalf of
+	// triggers, so enqueue the brokercell of the broker.
+	r.uriResolver = resolver.NewURIResolver(ctx, func(key types.NamespacedName) {
+		if b, err := ls.brokerLister.Brokers(key.Namespace).Get(key.Name); err == nil {
+			enqueueBrokerCellOf(b)
+		}
+		if t, err := ls.triggerLister.Triggers(key.Namespace).Get(key.Name); err == nil {
+			enqueueBrokerCellOfTrigger(t)
+		}
+	})
 
 	logger.Info("Setting up event handlers.")
 
 	brokercellinformer.Get(ctx).Informer().AddEventHandlerWithResyncPeriod(controller.HandleAll(impl.Enqueue), reconciler.DefaultResyncPeriod)
 
 	// Watch brokers and triggers to invoke configmap update immediately.
-	brokerinformer.Get(ctx).Informer().AddEventHandler(controller.HandleAll(
-		func(obj interface{}) {
+	brokerinformer.Get(ctx).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
 			if b, ok := obj.(*brokerv1beta1.Broker); ok {
-				// TODO(#866) Select the brokercell that's associated with the given broker.
-				impl.EnqueueKey(types.NamespacedName{Namespace: b.Namespace, Name: brokerresources.DefaultBrokerCellName})
+				enqueueBrokerCellOf(b)
 			}
 		},
-	))
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Enqueue the old brokercell too so that it drops a broker placed on another brokercell.
+			if b, ok := oldObj.(*brokerv1beta1.Broker); ok {
+				enqueueBrokerCellOf(b)
+			}
+			if b, ok := newObj.(*brokerv1beta1.Broker); ok {
+				enqueueBrokerCellOf(b)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if b, ok := obj.(*brokerv1beta1.Broker); ok {
+				enqueueBrokerCellOf(b)
+			}
+		},
+	})
 	triggerinformer.Get(ctx).Informer().AddEventHandler(controller.HandleAll(
 		func(obj interface{}) {
 			if t, ok := obj.(*brokerv1beta1.Trigger); ok {
-				b, err := brokerinformer.Get(ctx).Lister().Brokers(t.Namespace).Get(t.Spec.Broker)
-				if err != nil {
-					logging.FromContext(ctx).Error("Failed to get broker", zap.Error(err))
-					return
-				}
-				// TODO(#866) Select the brokercell that's associated with the given broker.
-				impl.EnqueueKey(types.NamespacedName{Namespace: b.Namespace, Name: brokerresources.DefaultBrokerCellName})
+				enqueueBrokerCellOfTrigger(t)
 			}
 		},
 	))
@@ -111,10 +174,40 @@ func NewController(
 	hpainformer.Get(ctx).Informer().AddEventHandler(handleResourceUpdate(impl))
 	// 4. Watch the broker targets configmap.
 	configmapinformer.Get(ctx).Informer().AddEventHandler(handleResourceUpdate(impl))
+	// 5. Watch the data plane pods counted for the propagation of the targets config. The
+	// generation they applied is reported on the targets config stream, so other pod updates
+	// are ignored.
+	enqueueBrokerCellOfPod := impl.EnqueueLabelOfNamespaceScopedResource("", resources.BrokerCellLabelKey)
+	podinformer.Get(ctx).Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.LabelExistsFilterFunc(resources.BrokerCellLabelKey),
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: enqueueBrokerCellOfPod,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				if podDeletionChanged(oldObj, newObj) {
+					enqueueBrokerCellOfPod(newObj)
+				}
+			},
+			DeleteFunc: enqueueBrokerCellOfPod,
+		},
+	})
 
 	return impl
 }
 
+// podDeletionChanged returns true if the pod update started the deletion of the pod, which
+// stops counting it for the propagation of the targets config.
+func podDeletionChanged(oldObj, newObj interface{}) bool {
+	oldPod, ok := oldObj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	newPod, ok := newObj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	return (oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil)
+}
+
 // handleResourceUpdate returns an event handler for resources created by brokercell such as the ingress deployment.
 func handleResourceUpdate(impl *controller.Impl) cache.ResourceEventHandler {
 	// Since resources created by brokercell live in the same namespace as the brokercell, we use an
@@ -19,6 +19,8 @@ package resources
 import (
 	"fmt"
 
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/kmeta"
 
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
@@ -34,8 +36,18 @@ const (
 	BrokerCellLabelKey = "brokerCell"
 )
 
+const (
+	// targetsTokenVolume is the name of the volume of the token that data plane pods
+	// authenticate to the config service with.
+	targetsTokenVolume = "broker-targets-token"
+	// targetsTokenExpirationSeconds is the expiration of the token. The kubelet
+	// rotates it before it expires.
+	targetsTokenExpirationSeconds = 3600
+)
+
 var (
-	optionalSecretVolume = true
+	optionalSecretVolume        = true
+	optionalTargetsConfigVolume = true
 )
 
 // Args are the common arguments to create a Broker's data plane Deployment.
@@ -45,12 +57,27 @@ type Args struct {
 	Image              string
 	ServiceAccountName string
 	MetricsPort        int
+	// TargetsConfigService is the optional address of the service streaming the broker
+	// targets config.
+	TargetsConfigService string
+
+	// TargetsConfigShards is the number of ConfigMaps the targets config is sharded across.
+	TargetsConfigShards int
+
+	// Resources are the compute resources of the component container.
+	Resources corev1.ResourceRequirements
+	// PodAnnotations, NodeSelector and Tolerations are set on the pods of the component.
+	PodAnnotations map[string]string
+	NodeSelector   map[string]string
+	Tolerations    []corev1.Toleration
 }
 
 // IngressArgs are the arguments to create a Broker's ingress Deployment.
 type IngressArgs struct {
 	Args
 	Port int
+	// PublishSettings are the optional Pub/Sub publish settings of the ingress.
+	PublishSettings *intv1alpha1.PublishSettings
 }
 
 // FanoutArgs are the arguments to create a Broker's fanout Deployment.
@@ -69,7 +96,10 @@ type AutoscalingArgs struct {
 	BrokerCell        *intv1alpha1.BrokerCell
 	AvgCPUUtilization int32
 	AvgMemoryUsage    string
+	MinReplicas       int32
 	MaxReplicas       int32
+	// Metrics are scaled on in addition to the CPU and memory usage.
+	Metrics []hpav2beta2.MetricSpec
 }
 
 // Labels generates the labels present on all resources representing the
@@ -23,6 +23,8 @@ import (
 
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 	"github.com/google/knative-gcp/pkg/broker/config"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -32,20 +34,54 @@ const (
 	targetsCMKey  = "targets"
 )
 
-func MakeTargetsConfig(bc *intv1alpha1.BrokerCell, brokerTargets config.Targets) (*corev1.ConfigMap, error) {
-	data, err := brokerTargets.Bytes()
-	if err != nil {
-		return nil, fmt.Errorf("error serializing targets config: %w", err)
+// TargetsConfigName returns the name of the ConfigMap holding the given shard of the
+// targets config. The first shard keeps the name of the unsharded ConfigMap.
+func TargetsConfigName(bc *intv1alpha1.BrokerCell, shard int) string {
+	if shard == 0 {
+		return Name(bc.Name, targetsCMName)
 	}
+	return Name(bc.Name, fmt.Sprintf("%s-%d", targetsCMName, shard))
+}
+
+// TargetsConfigLabels returns the labels of the ConfigMaps holding the targets config.
+func TargetsConfigLabels(bc *intv1alpha1.BrokerCell) map[string]string {
+	return Labels(bc.Name, targetsCMName)
+}
+
+// MakeTargetsConfig creates the ConfigMaps holding the shards of the targets config, see
+// config.SplitShards.
+func MakeTargetsConfig(bc *intv1alpha1.BrokerCell, shards []*config.TargetsConfig) ([]*corev1.ConfigMap, error) {
+	cms := make([]*corev1.ConfigMap, 0, len(shards))
+	for i, s := range shards {
+		// Deterministic so that unchanged shards serialize to the same bytes.
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing targets config shard %d: %w", i, err)
+		}
+		cms = append(cms, makeTargetsConfigShard(bc, i, b, prototext.Format(s)))
+	}
+	return cms, nil
+}
+
+// ParseTargetsConfig returns the shard of the targets config held by the ConfigMap.
+func ParseTargetsConfig(cm *corev1.ConfigMap) (*config.TargetsConfig, error) {
+	var val config.TargetsConfig
+	if err := proto.Unmarshal(cm.BinaryData[targetsCMKey], &val); err != nil {
+		return nil, fmt.Errorf("error deserializing targets config: %w", err)
+	}
+	return &val, nil
+}
+
+func makeTargetsConfigShard(bc *intv1alpha1.BrokerCell, shard int, data []byte, text string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            Name(bc.Name, targetsCMName),
+			Name:            TargetsConfigName(bc, shard),
 			Namespace:       bc.Namespace,
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(bc)},
-			Labels:          Labels(bc.Name, "broker-targets"),
+			Labels:          TargetsConfigLabels(bc),
 		},
 		BinaryData: map[string][]byte{targetsCMKey: data},
 		// Write out the text version for debugging purposes only
-		Data: map[string]string{"targets.txt": brokerTargets.String()},
-	}, nil
+		Data: map[string]string{"targets.txt": text},
+	}
 }
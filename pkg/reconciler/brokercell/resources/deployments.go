@@ -17,12 +17,15 @@ limitations under the License.
 package resources
 
 import (
+	"fmt"
+	"path"
 	"strconv"
 
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	"github.com/google/knative-gcp/pkg/broker/config/stream"
 	"github.com/google/knative-gcp/pkg/broker/handler"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/kmeta"
@@ -34,6 +37,7 @@ func MakeIngressDeployment(args IngressArgs) *appsv1.Deployment {
 	container := containerTemplate(args.Args)
 	// Decorate the container template with ingress port.
 	container.Env = append(container.Env, corev1.EnvVar{Name: "PORT", Value: strconv.Itoa(args.Port)})
+	container.Env = append(container.Env, publishSettingsEnv(args.PublishSettings)...)
 	container.Ports = append(container.Ports, corev1.ContainerPort{Name: "http", ContainerPort: int32(args.Port)})
 	container.ReadinessProbe = &corev1.Probe{
 		Handler: corev1.Handler{
@@ -62,30 +66,39 @@ func MakeIngressDeployment(args IngressArgs) *appsv1.Deployment {
 		SuccessThreshold:    1,
 		TimeoutSeconds:      5,
 	}
-	container.Resources = corev1.ResourceRequirements{
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("1000Mi"),
-		},
-		Requests: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("500Mi"),
-			corev1.ResourceCPU:    resource.MustParse("1000m"),
-		},
-	}
+	container.Resources = args.Resources
 	return deploymentTemplate(args.Args, []corev1.Container{container})
 }
 
+// publishSettingsEnv returns the env vars the ingress reads its publish settings from.
+// Only the settings that are set are included.
+func publishSettingsEnv(ps *intv1alpha1.PublishSettings) []corev1.EnvVar {
+	if ps == nil {
+		return nil
+	}
+	var env []corev1.EnvVar
+	if ps.DelayThreshold != nil {
+		env = append(env, corev1.EnvVar{Name: "PUBLISH_DELAY_THRESHOLD", Value: ps.DelayThreshold.Duration.String()})
+	}
+	if ps.CountThreshold != nil {
+		env = append(env, corev1.EnvVar{Name: "PUBLISH_COUNT_THRESHOLD", Value: strconv.Itoa(int(*ps.CountThreshold))})
+	}
+	if ps.ByteThreshold != nil {
+		env = append(env, corev1.EnvVar{Name: "PUBLISH_BYTE_THRESHOLD", Value: strconv.Itoa(int(*ps.ByteThreshold))})
+	}
+	if ps.MaxOutstandingMessages != nil {
+		env = append(env, corev1.EnvVar{Name: "PUBLISH_MAX_OUTSTANDING_MESSAGES", Value: strconv.Itoa(int(*ps.MaxOutstandingMessages))})
+	}
+	if ps.MaxOutstandingBytes != nil {
+		env = append(env, corev1.EnvVar{Name: "PUBLISH_MAX_OUTSTANDING_BYTES", Value: strconv.FormatInt(*ps.MaxOutstandingBytes, 10)})
+	}
+	return env
+}
+
 // MakeFanoutDeployment creates the fanout Deployment object.
 func MakeFanoutDeployment(args FanoutArgs) *appsv1.Deployment {
 	container := containerTemplate(args.Args)
-	container.Resources = corev1.ResourceRequirements{
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("3000Mi"),
-		},
-		Requests: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("500Mi"),
-			corev1.ResourceCPU:    resource.MustParse("1500m"),
-		},
-	}
+	container.Resources = args.Resources
 	container.Ports = append(container.Ports,
 		corev1.ContainerPort{
 			Name:          "http-health",
@@ -95,7 +108,7 @@ func MakeFanoutDeployment(args FanoutArgs) *appsv1.Deployment {
 	container.Env = append(container.Env, corev1.EnvVar{
 		Name:  "MAX_CONCURRENCY_PER_EVENT",
 		Value: "100",
-	})
+	}, serviceAccountNameEnv())
 	container.LivenessProbe = &corev1.Probe{
 		Handler: corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
@@ -116,15 +129,8 @@ func MakeFanoutDeployment(args FanoutArgs) *appsv1.Deployment {
 // MakeRetryDeployment creates the retry Deployment object.
 func MakeRetryDeployment(args RetryArgs) *appsv1.Deployment {
 	container := containerTemplate(args.Args)
-	container.Resources = corev1.ResourceRequirements{
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("3000Mi"),
-		},
-		Requests: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("500Mi"),
-			corev1.ResourceCPU:    resource.MustParse("1000m"),
-		},
-	}
+	container.Env = append(container.Env, serviceAccountNameEnv())
+	container.Resources = args.Resources
 	container.Ports = append(container.Ports,
 		corev1.ContainerPort{
 			Name:          "http-health",
@@ -148,6 +154,19 @@ func MakeRetryDeployment(args RetryArgs) *appsv1.Deployment {
 	return deploymentTemplate(args.Args, []corev1.Container{container})
 }
 
+// serviceAccountNameEnv returns the env var of the pod service account, which issues the
+// Kubernetes tokens that authenticate deliveries.
+func serviceAccountNameEnv() corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: "SERVICE_ACCOUNT_NAME",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "spec.serviceAccountName",
+			},
+		},
+	}
+}
+
 // deploymentTemplate creates a template for data plane deployments.
 func deploymentTemplate(args Args, containers []corev1.Container) *appsv1.Deployment {
 	return &appsv1.Deployment{
@@ -160,29 +179,98 @@ func deploymentTemplate(args Args, containers []corev1.Container) *appsv1.Deploy
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{MatchLabels: Labels(args.BrokerCell.Name, args.ComponentName)},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: Labels(args.BrokerCell.Name, args.ComponentName)},
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      Labels(args.BrokerCell.Name, args.ComponentName),
+					Annotations: args.PodAnnotations,
+				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: args.ServiceAccountName,
-					Volumes: []corev1.Volume{
-						{
-							Name:         "broker-config",
-							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: Name(args.BrokerCell.Name, targetsCMName)}}},
-						},
-						{
-							Name:         "google-broker-key",
-							VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "google-broker-key", Optional: &optionalSecretVolume}},
-						},
-					},
-					Containers: containers,
+					NodeSelector:       args.NodeSelector,
+					Tolerations:        args.Tolerations,
+					Volumes:            volumes(args),
+					Containers:         containers,
 				},
 			},
 		},
 	}
 }
 
+// volumes returns the volumes of data plane pods. The pods load the targets config from
+// its configmap volume, unless it's streamed to them. Then they authenticate to the config
+// service with a projected service account token instead.
+func volumes(args Args) []corev1.Volume {
+	key := corev1.Volume{
+		Name:         "google-broker-key",
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "google-broker-key", Optional: &optionalSecretVolume}},
+	}
+	if args.TargetsConfigService != "" {
+		expiration := int64(targetsTokenExpirationSeconds)
+		return []corev1.Volume{{
+			Name: targetsTokenVolume,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{
+					ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+						Audience:          stream.TokenAudience,
+						ExpirationSeconds: &expiration,
+						Path:              path.Base(stream.TokenPath),
+					},
+				}},
+			}},
+		}, key}
+	}
+	return []corev1.Volume{{
+		Name:         "broker-config",
+		VolumeSource: targetsConfigVolumeSource(args),
+	}, key}
+}
+
+// volumeMounts returns the volume mounts of data plane containers.
+func volumeMounts(args Args) []corev1.VolumeMount {
+	key := corev1.VolumeMount{
+		Name:      "google-broker-key",
+		MountPath: "/var/secrets/google",
+	}
+	if args.TargetsConfigService != "" {
+		return []corev1.VolumeMount{{
+			Name:      targetsTokenVolume,
+			MountPath: path.Dir(stream.TokenPath),
+			ReadOnly:  true,
+		}, key}
+	}
+	return []corev1.VolumeMount{{
+		Name:      "broker-config",
+		MountPath: "/var/run/cloud-run-events/broker",
+	}, key}
+}
+
+// targetsConfigVolumeSource returns the volume source of the targets config. Sharded
+// configs are projected into a directory at the path of the unsharded config file, with
+// a file for each shard. The configmaps are optional so that pods start before they are
+// created and keep running after they are deleted, e.g. the shards deleted when the
+// number of shards is reduced while pods of the previous rollout still project them.
+func targetsConfigVolumeSource(args Args) corev1.VolumeSource {
+	if args.TargetsConfigShards <= 1 {
+		return corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: TargetsConfigName(args.BrokerCell, 0)},
+			Optional:             &optionalTargetsConfigVolume,
+		}}
+	}
+	sources := make([]corev1.VolumeProjection, 0, args.TargetsConfigShards)
+	for i := 0; i < args.TargetsConfigShards; i++ {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: TargetsConfigName(args.BrokerCell, i)},
+				Items:                []corev1.KeyToPath{{Key: targetsCMKey, Path: fmt.Sprintf("%s/%d", targetsCMKey, i)}},
+				Optional:             &optionalTargetsConfigVolume,
+			},
+		})
+	}
+	return corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}}
+}
+
 // containerTemplate returns a common template for broker data plane containers.
 func containerTemplate(args Args) corev1.Container {
-	return corev1.Container{
+	container := corev1.Container{
 		Image: args.Image,
 		Name:  args.ComponentName,
 		Env: []corev1.EnvVar{
@@ -222,15 +310,13 @@ func containerTemplate(args Args) corev1.Container {
 				ContainerPort: int32(args.MetricsPort),
 			},
 		},
-		VolumeMounts: []corev1.VolumeMount{
-			{
-				Name:      "broker-config",
-				MountPath: "/var/run/cloud-run-events/broker",
-			},
-			{
-				Name:      "google-broker-key",
-				MountPath: "/var/secrets/google",
-			},
-		},
+		VolumeMounts: volumeMounts(args),
+	}
+	if args.TargetsConfigService != "" {
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "TARGETS_CONFIG_SERVICE", Value: args.TargetsConfigService},
+			corev1.EnvVar{Name: "BROKER_CELL", Value: args.BrokerCell.Name},
+		)
 	}
+	return container
 }
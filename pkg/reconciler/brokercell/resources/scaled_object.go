@@ -0,0 +1,189 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
+	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
+)
+
+var (
+	// KedaSchemeGroupVersion is the API of KEDA 2, which the ScaledObjects of the brokercells
+	// use for the gcp-stackdriver scaler and pod identities. PullSubscriptions still use the
+	// KEDA 1 API.
+	KedaSchemeGroupVersion = schema.GroupVersion{Group: "keda.sh", Version: "v1alpha1"}
+
+	ScaledObjectGVK          = KedaSchemeGroupVersion.WithKind("ScaledObject")
+	TriggerAuthenticationGVK = KedaSchemeGroupVersion.WithKind("TriggerAuthentication")
+)
+
+const (
+	undeliveredMessagesMetric     = "pubsub.googleapis.com/subscription/num_undelivered_messages"
+	oldestUnackedMessageAgeMetric = "pubsub.googleapis.com/subscription/oldest_unacked_message_age"
+
+	// metricsAlignmentPeriodSeconds is the alignment period of the subscription metrics,
+	// Pub/Sub samples them every 60 seconds.
+	metricsAlignmentPeriodSeconds = "60"
+)
+
+// ScaledObjectArgs are the arguments to create a KEDA ScaledObject scaling a component on
+// the backlog of its subscriptions.
+type ScaledObjectArgs struct {
+	ComponentName string
+	BrokerCell    *intv1alpha1.BrokerCell
+	MinReplicas   int32
+	MaxReplicas   int32
+	// ProjectID is the project of the Pub/Sub subscriptions the component pulls from.
+	ProjectID string
+	// SubscriptionComponent is the value of the brokerresources.SubscriptionComponentLabelKey
+	// label of the subscriptions the component pulls from.
+	SubscriptionComponent string
+	// TriggerAuthentication is the name of the KEDA TriggerAuthentication of the metrics
+	// queries.
+	TriggerAuthentication string
+	// SubscriptionSize is the target number of undelivered messages per replica.
+	SubscriptionSize int32
+	// OldestUnackedMessageAgeSeconds is the target age of the oldest unacked message. The
+	// component isn't scaled on the age if it's 0.
+	OldestUnackedMessageAgeSeconds int64
+	PollingIntervalSeconds         int64
+	CooldownPeriodSeconds          int64
+}
+
+// ScaledObjectName returns the name of the ScaledObject of the component deployment.
+func ScaledObjectName(deployment *appsv1.Deployment) string {
+	return deployment.Name + "-scaledobject"
+}
+
+// TriggerAuthenticationName returns the name of the TriggerAuthentication created for the
+// backlog autoscaling of the brokercell.
+func TriggerAuthenticationName(bc *intv1alpha1.BrokerCell) string {
+	return Name(bc.Name, "keda-auth")
+}
+
+// MakeScaledObject makes a KEDA ScaledObject scaling the deployment on the backlog of the
+// subscriptions. The backlog of all the subscriptions of the component is queried at once
+// from Cloud Monitoring by the brokercell labels of the subscriptions, so that the number of
+// triggers and queries doesn't grow with the number of brokers and triggers.
+func MakeScaledObject(deployment *appsv1.Deployment, args ScaledObjectArgs) *unstructured.Unstructured {
+	authenticationRef := map[string]interface{}{"name": args.TriggerAuthentication}
+	triggers := []interface{}{
+		map[string]interface{}{
+			"type": "gcp-stackdriver",
+			"metadata": map[string]interface{}{
+				"projectId":              args.ProjectID,
+				"filter":                 subscriptionMetricFilter(undeliveredMessagesMetric, args),
+				"targetValue":            strconv.Itoa(int(args.SubscriptionSize)),
+				"alignmentPeriodSeconds": metricsAlignmentPeriodSeconds,
+				"alignmentAligner":       "max",
+				"alignmentReducer":       "sum",
+			},
+			"authenticationRef": authenticationRef,
+		},
+	}
+	if args.OldestUnackedMessageAgeSeconds > 0 {
+		triggers = append(triggers, map[string]interface{}{
+			"type": "gcp-stackdriver",
+			"metadata": map[string]interface{}{
+				"projectId":              args.ProjectID,
+				"filter":                 subscriptionMetricFilter(oldestUnackedMessageAgeMetric, args),
+				"targetValue":            strconv.FormatInt(args.OldestUnackedMessageAgeSeconds, 10),
+				"alignmentPeriodSeconds": metricsAlignmentPeriodSeconds,
+				"alignmentAligner":       "max",
+				"alignmentReducer":       "max",
+			},
+			"authenticationRef": authenticationRef,
+		})
+	}
+
+	// Using Unstructured for the same reason as the ScaledObjects of PullSubscriptions.
+	apiVersion, kind := ScaledObjectGVK.ToAPIVersionAndKind()
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   kedaObjectMeta(args.BrokerCell, ScaledObjectName(deployment), Labels(args.BrokerCell.Name, args.ComponentName)),
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"name": deployment.Name,
+				},
+				"minReplicaCount": int64(args.MinReplicas),
+				"maxReplicaCount": int64(args.MaxReplicas),
+				"pollingInterval": args.PollingIntervalSeconds,
+				"cooldownPeriod":  args.CooldownPeriodSeconds,
+				"triggers":        triggers,
+			},
+		},
+	}
+}
+
+// MakeTriggerAuthentication makes the KEDA TriggerAuthentication that authenticates the
+// metrics queries of the ScaledObjects of the brokercell with the GCP pod identity
+// (Workload Identity) of the KEDA operator. No credentials are exposed to KEDA.
+func MakeTriggerAuthentication(bc *intv1alpha1.BrokerCell) *unstructured.Unstructured {
+	apiVersion, kind := TriggerAuthenticationGVK.ToAPIVersionAndKind()
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   kedaObjectMeta(bc, TriggerAuthenticationName(bc), CommonLabels(bc.Name)),
+			"spec": map[string]interface{}{
+				"podIdentity": map[string]interface{}{
+					"provider": "gcp",
+				},
+			},
+		},
+	}
+}
+
+// subscriptionMetricFilter returns the Cloud Monitoring filter of the metric of all the
+// subscriptions the component of the brokercell pulls from.
+func subscriptionMetricFilter(metric string, args ScaledObjectArgs) string {
+	return fmt.Sprintf(`metric.type=%q AND resource.type="pubsub_subscription" AND metadata.user_labels.%q=%q AND metadata.user_labels.%q=%q`,
+		metric,
+		brokerresources.SubscriptionBrokerCellLabelKey, args.BrokerCell.Name,
+		brokerresources.SubscriptionComponentLabelKey, args.SubscriptionComponent)
+}
+
+// kedaObjectMeta returns the metadata of a KEDA object of the brokercell.
+func kedaObjectMeta(bc *intv1alpha1.BrokerCell, name string, labels map[string]string) map[string]interface{} {
+	ulabels := make(map[string]interface{}, len(labels))
+	for k, v := range labels {
+		ulabels[k] = v
+	}
+	return map[string]interface{}{
+		"namespace": bc.Namespace,
+		"name":      name,
+		"labels":    ulabels,
+		"ownerReferences": []interface{}{
+			map[string]interface{}{
+				"apiVersion":         bc.GetGroupVersionKind().GroupVersion().String(),
+				"kind":               bc.GetGroupVersionKind().Kind,
+				"blockOwnerDeletion": true,
+				"controller":         true,
+				"name":               bc.Name,
+				"uid":                string(bc.UID),
+			}},
+	}
+}
@@ -18,13 +18,14 @@ package testingdata
 
 import (
 	"testing"
+	"time"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	intv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 	"github.com/google/knative-gcp/pkg/broker/config"
-	"github.com/google/knative-gcp/pkg/broker/config/memory"
 	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	"github.com/google/knative-gcp/pkg/reconciler/brokercell/resources"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -34,11 +35,34 @@ const (
 )
 
 func EmptyConfig(t *testing.T, bc *intv1alpha1.BrokerCell) *corev1.ConfigMap {
-	cm, _ := resources.MakeTargetsConfig(bc, memory.NewEmptyTargets())
-	return cm
+	cms, _ := resources.MakeTargetsConfig(bc, []*config.TargetsConfig{{}})
+	return cms[0]
+}
+
+// Stamp returns the targets config ConfigMap of the BrokerCell stamped with the generation.
+func Stamp(t *testing.T, bc *intv1alpha1.BrokerCell, cm *corev1.ConfigMap, generation int64, updateTime time.Time) *corev1.ConfigMap {
+	cfg, err := resources.ParseTargetsConfig(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Generation = generation
+	cfg.UpdateTime = timestamppb.New(updateTime)
+	cms, _ := resources.MakeTargetsConfig(bc, []*config.TargetsConfig{cfg})
+	return cms[0]
 }
 
 func Config(t *testing.T, bc *intv1alpha1.BrokerCell, broker *brokerv1beta1.Broker, triggers ...*brokerv1beta1.Trigger) *corev1.ConfigMap {
+	// construct delivery config from the broker delivery spec
+	var deadLetterAddress string
+	var maxAttempts int32
+	if broker.Spec.Delivery != nil && broker.Spec.Delivery.DeadLetterSink != nil {
+		deadLetterAddress = broker.Spec.Delivery.DeadLetterSink.URI.String()
+		maxAttempts = 1
+		if broker.Spec.Delivery.Retry != nil {
+			maxAttempts += *broker.Spec.Delivery.Retry
+		}
+	}
+
 	// construct triggers config
 	targets := make(map[string]*config.Target, len(triggers))
 	for _, t := range triggers {
@@ -60,9 +84,21 @@ func Config(t *testing.T, bc *intv1alpha1.BrokerCell, broker *brokerv1beta1.Brok
 				Topic:        brokerresources.GenerateRetryTopicName(t),
 				Subscription: brokerresources.GenerateRetrySubscriptionName(t),
 			},
-			State:            state,
-			FilterAttributes: filterAttributes,
+			State:             state,
+			FilterAttributes:  filterAttributes,
+			DeadLetterAddress: deadLetterAddress,
+			MaxAttempts:       maxAttempts,
+		}
+
+		// URI reply destinations don't need to be resolved. Ref reply destinations are expected to
+		// be unresolvable, so their replies are sent to the address in the trigger status or dropped.
+		if dest, _ := t.GetReplyDestination(); dest != nil && dest.URI != nil {
+			target.ReplyAddress = dest.URI.String()
+		} else if dest != nil {
+			target.ReplyAddress = t.Status.ReplyAddress()
+			target.RepliesDisabled = target.ReplyAddress == ""
 		}
+		target.ReplyRequiredAttributes = t.GetReplyRequiredAttributes()
 
 		targets[t.Name] = target
 	}
@@ -81,15 +117,15 @@ func Config(t *testing.T, bc *intv1alpha1.BrokerCell, broker *brokerv1beta1.Brok
 			Topic:        brokerresources.GenerateDecouplingTopicName(broker),
 			Subscription: brokerresources.GenerateDecouplingSubscriptionName(broker),
 		},
-		Targets: targets,
-		State:   state,
+		Targets:              targets,
+		State:                state,
+		OrderingKeyAttribute: broker.GetOrderingKeyAttribute(),
 	}
 	bt := &config.TargetsConfig{
 		Brokers: map[string]*config.Broker{
 			brokerConfig.Key(): brokerConfig,
 		},
 	}
-	brokerTargets := memory.NewTargets(bt)
-	cm, _ := resources.MakeTargetsConfig(bc, brokerTargets)
-	return cm
+	cms, _ := resources.MakeTargetsConfig(bc, []*config.TargetsConfig{bt})
+	return cms[0]
 }
@@ -494,6 +494,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(sourceName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 					},
@@ -543,6 +544,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(sourceName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -597,6 +599,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(sourceName, testNS, WithPullSubscriptionFailed(),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -651,6 +654,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(sourceName, testNS, WithPullSubscriptionUnknown(),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -706,6 +710,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -769,6 +774,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -832,6 +838,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -895,6 +902,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -960,6 +968,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -1035,6 +1044,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -1110,6 +1120,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -1179,6 +1190,7 @@ func TestAllCases(t *testing.T) {
 				WithPullSubscriptionReady(sinkURI),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
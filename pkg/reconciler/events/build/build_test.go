@@ -191,6 +191,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(buildName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -230,6 +231,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(buildName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -271,6 +273,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(buildName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -312,6 +315,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(buildName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
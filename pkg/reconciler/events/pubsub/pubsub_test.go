@@ -190,6 +190,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(pubsubName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 					},
@@ -227,6 +228,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(pubsubName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -270,6 +272,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(pubsubName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -313,6 +316,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(pubsubName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -520,6 +520,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(schedulerName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 						},
@@ -567,6 +568,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(schedulerName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -621,6 +623,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(schedulerName, testNS, WithPullSubscriptionFailed(),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -675,6 +678,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(schedulerName, testNS, WithPullSubscriptionUnknown(),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -732,6 +736,7 @@ func TestAllCases(t *testing.T) {
 					WithPullSubscriptionReady(sinkURI),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -800,6 +805,7 @@ func TestAllCases(t *testing.T) {
 					WithPullSubscriptionReady(sinkURI),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -868,6 +874,7 @@ func TestAllCases(t *testing.T) {
 					WithPullSubscriptionReady(sinkURI),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -936,6 +943,7 @@ func TestAllCases(t *testing.T) {
 					WithPullSubscriptionReady(sinkURI),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -1005,6 +1013,7 @@ func TestAllCases(t *testing.T) {
 					WithPullSubscriptionReady(sinkURI),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -1073,6 +1082,7 @@ func TestAllCases(t *testing.T) {
 					WithPullSubscriptionReady(sinkURI),
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Secret: &secret,
 							SourceSpec: duckv1.SourceSpec{
@@ -513,6 +513,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(storageName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 					},
@@ -560,6 +561,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(storageName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -614,6 +616,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(storageName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -667,6 +670,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(storageName, testNS, WithPullSubscriptionUnknown(),
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -723,6 +727,7 @@ func TestAllCases(t *testing.T) {
 			NewPullSubscription(storageName, testNS,
 				WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: duckv1beta1.PubSubSpec{
 						Project: testProject,
 						Secret:  &secret,
@@ -795,6 +800,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(storageName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Project: testProject,
 							Secret:  &secret,
@@ -868,6 +874,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(storageName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Project: testProject,
 							Secret:  &secret,
@@ -941,6 +948,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(storageName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Project: testProject,
 							Secret:  &secret,
@@ -1014,6 +1022,7 @@ func TestAllCases(t *testing.T) {
 				NewPullSubscription(storageName, testNS,
 					WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 						Topic: testTopicID,
+						Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 						PubSubSpec: duckv1beta1.PubSubSpec{
 							Project: testProject,
 							Secret:  &secret,
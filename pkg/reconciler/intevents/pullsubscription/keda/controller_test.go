@@ -20,6 +20,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
 	iamtesting "github.com/google/knative-gcp/pkg/reconciler/testing"
 
 	"knative.dev/pkg/configmap"
@@ -71,6 +72,13 @@ func TestNew(t *testing.T) {
 			},
 			Data: map[string]string{},
 		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      converters.ConvertersConfigMapName,
+				Namespace: system.Namespace(),
+			},
+			Data: map[string]string{},
+		},
 	)
 	c := newController(ctx, cmw, iamtesting.NoopIAMPolicyManager, iamtesting.NewGCPAuthTestStore(t, nil))
 
@@ -23,8 +23,11 @@ import (
 
 	"go.uber.org/zap"
 
+	"cloud.google.com/go/pubsub"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -45,6 +48,7 @@ import (
 	"github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	listers "github.com/google/knative-gcp/pkg/client/listers/intevents/v1beta1"
 	gpubsub "github.com/google/knative-gcp/pkg/gclient/pubsub"
+	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents"
 	"github.com/google/knative-gcp/pkg/reconciler/intevents/pullsubscription/resources"
@@ -90,6 +94,9 @@ type Base struct {
 	MetricsConfig *metrics.ExporterOptions
 	TracingConfig *tracingconfig.Config
 
+	// ConvertersConfig holds the generic converters the receive adapters can use.
+	ConvertersConfig converters.GenericConfigs
+
 	// CreateClientFn is the function used to create the Pub/Sub client that interacts with Pub/Sub.
 	// This is needed so that we can inject a mock client for UTs purposes.
 	CreateClientFn gpubsub.CreateFn
@@ -138,6 +145,18 @@ func (r *Base) ReconcileKind(ctx context.Context, ps *v1beta1.PullSubscription)
 		ps.Status.TransformerURI = nil
 	}
 
+	// Dead letter sink is optional.
+	if ps.Spec.Delivery != nil && ps.Spec.Delivery.DeadLetterSink != nil {
+		deadLetterSinkURI, err := r.resolveDestination(ctx, *ps.Spec.Delivery.DeadLetterSink, ps)
+		if err != nil {
+			ps.Status.MarkNoSink("InvalidDeadLetterSink", err.Error())
+			return reconciler.NewEvent(corev1.EventTypeWarning, "InvalidDeadLetterSink", "InvalidDeadLetterSink: %s", err.Error())
+		}
+		ps.Status.DeadLetterSinkURI = deadLetterSinkURI
+	} else {
+		ps.Status.DeadLetterSinkURI = nil
+	}
+
 	subscriptionID, err := r.reconcileSubscription(ctx, ps)
 	if err != nil {
 		ps.Status.MarkNoSubscription(reconciledPubSubFailedReason, "Failed to reconcile Pub/Sub subscription: %s", err.Error())
@@ -219,6 +238,13 @@ func (r *Base) reconcileSubscription(ctx context.Context, ps *v1beta1.PullSubscr
 		subConfig.RetentionDuration = retentionDuration
 	}
 
+	subConfig.DeadLetterPolicy = resources.MakeDeadLetterPolicy(ps, ps.Status.ProjectID)
+	subConfig.RetryPolicy, err = resources.MakeRetryPolicy(ps)
+	if err != nil {
+		logging.FromContext(ctx).Desugar().Error("Invalid backoff", zap.Error(err))
+		return "", err
+	}
+
 	// Check if the topic of the subscription is "_deleted-topic_"
 	if subExists {
 		config, err := sub.Config(ctx)
@@ -239,6 +265,9 @@ func (r *Base) reconcileSubscription(ctx context.Context, ps *v1beta1.PullSubscr
 				logging.FromContext(ctx).Desugar().Error("Failed to create subscription", zap.Error(err))
 				return "", err
 			}
+		} else if err := updateDeliveryPolicies(ctx, sub, config, subConfig); err != nil {
+			logging.FromContext(ctx).Desugar().Error("Failed to update the delivery policies of the subscription", zap.Error(err))
+			return "", err
 		}
 	} else {
 		sub, err = client.CreateSubscription(ctx, subID, subConfig)
@@ -247,10 +276,30 @@ func (r *Base) reconcileSubscription(ctx context.Context, ps *v1beta1.PullSubscr
 			return "", err
 		}
 	}
-	// TODO update the subscription's config if needed.
+	// TODO update the rest of the subscription's config if needed.
 	return subID, nil
 }
 
+// updateDeliveryPolicies updates the dead letter and retry policies of the
+// subscription if they differ from the wanted ones in subConfig.
+func updateDeliveryPolicies(ctx context.Context, sub gpubsub.Subscription, config, subConfig gpubsub.SubscriptionConfig) error {
+	if equality.Semantic.DeepEqual(config.DeadLetterPolicy, subConfig.DeadLetterPolicy) &&
+		equality.Semantic.DeepEqual(config.RetryPolicy, subConfig.RetryPolicy) {
+		return nil
+	}
+	// The update also carries the rest of the wanted config, as it always sets RetainAckedMessages.
+	update := subConfig
+	// Empty policies remove the existing ones from the subscription.
+	if update.DeadLetterPolicy == nil {
+		update.DeadLetterPolicy = &pubsub.DeadLetterPolicy{}
+	}
+	if update.RetryPolicy == nil {
+		update.RetryPolicy = &pubsub.RetryPolicy{}
+	}
+	_, err := sub.Update(ctx, update)
+	return err
+}
+
 // deleteSubscription looks at the status.SubscriptionID and if non-empty,
 // hence indicating that we have created a subscription successfully
 // in the PullSubscription, remove it.
@@ -309,16 +358,23 @@ func (r *Base) reconcileDataPlaneResources(ctx context.Context, ps *v1beta1.Pull
 		logging.FromContext(ctx).Desugar().Error("Error serializing tracing config", zap.Error(err))
 	}
 
+	var converterConfig *converters.GenericConfig
+	if cfg, ok := r.ConvertersConfig[converters.ConverterType(ps.Spec.AdapterType)]; ok {
+		converterConfig = &cfg
+	}
+
 	desired := resources.MakeReceiveAdapter(ctx, &resources.ReceiveAdapterArgs{
-		Image:            r.ReceiveAdapterImage,
-		PullSubscription: ps,
-		Labels:           resources.GetLabels(r.ControllerAgentName, ps.Name),
-		SubscriptionID:   ps.Status.SubscriptionID,
-		SinkURI:          ps.Status.SinkURI,
-		TransformerURI:   ps.Status.TransformerURI,
-		LoggingConfig:    loggingConfig,
-		MetricsConfig:    metricsConfig,
-		TracingConfig:    tracingConfig,
+		Image:             r.ReceiveAdapterImage,
+		PullSubscription:  ps,
+		Labels:            resources.GetLabels(r.ControllerAgentName, ps.Name),
+		SubscriptionID:    ps.Status.SubscriptionID,
+		SinkURI:           ps.Status.SinkURI,
+		TransformerURI:    ps.Status.TransformerURI,
+		DeadLetterSinkURI: ps.Status.DeadLetterSinkURI,
+		ConverterConfig:   converterConfig,
+		LoggingConfig:     loggingConfig,
+		MetricsConfig:     metricsConfig,
+		TracingConfig:     tracingConfig,
 	})
 
 	return f(ctx, desired, ps)
@@ -408,6 +464,22 @@ func (r *Base) UpdateFromTracingConfigMap(cfg *corev1.ConfigMap) {
 	// TODO: requeue all PullSubscriptions. See https://github.com/google/knative-gcp/issues/457.
 }
 
+func (r *Base) UpdateFromConvertersConfigMap(cfg *corev1.ConfigMap) {
+	if cfg == nil {
+		r.Logger.Error("Converters ConfigMap is nil")
+		return
+	}
+
+	convertersCfg, err := converters.NewGenericConfigsFromConfigMap(cfg)
+	if err != nil {
+		r.Logger.Warnw("Failed to create converters config from configmap", zap.String("cfg.Name", cfg.Name), zap.Error(err))
+		return
+	}
+	r.ConvertersConfig = convertersCfg
+	r.Logger.Debugw("Updated Converters config", zap.Any("convertersCfg", r.ConvertersConfig))
+	// TODO: requeue all PullSubscriptions. See https://github.com/google/knative-gcp/issues/457.
+}
+
 func (r *Base) resolveDestination(ctx context.Context, destination duckv1.Destination, ps *v1beta1.PullSubscription) (*apis.URL, error) {
 	// To call URIFromDestinationV1(), dest.Ref must have a Namespace. If there is
 	// no Namespace defined in dest.Ref, we will use the Namespace of the PS
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+
+	"github.com/google/knative-gcp/pkg/apis/duck"
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
+)
+
+// MakeDeadLetterPolicy generates the Pub/Sub dead letter policy of the
+// PullSubscription's subscription. It returns nil if the PullSubscription
+// doesn't dead letter to a Pub/Sub topic.
+func MakeDeadLetterPolicy(ps *v1beta1.PullSubscription, projectID string) *pubsub.DeadLetterPolicy {
+	delivery := ps.Spec.Delivery
+	if delivery == nil || delivery.DeadLetterTopic == "" {
+		return nil
+	}
+	return &pubsub.DeadLetterPolicy{
+		DeadLetterTopic:     fmt.Sprintf("projects/%s/topics/%s", projectID, delivery.DeadLetterTopic),
+		MaxDeliveryAttempts: int(MaxDeliveryAttempts(ps)),
+	}
+}
+
+// MakeRetryPolicy generates the Pub/Sub retry policy of the PullSubscription's
+// subscription. It returns nil if the PullSubscription doesn't set any backoff.
+// Both backoffs are always set so that the policy matches the one Pub/Sub
+// reports back.
+func MakeRetryPolicy(ps *v1beta1.PullSubscription) (*pubsub.RetryPolicy, error) {
+	delivery := ps.Spec.Delivery
+	if delivery == nil || (delivery.MinimumBackoff == nil && delivery.MaximumBackoff == nil) {
+		return nil, nil
+	}
+	minBackoff, err := parseBackoff(delivery.MinimumBackoff, duck.DefaultMinimumBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minimumBackoff: %w", err)
+	}
+	maxBackoff, err := parseBackoff(delivery.MaximumBackoff, duck.DefaultMaximumBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maximumBackoff: %w", err)
+	}
+	if delivery.MinimumBackoff == nil && minBackoff > maxBackoff {
+		minBackoff = maxBackoff
+	}
+	return &pubsub.RetryPolicy{
+		MinimumBackoff: minBackoff,
+		MaximumBackoff: maxBackoff,
+	}, nil
+}
+
+// MaxDeliveryAttempts returns the number of delivery attempts of an event
+// before it is dead lettered or dropped, or 0 if events are retried until
+// they expire.
+func MaxDeliveryAttempts(ps *v1beta1.PullSubscription) int32 {
+	delivery := ps.Spec.Delivery
+	if delivery == nil {
+		return 0
+	}
+	if delivery.MaxDeliveryAttempts != nil {
+		return *delivery.MaxDeliveryAttempts
+	}
+	if delivery.DeadLetterTopic != "" || delivery.DeadLetterSink != nil {
+		return duck.DefaultMaxDeliveryAttempts
+	}
+	return 0
+}
+
+func parseBackoff(backoff *string, defaultBackoff time.Duration) (time.Duration, error) {
+	if backoff == nil {
+		return defaultBackoff, nil
+	}
+	return time.ParseDuration(*backoff)
+}
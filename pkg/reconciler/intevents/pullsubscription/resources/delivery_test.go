@@ -0,0 +1,177 @@
+/*
+Copyright 2020 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+
+	duckv1beta1 "github.com/google/knative-gcp/pkg/apis/duck/v1beta1"
+	"github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
+)
+
+func newPullSubscriptionWithDelivery(delivery *duckv1beta1.DeliverySpec) *v1beta1.PullSubscription {
+	return &v1beta1.PullSubscription{
+		Spec: v1beta1.PullSubscriptionSpec{
+			PubSubSpec: duckv1beta1.PubSubSpec{
+				Delivery: delivery,
+			},
+		},
+	}
+}
+
+func TestMakeDeadLetterPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *duckv1beta1.DeliverySpec
+		want     *pubsub.DeadLetterPolicy
+	}{{
+		name: "no delivery",
+	}, {
+		name: "dead letter sink",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterSink: &duckv1.Destination{URI: apis.HTTP("dead-letter")},
+		},
+	}, {
+		name: "dead letter topic with default attempts",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterTopic: "dead-letter",
+		},
+		want: &pubsub.DeadLetterPolicy{
+			DeadLetterTopic:     "projects/my-project/topics/dead-letter",
+			MaxDeliveryAttempts: 5,
+		},
+	}, {
+		name: "dead letter topic",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterTopic:     "dead-letter",
+			MaxDeliveryAttempts: ptr.Int32(20),
+		},
+		want: &pubsub.DeadLetterPolicy{
+			DeadLetterTopic:     "projects/my-project/topics/dead-letter",
+			MaxDeliveryAttempts: 20,
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MakeDeadLetterPolicy(newPullSubscriptionWithDelivery(test.delivery), "my-project")
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *duckv1beta1.DeliverySpec
+		want     *pubsub.RetryPolicy
+		wantErr  bool
+	}{{
+		name: "no delivery",
+	}, {
+		name: "no backoff",
+		delivery: &duckv1beta1.DeliverySpec{
+			MaxDeliveryAttempts: ptr.Int32(20),
+		},
+	}, {
+		name: "minimum backoff",
+		delivery: &duckv1beta1.DeliverySpec{
+			MinimumBackoff: ptr.String("1m"),
+		},
+		want: &pubsub.RetryPolicy{
+			MinimumBackoff: time.Minute,
+			MaximumBackoff: 600 * time.Second,
+		},
+	}, {
+		name: "maximum backoff below the default minimum backoff",
+		delivery: &duckv1beta1.DeliverySpec{
+			MaximumBackoff: ptr.String("5s"),
+		},
+		want: &pubsub.RetryPolicy{
+			MinimumBackoff: 5 * time.Second,
+			MaximumBackoff: 5 * time.Second,
+		},
+	}, {
+		name: "both backoffs",
+		delivery: &duckv1beta1.DeliverySpec{
+			MinimumBackoff: ptr.String("1s"),
+			MaximumBackoff: ptr.String("1m"),
+		},
+		want: &pubsub.RetryPolicy{
+			MinimumBackoff: time.Second,
+			MaximumBackoff: time.Minute,
+		},
+	}, {
+		name: "invalid backoff",
+		delivery: &duckv1beta1.DeliverySpec{
+			MinimumBackoff: ptr.String("1 second"),
+		},
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MakeRetryPolicy(newPullSubscriptionWithDelivery(test.delivery))
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("unexpected error: got=%v, want error=%v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMaxDeliveryAttempts(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *duckv1beta1.DeliverySpec
+		want     int32
+	}{{
+		name: "no delivery",
+	}, {
+		name:     "no dead letter",
+		delivery: &duckv1beta1.DeliverySpec{},
+	}, {
+		name: "dead letter with default attempts",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterSink: &duckv1.Destination{URI: apis.HTTP("dead-letter")},
+		},
+		want: 5,
+	}, {
+		name: "attempts without dead letter",
+		delivery: &duckv1beta1.DeliverySpec{
+			MaxDeliveryAttempts: ptr.Int32(10),
+		},
+		want: 10,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MaxDeliveryAttempts(newPullSubscriptionWithDelivery(test.delivery))
+			if got != test.want {
+				t.Errorf("unexpected max delivery attempts: got=%v, want=%v", got, test.want)
+			}
+		})
+	}
+}
@@ -18,7 +18,9 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"go.uber.org/zap"
 
@@ -40,15 +42,17 @@ import (
 // ReceiveAdapterArgs are the arguments needed to create a PullSubscription Receive
 // Adapter. Every field is required.
 type ReceiveAdapterArgs struct {
-	Image            string
-	PullSubscription *v1beta1.PullSubscription
-	Labels           map[string]string
-	SubscriptionID   string
-	SinkURI          *apis.URL
-	TransformerURI   *apis.URL
-	MetricsConfig    string
-	LoggingConfig    string
-	TracingConfig    string
+	Image             string
+	PullSubscription  *v1beta1.PullSubscription
+	Labels            map[string]string
+	SubscriptionID    string
+	SinkURI           *apis.URL
+	TransformerURI    *apis.URL
+	DeadLetterSinkURI *apis.URL
+	ConverterConfig   *converters.GenericConfig
+	MetricsConfig     string
+	LoggingConfig     string
+	TracingConfig     string
 }
 
 const (
@@ -101,7 +105,8 @@ func makeReceiveAdapterPodSpec(ctx context.Context, args *ReceiveAdapterArgs) *c
 	// Then we set the adapter type to be PubSubPull.
 	_, isFromSource := args.PullSubscription.Labels[intevents.SourceLabelKey]
 	_, isFromChannel := args.PullSubscription.Labels[intevents.ChannelLabelKey]
-	if !isFromSource && !isFromChannel {
+	// Generic converters can be used by any PullSubscription.
+	if !isFromSource && !isFromChannel && args.ConverterConfig == nil {
 		adapterType = string(converters.PubSubPull)
 	}
 
@@ -172,6 +177,50 @@ func makeReceiveAdapterPodSpec(ctx context.Context, args *ReceiveAdapterArgs) *c
 		}},
 	}
 
+	// The receive adapter only counts the delivery attempts when Pub/Sub doesn't dead letter the events itself.
+	if maxDeliveryAttempts := MaxDeliveryAttempts(args.PullSubscription); maxDeliveryAttempts > 0 && args.PullSubscription.Spec.Delivery.DeadLetterTopic == "" {
+		var deadLetterSinkURI string
+		if args.DeadLetterSinkURI != nil {
+			deadLetterSinkURI = args.DeadLetterSinkURI.String()
+		}
+		receiveAdapterContainer.Env = append(
+			receiveAdapterContainer.Env,
+			corev1.EnvVar{
+				Name:  "MAX_DELIVERY_ATTEMPTS",
+				Value: strconv.Itoa(int(maxDeliveryAttempts)),
+			},
+			corev1.EnvVar{
+				Name:  "DEAD_LETTER_SINK_URI",
+				Value: deadLetterSinkURI,
+			})
+	}
+
+	if batching := args.PullSubscription.Spec.Batching; batching != nil {
+		receiveAdapterContainer.Env = append(
+			receiveAdapterContainer.Env,
+			corev1.EnvVar{
+				Name:  "BATCH_MAX_EVENTS",
+				Value: strconv.Itoa(batching.GetMaxEvents()),
+			},
+			corev1.EnvVar{
+				Name:  "BATCH_MAX_LATENCY",
+				Value: batching.GetMaxLatency().String(),
+			})
+	}
+
+	if args.ConverterConfig != nil {
+		converterConfig, err := json.Marshal(args.ConverterConfig)
+		if err != nil {
+			logging.FromContext(ctx).Warnw("failed to marshal converter config",
+				zap.Error(err),
+				zap.Any("converterConfig", args.ConverterConfig))
+		}
+		receiveAdapterContainer.Env = append(receiveAdapterContainer.Env, corev1.EnvVar{
+			Name:  "CONVERTER_CONFIG",
+			Value: string(converterConfig),
+		})
+	}
+
 	// If there is no secret to embed, return what we have.
 	if args.PullSubscription.Spec.Secret == nil {
 		return &corev1.PodSpec{
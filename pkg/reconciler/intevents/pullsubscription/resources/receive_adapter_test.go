@@ -34,6 +34,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
 )
 
 func TestMakeMinimumReceiveAdapter(t *testing.T) {
@@ -523,3 +524,200 @@ func TestMakeReceiveAdapterWithServiceAccount(t *testing.T) {
 		t.Errorf("unexpected deploy (-want, +got) = %v", diff)
 	}
 }
+
+func TestMakeReceiveAdapterWithDelivery(t *testing.T) {
+	maxDeliveryAttempts := int32(10)
+	tests := []struct {
+		name              string
+		delivery          *duckv1beta1.DeliverySpec
+		deadLetterSinkURI *apis.URL
+		want              []corev1.EnvVar
+	}{{
+		name: "no delivery",
+	}, {
+		name: "dead letter topic",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterTopic:     "dead-letter",
+			MaxDeliveryAttempts: &maxDeliveryAttempts,
+		},
+	}, {
+		name: "dead letter sink",
+		delivery: &duckv1beta1.DeliverySpec{
+			DeadLetterSink: &duckv1.Destination{URI: apis.HTTP("dead-letter")},
+		},
+		deadLetterSinkURI: apis.HTTP("dead-letter"),
+		want: []corev1.EnvVar{{
+			Name:  "MAX_DELIVERY_ATTEMPTS",
+			Value: "5",
+		}, {
+			Name:  "DEAD_LETTER_SINK_URI",
+			Value: "http://dead-letter",
+		}},
+	}, {
+		name: "attempts without dead letter",
+		delivery: &duckv1beta1.DeliverySpec{
+			MaxDeliveryAttempts: &maxDeliveryAttempts,
+		},
+		want: []corev1.EnvVar{{
+			Name:  "MAX_DELIVERY_ATTEMPTS",
+			Value: "10",
+		}, {
+			Name:  "DEAD_LETTER_SINK_URI",
+			Value: "",
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ps := &v1beta1.PullSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testname",
+					Namespace: "testnamespace",
+				},
+				Spec: v1beta1.PullSubscriptionSpec{
+					PubSubSpec: duckv1beta1.PubSubSpec{
+						Delivery: test.delivery,
+					},
+					Topic: "topic",
+				},
+			}
+			got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+				Image:             "test-image",
+				PullSubscription:  ps,
+				SubscriptionID:    "sub-id",
+				SinkURI:           apis.HTTP("sink-uri"),
+				DeadLetterSinkURI: test.deadLetterSinkURI,
+			})
+
+			var gotEnv []corev1.EnvVar
+			for _, env := range got.Spec.Template.Spec.Containers[0].Env {
+				if env.Name == "MAX_DELIVERY_ATTEMPTS" || env.Name == "DEAD_LETTER_SINK_URI" {
+					gotEnv = append(gotEnv, env)
+				}
+			}
+			if diff := cmp.Diff(test.want, gotEnv); diff != "" {
+				t.Errorf("unexpected delivery env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeReceiveAdapterWithConverterConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		converterConfig *converters.GenericConfig
+		want            []corev1.EnvVar
+	}{{
+		name: "no converter config",
+		want: []corev1.EnvVar{{
+			Name:  "ADAPTER_TYPE",
+			Value: string(converters.PubSubPull),
+		}},
+	}, {
+		name: "converter config",
+		converterConfig: &converters.GenericConfig{
+			Type:   "com.example.foo",
+			Source: "//example.googleapis.com/{.attributes.source}",
+		},
+		want: []corev1.EnvVar{{
+			Name:  "ADAPTER_TYPE",
+			Value: "com.example.foo",
+		}, {
+			Name:  "CONVERTER_CONFIG",
+			Value: `{"type":"com.example.foo","source":"//example.googleapis.com/{.attributes.source}"}`,
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ps := &v1beta1.PullSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testname",
+					Namespace: "testnamespace",
+				},
+				Spec: v1beta1.PullSubscriptionSpec{
+					Topic:       "topic",
+					AdapterType: "com.example.foo",
+				},
+			}
+			got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+				Image:            "test-image",
+				PullSubscription: ps,
+				SubscriptionID:   "sub-id",
+				SinkURI:          apis.HTTP("sink-uri"),
+				ConverterConfig:  test.converterConfig,
+			})
+
+			var gotEnv []corev1.EnvVar
+			for _, env := range got.Spec.Template.Spec.Containers[0].Env {
+				if env.Name == "ADAPTER_TYPE" || env.Name == "CONVERTER_CONFIG" {
+					gotEnv = append(gotEnv, env)
+				}
+			}
+			if diff := cmp.Diff(test.want, gotEnv); diff != "" {
+				t.Errorf("unexpected converter env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeReceiveAdapterWithBatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		batching *v1beta1.BatchingSpec
+		want     []corev1.EnvVar
+	}{{
+		name: "no batching",
+	}, {
+		name:     "default batching",
+		batching: &v1beta1.BatchingSpec{},
+		want: []corev1.EnvVar{{
+			Name:  "BATCH_MAX_EVENTS",
+			Value: "100",
+		}, {
+			Name:  "BATCH_MAX_LATENCY",
+			Value: "1s",
+		}},
+	}, {
+		name: "batching",
+		batching: &v1beta1.BatchingSpec{
+			MaxEvents:  ptr.Int32(500),
+			MaxLatency: ptr.String("100ms"),
+		},
+		want: []corev1.EnvVar{{
+			Name:  "BATCH_MAX_EVENTS",
+			Value: "500",
+		}, {
+			Name:  "BATCH_MAX_LATENCY",
+			Value: "100ms",
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ps := &v1beta1.PullSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testname",
+					Namespace: "testnamespace",
+				},
+				Spec: v1beta1.PullSubscriptionSpec{
+					Topic:    "topic",
+					Batching: test.batching,
+				},
+			}
+			got := MakeReceiveAdapter(context.Background(), &ReceiveAdapterArgs{
+				Image:            "test-image",
+				PullSubscription: ps,
+				SubscriptionID:   "sub-id",
+				SinkURI:          apis.HTTP("sink-uri"),
+			})
+
+			var gotEnv []corev1.EnvVar
+			for _, env := range got.Spec.Template.Spec.Containers[0].Env {
+				if env.Name == "BATCH_MAX_EVENTS" || env.Name == "BATCH_MAX_LATENCY" {
+					gotEnv = append(gotEnv, env)
+				}
+			}
+			if diff := cmp.Diff(test.want, gotEnv); diff != "" {
+				t.Errorf("unexpected batching env (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
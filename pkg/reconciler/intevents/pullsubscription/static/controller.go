@@ -26,6 +26,7 @@ import (
 	"github.com/google/knative-gcp/pkg/apis/intevents/v1beta1"
 	pullsubscriptioninformers "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1beta1/pullsubscription"
 	gpubsub "github.com/google/knative-gcp/pkg/gclient/pubsub"
+	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
 	"github.com/google/knative-gcp/pkg/reconciler"
 	"github.com/google/knative-gcp/pkg/reconciler/identity"
 	"github.com/google/knative-gcp/pkg/reconciler/identity/iam"
@@ -138,6 +139,7 @@ func newController(
 	cmw.Watch(logging.ConfigMapName(), r.UpdateFromLoggingConfigMap)
 	cmw.Watch(metrics.ConfigMapName(), r.UpdateFromMetricsConfigMap)
 	cmw.Watch(tracingconfig.ConfigName, r.UpdateFromTracingConfigMap)
+	cmw.Watch(converters.ConvertersConfigMapName, r.UpdateFromConvertersConfigMap)
 
 	return impl
 }
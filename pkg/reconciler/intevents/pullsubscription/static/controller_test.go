@@ -35,6 +35,7 @@ import (
 	"knative.dev/pkg/system"
 	tracingconfig "knative.dev/pkg/tracing/config"
 
+	"github.com/google/knative-gcp/pkg/pubsub/adapter/converters"
 	iamtesting "github.com/google/knative-gcp/pkg/reconciler/testing"
 
 	// Fake injection informers
@@ -69,6 +70,13 @@ func TestNew(t *testing.T) {
 			},
 			Data: map[string]string{},
 		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      converters.ConvertersConfigMapName,
+				Namespace: system.Namespace(),
+			},
+			Data: map[string]string{},
+		},
 	)
 	c := newController(ctx, cmw, iamtesting.NoopIAMPolicyManager, iamtesting.NewGCPAuthTestStore(t, nil))
 
@@ -45,6 +45,12 @@ const (
 
 var falseVal = false
 
+// pubSubModer is implemented by the PubSubables that choose the mode in which
+// their PullSubscription invokes the sink.
+type pubSubModer interface {
+	PubSubMode() inteventsv1beta1.ModeType
+}
+
 type PubSubBase struct {
 	*reconciler.Base
 
@@ -151,6 +157,14 @@ func (psb *PubSubBase) ReconcilePullSubscription(ctx context.Context, pubsubable
 		Labels:      resources.GetLabels(psb.receiveAdapterName, name),
 		Annotations: resources.GetAnnotations(annotations, resourceGroup),
 	}
+	if m, ok := pubsubable.(pubSubModer); ok {
+		args.Mode = m.PubSubMode()
+		// An unset mode is ignored when comparing the PullSubscription specs, so set the
+		// default explicitly for clearing the mode to switch the PullSubscription back.
+		if args.Mode == "" {
+			args.Mode = inteventsv1beta1.ModeCloudEventsBinary
+		}
+	}
 
 	newPS := resources.MakePullSubscription(args)
 
@@ -391,6 +391,7 @@ func TestCreates(t *testing.T) {
 		expectedPS: rectesting.NewPullSubscription(name, testNS,
 			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 				PubSubSpec: v1beta1.PubSubSpec{
 					Secret: &secret,
 					SourceSpec: duckv1.SourceSpec{
@@ -413,6 +414,7 @@ func TestCreates(t *testing.T) {
 			rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -501,6 +503,7 @@ func TestCreates(t *testing.T) {
 		expectedPS: rectesting.NewPullSubscription(name, testNS,
 			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 				PubSubSpec: v1beta1.PubSubSpec{
 					Secret: &secret,
 					SourceSpec: duckv1.SourceSpec{
@@ -523,6 +526,7 @@ func TestCreates(t *testing.T) {
 			rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -563,6 +567,7 @@ func TestCreates(t *testing.T) {
 			rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -601,6 +606,7 @@ func TestCreates(t *testing.T) {
 		expectedPS: rectesting.NewPullSubscription(name, testNS,
 			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 				PubSubSpec: v1beta1.PubSubSpec{
 					Secret: &secret,
 					SourceSpec: duckv1.SourceSpec{
@@ -640,6 +646,7 @@ func TestCreates(t *testing.T) {
 			rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -679,6 +686,7 @@ func TestCreates(t *testing.T) {
 		expectedPS: rectesting.NewPullSubscription(name, testNS,
 			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 				PubSubSpec: v1beta1.PubSubSpec{
 					Secret: &secret,
 					SourceSpec: duckv1.SourceSpec{
@@ -719,6 +727,7 @@ func TestCreates(t *testing.T) {
 			rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -758,6 +767,7 @@ func TestCreates(t *testing.T) {
 		expectedPS: rectesting.NewPullSubscription(name, testNS,
 			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 				PubSubSpec: v1beta1.PubSubSpec{
 					Secret: &secret,
 					SourceSpec: duckv1.SourceSpec{
@@ -798,6 +808,7 @@ func TestCreates(t *testing.T) {
 			rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -837,6 +848,7 @@ func TestCreates(t *testing.T) {
 		expectedPS: rectesting.NewPullSubscription(name, testNS,
 			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 				PubSubSpec: v1beta1.PubSubSpec{
 					Secret: &secret,
 					SourceSpec: duckv1.SourceSpec{
@@ -859,6 +871,7 @@ func TestCreates(t *testing.T) {
 			Object: rectesting.NewPullSubscription(name, testNS,
 				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
 					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
 					PubSubSpec: v1beta1.PubSubSpec{
 						Secret: &secret,
 						SourceSpec: duckv1.SourceSpec{
@@ -877,6 +890,109 @@ func TestCreates(t *testing.T) {
 				rectesting.WithPullSubscriptionReady(oldSink.URI),
 			),
 		}},
+	}, {
+		name: "topic exists and is ready, pullsubscription mode is cleared, switched back to CloudEventsBinary",
+		objects: []runtime.Object{
+			rectesting.NewTopic(name, testNS,
+				rectesting.WithTopicSpec(inteventsv1beta1.TopicSpec{
+					Topic:             testTopicID,
+					PropagationPolicy: "CreateDelete",
+					EnablePublisher:   &falseVal,
+				}),
+				rectesting.WithTopicLabels(map[string]string{
+					"receive-adapter":                     receiveAdapterName,
+					"events.cloud.google.com/source-name": name,
+				}),
+				rectesting.WithTopicOwnerReferences([]metav1.OwnerReference{ownerRef()}),
+				rectesting.WithTopicProjectID(testProjectID),
+				rectesting.WithTopicReadyAndPublisherDeployed(testTopicID),
+				rectesting.WithTopicAddress(testTopicURI),
+				rectesting.WithTopicSetDefaults,
+			),
+			rectesting.NewPullSubscription(name, testNS,
+				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
+					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsStructured,
+					PubSubSpec: v1beta1.PubSubSpec{
+						Secret: &secret,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sink,
+						},
+					},
+				}),
+				rectesting.WithPullSubscriptionLabels(map[string]string{
+					"receive-adapter":                     receiveAdapterName,
+					"events.cloud.google.com/source-name": name,
+				}),
+				rectesting.WithPullSubscriptionAnnotations(map[string]string{
+					"metrics-resource-group": resourceGroup,
+				}),
+				rectesting.WithPullSubscriptionOwnerReferences([]metav1.OwnerReference{ownerRef()}),
+				rectesting.WithPullSubscriptionReady(sink.URI),
+			),
+		},
+		expectedTopic: rectesting.NewTopic(name, testNS,
+			rectesting.WithTopicSpec(inteventsv1beta1.TopicSpec{
+				Secret:            &secret,
+				Topic:             testTopicID,
+				PropagationPolicy: "CreateDelete",
+				EnablePublisher:   &falseVal,
+			}),
+			rectesting.WithTopicLabels(map[string]string{
+				"receive-adapter":                     receiveAdapterName,
+				"events.cloud.google.com/source-name": name,
+			}),
+			rectesting.WithTopicOwnerReferences([]metav1.OwnerReference{ownerRef()}),
+			rectesting.WithTopicReadyAndPublisherDeployed(testTopicID),
+			rectesting.WithTopicProjectID(testProjectID),
+			rectesting.WithTopicAddress(testTopicURI),
+			rectesting.WithTopicOwnerReferences([]metav1.OwnerReference{ownerRef()}),
+			rectesting.WithTopicSetDefaults,
+		),
+		expectedPS: rectesting.NewPullSubscription(name, testNS,
+			rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
+				Topic: testTopicID,
+				Mode:  inteventsv1beta1.ModeCloudEventsBinary,
+				PubSubSpec: v1beta1.PubSubSpec{
+					Secret: &secret,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: sink,
+					},
+				},
+			}),
+			rectesting.WithPullSubscriptionLabels(map[string]string{
+				"receive-adapter":                     receiveAdapterName,
+				"events.cloud.google.com/source-name": name,
+			}),
+			rectesting.WithPullSubscriptionAnnotations(map[string]string{
+				"metrics-resource-group": resourceGroup,
+			}),
+			rectesting.WithPullSubscriptionOwnerReferences([]metav1.OwnerReference{ownerRef()}),
+			rectesting.WithPullSubscriptionReady(sink.URI),
+		),
+		wantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rectesting.NewPullSubscription(name, testNS,
+				rectesting.WithPullSubscriptionSpec(inteventsv1beta1.PullSubscriptionSpec{
+					Topic: testTopicID,
+					Mode:  inteventsv1beta1.ModeCloudEventsBinary,
+					PubSubSpec: v1beta1.PubSubSpec{
+						Secret: &secret,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sink,
+						},
+					},
+				}),
+				rectesting.WithPullSubscriptionLabels(map[string]string{
+					"receive-adapter":                     receiveAdapterName,
+					"events.cloud.google.com/source-name": name,
+				}),
+				rectesting.WithPullSubscriptionAnnotations(map[string]string{
+					"metrics-resource-group": resourceGroup,
+				}),
+				rectesting.WithPullSubscriptionOwnerReferences([]metav1.OwnerReference{ownerRef()}),
+				rectesting.WithPullSubscriptionReady(sink.URI),
+			),
+		}},
 	}}
 
 	defer logtesting.ClearAll()
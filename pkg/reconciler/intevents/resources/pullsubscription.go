@@ -53,8 +53,9 @@ func MakePullSubscription(args *PullSubscriptionArgs) *inteventsv1beta1.PullSubs
 				IdentitySpec: duckv1beta1.IdentitySpec{
 					ServiceAccountName: args.Spec.IdentitySpec.ServiceAccountName,
 				},
-				Secret:  args.Spec.Secret,
-				Project: args.Spec.Project,
+				Secret:   args.Spec.Secret,
+				Project:  args.Spec.Project,
+				Delivery: args.Spec.Delivery,
 				SourceSpec: duckv1.SourceSpec{
 					Sink: args.Spec.SourceSpec.Sink,
 				},
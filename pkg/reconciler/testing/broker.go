@@ -23,6 +23,7 @@ import (
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/pkg/apis"
 )
@@ -145,6 +146,22 @@ func WithBrokerTopicReady(b *brokerv1beta1.Broker) {
 	b.Status.MarkTopicReady()
 }
 
+func WithBrokerTargetsConfigApplied(b *brokerv1beta1.Broker) {
+	b.Status.MarkTargetsConfigApplied()
+}
+
+func WithBrokerTargetsConfigPropagating(reason, msg string) BrokerOption {
+	return func(b *brokerv1beta1.Broker) {
+		b.Status.MarkTargetsConfigPropagating(reason, msg)
+	}
+}
+
+func WithBrokerReplayed(r *brokerv1beta1.Replay) BrokerOption {
+	return func(b *brokerv1beta1.Broker) {
+		b.Status.MarkReplayed(r)
+	}
+}
+
 func WithBrokerClass(bc string) BrokerOption {
 	return func(b *brokerv1beta1.Broker) {
 		annotations := b.GetAnnotations()
@@ -156,6 +173,23 @@ func WithBrokerClass(bc string) BrokerOption {
 	}
 }
 
+func WithBrokerAnnotation(key, value string) BrokerOption {
+	return func(b *brokerv1beta1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[key] = value
+		b.SetAnnotations(annotations)
+	}
+}
+
+func WithBrokerDeliverySpec(ds *eventingduckv1beta1.DeliverySpec) BrokerOption {
+	return func(b *brokerv1beta1.Broker) {
+		b.Spec.Delivery = ds
+	}
+}
+
 func WithBrokerSetDefaults(b *brokerv1beta1.Broker) {
 	b.SetDefaults(context.Background())
 }
@@ -48,6 +48,24 @@ func WithBrokerCellAnnotations(annotations map[string]string) BrokerCellOption {
 	}
 }
 
+func WithBrokerCellBrokerNamespace(namespace string) BrokerCellOption {
+	return func(bc *intv1alpha1.BrokerCell) {
+		bc.Spec.BrokerNamespace = namespace
+	}
+}
+
+func WithBrokerCellFanout(params *intv1alpha1.ComponentParameters) BrokerCellOption {
+	return func(bc *intv1alpha1.BrokerCell) {
+		bc.Spec.Fanout = params
+	}
+}
+
+func WithBrokerCellRetry(params *intv1alpha1.ComponentParameters) BrokerCellOption {
+	return func(bc *intv1alpha1.BrokerCell) {
+		bc.Spec.Retry = params
+	}
+}
+
 // WithInitBrokerCellConditions initializes the BrokerCell's conditions.
 func WithInitBrokerCellConditions(bc *intv1alpha1.BrokerCell) {
 	bc.Status.InitializeConditions()
@@ -82,6 +100,30 @@ func WithIngressTemplate(address string) BrokerCellOption {
 	}
 }
 
+// WithTargetsConfigGeneration sets the generation of the targets config in the BrokerCell status.
+func WithTargetsConfigGeneration(generation int64) BrokerCellOption {
+	return func(bc *intv1alpha1.BrokerCell) {
+		bc.Status.TargetsConfigGeneration = generation
+	}
+}
+
+// WithTargetsConfigAppliedPods sets the number of data plane pods that applied the generation
+// of the targets config out of all pods in the BrokerCell status.
+func WithTargetsConfigAppliedPods(applied, total int32) BrokerCellOption {
+	return func(bc *intv1alpha1.BrokerCell) {
+		bc.Status.TargetsConfigAppliedPods = applied
+		bc.Status.TargetsConfigPods = total
+	}
+}
+
+// WithOpenCircuitBreaker adds the target whose circuit breaker is open on the pods to the
+// BrokerCell status.
+func WithOpenCircuitBreaker(target string, pods ...string) BrokerCellOption {
+	return func(bc *intv1alpha1.BrokerCell) {
+		bc.Status.OpenCircuitBreakers = append(bc.Status.OpenCircuitBreakers, intv1alpha1.OpenCircuitBreaker{Target: target, Pods: pods})
+	}
+}
+
 func WithBrokerCellReady(bc *intv1alpha1.BrokerCell) {
 	bc.Status = *intv1alpha1.TestHelper.ReadyBrokerCellStatus()
 }
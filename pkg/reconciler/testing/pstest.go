@@ -59,6 +59,25 @@ func TopicAndSub(tid, sid string) PubsubAction {
 	}
 }
 
+// RetryTopicAndSub creates a topic and a subscription with a dead letter policy that
+// publishes back to the topic, like the retry topic and subscription of a trigger.
+func RetryTopicAndSub(tid, sid string, maxDeliveryAttempts int) PubsubAction {
+	return func(ctx context.Context, t *testing.T, c *pubsub.Client) {
+		Topic(tid)(ctx, t, c)
+		_, err := c.CreateSubscription(ctx, sid, pubsub.SubscriptionConfig{
+			Topic: c.Topic(tid),
+			DeadLetterPolicy: &pubsub.DeadLetterPolicy{
+				DeadLetterTopic:     c.Topic(tid).String(),
+				MaxDeliveryAttempts: maxDeliveryAttempts,
+			},
+		})
+		if err != nil {
+			t.Fatalf("Error creating subscription %q: %v", sid, err)
+		}
+		t.Logf("Created subscription %q", sid)
+	}
+}
+
 func TopicExists(id string) func(*testing.T, *rtesting.TableRow) {
 	return func(t *testing.T, r *rtesting.TableRow) {
 		c := getPubsubClient(r)
@@ -113,6 +132,26 @@ func SubscriptionExists(id string) func(*testing.T, *rtesting.TableRow) {
 	}
 }
 
+// SubscriptionHasDeadLetterPolicy checks that the subscription dead letters its
+// messages to the topic after the given max delivery attempts.
+func SubscriptionHasDeadLetterPolicy(id, tid string, maxDeliveryAttempts int) func(*testing.T, *rtesting.TableRow) {
+	return func(t *testing.T, r *rtesting.TableRow) {
+		c := getPubsubClient(r)
+		config, err := c.Subscription(id).Config(context.Background())
+		if err != nil {
+			t.Errorf("Error getting subscription config: %v", err)
+			return
+		}
+		want := pubsub.DeadLetterPolicy{
+			DeadLetterTopic:     c.Topic(tid).String(),
+			MaxDeliveryAttempts: maxDeliveryAttempts,
+		}
+		if config.DeadLetterPolicy == nil || *config.DeadLetterPolicy != want {
+			t.Errorf("Unexpected dead letter policy of subscription %q, got: %+v, want: %+v", id, config.DeadLetterPolicy, want)
+		}
+	}
+}
+
 func OnlySubscriptions(ids ...string) func(*testing.T, *rtesting.TableRow) {
 	return func(t *testing.T, r *rtesting.TableRow) {
 		c := getPubsubClient(r)
@@ -151,6 +151,90 @@ func WithDependencyAnnotation(dependencyAnnotation string) TriggerOption {
 	}
 }
 
+func WithTriggerCircuitBreakerAnnotation(threshold string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[brokerv1beta1.CircuitBreakerThresholdAnnotationKey] = threshold
+	}
+}
+
+func WithTriggerReplyDestinationAnnotation(dest string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[brokerv1beta1.ReplyDestinationAnnotationKey] = dest
+	}
+}
+
+func WithTriggerReplayTimeAnnotation(replayTime string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[brokerv1beta1.TriggerReplayTimeAnnotationKey] = replayTime
+	}
+}
+
+func WithTriggerIsolated(t *brokerv1beta1.Trigger) {
+	if t.Annotations == nil {
+		t.Annotations = make(map[string]string)
+	}
+	t.Annotations[brokerv1beta1.IsolatedAnnotationKey] = "true"
+}
+
+func WithTriggerIsolatedSubscription(subID string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkIsolatedSubscription(subID)
+	}
+}
+
+func WithTriggerReplayed(r *brokerv1beta1.Replay) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkReplayed(r)
+	}
+}
+
+func WithTriggerReplayFailed(reason, message string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkReplayFailed(reason, message)
+	}
+}
+
+func WithTriggerCircuitBreakerClosed(t *brokerv1beta1.Trigger) {
+	t.Status.MarkCircuitBreakerClosed()
+}
+
+func WithTriggerCircuitBreakerOpen(reason, message string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkCircuitBreakerOpen(reason, message)
+	}
+}
+
+func WithTriggerTargetsConfigApplied(t *brokerv1beta1.Trigger) {
+	t.Status.MarkTargetsConfigApplied()
+}
+
+func WithTriggerTargetsConfigPropagating(reason, message string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkTargetsConfigPropagating(reason, message)
+	}
+}
+
+func WithTriggerReplyResolved(address string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkReplyResolved(address)
+	}
+}
+
+func WithTriggerReplyResolveFailed(reason, message string) TriggerOption {
+	return func(t *brokerv1beta1.Trigger) {
+		t.Status.MarkReplyResolveFailed(reason, message)
+	}
+}
+
 func WithTriggerDependencyReady(t *brokerv1beta1.Trigger) {
 	t.Status.MarkDependencySucceeded()
 }
@@ -22,6 +22,7 @@ import (
 
 	"cloud.google.com/go/pubsub"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 
@@ -36,13 +37,17 @@ import (
 	pkgcontroller "knative.dev/pkg/controller"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/system"
 
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	inteventsv1alpha1 "github.com/google/knative-gcp/pkg/apis/intevents/v1alpha1"
 	brokerinformer "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/broker"
 	triggerinformer "github.com/google/knative-gcp/pkg/client/injection/informers/broker/v1beta1/trigger"
+	brokercellinformer "github.com/google/knative-gcp/pkg/client/injection/informers/intevents/v1alpha1/brokercell"
 	triggerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/trigger"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
+	brokerresources "github.com/google/knative-gcp/pkg/reconciler/broker/resources"
 	"github.com/google/knative-gcp/pkg/utils"
 )
 
@@ -82,10 +87,11 @@ func NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl
 	}
 
 	r := &Reconciler{
-		Base:         reconciler.NewBase(ctx, controllerAgentName, cmw),
-		brokerLister: brokerinformer.Get(ctx).Lister(),
-		pubsubClient: client,
-		projectID:    projectID,
+		Base:             reconciler.NewBase(ctx, controllerAgentName, cmw),
+		brokerLister:     brokerinformer.Get(ctx).Lister(),
+		brokerCellLister: brokercellinformer.Get(ctx).Lister(),
+		pubsubClient:     client,
+		projectID:        projectID,
 	}
 
 	impl := triggerreconciler.NewImpl(ctx, r, withAgentAndFinalizer)
@@ -117,6 +123,55 @@ func NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl
 		},
 	)
 
+	// enqueueTriggersOn enqueues the triggers of the brokers placed on the brokercell in the
+	// system namespace.
+	enqueueTriggersOn := func(bcName string) {
+		brokers, err := brokerinformer.Get(ctx).Lister().List(labels.Everything())
+		if err != nil {
+			r.Logger.Warn("Failed to list brokers", zap.Error(err))
+			return
+		}
+		for _, b := range brokers {
+			if !filterBroker(b) || brokerresources.BrokerCellName(b) != bcName {
+				continue
+			}
+			triggers, err := triggerInformer.Lister().Triggers(b.Namespace).List(labels.SelectorFromSet(map[string]string{eventing.BrokerLabelKey: b.Name}))
+			if err != nil {
+				r.Logger.Warn("Failed to list triggers", zap.String("Namespace", b.Namespace), zap.String("Broker", b.Name))
+				continue
+			}
+			for _, trigger := range triggers {
+				impl.Enqueue(trigger)
+			}
+		}
+	}
+
+	// Watch brokercells for the propagation of the targets config to their pods and the
+	// circuit breakers the pods report open. Only the triggers of the brokers placed on the
+	// brokercell are enqueued.
+	brokercellinformer.Get(ctx).Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.NamespaceFilterFunc(system.Namespace()),
+		Handler: cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldBC, ok := oldObj.(*inteventsv1alpha1.BrokerCell)
+				if !ok {
+					return
+				}
+				newBC, ok := newObj.(*inteventsv1alpha1.BrokerCell)
+				if !ok {
+					return
+				}
+				if oldBC.Status.TargetsConfigGeneration == newBC.Status.TargetsConfigGeneration &&
+					oldBC.Status.TargetsConfigAppliedPods == newBC.Status.TargetsConfigAppliedPods &&
+					oldBC.Status.TargetsConfigPods == newBC.Status.TargetsConfigPods &&
+					equality.Semantic.DeepEqual(oldBC.Status.OpenCircuitBreakers, newBC.Status.OpenCircuitBreakers) {
+					return
+				}
+				enqueueTriggersOn(newBC.Name)
+			},
+		},
+	})
+
 	return impl
 }
 
@@ -19,6 +19,8 @@ package trigger
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -27,14 +29,18 @@ import (
 
 	"knative.dev/eventing/pkg/duck"
 	"knative.dev/eventing/pkg/logging"
+	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/system"
 
 	"cloud.google.com/go/pubsub"
 	brokerv1beta1 "github.com/google/knative-gcp/pkg/apis/broker/v1beta1"
+	"github.com/google/knative-gcp/pkg/broker/config"
 	triggerreconciler "github.com/google/knative-gcp/pkg/client/injection/reconciler/broker/v1beta1/trigger"
 	brokerlisters "github.com/google/knative-gcp/pkg/client/listers/broker/v1beta1"
+	inteventslisters "github.com/google/knative-gcp/pkg/client/listers/intevents/v1alpha1"
 	metadataClient "github.com/google/knative-gcp/pkg/gclient/metadata"
 	"github.com/google/knative-gcp/pkg/reconciler"
 	"github.com/google/knative-gcp/pkg/reconciler/broker/resources"
@@ -53,7 +59,8 @@ const (
 type Reconciler struct {
 	*reconciler.Base
 
-	brokerLister brokerlisters.BrokerLister
+	brokerLister     brokerlisters.BrokerLister
+	brokerCellLister inteventslisters.BrokerCellLister
 
 	// Dynamic tracker to track KResources. It tracks the dependency between Triggers and Sources.
 	kresourceTracker duck.ListableTracker
@@ -106,7 +113,9 @@ func (r *Reconciler) reconcile(ctx context.Context, t *brokerv1beta1.Trigger, b
 		return err
 	}
 
-	if err := r.reconcileRetryTopicAndSubscription(ctx, t); err != nil {
+	r.resolveReply(ctx, t)
+
+	if err := r.reconcileRetryTopicAndSubscription(ctx, t, b); err != nil {
 		return err
 	}
 
@@ -114,6 +123,14 @@ func (r *Reconciler) reconcile(ctx context.Context, t *brokerv1beta1.Trigger, b
 		return err
 	}
 
+	if err := r.propagateCircuitBreakerStatus(t, b); err != nil {
+		return err
+	}
+
+	if err := r.propagateTargetsConfigStatus(t, b); err != nil {
+		return err
+	}
+
 	return pkgreconciler.NewEvent(corev1.EventTypeNormal, triggerReconciled, "Trigger reconciled: \"%s/%s\"", t.Namespace, t.Name)
 }
 
@@ -155,6 +172,36 @@ func (r *Reconciler) resolveSubscriber(ctx context.Context, t *brokerv1beta1.Tri
 	return nil
 }
 
+// resolveReply resolves the reply destination of the Trigger. While it cannot be resolved, the
+// replies are sent to the address it was last resolved to, or dropped if it never was.
+func (r *Reconciler) resolveReply(ctx context.Context, t *brokerv1beta1.Trigger) {
+	dest, err := t.GetReplyDestination()
+	if err == nil && dest == nil {
+		t.Status.ClearReplyResolved()
+		return
+	}
+	var replyURI *apis.URL
+	if err == nil {
+		if dest.Ref != nil {
+			// The reply destination ref must be in the same namespace as the Trigger.
+			ref := *dest.Ref
+			ref.Namespace = t.Namespace
+			dest.Ref = &ref
+		}
+		replyURI, err = r.uriResolver.URIFromDestinationV1(*dest, t)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Unable to resolve the reply destination URI", zap.Error(err))
+		if address := t.Status.ReplyAddress(); address != "" {
+			t.Status.MarkReplyResolveFailed("ReplyUnresolved", "Unable to resolve the reply destination URI, replies are sent to %s: %v", address, err)
+		} else {
+			t.Status.MarkReplyResolveFailed("ReplyUnresolved", "Unable to resolve the reply destination URI, replies are dropped: %v", err)
+		}
+		return
+	}
+	t.Status.MarkReplyResolved(replyURI.String())
+}
+
 // hasGCPBrokerFinalizer checks if the Trigger object has a finalizer matching the one added by this controller.
 func hasGCPBrokerFinalizer(t *brokerv1beta1.Trigger) bool {
 	for _, f := range t.Finalizers {
@@ -165,7 +212,7 @@ func hasGCPBrokerFinalizer(t *brokerv1beta1.Trigger) bool {
 	return false
 }
 
-func (r *Reconciler) reconcileRetryTopicAndSubscription(ctx context.Context, trig *brokerv1beta1.Trigger) error {
+func (r *Reconciler) reconcileRetryTopicAndSubscription(ctx context.Context, trig *brokerv1beta1.Trigger, b *brokerv1beta1.Broker) error {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Reconciling retry topic")
 	// get ProjectID from metadata
@@ -215,16 +262,32 @@ func (r *Reconciler) reconcileRetryTopicAndSubscription(ctx context.Context, tri
 
 	// Check if PullSub exists, and if not, create it.
 	subID := resources.GenerateRetrySubscriptionName(trig)
+	retention, _ := b.GetRetentionDuration()
 	subConfig := pubsub.SubscriptionConfig{
 		Topic:  topic,
-		Labels: labels,
+		Labels: resources.SubscriptionLabels(labels, b, resources.RetrySubscriptionComponent),
+		// Acked events are only retained for replay if the broker has a retention duration.
+		RetainAckedMessages: retention != 0,
+		RetentionDuration:   retention,
+		// The delivery attempts of the retry queue are tracked by Pub/Sub.
+		DeadLetterPolicy: resources.MakeRetryDeadLetterPolicy(topic),
+		// Ordered brokers retry the events of each trigger in order. Message ordering
+		// can only be set when the subscription is created.
+		EnableMessageOrdering: b.GetOrderingKeyAttribute() != "",
 		//TODO(grantr): configure these settings?
 		// AckDeadline
-		// RetentionDuration
 	}
 	if _, err := pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, trig, &trig.Status); err != nil {
 		return err
 	}
+
+	if err := reconcileIsolatedSubscription(ctx, client, pubsubReconciler, trig, b, labels, retention); err != nil {
+		return err
+	}
+
+	if err := reconcileReplay(ctx, pubsubReconciler, trig, retention); err != nil {
+		return err
+	}
 	// TODO(grantr): this isn't actually persisted due to webhook issues.
 	//TODO uncomment when eventing webhook allows this
 	//trig.Status.SubscriptionID = sub.ID()
@@ -232,6 +295,66 @@ func (r *Reconciler) reconcileRetryTopicAndSubscription(ctx context.Context, tri
 	return nil
 }
 
+// reconcileIsolatedSubscription creates the subscription of an isolated trigger on the
+// decoupling topic of its broker, and deletes it once the trigger is no longer isolated.
+// The events the deleted subscription didn't deliver yet are dropped.
+func reconcileIsolatedSubscription(ctx context.Context, client *pubsub.Client, pubsubReconciler *reconcilerutilspubsub.Reconciler, trig *brokerv1beta1.Trigger, b *brokerv1beta1.Broker, labels map[string]string, retention time.Duration) error {
+	if !trig.IsIsolated() {
+		subID := trig.Status.IsolatedSubscription()
+		if subID == "" {
+			return nil
+		}
+		if err := pubsubReconciler.DeleteSubscription(ctx, subID, trig, &trig.Status); err != nil {
+			return err
+		}
+		trig.Status.ClearIsolatedSubscription()
+		return nil
+	}
+	subID := resources.GenerateIsolatedSubscriptionName(trig)
+	subConfig := pubsub.SubscriptionConfig{
+		Topic:  client.Topic(resources.GenerateDecouplingTopicName(b)),
+		Labels: resources.SubscriptionLabels(labels, b, resources.FanoutSubscriptionComponent),
+		// Isolated triggers get the events in the same order as the broker fanout.
+		EnableMessageOrdering: b.GetOrderingKeyAttribute() != "",
+		RetainAckedMessages:   retention != 0,
+		RetentionDuration:     retention,
+	}
+	if _, err := pubsubReconciler.ReconcileSubscription(ctx, subID, subConfig, trig, &trig.Status); err != nil {
+		return err
+	}
+	trig.Status.MarkIsolatedSubscription(subID)
+	return nil
+}
+
+// reconcileReplay seeks the isolated subscription of the trigger to the replay requested on
+// it, unless it was already applied. Other triggers share the decoupling subscription of
+// their broker and can't be replayed on their own.
+func reconcileReplay(ctx context.Context, pubsubReconciler *reconcilerutilspubsub.Reconciler, trig *brokerv1beta1.Trigger, retention time.Duration) error {
+	replay, _ := trig.GetReplay()
+	if replay == nil {
+		trig.Status.ClearReplayed()
+		return nil
+	}
+	if trig.Status.IsReplayed(replay) {
+		return nil
+	}
+	if !trig.IsIsolated() {
+		trig.Status.MarkReplayFailed("TriggerNotIsolated", "Only isolated triggers can be replayed")
+		return nil
+	}
+	if replay.Snapshot == "" && retention == 0 {
+		trig.Status.MarkReplayFailed("RetentionNotSet", "Replaying to a time requires the broker annotation %s", brokerv1beta1.BrokerRetentionDurationAnnotationKey)
+		return nil
+	}
+	subID := resources.GenerateIsolatedSubscriptionName(trig)
+	if err := pubsubReconciler.SeekSubscription(ctx, subID, replay.Time, replay.Snapshot, trig); err != nil {
+		trig.Status.MarkReplayFailed("SubscriptionSeekFailed", "Failed to seek subscription to %s: %v", replay, err)
+		return err
+	}
+	trig.Status.MarkReplayed(replay)
+	return nil
+}
+
 func (r *Reconciler) deleteRetryTopicAndSubscription(ctx context.Context, trig *brokerv1beta1.Trigger) error {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Deleting retry topic")
@@ -266,6 +389,9 @@ func (r *Reconciler) deleteRetryTopicAndSubscription(ctx context.Context, trig *
 	// Delete pull subscription if it exists.
 	subID := resources.GenerateRetrySubscriptionName(trig)
 	err = multierr.Append(nil, pubsubReconciler.DeleteSubscription(ctx, subID, trig, &trig.Status))
+	// Delete the subscription of the trigger on the decoupling topic if it was isolated.
+	isolatedSubID := resources.GenerateIsolatedSubscriptionName(trig)
+	err = multierr.Append(err, pubsubReconciler.DeleteSubscription(ctx, isolatedSubID, trig, &trig.Status))
 	return err
 }
 
@@ -320,3 +446,62 @@ func (r *Reconciler) propagateDependencyReadiness(ctx context.Context, t *broker
 	t.Status.PropagateDependencyStatus(dependency)
 	return nil
 }
+
+// propagateCircuitBreakerStatus reflects the circuit breakers that the broker data plane pods
+// report open for the trigger.
+func (r *Reconciler) propagateCircuitBreakerStatus(t *brokerv1beta1.Trigger, b *brokerv1beta1.Broker) error {
+	if cb, _ := t.GetCircuitBreaker(); cb == nil {
+		t.Status.ClearCircuitBreaker()
+		return nil
+	}
+	bc, err := r.brokerCellLister.BrokerCells(system.Namespace()).Get(resources.BrokerCellName(b))
+	if apierrs.IsNotFound(err) {
+		t.Status.MarkCircuitBreakerClosed()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting brokercell: %w", err)
+	}
+	// The brokercell reconciler collects the circuit breakers its pods report open in its status.
+	key := config.TriggerKey(t.Namespace, t.Spec.Broker, t.Name)
+	var open []string
+	for _, cb := range bc.Status.OpenCircuitBreakers {
+		if cb.Target == key {
+			open = cb.Pods
+			break
+		}
+	}
+	if len(open) == 0 {
+		t.Status.MarkCircuitBreakerClosed()
+		return nil
+	}
+	t.Status.MarkCircuitBreakerOpen("CircuitBreakerOpen", "Deliveries to the subscriber are sent to the retry queue on pods: %s", strings.Join(open, ", "))
+	return nil
+}
+
+// propagateTargetsConfigStatus reflects how many data plane pods of the broker's BrokerCell
+// applied its latest targets config generation.
+func (r *Reconciler) propagateTargetsConfigStatus(t *brokerv1beta1.Trigger, b *brokerv1beta1.Broker) error {
+	bc, err := r.brokerCellLister.BrokerCells(system.Namespace()).Get(resources.BrokerCellName(b))
+	if apierrs.IsNotFound(err) {
+		t.Status.ClearTargetsConfigApplied()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting brokercell: %w", err)
+	}
+	// The brokercell reconciler summarizes the propagation to its pods in its status. No pods
+	// are counted when they don't report the generation they applied.
+	generation := bc.Status.TargetsConfigGeneration
+	applied, total := bc.Status.TargetsConfigAppliedPods, bc.Status.TargetsConfigPods
+	if generation == 0 || total == 0 {
+		t.Status.ClearTargetsConfigApplied()
+		return nil
+	}
+	if applied < total {
+		t.Status.MarkTargetsConfigPropagating("TargetsConfigPropagating", "Targets config generation %d applied on %d/%d pods", generation, applied, total)
+		return nil
+	}
+	t.Status.MarkTargetsConfigApplied()
+	return nil
+}
@@ -19,6 +19,8 @@ package pubsub
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"go.uber.org/zap"
@@ -32,7 +34,13 @@ const (
 	// See https://cloud.google.com/pubsub/docs/reference/rpc/google.pubsub.v1#subscription
 	deletedTopic = "_deleted-topic_"
 	subCreated   = "SubscriptionCreated"
+	subUpdated   = "SubscriptionUpdated"
 	subDeleted   = "SubscriptionDeleted"
+	subSeeked    = "SubscriptionSeeked"
+
+	// defaultRetentionDuration is the message retention duration Pub/Sub uses when the
+	// subscription config doesn't set one.
+	defaultRetentionDuration = 7 * 24 * time.Hour
 )
 
 func (r *Reconciler) ReconcileSubscription(ctx context.Context, id string, subConfig pubsub.SubscriptionConfig, obj runtime.Object, updater StatusUpdater) (*pubsub.Subscription, error) {
@@ -64,6 +72,15 @@ func (r *Reconciler) ReconcileSubscription(ctx context.Context, id string, subCo
 			}
 			return r.createSubscription(ctx, id, subConfig, obj, updater)
 		}
+		if update, ok := subscriptionUpdate(config, subConfig); ok {
+			if _, err := sub.Update(ctx, update); err != nil {
+				logger.Error("Failed to update Pub/Sub subscription", zap.Error(err))
+				updater.MarkSubscriptionFailed("SubscriptionUpdateFailed", "Failed to update Pub/Sub subscription: %w", err)
+				return nil, err
+			}
+			logger.Info("Updated PubSub subscription", zap.String("name", sub.ID()))
+			r.recorder.Eventf(obj, corev1.EventTypeNormal, subUpdated, "Updated PubSub subscription %q", sub.ID())
+		}
 		updater.MarkSubscriptionReady()
 		return sub, nil
 	}
@@ -71,6 +88,54 @@ func (r *Reconciler) ReconcileSubscription(ctx context.Context, id string, subCo
 	return r.createSubscription(ctx, id, subConfig, obj, updater)
 }
 
+// subscriptionUpdate returns the update that applies the retention settings, the dead
+// letter policy and the labels of the desired config to an existing subscription, if they
+// differ. The dead letter policy and the labels are left unchanged if the desired config
+// has none.
+func subscriptionUpdate(got pubsub.SubscriptionConfig, want pubsub.SubscriptionConfig) (pubsub.SubscriptionConfigToUpdate, bool) {
+	var update pubsub.SubscriptionConfigToUpdate
+	changed := false
+	wantRetention := want.RetentionDuration
+	if wantRetention == 0 {
+		wantRetention = defaultRetentionDuration
+	}
+	if got.RetainAckedMessages != want.RetainAckedMessages || got.RetentionDuration != wantRetention {
+		update.RetainAckedMessages = want.RetainAckedMessages
+		update.RetentionDuration = wantRetention
+		changed = true
+	}
+	if want.DeadLetterPolicy != nil && (got.DeadLetterPolicy == nil || *got.DeadLetterPolicy != *want.DeadLetterPolicy) {
+		update.DeadLetterPolicy = want.DeadLetterPolicy
+		changed = true
+	}
+	if want.Labels != nil && !reflect.DeepEqual(got.Labels, want.Labels) {
+		update.Labels = want.Labels
+		changed = true
+	}
+	return update, changed
+}
+
+// SeekSubscription seeks the subscription to the given snapshot or, if the snapshot is
+// empty, to the given time. Retained messages published after the seek target are
+// redelivered.
+func (r *Reconciler) SeekSubscription(ctx context.Context, id string, t time.Time, snapshot string, obj runtime.Object) error {
+	logger := logging.FromContext(ctx)
+	sub := r.client.Subscription(id)
+	var err error
+	if snapshot != "" {
+		err = sub.SeekToSnapshot(ctx, r.client.Snapshot(snapshot))
+	} else {
+		err = sub.SeekToTime(ctx, t)
+	}
+	if err != nil {
+		logger.Error("Failed to seek Pub/Sub subscription", zap.Error(err))
+		return err
+	}
+	logger.Info("Seeked PubSub subscription", zap.String("name", sub.ID()), zap.Time("time", t), zap.String("snapshot", snapshot))
+	r.recorder.Eventf(obj, corev1.EventTypeNormal, subSeeked, "Seeked PubSub subscription %q", sub.ID())
+	return nil
+}
+
 func (r *Reconciler) DeleteSubscription(ctx context.Context, id string, obj runtime.Object, updater StatusUpdater) error {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Deleting decoupling sub")
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	corev1 "k8s.io/api/core/v1"
@@ -78,6 +79,143 @@ func TestReconcileSub(t *testing.T) {
 
 }
 
+func TestReconcileSubRetention(t *testing.T) {
+	tests := []testCase{
+		{
+			name:             "new sub created with retention",
+			pre:              []reconcilertesting.PubsubAction{reconcilertesting.Topic(topic)},
+			wantEvents:       []string{`Normal SubscriptionCreated Created PubSub subscription "test-sub"`},
+			wantSubCondition: apis.Condition{Status: corev1.ConditionTrue},
+		},
+		{
+			name:             "existing sub updated with retention",
+			pre:              []reconcilertesting.PubsubAction{reconcilertesting.TopicAndSub(topic, sub)},
+			wantEvents:       []string{`Normal SubscriptionUpdated Updated PubSub subscription "test-sub"`},
+			wantSubCondition: apis.Condition{Status: corev1.ConditionTrue},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, cleanup := newTestRunner(t, tc)
+			defer cleanup()
+			r := NewReconciler(tr.client, tr.recorder)
+			su := &utilspubsubtesting.StatusUpdater{}
+			subConfig := pubsub.SubscriptionConfig{
+				Topic:               tr.client.Topic(topic),
+				RetainAckedMessages: true,
+				RetentionDuration:   time.Hour,
+			}
+			res, err := r.ReconcileSubscription(context.Background(), sub, subConfig, obj, su)
+
+			tr.verify(t, tc, su, err)
+			gotConfig, err := res.Config(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to get config: %v", err)
+			}
+			if !gotConfig.RetainAckedMessages || gotConfig.RetentionDuration != time.Hour {
+				t.Errorf("Unexpected retention, got: %v %v, want: true %v", gotConfig.RetainAckedMessages, gotConfig.RetentionDuration, time.Hour)
+			}
+		})
+	}
+}
+
+func TestSubscriptionUpdate(t *testing.T) {
+	dlp := &pubsub.DeadLetterPolicy{DeadLetterTopic: "projects/test-project/topics/test-topic", MaxDeliveryAttempts: 100}
+	tests := []struct {
+		name       string
+		got        pubsub.SubscriptionConfig
+		want       pubsub.SubscriptionConfig
+		wantUpdate pubsub.SubscriptionConfigToUpdate
+		wantOK     bool
+	}{{
+		name: "up to date",
+		got:  pubsub.SubscriptionConfig{RetentionDuration: defaultRetentionDuration, DeadLetterPolicy: dlp},
+		want: pubsub.SubscriptionConfig{DeadLetterPolicy: dlp},
+	}, {
+		name: "no desired dead letter policy",
+		got:  pubsub.SubscriptionConfig{RetentionDuration: defaultRetentionDuration, DeadLetterPolicy: dlp},
+		want: pubsub.SubscriptionConfig{},
+	}, {
+		name:       "dead letter policy added",
+		got:        pubsub.SubscriptionConfig{RetentionDuration: defaultRetentionDuration},
+		want:       pubsub.SubscriptionConfig{DeadLetterPolicy: dlp},
+		wantUpdate: pubsub.SubscriptionConfigToUpdate{DeadLetterPolicy: dlp},
+		wantOK:     true,
+	}, {
+		name: "dead letter policy changed",
+		got: pubsub.SubscriptionConfig{
+			RetentionDuration: defaultRetentionDuration,
+			DeadLetterPolicy:  &pubsub.DeadLetterPolicy{DeadLetterTopic: dlp.DeadLetterTopic, MaxDeliveryAttempts: 5},
+		},
+		want:       pubsub.SubscriptionConfig{DeadLetterPolicy: dlp},
+		wantUpdate: pubsub.SubscriptionConfigToUpdate{DeadLetterPolicy: dlp},
+		wantOK:     true,
+	}, {
+		name: "retention changed",
+		got:  pubsub.SubscriptionConfig{RetentionDuration: defaultRetentionDuration},
+		want: pubsub.SubscriptionConfig{RetainAckedMessages: true, RetentionDuration: time.Hour},
+		wantUpdate: pubsub.SubscriptionConfigToUpdate{
+			RetainAckedMessages: true,
+			RetentionDuration:   time.Hour,
+		},
+		wantOK: true,
+	}, {
+		name: "labels unchanged",
+		got:  pubsub.SubscriptionConfig{RetentionDuration: defaultRetentionDuration, Labels: map[string]string{"broker_cell": "default"}},
+		want: pubsub.SubscriptionConfig{Labels: map[string]string{"broker_cell": "default"}},
+	}, {
+		name:       "labels changed",
+		got:        pubsub.SubscriptionConfig{RetentionDuration: defaultRetentionDuration, Labels: map[string]string{"broker_cell": "default"}},
+		want:       pubsub.SubscriptionConfig{Labels: map[string]string{"broker_cell": "team-a"}},
+		wantUpdate: pubsub.SubscriptionConfigToUpdate{Labels: map[string]string{"broker_cell": "team-a"}},
+		wantOK:     true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotUpdate, gotOK := subscriptionUpdate(tc.got, tc.want)
+			if gotOK != tc.wantOK {
+				t.Errorf("Unexpected update needed, got: %v, want: %v", gotOK, tc.wantOK)
+			}
+			if !reflect.DeepEqual(gotUpdate, tc.wantUpdate) {
+				t.Errorf("Unexpected update, got: %+v, want: %+v", gotUpdate, tc.wantUpdate)
+			}
+		})
+	}
+}
+
+func TestSeekSub(t *testing.T) {
+	tests := []struct {
+		testCase
+		wantErr bool
+	}{
+		{
+			testCase: testCase{
+				name:       "sub seeked",
+				pre:        []reconcilertesting.PubsubAction{reconcilertesting.TopicAndSub(topic, sub)},
+				wantEvents: []string{`Normal SubscriptionSeeked Seeked PubSub subscription "test-sub"`},
+			},
+		},
+		{
+			testCase: testCase{
+				name: "sub doesn't exist",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, cleanup := newTestRunner(t, tc.testCase)
+			defer cleanup()
+			r := NewReconciler(tr.client, tr.recorder)
+			err := r.SeekSubscription(context.Background(), sub, time.Now().Add(-time.Hour), "", obj)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Unexpected error, got=%v, wantErr=%v", err, tc.wantErr)
+			}
+			tr.verify(t, tc.testCase, &utilspubsubtesting.StatusUpdater{}, nil)
+		})
+	}
+}
+
 func TestDeleteSub(t *testing.T) {
 	tests := []testCase{
 		{
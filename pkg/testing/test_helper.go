@@ -18,6 +18,10 @@ var (
 	trueVal = true
 	seconds = int64(314)
 
+	maxDeliveryAttempts = int32(10)
+	minimumBackoff      = "1s"
+	maximumBackoff      = "1m"
+
 	CompleteObjectMeta = metav1.ObjectMeta{
 		Name:            "name",
 		GenerateName:    "generateName",
@@ -128,11 +132,19 @@ var (
 		ServiceAccountName: "k8sServiceAccount",
 	}
 
+	CompleteV1beta1DeliverySpec = &duckv1beta1.DeliverySpec{
+		MaxDeliveryAttempts: &maxDeliveryAttempts,
+		DeadLetterSink:      &CompleteDestination,
+		MinimumBackoff:      &minimumBackoff,
+		MaximumBackoff:      &maximumBackoff,
+	}
+
 	CompleteV1beta1PubSubSpec = duckv1beta1.PubSubSpec{
 		SourceSpec:   CompleteSourceSpec,
 		IdentitySpec: CompleteV1beta1IdentitySpec,
 		Secret:       CompleteSecret,
 		Project:      "project",
+		Delivery:     CompleteV1beta1DeliverySpec,
 	}
 
 	CompleteV1beta1IdentityStatus = duckv1beta1.IdentityStatus{